@@ -18,19 +18,26 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"os"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 	"github.com/spf13/cobra"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/server/healthz"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/component-base/metrics/legacyregistry"
 	"k8s.io/component-base/version"
 	"k8s.io/klog/v2"
 
 	synceroptions "github.com/kcp-dev/kcp/cmd/syncer/options"
 	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
 	"github.com/kcp-dev/kcp/pkg/syncer"
+	syncermetrics "github.com/kcp-dev/kcp/pkg/syncer/metrics"
 )
 
 const numThreads = 2
@@ -52,14 +59,21 @@ func NewSyncerCommand() *cobra.Command {
 				return err
 			}
 
-			ctx := genericapiserver.SetupSignalContext()
-			if err := Run(options, ctx); err != nil {
+			ctx, cancel := context.WithCancel(genericapiserver.SetupSignalContext())
+			defer cancel()
+
+			// A replica losing the leader election lease exits, to be restarted as a standby replica.
+			leadershipLost := make(chan struct{})
+			if err := Run(options, ctx, func() { close(leadershipLost) }); err != nil {
 				return err
 			}
 
-			<-ctx.Done()
-
-			return nil
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-leadershipLost:
+				return errors.New("leader election lost")
+			}
 		},
 	}
 
@@ -74,7 +88,7 @@ func NewSyncerCommand() *cobra.Command {
 	return syncerCommand
 }
 
-func Run(options *synceroptions.Options, ctx context.Context) error {
+func Run(options *synceroptions.Options, ctx context.Context, onStoppedLeading func()) error {
 	klog.Infof("Syncing the following resource types: %s", options.SyncedResourceTypes)
 
 	kcpConfigOverrides := &clientcmd.ConfigOverrides{
@@ -89,6 +103,8 @@ func Run(options *synceroptions.Options, ctx context.Context) error {
 
 	upstreamConfig.QPS = options.QPS
 	upstreamConfig.Burst = options.Burst
+	// Pick up rotated credentials without restarting.
+	upstreamConfig = syncer.WithKubeconfigTokenReload(upstreamConfig, options.FromKubeconfig, options.FromContext)
 
 	downstreamConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		&clientcmd.ClientConfigLoadingRules{ExplicitPath: options.ToKubeconfig},
@@ -102,14 +118,41 @@ func Run(options *synceroptions.Options, ctx context.Context) error {
 	downstreamConfig.QPS = options.QPS
 	downstreamConfig.Burst = options.Burst
 
+	informersSyncedCheck := syncer.NewInformersSyncedCheck()
+	if options.MetricsBindAddress != "" {
+		syncermetrics.Register()
+		serveMetricsAndHealth(ctx, options.MetricsBindAddress, informersSyncedCheck)
+	}
+
+	var leaderElection *syncer.LeaderElectionConfig
+	if options.LeaderElect {
+		identity, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+		leaderElection = &syncer.LeaderElectionConfig{
+			Namespace:        options.LeaderElectionNamespace,
+			Name:             "kcp-syncer-" + options.SyncTargetName,
+			Identity:         identity + "_" + string(uuid.NewUUID()),
+			LeaseDuration:    options.LeaderElectionLeaseDuration,
+			RenewDeadline:    options.LeaderElectionRenewDeadline,
+			RetryPeriod:      options.LeaderElectionRetryPeriod,
+			OnStoppedLeading: onStoppedLeading,
+		}
+	}
+
 	if err := syncer.StartSyncer(
 		ctx,
 		&syncer.SyncerConfig{
-			UpstreamConfig:      upstreamConfig,
-			DownstreamConfig:    downstreamConfig,
-			ResourcesToSync:     sets.NewString(options.SyncedResourceTypes...),
-			SyncTargetWorkspace: logicalcluster.New(options.FromClusterName),
-			SyncTargetName:      options.SyncTargetName,
+			UpstreamConfig:       upstreamConfig,
+			DownstreamConfig:     downstreamConfig,
+			ResourcesToSync:      sets.NewString(options.SyncedResourceTypes...),
+			ResourcesToUpsync:    sets.NewString(options.UpsyncedResourceTypes...),
+			SyncTargetWorkspace:  logicalcluster.New(options.FromClusterName),
+			SyncTargetName:       options.SyncTargetName,
+			LeaderElection:       leaderElection,
+			DryRun:               options.DryRun,
+			InformersSyncedCheck: informersSyncedCheck,
 		},
 		numThreads,
 		options.APIImportPollInterval,
@@ -119,3 +162,26 @@ func Run(options *synceroptions.Options, ctx context.Context) error {
 
 	return nil
 }
+
+// serveMetricsAndHealth serves the metrics, and the liveness and readiness probes, on the given address
+// until ctx is done. The syncer is ready once its informers have synced.
+func serveMetricsAndHealth(ctx context.Context, address string, informersSyncedCheck healthz.HealthChecker) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", legacyregistry.Handler())
+	healthz.InstallHandler(mux)
+	healthz.InstallReadyzHandler(mux, informersSyncedCheck)
+
+	server := &http.Server{Addr: address, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		if err := server.Close(); err != nil {
+			klog.Errorf("Failed to stop the metrics server: %v", err)
+		}
+	}()
+	go func() {
+		klog.Infof("Serving metrics and health probes on %s", address)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.Errorf("Failed to serve metrics and health probes on %s: %v", address, err)
+		}
+	}()
+}
@@ -30,18 +30,27 @@ import (
 )
 
 type Options struct {
-	QPS                 float32
-	Burst               int
-	FromKubeconfig      string
-	FromContext         string
-	FromClusterName     string
-	ToKubeconfig        string
-	ToContext           string
-	SyncTargetName      string
-	Logs                *logs.Options
-	SyncedResourceTypes []string
+	QPS                   float32
+	Burst                 int
+	FromKubeconfig        string
+	FromContext           string
+	FromClusterName       string
+	ToKubeconfig          string
+	ToContext             string
+	SyncTargetName        string
+	Logs                  *logs.Options
+	SyncedResourceTypes   []string
+	UpsyncedResourceTypes []string
 
 	APIImportPollInterval time.Duration
+	MetricsBindAddress    string
+	DryRun                bool
+
+	LeaderElect                 bool
+	LeaderElectionNamespace     string
+	LeaderElectionLeaseDuration time.Duration
+	LeaderElectionRenewDeadline time.Duration
+	LeaderElectionRetryPeriod   time.Duration
 }
 
 func NewOptions() *Options {
@@ -53,8 +62,13 @@ func NewOptions() *Options {
 		QPS:                   30,
 		Burst:                 20,
 		SyncedResourceTypes:   []string{},
+		UpsyncedResourceTypes: []string{},
 		Logs:                  logs,
 		APIImportPollInterval: 1 * time.Minute,
+
+		LeaderElectionLeaseDuration: 15 * time.Second,
+		LeaderElectionRenewDeadline: 10 * time.Second,
+		LeaderElectionRetryPeriod:   2 * time.Second,
 	}
 }
 
@@ -69,7 +83,15 @@ func (options *Options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&options.SyncTargetName, "sync-target-name", options.SyncTargetName,
 		fmt.Sprintf("ID of the -to cluster. Resources with this ID set in the '%s' label will be synced.", workloadv1alpha1.ClusterResourceStateLabelPrefix+"<ClusterID>"))
 	fs.StringArrayVarP(&options.SyncedResourceTypes, "resources", "r", options.SyncedResourceTypes, "Resources to be synchronized in kcp.")
-	fs.DurationVar(&options.APIImportPollInterval, "api-import-poll-interval", options.APIImportPollInterval, "Polling interval for API import.")
+	fs.StringArrayVar(&options.UpsyncedResourceTypes, "upsync-resources", options.UpsyncedResourceTypes, "Resources created in the -to cluster to be synchronized back in kcp. They are synchronized in kcp as well.")
+	fs.DurationVar(&options.APIImportPollInterval, "api-import-poll-interval", options.APIImportPollInterval, "Interval, jittered, at which the discovery of the -to cluster is checked for changes of the resources to import. The changes of their CRDs are imported right away.")
+	fs.StringVar(&options.MetricsBindAddress, "metrics-bind-address", options.MetricsBindAddress, "Address to serve the Prometheus metrics on /metrics, the liveness probe on /healthz and the readiness probe on /readyz. Empty to disable.")
+	fs.BoolVar(&options.DryRun, "dry-run", options.DryRun, "Write nothing, and log the objects that would be created, updated or deleted in the -to cluster as diffs against the current objects. Only the spec syncer runs, without heartbeat.")
+	fs.BoolVar(&options.LeaderElect, "leader-elect", options.LeaderElect, "Elect the active syncer replica with a lease in the -to cluster, so that several replicas can run for the same sync target.")
+	fs.StringVar(&options.LeaderElectionNamespace, "leader-election-namespace", options.LeaderElectionNamespace, "Namespace of the leader election lease in the -to cluster. Required with --leader-elect.")
+	fs.DurationVar(&options.LeaderElectionLeaseDuration, "leader-election-lease-duration", options.LeaderElectionLeaseDuration, "Duration that standby syncer replicas wait before trying to take over an unrenewed lease.")
+	fs.DurationVar(&options.LeaderElectionRenewDeadline, "leader-election-renew-deadline", options.LeaderElectionRenewDeadline, "Duration that the active syncer replica retries renewing the lease before giving up.")
+	fs.DurationVar(&options.LeaderElectionRetryPeriod, "leader-election-retry-period", options.LeaderElectionRetryPeriod, "Duration syncer replicas wait between tries of acquiring or renewing the lease.")
 
 	options.Logs.AddFlags(fs)
 }
@@ -85,6 +107,30 @@ func (options *Options) Validate() error {
 	if options.FromKubeconfig == "" {
 		return errors.New("--from-kubeconfig is required")
 	}
+	for _, resource := range options.SyncedResourceTypes {
+		if resource == "namespaces" {
+			return errors.New("--resources cannot include namespaces: downstream namespaces are created for the synced namespaced resources")
+		}
+	}
+	for _, resource := range options.UpsyncedResourceTypes {
+		if resource == "namespaces" {
+			return errors.New("--upsync-resources cannot include namespaces: downstream namespaces are created for the synced namespaced resources")
+		}
+	}
+	if options.DryRun && options.LeaderElect {
+		return errors.New("--leader-elect is not supported with --dry-run, as it writes a lease in the -to cluster")
+	}
+	if options.LeaderElect {
+		if options.LeaderElectionNamespace == "" {
+			return errors.New("--leader-election-namespace is required with --leader-elect")
+		}
+		if options.SyncTargetName == "" {
+			return errors.New("--sync-target-name is required with --leader-elect")
+		}
+		if options.LeaderElectionLeaseDuration <= options.LeaderElectionRenewDeadline {
+			return errors.New("--leader-election-lease-duration must be greater than --leader-election-renew-deadline")
+		}
+	}
 
 	return nil
 }
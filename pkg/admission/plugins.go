@@ -55,6 +55,7 @@ import (
 	"github.com/kcp-dev/kcp/pkg/admission/reservedcrdannotations"
 	"github.com/kcp-dev/kcp/pkg/admission/reservedcrdgroups"
 	"github.com/kcp-dev/kcp/pkg/admission/reservedmetadata"
+	"github.com/kcp-dev/kcp/pkg/admission/specdiff"
 	kcpvalidatingwebhook "github.com/kcp-dev/kcp/pkg/admission/validatingwebhook"
 )
 
@@ -77,6 +78,7 @@ var AllOrderedPlugins = beforeWebhooks(kubeapiserveroptions.AllOrderedPlugins,
 	reservedmetadata.PluginName,
 	permissionclaims.PluginName,
 	kubequota.PluginName,
+	specdiff.PluginName,
 )
 
 func beforeWebhooks(recommended []string, plugins ...string) []string {
@@ -111,6 +113,7 @@ func RegisterAllKcpAdmissionPlugins(plugins *admission.Plugins) {
 	reservedmetadata.Register(plugins)
 	permissionclaims.Register(plugins)
 	kubequota.Register(plugins)
+	specdiff.Register(plugins)
 }
 
 var defaultOnPluginsInKcp = sets.NewString(
@@ -135,6 +138,7 @@ var defaultOnPluginsInKcp = sets.NewString(
 	reservedcrdgroups.PluginName,
 	permissionclaims.PluginName,
 	kubequota.PluginName,
+	specdiff.PluginName,
 )
 
 // defaultOnKubePluginsInKube is a copy of kubeapiserveroptions.defaultOnKubePlugins.
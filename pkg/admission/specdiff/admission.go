@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specdiff
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/admission"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+const (
+	PluginName = "workload.kcp.dev/SpecDiff"
+)
+
+// Register registers the spec diff plugin for creation and updates.
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return &specDiff{
+				Handler: admission.NewHandler(admission.Create, admission.Update),
+			}, nil
+		})
+}
+
+// specDiff is a validating admission plugin checking that the
+// experimental.spec-diff.workload.kcp.dev/<sync-target-name> annotations
+// hold JSON patches the syncers can apply.
+type specDiff struct {
+	*admission.Handler
+}
+
+var _ = admission.ValidationInterface(&specDiff{})
+
+// Validate asserts that the spec diff annotations that are added or changed are valid.
+func (o *specDiff) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) (err error) {
+	newMeta, err := meta.Accessor(a.GetObject())
+	// nolint: nilerr
+	if err != nil {
+		// The object we are dealing with doesn't have object metadata defined
+		// hence it doesn't have annotations to be checked.
+		return nil
+	}
+
+	var oldAnnotations map[string]string
+	if oldMeta, err := meta.Accessor(a.GetOldObject()); err == nil {
+		oldAnnotations = oldMeta.GetAnnotations()
+	}
+
+	annotations := newMeta.GetAnnotations()
+	var errs []error
+	for _, key := range sets.StringKeySet(annotations).List() {
+		value := annotations[key]
+		if !strings.HasPrefix(key, workloadv1alpha1.ClusterSpecDiffAnnotationPrefix) {
+			continue
+		}
+		if oldValue, found := oldAnnotations[key]; found && oldValue == value {
+			continue
+		}
+		if strings.TrimPrefix(key, workloadv1alpha1.ClusterSpecDiffAnnotationPrefix) == "" {
+			errs = append(errs, fmt.Errorf("metadata.annotations[%s]: missing SyncTarget name", key))
+			continue
+		}
+		if _, err := shared.DecodeSpecDiff(value); err != nil {
+			errs = append(errs, fmt.Errorf("metadata.annotations[%s]: %w", key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return admission.NewForbidden(a, utilerrors.NewAggregate(errs))
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specdiff
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+func newAttr(obj, oldObject runtime.Object, op admission.Operation) admission.Attributes {
+	return admission.NewAttributesRecord(
+		obj,
+		oldObject,
+		schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		"default",
+		"test",
+		schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		"",
+		op,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func deployment(annotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		testName string
+		attr     admission.Attributes
+		wantErr  string
+	}{
+		{
+			testName: "no spec diff",
+			attr:     newAttr(deployment(map[string]string{"foo": "bar"}), nil, admission.Create),
+		},
+		{
+			testName: "valid spec diff",
+			attr: newAttr(deployment(map[string]string{
+				"experimental.spec-diff.workload.kcp.dev/us-west1": `[{"op":"replace","path":"/replicas","value":3}]`,
+			}), nil, admission.Create),
+		},
+		{
+			testName: "invalid spec diff",
+			attr: newAttr(deployment(map[string]string{
+				"experimental.spec-diff.workload.kcp.dev/us-west1": `{"replicas":3}`,
+			}), nil, admission.Create),
+			wantErr: `metadata.annotations[experimental.spec-diff.workload.kcp.dev/us-west1]: invalid JSON patch`,
+		},
+		{
+			testName: "spec diff without SyncTarget name",
+			attr: newAttr(deployment(map[string]string{
+				"experimental.spec-diff.workload.kcp.dev/": `[]`,
+			}), nil, admission.Create),
+			wantErr: "missing SyncTarget name",
+		},
+		{
+			testName: "unchanged invalid spec diff is tolerated",
+			attr: newAttr(
+				deployment(map[string]string{
+					"experimental.spec-diff.workload.kcp.dev/us-west1": `garbage`,
+					"foo": "bar",
+				}),
+				deployment(map[string]string{
+					"experimental.spec-diff.workload.kcp.dev/us-west1": `garbage`,
+				}),
+				admission.Update,
+			),
+		},
+		{
+			testName: "changed invalid spec diff",
+			attr: newAttr(
+				deployment(map[string]string{
+					"experimental.spec-diff.workload.kcp.dev/us-west1": `[{"op":"unknown","path":"/replicas"}]`,
+				}),
+				deployment(map[string]string{
+					"experimental.spec-diff.workload.kcp.dev/us-west1": `[]`,
+				}),
+				admission.Update,
+			),
+			wantErr: `unsupported op "unknown"`,
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			plugin := &specDiff{Handler: admission.NewHandler(admission.Create, admission.Update)}
+			err := plugin.Validate(context.Background(), tc.attr, nil)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
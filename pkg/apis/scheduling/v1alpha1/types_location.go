@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -67,6 +68,8 @@ const (
 // +kubebuilder:printcolumn:name="Resource",type=string,JSONPath=`.spec.resource.resource`,description="Type of the workspace"
 // +kubebuilder:printcolumn:name="Available",type=string,JSONPath=`.status.availableInstances`,description="Available instances in this location"
 // +kubebuilder:printcolumn:name="Instances",type=string,JSONPath=`.status.instances`,description="Instances in this location"
+// +kubebuilder:printcolumn:name="Allocatable CPU",type=string,JSONPath=`.status.allocatable.cpu`,description="Allocatable CPU of the available instances in this location"
+// +kubebuilder:printcolumn:name="Allocatable Memory",type=string,JSONPath=`.status.allocatable.memory`,description="Allocatable memory of the available instances in this location"
 // +kubebuilder:printcolumn:name="Labels",type=string,JSONPath=`.metadata.annotations['scheduling\.kcp\.dev/labels']`,description="The common labels of this location"
 type Location struct {
 	metav1.TypeMeta `json:",inline"`
@@ -176,6 +179,29 @@ type LocationStatus struct {
 
 	// available is the number of actual instances that are available at this location.
 	AvailableInstances *uint32 `json:"availableInstances,omitempty"`
+
+	// unreadyInstances is the number of actual instances at this location which are not ready.
+	// +optional
+	UnreadyInstances *uint32 `json:"unreadyInstances,omitempty"`
+
+	// cordonedInstances is the number of actual instances at this location which are cordoned,
+	// i.e. marked as unschedulable.
+	// +optional
+	CordonedInstances *uint32 `json:"cordonedInstances,omitempty"`
+
+	// capacity is the sum of the total resources of the instances at this location.
+	// +optional
+	Capacity *corev1.ResourceList `json:"capacity,omitempty"`
+
+	// allocatable is the sum of the resources available for scheduling on the available
+	// instances at this location.
+	// +optional
+	Allocatable *corev1.ResourceList `json:"allocatable,omitempty"`
+
+	// syncedResources is the union of the resources the instances at this location can sync,
+	// as sorted <resource>.<group> strings, e.g. deployments.apps, or services for the core group.
+	// +optional
+	SyncedResources []string `json:"syncedResources,omitempty"`
 }
 
 // LocationList is a list of locations.
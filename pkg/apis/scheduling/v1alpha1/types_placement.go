@@ -81,6 +81,38 @@ type PlacementSpec struct {
 	// +optional
 	// +kubebuilder:validation:Pattern:="^root(:[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$"
 	LocationWorkspace string `json:"locationWorkspace,omitempty"`
+
+	// removalGracePeriod is the amount of time a namespace and its resources are kept on a sync target
+	// that is not selected anymore, before they are removed from it. Meanwhile, they are already synced
+	// to the newly selected sync target, giving the workloads time to drain. If not set, the default
+	// removal grace period of the kcp server is used.
+	//
+	// +optional
+	RemovalGracePeriod *metav1.Duration `json:"removalGracePeriod,omitempty"`
+
+	// instanceAffinity expresses preferences for the instances of the selected location, e.g. the
+	// SyncTargets, by their labels. The instance matching the preferences with the highest total
+	// weight is preferred, all other things being equal.
+	//
+	// +optional
+	InstanceAffinity []WeightedLabelSelector `json:"instanceAffinity,omitempty"`
+}
+
+// WeightedLabelSelector is a label selector with a weight.
+type WeightedLabelSelector struct {
+	// weight is the weight of the preference, in the range 1-100.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+
+	// selector selects the instances the preference applies to.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Selector metav1.LabelSelector `json:"selector"`
 }
 
 type PlacementStatus struct {
@@ -94,11 +126,58 @@ type PlacementStatus struct {
 	// +optional
 	SelectedLocation *LocationReference `json:"selectedLocation,omitempty"`
 
+	// locationDecision records the last selection of a location for this placement.
+	// +optional
+	LocationDecision *SchedulingDecision `json:"locationDecision,omitempty"`
+
+	// instanceDecision records the last selection of an instance, e.g. a SyncTarget, in the
+	// selected location.
+	// +optional
+	InstanceDecision *SchedulingDecision `json:"instanceDecision,omitempty"`
+
 	// Current processing state of the Placement.
 	// +optional
 	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
 }
 
+// SchedulingDecision records the outcome of a scheduling decision, with the scores of the best
+// candidates and the reasons why other candidates were not feasible.
+type SchedulingDecision struct {
+	// selected is the name of the selected candidate. It is empty if no candidate is feasible.
+	// +optional
+	Selected string `json:"selected,omitempty"`
+
+	// candidates are the candidates considered by the decision, the feasible ones first, by
+	// decreasing score. The list is truncated to the first 10 candidates.
+	// +optional
+	Candidates []CandidateScore `json:"candidates,omitempty"`
+
+	// decisionTime is the time the decision was taken.
+	// +optional
+	DecisionTime metav1.Time `json:"decisionTime,omitempty"`
+}
+
+// CandidateScore is the score of a candidate of a scheduling decision.
+type CandidateScore struct {
+	// name is the name of the candidate.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// feasible tells whether the candidate passed all filters.
+	Feasible bool `json:"feasible"`
+
+	// score is the weighted sum of the scores of the candidate. It is zero for candidates
+	// which are not feasible.
+	// +optional
+	Score int64 `json:"score,omitempty"`
+
+	// reasons explain the score of the candidate, or why it is not feasible.
+	// +optional
+	Reasons []string `json:"reasons,omitempty"`
+}
+
 // LocationReference describes a loaction that are provided in the specified Workspace.
 type LocationReference struct {
 	// path is an absolute reference to a workspace, e.g. root:org:ws. The workspace must
@@ -22,7 +22,9 @@ limitations under the License.
 package v1alpha1
 
 import (
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	resource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
@@ -49,6 +51,27 @@ func (in *AvailableSelectorLabel) DeepCopy() *AvailableSelectorLabel {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CandidateScore) DeepCopyInto(out *CandidateScore) {
+	*out = *in
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CandidateScore.
+func (in *CandidateScore) DeepCopy() *CandidateScore {
+	if in == nil {
+		return nil
+	}
+	out := new(CandidateScore)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GroupVersionResource) DeepCopyInto(out *GroupVersionResource) {
 	*out = *in
@@ -155,7 +178,7 @@ func (in *LocationSpec) DeepCopyInto(out *LocationSpec) {
 	}
 	if in.InstanceSelector != nil {
 		in, out := &in.InstanceSelector, &out.InstanceSelector
-		*out = new(v1.LabelSelector)
+		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
 	return
@@ -184,6 +207,43 @@ func (in *LocationStatus) DeepCopyInto(out *LocationStatus) {
 		*out = new(uint32)
 		**out = **in
 	}
+	if in.UnreadyInstances != nil {
+		in, out := &in.UnreadyInstances, &out.UnreadyInstances
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.CordonedInstances != nil {
+		in, out := &in.CordonedInstances, &out.CordonedInstances
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = new(corev1.ResourceList)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make(map[corev1.ResourceName]resource.Quantity, len(*in))
+			for key, val := range *in {
+				(*out)[key] = val.DeepCopy()
+			}
+		}
+	}
+	if in.Allocatable != nil {
+		in, out := &in.Allocatable, &out.Allocatable
+		*out = new(corev1.ResourceList)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make(map[corev1.ResourceName]resource.Quantity, len(*in))
+			for key, val := range *in {
+				(*out)[key] = val.DeepCopy()
+			}
+		}
+	}
+	if in.SyncedResources != nil {
+		in, out := &in.SyncedResources, &out.SyncedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -285,7 +345,7 @@ func (in *PlacementSpec) DeepCopyInto(out *PlacementSpec) {
 	*out = *in
 	if in.LocationSelectors != nil {
 		in, out := &in.LocationSelectors, &out.LocationSelectors
-		*out = make([]v1.LabelSelector, len(*in))
+		*out = make([]metav1.LabelSelector, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -293,9 +353,21 @@ func (in *PlacementSpec) DeepCopyInto(out *PlacementSpec) {
 	out.LocationResource = in.LocationResource
 	if in.NamespaceSelector != nil {
 		in, out := &in.NamespaceSelector, &out.NamespaceSelector
-		*out = new(v1.LabelSelector)
+		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RemovalGracePeriod != nil {
+		in, out := &in.RemovalGracePeriod, &out.RemovalGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.InstanceAffinity != nil {
+		in, out := &in.InstanceAffinity, &out.InstanceAffinity
+		*out = make([]WeightedLabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -317,6 +389,16 @@ func (in *PlacementStatus) DeepCopyInto(out *PlacementStatus) {
 		*out = new(LocationReference)
 		**out = **in
 	}
+	if in.LocationDecision != nil {
+		in, out := &in.LocationDecision, &out.LocationDecision
+		*out = new(SchedulingDecision)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstanceDecision != nil {
+		in, out := &in.InstanceDecision, &out.InstanceDecision
+		*out = new(SchedulingDecision)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(conditionsv1alpha1.Conditions, len(*in))
@@ -336,3 +418,44 @@ func (in *PlacementStatus) DeepCopy() *PlacementStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingDecision) DeepCopyInto(out *SchedulingDecision) {
+	*out = *in
+	if in.Candidates != nil {
+		in, out := &in.Candidates, &out.Candidates
+		*out = make([]CandidateScore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.DecisionTime.DeepCopyInto(&out.DecisionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingDecision.
+func (in *SchedulingDecision) DeepCopy() *SchedulingDecision {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingDecision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeightedLabelSelector) DeepCopyInto(out *WeightedLabelSelector) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeightedLabelSelector.
+func (in *WeightedLabelSelector) DeepCopy() *WeightedLabelSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(WeightedLabelSelector)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -70,7 +70,8 @@ func findCEL(t *testing.T, s *schema.Structural, pth *field.Path) (map[string]CE
 		}
 	}
 
-	for k, v := range s.Properties {
+	for k := range s.Properties {
+		v := s.Properties[k]
 		sub, err := findCEL(t, &v, pth.Child("properties").Child(k))
 		if err != nil {
 			return nil, err
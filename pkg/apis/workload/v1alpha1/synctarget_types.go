@@ -78,8 +78,61 @@ type SyncTargetSpec struct {
 	// they are in the same physical cluster. Each key/value pair in the cells should be added and updated by service providers
 	// (i.e. a network provider updates one key/value, while the storage provider updates another.)
 	Cells map[string]string `json:"cells,omitempty"`
+
+	// Heartbeat overrides the heartbeat settings of kcp for the SyncTarget, and sets the actions taken when the
+	// heartbeats of its syncer stop.
+	// +optional
+	Heartbeat *SyncTargetHeartbeat `json:"heartbeat,omitempty"`
+}
+
+// SyncTargetHeartbeat configures the heartbeats of the syncer of a SyncTarget.
+type SyncTargetHeartbeat struct {
+	// Interval is the interval at which the syncer heartbeats. By default, the interval configured in kcp is used.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Threshold is the amount of time without heartbeat after which the SyncTarget is not ready. By default, the
+	// threshold configured in kcp is used.
+	// +optional
+	Threshold *metav1.Duration `json:"threshold,omitempty"`
+
+	// UnhealthyActions are taken when no heartbeat has been seen for a while. They are undone when the heartbeats
+	// resume.
+	// +optional
+	UnhealthyActions []HeartbeatUnhealthyAction `json:"unhealthyActions,omitempty"`
+}
+
+// HeartbeatUnhealthyAction is an action taken when no heartbeat has been seen for a while.
+type HeartbeatUnhealthyAction struct {
+	// Type is the type of the action:
+	//
+	// - Cordon marks the SyncTarget unschedulable, once no heartbeat has been seen for After.
+	// - Evict starts an eviction timer as soon as the heartbeat is missed, setting evictAfter for the workloads of
+	//   the SyncTarget to be evicted once no heartbeat has been seen for After.
+	// - Event emits a Warning event on the SyncTarget, once no heartbeat has been seen for After.
+	//
+	// +kubebuilder:validation:Enum=Cordon;Evict;Event
+	// +required
+	// +kubebuilder:validation:Required
+	Type HeartbeatUnhealthyActionType `json:"type"`
+
+	// After is the amount of time since the last heartbeat after which the action takes effect.
+	// +required
+	// +kubebuilder:validation:Required
+	After metav1.Duration `json:"after"`
 }
 
+type HeartbeatUnhealthyActionType string
+
+const (
+	// HeartbeatUnhealthyActionCordon marks the SyncTarget unschedulable.
+	HeartbeatUnhealthyActionCordon HeartbeatUnhealthyActionType = "Cordon"
+	// HeartbeatUnhealthyActionEvict evicts the workloads of the SyncTarget.
+	HeartbeatUnhealthyActionEvict HeartbeatUnhealthyActionType = "Evict"
+	// HeartbeatUnhealthyActionEvent emits a Warning event on the SyncTarget.
+	HeartbeatUnhealthyActionEvent HeartbeatUnhealthyActionType = "Event"
+)
+
 // SyncTargetStatus communicates the observed state of the SyncTarget (from the controller).
 type SyncTargetStatus struct {
 
@@ -104,6 +157,17 @@ type SyncTargetStatus struct {
 	// +optional
 	LastSyncerHeartbeatTime *metav1.Time `json:"lastSyncerHeartbeatTime,omitempty"`
 
+	// ActiveSyncer is the identity of the syncer replica that last reported status. When several
+	// syncer replicas run for the SyncTarget, it is the one holding the leader election lease.
+	// It is cleared once the replica stops being active without another one taking over.
+	// +optional
+	ActiveSyncer string `json:"activeSyncer,omitempty"`
+
+	// HeartbeatInterval is the interval at which the syncer is expected to heartbeat, from the heartbeat settings
+	// of the SyncTarget or of kcp. It MUST be updated by kcp server.
+	// +optional
+	HeartbeatInterval *metav1.Duration `json:"heartbeatInterval,omitempty"`
+
 	// VirtualWorkspaces contains all syncer virtual workspace URLs.
 	// +optional
 	VirtualWorkspaces []VirtualWorkspace `json:"virtualWorkspaces,omitempty"`
@@ -176,6 +240,9 @@ const (
 	// HeartbeatHealthy means the HeartbeatManager has seen a heartbeat for the SyncTarget within the expected interval.
 	HeartbeatHealthy conditionsv1alpha1.ConditionType = "HeartbeatHealthy"
 
+	// SyncTargetDrained means the namespaces of a SyncTarget drained gracefully have all been moved off it.
+	SyncTargetDrained conditionsv1alpha1.ConditionType = "Drained"
+
 	// SyncTargetUnknownReason documents a SyncTarget which readiness is unknown.
 	SyncTargetUnknownReason = "SyncTargetStatusUnknown"
 
@@ -203,6 +270,9 @@ const (
 	// ErrorStartingAPIImporterReason indicates an error starting the API Importer.
 	ErrorStartingAPIImporterReason = "ErrorStartingAPIImporter"
 
+	// DrainInProgressReason documents a SyncTarget drained gracefully with namespaces left to move off it.
+	DrainInProgressReason = "DrainInProgress"
+
 	// ErrorHeartbeatMissedReason indicates that a heartbeat update was not received within the configured threshold.
 	ErrorHeartbeatMissedReason = "ErrorHeartbeat"
 )
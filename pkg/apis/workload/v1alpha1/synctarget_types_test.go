@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apitest "github.com/kcp-dev/kcp/pkg/apis/test"
+)
+
+// TestSyncTargetHeartbeatCELValidation validates the heartbeat settings of an otherwise valid SyncTarget.
+func TestSyncTargetHeartbeatCELValidation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		heartbeat map[string]interface{}
+		valid     bool
+	}{
+		{
+			name:      "defaults",
+			heartbeat: map[string]interface{}{},
+			valid:     true,
+		},
+		{
+			name: "interval less than threshold",
+			heartbeat: map[string]interface{}{
+				"interval":  "20s",
+				"threshold": "1m0s",
+			},
+			valid: true,
+		},
+		{
+			name: "interval only",
+			heartbeat: map[string]interface{}{
+				"interval": "20s",
+			},
+			valid: true,
+		},
+		{
+			name: "zero interval",
+			heartbeat: map[string]interface{}{
+				"interval": "0s",
+			},
+			valid: false,
+		},
+		{
+			name: "negative threshold",
+			heartbeat: map[string]interface{}{
+				"threshold": "-1m",
+			},
+			valid: false,
+		},
+		{
+			name: "interval equal to threshold",
+			heartbeat: map[string]interface{}{
+				"interval":  "1m",
+				"threshold": "60s",
+			},
+			valid: false,
+		},
+		{
+			name: "interval greater than threshold",
+			heartbeat: map[string]interface{}{
+				"interval":  "2m",
+				"threshold": "1m",
+			},
+			valid: false,
+		},
+	}
+
+	validators := apitest.ValidatorsFromFile(t, "../../../../config/crds/workload.kcp.dev_synctargets.yaml")
+
+	for _, tc := range testCases {
+		pth := "openAPIV3Schema.properties.spec.properties.heartbeat"
+		validator, found := validators["v1alpha1"][pth]
+		require.True(t, found, "failed to find validator for %s", pth)
+
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validator(tc.heartbeat, nil)
+			if len(errs) == 0 && !tc.valid {
+				t.Error("No errors were found, but should be invalid heartbeat")
+				return
+			}
+			if len(errs) > 0 && tc.valid {
+				t.Errorf("found errors: %v but should be valid heartbeat", errs.ToAggregate().Error())
+				return
+			}
+		})
+	}
+}
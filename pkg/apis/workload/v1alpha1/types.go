@@ -27,6 +27,24 @@ const (
 	// This includes the deletion process until the resource is deleted downstream and the
 	// syncer removes the state.workload.kcp.dev/<sync-target-name> label.
 	ResourceStateSync ResourceState = "Sync"
+	// ResourceStateUpsync is the state of a resource created upstream by the syncer from an
+	// object owned by the sync target. The syncer keeps it up-to-date with the downstream object
+	// and deletes it when the downstream object is deleted. Workload controllers and the spec
+	// syncer ignore resources in this state.
+	ResourceStateUpsync ResourceState = "Upsync"
+)
+
+// DrainState is the value of the drain.internal.workload.kcp.dev/<sync-target-name> annotation
+// of a namespace leaving a sync target drained gracefully.
+type DrainState string
+
+const (
+	// DrainStateHeld is the state of a namespace kept on the sync target until the drain
+	// controller lets it move.
+	DrainStateHeld DrainState = "Held"
+	// DrainStateEvicting is the state of a namespace let go by the drain controller, and not
+	// yet synced to a ready sync target.
+	DrainStateEvicting DrainState = "Evicting"
 )
 
 const (
@@ -43,6 +61,20 @@ const (
 	// TODO(sttts): use sync-target-uid instead of sync-target-name
 	InternalClusterDeletionTimestampAnnotationPrefix = "deletion.internal.workload.kcp.dev/"
 
+	// InternalClusterRemovalGracePeriodAnnotationPrefix is the prefix of the annotation
+	//
+	//   removal-grace-period.internal.workload.kcp.dev/<sync-target-name>
+	//
+	// on namespaces storing the grace period of the removal of the namespace from the sync target,
+	// starting at the deletion.internal.workload.kcp.dev/<sync-target-name> timestamp. It is derived
+	// from the placements of the namespace when the sync target stops being selected. Until the
+	// grace period expires, the resources of the namespace stay synced to the sync target.
+	//
+	// The format is a Go duration, e.g. "30s".
+	//
+	// TODO(sttts): use sync-target-uid instead of sync-target-name
+	InternalClusterRemovalGracePeriodAnnotationPrefix = "removal-grace-period.internal.workload.kcp.dev/"
+
 	// ClusterFinalizerAnnotationPrefix is the prefix of the annotation
 	//
 	//   finalizers.workload.kcp.dev/<sync-target-name>
@@ -50,7 +82,8 @@ const (
 	// on upstream resources storing a comma-separated list of finalizer names that are set on
 	// the sync target resource in the view of the syncer. This blocks the deletion of the
 	// resource on that sync target. External (custom) controllers can set this annotation
-	// create back-pressure on the resource.
+	// create back-pressure on the resource. The helpers of the
+	// github.com/kcp-dev/kcp/pkg/syncer/shared package maintain this list.
 	//
 	// TODO(sttts): use sync-target-uid instead of sync-target-name
 	ClusterFinalizerAnnotationPrefix = "finalizers.workload.kcp.dev/"
@@ -68,6 +101,8 @@ const (
 	//       controller will have to set the value to "Sync" after initializion in order to
 	//       start the sync process.
 	// - "Sync": the object is assigned and the syncer will start the sync process.
+	// - "Upsync": the object is owned by the sync target, and the syncer keeps it in sync
+	//       with the downstream object it has been created from.
 	//
 	// While being in "Sync" state, a deletion timestamp in deletion.internal.workload.kcp.dev/<sync-target-name>
 	// will signal the start of the deletion process of the object. During the deletion process
@@ -103,12 +138,113 @@ const (
 	// resource's Spec field.
 	//
 	// The format for the value of this annotation is: JSON Patch (https://tools.ietf.org/html/rfc6902).
+	// It is validated on admission. When the patch does not apply to the spec, the syncer does not update
+	// the downstream resource, and reports the failure in the
+	// experimental.spec-diff-error.workload.kcp.dev/<sync-target-name> annotation.
 	ClusterSpecDiffAnnotationPrefix = "experimental.spec-diff.workload.kcp.dev/"
 
+	// ClusterSpecDiffErrorAnnotationPrefix is the prefix of the annotation
+	//
+	//   experimental.spec-diff-error.workload.kcp.dev/<sync-target-name>
+	//
+	// on upstream resources storing why their experimental.spec-diff.workload.kcp.dev/<sync-target-name>
+	// annotation does not apply to their spec. It is set by the syncer, and removed once the spec diff
+	// applies again.
+	//
+	// The format is JSON, with the reason, the message and the lastTransitionTime of the failure.
+	ClusterSpecDiffErrorAnnotationPrefix = "experimental.spec-diff-error.workload.kcp.dev/"
+
+	// ClusterSyncErrorAnnotationPrefix is the prefix of the annotation
+	//
+	//   experimental.sync-error.workload.kcp.dev/<sync-target-name>
+	//
+	// on upstream resources storing why the <sync-target-name> rejected the resource when the syncer
+	// applied it downstream, for instance on admission, quota or validation. It is set by the syncer,
+	// along with a warning Event on the SyncTarget, and removed on the next successful sync.
+	//
+	// The format is JSON, with the reason, the message and the lastTransitionTime of the failure.
+	ClusterSyncErrorAnnotationPrefix = "experimental.sync-error.workload.kcp.dev/"
+
+	// ClusterBoundVolumeAnnotationPrefix is the prefix of the annotation
+	//
+	//   experimental.bound-volume.workload.kcp.dev/<sync-target-name>
+	//
+	// on upstream persistent volume claims storing the details of the persistent volume the downstream
+	// claim is bound to on the <sync-target-name>. It is set by the syncer, and removed when the claim is
+	// not bound anymore. While set, the namespace of the claim is not moved away from the <sync-target-name>,
+	// unless the namespace has the experimental.workload.kcp.dev/force-move-bound-volumes annotation.
+	//
+	// The format is JSON.
+	ClusterBoundVolumeAnnotationPrefix = "experimental.bound-volume.workload.kcp.dev/"
+
+	// ForceMoveBoundVolumesAnnotation is the annotation of a namespace allowing it, when set to "true", to be
+	// moved away from sync targets its persistent volume claims are bound to volumes of. The bound volumes
+	// are not moved with the namespace.
+	ForceMoveBoundVolumesAnnotation = "experimental.workload.kcp.dev/force-move-bound-volumes"
+
+	// StorageClassMappingAnnotation is the annotation of a SyncTarget mapping the storage classes of the
+	// upstream persistent volume claims to the storage classes of the sync target, in the comma-separated
+	// <upstream-class>=<downstream-class> form. An empty upstream class maps the claims not setting a
+	// storage class. Storage classes that are not mapped are kept.
+	StorageClassMappingAnnotation = "experimental.workload.kcp.dev/storage-class-mapping"
+
+	// NamespaceNamingAnnotation is the annotation of a SyncTarget setting how the syncer names the namespaces
+	// it creates on the sync target for the upstream namespaces:
+	//
+	//   - "hash" (the default) names them kcp-<hash of the namespace locator>.
+	//   - "readable" names them <workspace>-<namespace>, with the colons of the workspace replaced by dashes.
+	//   - "template:<template>" names them from a Go template, given the .Workspace, .Namespace,
+	//     .SyncTargetName and .Hash fields.
+	//
+	// Readable and templated names are sanitized into DNS labels, and get a hash suffix when they collide with
+	// an existing namespace. The strategy only applies to the namespaces created after it is set.
+	NamespaceNamingAnnotation = "experimental.workload.kcp.dev/namespace-naming"
+
+	// ClusterScopedPlacementAnnotation is the annotation of a cluster-scoped object listing, comma-separated, the
+	// names of the sync targets the object is placed on. Cluster-scoped objects don't belong to a namespace to be
+	// placed with, so their state.workload.kcp.dev/<sync-target-name> labels are set from this annotation. An empty
+	// value, or removing the annotation, removes the object from all sync targets.
+	ClusterScopedPlacementAnnotation = "experimental.workload.kcp.dev/sync-targets"
+
+	// DrainMaxUnavailableAnnotation is the annotation of a SyncTarget making its drain graceful: while the SyncTarget
+	// is unschedulable and before its evictAfter time, the namespaces leaving it are moved off it in batches, at most
+	// the given number, or percentage, of its namespaces being unavailable at once. A namespace is unavailable from
+	// the time it is let go until it is synced to a ready SyncTarget. Set on a Placement, it also limits the
+	// unavailable namespaces bound to the placement.
+	//
+	// The format is an integer, e.g. "2", or a percentage, e.g. "10%".
+	DrainMaxUnavailableAnnotation = "experimental.workload.kcp.dev/drain-max-unavailable"
+
+	// InternalClusterDrainAnnotationPrefix is the prefix of the annotation
+	//
+	//   drain.internal.workload.kcp.dev/<sync-target-name>
+	//
+	// on namespaces leaving a sync target drained gracefully. It is set to "Held" by the namespace scheduler,
+	// keeping the namespace on the sync target, then to "Evicting" by the drain controller, letting the namespace
+	// move. The drain controller removes it once the namespace is synced to a ready sync target.
+	//
+	// TODO(sttts): use sync-target-uid instead of sync-target-name
+	InternalClusterDrainAnnotationPrefix = "drain.internal.workload.kcp.dev/"
+
+	// RevokeTokenAfterAnnotation is the annotation of the previous token Secrets of a syncer service account, set by
+	// kubectl kcp workload rotate-credentials, with the time in RFC-3339 format after which kcp revokes the token by
+	// deleting its Secret.
+	RevokeTokenAfterAnnotation = "workload.kcp.dev/revoke-token-after"
+
+	// InternalHeartbeatActionsAnnotation is the annotation of a SyncTarget listing, comma-separated, the heartbeat
+	// unhealthy actions taken by kcp while the heartbeats of its syncer are missing, to be undone when they resume.
+	// The Evict action is recorded with the evictAfter time it set, in the Evict=<RFC-3339 time> form.
+	InternalHeartbeatActionsAnnotation = "internal.workload.kcp.dev/heartbeat-actions"
+
 	// InternalDownstreamClusterLabel is a label with the upstream cluster name applied on the downstream cluster
 	// instead of state.workload.kcp.dev/<sync-target-name> which is used upstream.
 	InternalDownstreamClusterLabel = "internal.workload.kcp.dev/cluster"
 
+	// InternalDownstreamOwnerLabel is a label applied on the downstream cluster-scoped objects, with a hash of
+	// the workspace owning them. Cluster-scoped objects keep their name downstream, so the syncer never
+	// overwrites the objects owned by another workspace, or not created by the syncer.
+	InternalDownstreamOwnerLabel = "internal.workload.kcp.dev/owner"
+
 	// AnnotationSkipDefaultObjectCreation is the annotation key for an apiexport or apibinding indicating the other default resources
 	// has been created already. If the created default resource is deleted, it will not be recreated.
 	AnnotationSkipDefaultObjectCreation = "workload.kcp.dev/skip-default-object-creation"
@@ -24,12 +24,30 @@ package v1alpha1
 import (
 	v1 "k8s.io/api/core/v1"
 	resource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeartbeatUnhealthyAction) DeepCopyInto(out *HeartbeatUnhealthyAction) {
+	*out = *in
+	out.After = in.After
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeartbeatUnhealthyAction.
+func (in *HeartbeatUnhealthyAction) DeepCopy() *HeartbeatUnhealthyAction {
+	if in == nil {
+		return nil
+	}
+	out := new(HeartbeatUnhealthyAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceToSync) DeepCopyInto(out *ResourceToSync) {
 	*out = *in
@@ -80,6 +98,37 @@ func (in *SyncTarget) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncTargetHeartbeat) DeepCopyInto(out *SyncTargetHeartbeat) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Threshold != nil {
+		in, out := &in.Threshold, &out.Threshold
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.UnhealthyActions != nil {
+		in, out := &in.UnhealthyActions, &out.UnhealthyActions
+		*out = make([]HeartbeatUnhealthyAction, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncTargetHeartbeat.
+func (in *SyncTargetHeartbeat) DeepCopy() *SyncTargetHeartbeat {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncTargetHeartbeat)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncTargetList) DeepCopyInto(out *SyncTargetList) {
 	*out = *in
@@ -134,6 +183,11 @@ func (in *SyncTargetSpec) DeepCopyInto(out *SyncTargetSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Heartbeat != nil {
+		in, out := &in.Heartbeat, &out.Heartbeat
+		*out = new(SyncTargetHeartbeat)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -190,6 +244,11 @@ func (in *SyncTargetStatus) DeepCopyInto(out *SyncTargetStatus) {
 		in, out := &in.LastSyncerHeartbeatTime, &out.LastSyncerHeartbeatTime
 		*out = (*in).DeepCopy()
 	}
+	if in.HeartbeatInterval != nil {
+		in, out := &in.HeartbeatInterval, &out.HeartbeatInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.VirtualWorkspaces != nil {
 		in, out := &in.VirtualWorkspaces, &out.VirtualWorkspaces
 		*out = make([]VirtualWorkspace, len(*in))
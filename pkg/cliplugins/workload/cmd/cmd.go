@@ -19,6 +19,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -36,6 +37,11 @@ var (
 
 	# Directly apply the manifest
 	%[1]s workload sync <sync-target-name> --syncer-image <kcp-syncer-image> -o - | KUBECONFIG=<pcluster-config> kubectl apply -f -
+`
+	rotateCredentialsExample = `
+	# Mint a new token for the syncer of a sync target, and have kcp revoke the previous ones after 10 minutes.
+	%[1]s workload rotate-credentials <sync-target-name> --overlap 10m -o syncer-secret.yaml
+	KUBECONFIG=<pcluster-config> kubectl apply -f syncer-secret.yaml
 `
 	cordonExample = `
 	# Mark a sync target as unschedulable.
@@ -48,6 +54,35 @@ var (
 	drainExample = `
 	# Start draining a sync target in preparation for maintenance.
 	%[1]s workload drain <sync-target-name>
+
+	# Move the namespaces off a sync target in batches, at most 10%% of them being unavailable at once.
+	%[1]s workload drain <sync-target-name> --max-unavailable 10%%
+`
+	pendingRemovalsExample = `
+	# List the resources being removed from sync targets, and the finalizers holding them.
+	%[1]s workload pending-removals
+
+	# List the resources being removed from a given sync target.
+	%[1]s workload pending-removals --sync-target <sync-target-name>
+`
+	listExample = `
+	# List the sync targets of the current workspace.
+	%[1]s workload list
+`
+	statusExample = `
+	# Show the readiness, heartbeat and conditions of a sync target.
+	%[1]s workload status <sync-target-name>
+`
+	describeExample = `
+	# Show the status of a sync target, its synced resources, locations and the namespaces of the current workspace placed on it.
+	%[1]s workload describe <sync-target-name>
+`
+	namespacesExample = `
+	# List the namespaces created by syncers in a physical cluster, with the workspaces and namespaces they are synced from.
+	KUBECONFIG=<pcluster-config> %[1]s workload namespaces
+
+	# List the namespaces created for a given sync target.
+	KUBECONFIG=<pcluster-config> %[1]s workload namespaces --sync-target <sync-target-name>
 `
 )
 
@@ -114,10 +149,6 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 			if replicas < 0 {
 				return errors.New("a non-negative value must be specified for --replicas")
 			}
-			if replicas > 1 {
-				// TODO: relax when we have leader-election in the syncer
-				return errors.New("only 0 and 1 are allowed as --replicas values")
-			}
 			if len(outputFile) == 0 {
 				return errors.New("a value must be specified for --output-file")
 			}
@@ -145,7 +176,7 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 	}
 	enableSyncerCmd.Flags().StringSliceVar(&userResourcesToSync, "resources", userResourcesToSync, "Resources to synchronize with kcp.")
 	enableSyncerCmd.Flags().StringVar(&syncerImage, "syncer-image", syncerImage, "The syncer image to use in the syncer's deployment YAML. Images are published at https://github.com/kcp-dev/kcp/pkgs/container/kcp%2Fsyncer.")
-	enableSyncerCmd.Flags().IntVar(&replicas, "replicas", replicas, "Number of replicas of the syncer deployment.")
+	enableSyncerCmd.Flags().IntVar(&replicas, "replicas", replicas, "Number of replicas of the syncer deployment. Replicas elect a single active syncer, the others are on standby.")
 	enableSyncerCmd.Flags().StringVar(&kcpNamespace, "kcp-namespace", kcpNamespace, "The name of the kcp namespace to create a service account in.")
 	enableSyncerCmd.Flags().StringVarP(&outputFile, "output-file", "o", outputFile, "The manifest file to be created and applied to the physical cluster. Use - for stdout.")
 	enableSyncerCmd.Flags().StringVarP(&downstreamNamespace, "namespace", "n", downstreamNamespace, "The namespace to create the syncer in in the physical cluster. By default this is \"kcp-syncer-<synctarget-name>-<uid>\".")
@@ -154,6 +185,53 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 
 	cmd.AddCommand(enableSyncerCmd)
 
+	// rotate-credentials
+	var (
+		rotateOutputFile          string
+		rotateDownstreamNamespace string
+		rotateKCPNamespace        = "default"
+		rotateOverlap             = 5 * time.Minute
+	)
+	rotateCredentialsCmd := &cobra.Command{
+		Use:          "rotate-credentials <sync-target-name> -o <output-file>",
+		Short:        "Mint a new token for the syncer of a sync target. Output the syncer Secret to apply in the physical cluster, and have kcp revoke the previous tokens after an overlap.",
+		Example:      fmt.Sprintf(rotateCredentialsExample, "kubectl kcp"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			kubeconfig, err := plugin.NewConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			if len(rotateKCPNamespace) == 0 {
+				return errors.New("a value must be specified for --kcp-namespace")
+			}
+			if len(rotateOutputFile) == 0 {
+				return errors.New("a value must be specified for --output-file")
+			}
+			if rotateOverlap < 0 {
+				return errors.New("a non-negative value must be specified for --overlap")
+			}
+
+			syncTargetName := args[0]
+
+			return kubeconfig.RotateCredentials(c.Context(), rotateOutputFile, syncTargetName, rotateKCPNamespace, rotateDownstreamNamespace, rotateOverlap)
+		},
+	}
+	rotateCredentialsCmd.Flags().StringVar(&rotateKCPNamespace, "kcp-namespace", rotateKCPNamespace, "The name of the kcp namespace of the syncer service account.")
+	rotateCredentialsCmd.Flags().StringVarP(&rotateOutputFile, "output-file", "o", rotateOutputFile, "The Secret manifest file to be created and applied to the physical cluster. Use - for stdout.")
+	rotateCredentialsCmd.Flags().StringVarP(&rotateDownstreamNamespace, "namespace", "n", rotateDownstreamNamespace, "The namespace of the syncer in the physical cluster. By default this is \"kcp-syncer-<synctarget-name>-<uid>\".")
+	rotateCredentialsCmd.Flags().DurationVar(&rotateOverlap, "overlap", rotateOverlap, "How long the previous tokens remain valid, for the syncer to switch to the new one. They are then revoked by kcp, or right away if 0.")
+
+	cmd.AddCommand(rotateCredentialsCmd)
+
 	// cordon
 	cordonCmd := &cobra.Command{
 		Use:          "cordon <sync-target-name>",
@@ -209,6 +287,7 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 	cmd.AddCommand(uncordonCmd)
 
 	// drain
+	var drainMaxUnavailable string
 	drainCmd := &cobra.Command{
 		Use:          "drain <sync-target-name>",
 		Short:        "Start draining sync target in preparation for maintenance",
@@ -229,11 +308,145 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 
 			syncTargetName := args[0]
 
-			return kubeconfig.Drain(c.Context(), syncTargetName)
+			return kubeconfig.Drain(c.Context(), syncTargetName, drainMaxUnavailable)
 		},
 	}
+	drainCmd.Flags().StringVar(&drainMaxUnavailable, "max-unavailable", drainMaxUnavailable, "Drain gracefully, moving the namespaces in batches with at most this number, or percentage, of them being unavailable at once.")
 
 	cmd.AddCommand(drainCmd)
 
+	// pending-removals
+	var pendingRemovalsSyncTarget string
+	pendingRemovalsCmd := &cobra.Command{
+		Use:          "pending-removals [--sync-target <sync-target-name>]",
+		Short:        "List resources being removed from sync targets with the finalizers blocking their removal",
+		Example:      fmt.Sprintf(pendingRemovalsExample, "kubectl kcp"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			kubeconfig, err := plugin.NewConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			if len(args) != 0 {
+				return cmd.Help()
+			}
+
+			return kubeconfig.PendingRemovals(c.Context(), pendingRemovalsSyncTarget)
+		},
+	}
+	pendingRemovalsCmd.Flags().StringVar(&pendingRemovalsSyncTarget, "sync-target", pendingRemovalsSyncTarget, "Only list the resources being removed from this sync target.")
+
+	cmd.AddCommand(pendingRemovalsCmd)
+
+	// namespaces
+	var namespacesSyncTarget string
+	namespacesCmd := &cobra.Command{
+		Use:          "namespaces [--sync-target <sync-target-name>]",
+		Short:        "List the namespaces created by syncers in a physical cluster with the workspace namespaces they are synced from",
+		Example:      fmt.Sprintf(namespacesExample, "kubectl kcp"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			kubeconfig, err := plugin.NewConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			if len(args) != 0 {
+				return cmd.Help()
+			}
+
+			return kubeconfig.Namespaces(c.Context(), namespacesSyncTarget)
+		},
+	}
+	namespacesCmd.Flags().StringVar(&namespacesSyncTarget, "sync-target", namespacesSyncTarget, "Only list the namespaces created for this sync target.")
+
+	cmd.AddCommand(namespacesCmd)
+
+	// list
+	listCmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List the sync targets of the current workspace",
+		Example:      fmt.Sprintf(listExample, "kubectl kcp"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			kubeconfig, err := plugin.NewConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			if len(args) != 0 {
+				return cmd.Help()
+			}
+
+			return kubeconfig.List(c.Context())
+		},
+	}
+
+	cmd.AddCommand(listCmd)
+
+	// status
+	statusCmd := &cobra.Command{
+		Use:          "status <sync-target-name>",
+		Short:        "Show the readiness, heartbeat and conditions of a sync target",
+		Example:      fmt.Sprintf(statusExample, "kubectl kcp"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			kubeconfig, err := plugin.NewConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			syncTargetName := args[0]
+
+			return kubeconfig.Status(c.Context(), syncTargetName)
+		},
+	}
+
+	cmd.AddCommand(statusCmd)
+
+	// describe
+	describeCmd := &cobra.Command{
+		Use:          "describe <sync-target-name>",
+		Short:        "Show the status of a sync target with its synced resources, locations and placed namespaces",
+		Example:      fmt.Sprintf(describeExample, "kubectl kcp"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			kubeconfig, err := plugin.NewConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			syncTargetName := args[0]
+
+			return kubeconfig.Describe(c.Context(), syncTargetName)
+		},
+	}
+
+	cmd.AddCommand(describeCmd)
+
 	return cmd, nil
 }
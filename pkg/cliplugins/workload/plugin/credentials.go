@@ -0,0 +1,305 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubernetesclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/helpers"
+)
+
+// RotateCredentials mints a new token for the syncer of the given sync target, and outputs the secret holding the
+// kubeconfig of the syncer updated with it, to be applied to the pcluster. The previous tokens of the syncer are
+// marked to be revoked once the overlap has elapsed, leaving time to the syncer to switch to the new token. They
+// are then revoked by kcp, or right away without overlap.
+func (c *Config) RotateCredentials(ctx context.Context, outputFilePath, syncTargetName, kcpNamespaceName, downstreamNamespace string, overlap time.Duration) error {
+	config, err := clientcmd.NewDefaultClientConfig(*c.startingConfig, c.overrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	configURL, currentClusterName, err := helpers.ParseClusterURL(config.Host)
+	if err != nil {
+		return fmt.Errorf("current URL %q does not point to cluster workspace", config.Host)
+	}
+
+	kcpClient, err := kcpclientset.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kcp client: %w", err)
+	}
+	kubeClient, err := kubernetesclientset.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	syncTarget, err := kcpClient.WorkloadV1alpha1().SyncTargets().Get(ctx, syncTargetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get synctarget %q: %w", syncTargetName, err)
+	}
+	syncerID := getSyncerID(syncTarget)
+
+	sa, previousSecrets, err := getSyncerTokenSecrets(ctx, kubeClient, syncTargetName, kcpNamespaceName, syncerID)
+	if err != nil {
+		return err
+	}
+
+	c.ErrOut.Write([]byte(fmt.Sprintf("Creating a new token for service account %q\n", syncerID))) // nolint: errcheck
+	tokenSecret, err := kubeClient.CoreV1().Secrets(kcpNamespaceName).Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: syncerID + "-token-",
+			Annotations: map[string]string{
+				corev1.ServiceAccountNameKey: sa.Name,
+			},
+		},
+		Type: corev1.SecretTypeServiceAccountToken,
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create token Secret for ServiceAccount %s|%s/%s: %w", syncTargetName, kcpNamespaceName, syncerID, err)
+	}
+
+	// Wait for the token controller to populate the token
+	var token string
+	err = wait.PollImmediateWithContext(ctx, 100*time.Millisecond, 20*time.Second, func(ctx context.Context) (bool, error) {
+		secret, err := kubeClient.CoreV1().Secrets(kcpNamespaceName).Get(ctx, tokenSecret.Name, metav1.GetOptions{})
+		if err != nil {
+			klog.V(5).Infof("failed to retrieve Secret: %v", err)
+			return false, nil
+		}
+		token = string(secret.Data[corev1.ServiceAccountTokenKey])
+		return token != "", nil
+	})
+	if err != nil {
+		return fmt.Errorf("timed out waiting for token to be set on Secret %s/%s", kcpNamespaceName, tokenSecret.Name)
+	}
+
+	// Reference the new token first, for sync to output it
+	if err := c.patchServiceAccountSecrets(ctx, kubeClient, sa, append([]corev1.ObjectReference{{Name: tokenSecret.Name}}, sa.Secrets...)); err != nil {
+		return err
+	}
+
+	// Record when the previous tokens are to be revoked before outputting the new one, so that kcp
+	// revokes them even if this run is interrupted.
+	revokeAfter := time.Now().Add(overlap)
+	for i := range previousSecrets {
+		if deadline, ok := tokenRevokeAfter(&previousSecrets[i]); ok && !deadline.After(revokeAfter) {
+			continue
+		}
+		if err := markTokenForRevocation(ctx, kubeClient, &previousSecrets[i], revokeAfter); err != nil {
+			return err
+		}
+	}
+
+	if downstreamNamespace == "" {
+		downstreamNamespace = syncerID
+	}
+	secret, err := renderSyncerSecret(templateInput{
+		ServerURL:      configURL.Scheme + "://" + configURL.Host,
+		CAData:         base64.StdEncoding.EncodeToString(config.CAData),
+		Token:          token,
+		KCPNamespace:   kcpNamespaceName,
+		Namespace:      downstreamNamespace,
+		LogicalCluster: currentClusterName.String(),
+		SyncTarget:     syncTargetName,
+	}, syncerID)
+	if err != nil {
+		return err
+	}
+	secret = append(secret, '\n')
+
+	if outputFilePath == "-" {
+		if _, err := os.Stdout.Write(secret); err != nil {
+			return err
+		}
+	} else {
+		if err := os.WriteFile(outputFilePath, secret, 0600); err != nil {
+			return err
+		}
+		// nolint: errcheck
+		c.ErrOut.Write([]byte(fmt.Sprintf("\nWrote the syncer Secret with the new token to %s. Use\n\n  KUBECONFIG=<pcluster-config> kubectl apply -f %q\n\nto apply it.\n", outputFilePath, outputFilePath)))
+	}
+
+	return c.revokeExpiredTokens(ctx, kubeClient, syncTargetName, sa, previousSecrets, time.Now())
+}
+
+func getSyncerTokenSecrets(ctx context.Context, kubeClient kubernetesclientset.Interface, syncTargetName, kcpNamespaceName, syncerID string) (*corev1.ServiceAccount, []corev1.Secret, error) {
+	sa, err := kubeClient.CoreV1().ServiceAccounts(kcpNamespaceName).Get(ctx, syncerID, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil, fmt.Errorf("the syncer of synctarget %q has no ServiceAccount %s/%s, run sync first", syncTargetName, kcpNamespaceName, syncerID)
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the ServiceAccount %s|%s/%s: %w", syncTargetName, kcpNamespaceName, syncerID, err)
+	}
+
+	secrets, err := kubeClient.CoreV1().Secrets(kcpNamespaceName).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list Secrets in namespace %q: %w", kcpNamespaceName, err)
+	}
+	return sa, tokenSecrets(secrets.Items, sa.Name), nil
+}
+
+// revokeExpiredTokens deletes the given token secrets whose revocation time has passed, and removes their
+// references from the service account. The tokens still to be revoked by kcp are reported.
+func (c *Config) revokeExpiredTokens(ctx context.Context, kubeClient kubernetesclientset.Interface, syncTargetName string, sa *corev1.ServiceAccount, secrets []corev1.Secret, now time.Time) error {
+	expired, pending := expiredTokenSecrets(secrets, now)
+	for _, secret := range pending {
+		deadline, _ := tokenRevokeAfter(&secret)
+		// nolint: errcheck
+		c.ErrOut.Write([]byte(fmt.Sprintf("Token %q will be revoked by kcp after %s\n", secret.Name, deadline.Format(time.RFC3339))))
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	for _, secret := range expired {
+		c.ErrOut.Write([]byte(fmt.Sprintf("Revoking token %q\n", secret.Name))) // nolint: errcheck
+		if err := kubeClient.CoreV1().Secrets(secret.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete token Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+	}
+
+	current, err := kubeClient.CoreV1().ServiceAccounts(sa.Namespace).Get(ctx, sa.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get the ServiceAccount %s|%s/%s: %w", syncTargetName, sa.Namespace, sa.Name, err)
+	}
+	return c.patchServiceAccountSecrets(ctx, kubeClient, current, withoutSecrets(current.Secrets, expired))
+}
+
+// expiredTokenSecrets splits the token secrets marked for revocation into the ones whose revocation time
+// has passed, and the ones still to be revoked. The secrets not marked are left out.
+func expiredTokenSecrets(secrets []corev1.Secret, now time.Time) (expired, pending []corev1.Secret) {
+	for i := range secrets {
+		deadline, ok := tokenRevokeAfter(&secrets[i])
+		if !ok {
+			continue
+		}
+		if deadline.After(now) {
+			pending = append(pending, secrets[i])
+		} else {
+			expired = append(expired, secrets[i])
+		}
+	}
+	return expired, pending
+}
+
+// tokenRevokeAfter returns the time after which the given token secret is to be revoked, if it is marked for
+// revocation. A mark that cannot be parsed revokes the token right away.
+func tokenRevokeAfter(secret *corev1.Secret) (time.Time, bool) {
+	value, found := secret.Annotations[workloadv1alpha1.RevokeTokenAfterAnnotation]
+	if !found {
+		return time.Time{}, false
+	}
+	deadline, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		klog.Warningf("Invalid %s annotation on Secret %s/%s: %v", workloadv1alpha1.RevokeTokenAfterAnnotation, secret.Namespace, secret.Name, err)
+		return time.Time{}, true
+	}
+	return deadline, true
+}
+
+func markTokenForRevocation(ctx context.Context, kubeClient kubernetesclientset.Interface, secret *corev1.Secret, revokeAfter time.Time) error {
+	patchBytes, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				workloadv1alpha1.RevokeTokenAfterAnnotation: revokeAfter.UTC().Format(time.RFC3339),
+			},
+			"uid":             secret.UID,
+			"resourceVersion": secret.ResourceVersion,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create patch for Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+	patched, err := kubeClient.CoreV1().Secrets(secret.Namespace).Patch(ctx, secret.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to mark token Secret %s/%s for revocation: %w", secret.Namespace, secret.Name, err)
+	}
+	*secret = *patched
+	return nil
+}
+
+// tokenSecrets returns the service account token secrets of the given service account.
+func tokenSecrets(secrets []corev1.Secret, serviceAccountName string) []corev1.Secret {
+	var tokens []corev1.Secret
+	for _, secret := range secrets {
+		if secret.Type == corev1.SecretTypeServiceAccountToken && secret.Annotations[corev1.ServiceAccountNameKey] == serviceAccountName {
+			tokens = append(tokens, secret)
+		}
+	}
+	return tokens
+}
+
+// withoutSecrets returns the secret references, except the ones to the given secrets.
+func withoutSecrets(references []corev1.ObjectReference, secrets []corev1.Secret) []corev1.ObjectReference {
+	removed := make(map[string]bool, len(secrets))
+	for _, secret := range secrets {
+		removed[secret.Name] = true
+	}
+	var remaining []corev1.ObjectReference
+	for _, reference := range references {
+		if !removed[reference.Name] {
+			remaining = append(remaining, reference)
+		}
+	}
+	return remaining
+}
+
+func (c *Config) patchServiceAccountSecrets(ctx context.Context, kubeClient kubernetesclientset.Interface, sa *corev1.ServiceAccount, secrets []corev1.ObjectReference) error {
+	oldData, err := json.Marshal(corev1.ServiceAccount{
+		Secrets: sa.Secrets,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal old data for ServiceAccount %s/%s: %w", sa.Namespace, sa.Name, err)
+	}
+
+	newData, err := json.Marshal(corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:             sa.UID,
+			ResourceVersion: sa.ResourceVersion,
+		},
+		Secrets: secrets,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal new data for ServiceAccount %s/%s: %w", sa.Namespace, sa.Name, err)
+	}
+
+	patchBytes, err := jsonpatch.CreateMergePatch(oldData, newData)
+	if err != nil {
+		return fmt.Errorf("failed to create patch for ServiceAccount %s/%s: %w", sa.Namespace, sa.Name, err)
+	}
+
+	if _, err := kubeClient.CoreV1().ServiceAccounts(sa.Namespace).Patch(ctx, sa.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to patch ServiceAccount %s/%s: %w", sa.Namespace, sa.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+func TestNewSyncerSecretYAML(t *testing.T) {
+	expectedYAML := `apiVersion: v1
+kind: Secret
+metadata:
+  name: kcp-syncer-sync-target-name-34b23c4k
+  namespace: kcp-syncer-sync-target-name-34b23c4k
+stringData:
+  kubeconfig: |
+    apiVersion: v1
+    kind: Config
+    clusters:
+    - name: default-cluster
+      cluster:
+        certificate-authority-data: ca-data
+        server: server-url
+    contexts:
+    - name: default-context
+      context:
+        cluster: default-cluster
+        namespace: kcp-namespace
+        user: default-user
+    current-context: default-context
+    users:
+    - name: default-user
+      user:
+        token: new-token`
+	actualYAML, err := renderSyncerSecret(templateInput{
+		ServerURL:      "server-url",
+		Token:          "new-token",
+		CAData:         "ca-data",
+		KCPNamespace:   "kcp-namespace",
+		Namespace:      "kcp-syncer-sync-target-name-34b23c4k",
+		LogicalCluster: "root:default:foo",
+		SyncTarget:     "sync-target-name",
+	}, "kcp-syncer-sync-target-name-34b23c4k")
+	require.NoError(t, err)
+	require.Empty(t, cmp.Diff(expectedYAML, string(actualYAML)))
+}
+
+func TestTokenSecrets(t *testing.T) {
+	secret := func(name string, secretType corev1.SecretType, serviceAccountName string) corev1.Secret {
+		return corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{corev1.ServiceAccountNameKey: serviceAccountName},
+			},
+			Type: secretType,
+		}
+	}
+	secrets := []corev1.Secret{
+		secret("syncer-token-1", corev1.SecretTypeServiceAccountToken, "syncer"),
+		secret("other-token", corev1.SecretTypeServiceAccountToken, "other"),
+		secret("syncer-opaque", corev1.SecretTypeOpaque, "syncer"),
+		secret("syncer-token-2", corev1.SecretTypeServiceAccountToken, "syncer"),
+	}
+
+	tokens := tokenSecrets(secrets, "syncer")
+	require.Equal(t, []corev1.Secret{secrets[0], secrets[3]}, tokens)
+
+	references := []corev1.ObjectReference{{Name: "syncer-token-3"}, {Name: "syncer-token-1"}, {Name: "syncer-token-2"}}
+	require.Equal(t, []corev1.ObjectReference{{Name: "syncer-token-3"}}, withoutSecrets(references, tokens))
+	require.Empty(t, withoutSecrets(references[1:], tokens))
+}
+
+func TestExpiredTokenSecrets(t *testing.T) {
+	now := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	secret := func(name string, revokeAfter string) corev1.Secret {
+		secret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if revokeAfter != "" {
+			secret.Annotations = map[string]string{workloadv1alpha1.RevokeTokenAfterAnnotation: revokeAfter}
+		}
+		return secret
+	}
+	secrets := []corev1.Secret{
+		secret("current", ""),
+		secret("expired", now.Add(-time.Minute).Format(time.RFC3339)),
+		secret("due", now.Format(time.RFC3339)),
+		secret("pending", now.Add(time.Minute).Format(time.RFC3339)),
+		secret("invalid", "soon"),
+	}
+
+	expired, pending := expiredTokenSecrets(secrets, now)
+	require.Equal(t, []corev1.Secret{secrets[1], secrets[2], secrets[4]}, expired)
+	require.Equal(t, []corev1.Secret{secrets[3]}, pending)
+}
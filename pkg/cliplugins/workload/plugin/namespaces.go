@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+// namespaceMapping is a namespace created by a syncer in a physical cluster, and the upstream namespace it is
+// synced from, as recorded in its namespace locator.
+type namespaceMapping struct {
+	namespace         string
+	syncTargetName    string
+	workspace         string
+	upstreamNamespace string
+}
+
+// Namespaces lists the namespaces created by syncers in the physical cluster of the current kubeconfig context,
+// with the workspaces and namespaces they are synced from. If syncTargetName is not empty, only the namespaces
+// created for this sync target are listed.
+func (c *Config) Namespaces(ctx context.Context, syncTargetName string) error {
+	config, err := clientcmd.NewDefaultClientConfig(*c.startingConfig, c.overrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	selector := workloadv1alpha1.InternalDownstreamClusterLabel
+	if syncTargetName != "" {
+		selector += "=" + syncTargetName
+	}
+	namespaces, err := kubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	return printNamespaceMappings(c.Out, namespaceMappings(namespaces.Items))
+}
+
+// namespaceMappings returns the mappings of the given namespaces, read from their namespace locators.
+func namespaceMappings(namespaces []corev1.Namespace) []namespaceMapping {
+	mappings := make([]namespaceMapping, 0, len(namespaces))
+	for _, ns := range namespaces {
+		mapping := namespaceMapping{
+			namespace:         ns.Name,
+			syncTargetName:    ns.Labels[workloadv1alpha1.InternalDownstreamClusterLabel],
+			workspace:         "<none>",
+			upstreamNamespace: "<none>",
+		}
+		locator, exists, err := shared.LocatorFromAnnotations(ns.Annotations)
+		if err != nil {
+			mapping.workspace, mapping.upstreamNamespace = "<invalid>", "<invalid>"
+		} else if exists {
+			mapping.syncTargetName = locator.SyncTarget.Name
+			mapping.workspace = locator.Workspace.String()
+			mapping.upstreamNamespace = locator.Namespace
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings
+}
+
+// printNamespaceMappings prints the mappings as a table.
+func printNamespaceMappings(out io.Writer, mappings []namespaceMapping) error {
+	if len(mappings) == 0 {
+		_, err := fmt.Fprintln(out, "No synced namespaces found")
+		return err
+	}
+
+	sort.Slice(mappings, func(i, j int) bool {
+		a, b := mappings[i], mappings[j]
+		if a.syncTargetName != b.syncTargetName {
+			return a.syncTargetName < b.syncTargetName
+		}
+		return a.namespace < b.namespace
+	})
+
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "SyncTarget", Type: "string"},
+			{Name: "Namespace", Type: "string"},
+			{Name: "Workspace", Type: "string"},
+			{Name: "Upstream Namespace", Type: "string"},
+		},
+	}
+	for _, mapping := range mappings {
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []interface{}{mapping.syncTargetName, mapping.namespace, mapping.workspace, mapping.upstreamNamespace},
+		})
+	}
+
+	return printers.NewTablePrinter(printers.PrintOptions{}).PrintObj(table, out)
+}
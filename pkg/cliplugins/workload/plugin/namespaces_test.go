@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNamespaceMappings(t *testing.T) {
+	ns := func(name, syncTargetName, locator string) corev1.Namespace {
+		n := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"internal.workload.kcp.dev/cluster": syncTargetName},
+		}}
+		if locator != "" {
+			n.Annotations = map[string]string{"kcp.dev/namespace-locator": locator}
+		}
+		return n
+	}
+	namespaces := []corev1.Namespace{
+		ns("root-org-ws-test", "us-west1", `{"syncTarget":{"workspace":"root:org","name":"us-west1","uid":"uid"},"workspace":"root:org:ws","namespace":"test"}`),
+		ns("kcp-hcbsa8z6c2er", "us-east1", `{"syncTarget":{"path":"root:org","name":"us-east1","uid":"uid"},"workspace":"root:org:other","namespace":"default"}`),
+		ns("kcp-broken", "us-east1", "garbage"),
+		ns("kcp-unknown", "us-west1", ""),
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, printNamespaceMappings(&out, namespaceMappings(namespaces)))
+	require.Equal(t, `SYNCTARGET   NAMESPACE          WORKSPACE        UPSTREAM NAMESPACE
+us-east1     kcp-broken         <invalid>        <invalid>
+us-east1     kcp-hcbsa8z6c2er   root:org:other   default
+us-west1     kcp-unknown        <none>           <none>
+us-west1     root-org-ws-test   root:org:ws      test
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, printNamespaceMappings(&out, nil))
+	require.Equal(t, "No synced namespaces found\n", out.String())
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+// pendingRemoval is a resource being removed from a sync target.
+type pendingRemoval struct {
+	resource         schema.GroupResource
+	namespace        string
+	name             string
+	syncTargetName   string
+	removalRequested string
+	blockedBy        []string
+}
+
+// PendingRemovals lists the resources of the current workspace that are being removed from sync targets,
+// with the finalizers holding their removal. If syncTargetName is not empty, only the resources being
+// removed from this sync target are listed.
+func (c *Config) PendingRemovals(ctx context.Context, syncTargetName string) error {
+	config, err := clientcmd.NewDefaultClientConfig(*c.startingConfig, c.overrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	resourceLists, err := discovery.ServerPreferredNamespacedResources(discoveryClient)
+	if err != nil && len(resourceLists) == 0 {
+		return fmt.Errorf("failed to discover resources: %w", err)
+	}
+
+	listOptions := metav1.ListOptions{}
+	if syncTargetName != "" {
+		listOptions.LabelSelector = workloadv1alpha1.ClusterResourceStateLabelPrefix + syncTargetName
+	}
+
+	var removals []pendingRemoval
+	for _, gvr := range listableResources(resourceLists) {
+		list, err := dynamicClient.Resource(gvr).List(ctx, listOptions)
+		if err != nil {
+			fmt.Fprintf(c.ErrOut, "Skipping %s: %v\n", gvr.GroupResource(), err)
+			continue
+		}
+		removals = append(removals, pendingRemovals(gvr.GroupResource(), list.Items, syncTargetName)...)
+	}
+
+	return printPendingRemovals(c.Out, removals)
+}
+
+// listableResources returns the resources of the given resource lists supporting the list verb.
+func listableResources(resourceLists []*metav1.APIResourceList) []schema.GroupVersionResource {
+	var gvrs []schema.GroupVersionResource
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			if strings.Contains(resource.Name, "/") || !sets.NewString(resource.Verbs...).Has("list") {
+				continue
+			}
+			gvrs = append(gvrs, gv.WithResource(resource.Name))
+		}
+	}
+	return gvrs
+}
+
+// pendingRemovals returns the removals in progress of the given objects, for all sync targets or
+// only for the given one.
+func pendingRemovals(resource schema.GroupResource, objs []unstructured.Unstructured, syncTargetName string) []pendingRemoval {
+	var removals []pendingRemoval
+	for i := range objs {
+		obj := &objs[i]
+		beingRemoved := shared.SyncTargetsBeingRemoved(obj)
+		syncTargets := sets.StringKeySet(beingRemoved).List()
+		for _, syncTarget := range syncTargets {
+			if syncTargetName != "" && syncTarget != syncTargetName {
+				continue
+			}
+			blockedBy := shared.ClusterFinalizers(obj, syncTarget)
+			for _, finalizer := range obj.GetFinalizers() {
+				if finalizer == shared.SyncerFinalizerNamePrefix+syncTarget {
+					blockedBy = append(blockedBy, finalizer)
+				}
+			}
+			removals = append(removals, pendingRemoval{
+				resource:         resource,
+				namespace:        obj.GetNamespace(),
+				name:             obj.GetName(),
+				syncTargetName:   syncTarget,
+				removalRequested: beingRemoved[syncTarget],
+				blockedBy:        blockedBy,
+			})
+		}
+	}
+	return removals
+}
+
+// printPendingRemovals prints the removals as a table.
+func printPendingRemovals(out io.Writer, removals []pendingRemoval) error {
+	if len(removals) == 0 {
+		_, err := fmt.Fprintln(out, "No pending removals found")
+		return err
+	}
+
+	sort.Slice(removals, func(i, j int) bool {
+		a, b := removals[i], removals[j]
+		if a.syncTargetName != b.syncTargetName {
+			return a.syncTargetName < b.syncTargetName
+		}
+		if a.resource.String() != b.resource.String() {
+			return a.resource.String() < b.resource.String()
+		}
+		if a.namespace != b.namespace {
+			return a.namespace < b.namespace
+		}
+		return a.name < b.name
+	})
+
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "SyncTarget", Type: "string"},
+			{Name: "Resource", Type: "string"},
+			{Name: "Namespace", Type: "string"},
+			{Name: "Name", Type: "string"},
+			{Name: "Removal Requested", Type: "string"},
+			{Name: "Blocked By", Type: "string"},
+		},
+	}
+	for _, removal := range removals {
+		blockedBy := "<none>"
+		if len(removal.blockedBy) > 0 {
+			blockedBy = strings.Join(removal.blockedBy, ",")
+		}
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []interface{}{removal.syncTargetName, removal.resource.String(), removal.namespace, removal.name, removal.removalRequested, blockedBy},
+		})
+	}
+
+	return printers.NewTablePrinter(printers.PrintOptions{}).PrintObj(table, out)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestPendingRemovals(t *testing.T) {
+	obj := func(namespace, name string, annotations map[string]string, finalizers ...string) unstructured.Unstructured {
+		u := unstructured.Unstructured{}
+		u.SetNamespace(namespace)
+		u.SetName(name)
+		u.SetAnnotations(annotations)
+		u.SetFinalizers(finalizers)
+		return u
+	}
+	objs := []unstructured.Unstructured{
+		obj("default", "synced", nil, "workload.kcp.dev/syncer-us-west1"),
+		obj("default", "held", map[string]string{
+			"deletion.internal.workload.kcp.dev/us-west1": "2022-08-01T10:00:00Z",
+			"finalizers.workload.kcp.dev/us-west1":        "backup.example.com",
+		}, "workload.kcp.dev/syncer-us-west1"),
+		obj("other", "removing", map[string]string{
+			"deletion.internal.workload.kcp.dev/us-east1": "2022-08-01T11:00:00Z",
+		}),
+	}
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+	removals := pendingRemovals(deployments, objs, "")
+	require.Len(t, removals, 2)
+
+	var out bytes.Buffer
+	require.NoError(t, printPendingRemovals(&out, removals))
+	require.Equal(t, `SYNCTARGET   RESOURCE           NAMESPACE   NAME       REMOVAL REQUESTED      BLOCKED BY
+us-east1     deployments.apps   other       removing   2022-08-01T11:00:00Z   <none>
+us-west1     deployments.apps   default     held       2022-08-01T10:00:00Z   backup.example.com,workload.kcp.dev/syncer-us-west1
+`, out.String())
+
+	removals = pendingRemovals(deployments, objs, "us-east1")
+	require.Len(t, removals, 1)
+	require.Equal(t, "removing", removals[0].name)
+
+	out.Reset()
+	require.NoError(t, printPendingRemovals(&out, nil))
+	require.Equal(t, "No pending removals found\n", out.String())
+}
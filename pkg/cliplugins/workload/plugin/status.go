@@ -0,0 +1,375 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	conditionsapi "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+)
+
+// syncTargetConditions are the conditions shown for each sync target, in this order.
+var syncTargetConditions = []conditionsapi.ConditionType{
+	workloadv1alpha1.SyncerReady,
+	workloadv1alpha1.APIImporterReady,
+	workloadv1alpha1.HeartbeatHealthy,
+}
+
+// placed holds the namespaces and the number of namespaced objects of the current workspace placed on a sync target.
+type placed struct {
+	namespaces []string
+	objects    int
+}
+
+// List lists the sync targets of the current workspace, with their readiness, schedulability, last heartbeat,
+// synced resources and number of namespaces of the current workspace placed on them.
+func (c *Config) List(ctx context.Context) error {
+	config, err := clientcmd.NewDefaultClientConfig(*c.startingConfig, c.overrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	kcpClient, err := kcpclientset.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kcp client: %w", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	syncTargets, err := kcpClient.WorkloadV1alpha1().SyncTargets().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list sync targets: %w", err)
+	}
+	namespaces, err := kubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	return printSyncTargets(c.Out, syncTargets.Items, namespaces.Items, time.Now())
+}
+
+// Status prints the readiness, schedulability, last heartbeat and conditions of the sync target, with a summary
+// of its synced resources and of what the current workspace placed on it.
+func (c *Config) Status(ctx context.Context, syncTargetName string) error {
+	config, err := clientcmd.NewDefaultClientConfig(*c.startingConfig, c.overrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	syncTarget, err := getSyncTarget(ctx, config, syncTargetName)
+	if err != nil {
+		return err
+	}
+	placed, err := c.placedOn(ctx, config, syncTargetName)
+	if err != nil {
+		return err
+	}
+
+	return printSyncTargetStatus(c.Out, syncTarget, placed, time.Now())
+}
+
+// Describe prints the status of the sync target, followed by its synced resources, the locations including it and
+// the namespaces of the current workspace placed on it.
+func (c *Config) Describe(ctx context.Context, syncTargetName string) error {
+	config, err := clientcmd.NewDefaultClientConfig(*c.startingConfig, c.overrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	syncTarget, err := getSyncTarget(ctx, config, syncTargetName)
+	if err != nil {
+		return err
+	}
+	placed, err := c.placedOn(ctx, config, syncTargetName)
+	if err != nil {
+		return err
+	}
+
+	kcpClient, err := kcpclientset.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kcp client: %w", err)
+	}
+	locations, err := kcpClient.SchedulingV1alpha1().Locations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list locations: %w", err)
+	}
+
+	return describeSyncTarget(c.Out, syncTarget, locationsOf(syncTarget, locations.Items), placed, time.Now())
+}
+
+func getSyncTarget(ctx context.Context, config *rest.Config, syncTargetName string) (*workloadv1alpha1.SyncTarget, error) {
+	kcpClient, err := kcpclientset.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kcp client: %w", err)
+	}
+	syncTarget, err := kcpClient.WorkloadV1alpha1().SyncTargets().Get(ctx, syncTargetName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SyncTarget %s: %w", syncTargetName, err)
+	}
+	return syncTarget, nil
+}
+
+// placedOn returns the namespaces and the number of namespaced objects of the current workspace with the
+// state.workload.kcp.dev/<sync-target-name> label. Only the current workspace is counted: the sync target
+// can be used by other workspaces, which are not necessarily visible to the user.
+func (c *Config) placedOn(ctx context.Context, config *rest.Config, syncTargetName string) (*placed, error) {
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: workloadv1alpha1.ClusterResourceStateLabelPrefix + syncTargetName}
+
+	ret := &placed{}
+	namespaces, err := kubeClient.CoreV1().Namespaces().List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	for _, ns := range namespaces.Items {
+		ret.namespaces = append(ret.namespaces, ns.Name)
+	}
+	sort.Strings(ret.namespaces)
+
+	resourceLists, err := discovery.ServerPreferredNamespacedResources(discoveryClient)
+	if err != nil && len(resourceLists) == 0 {
+		return nil, fmt.Errorf("failed to discover resources: %w", err)
+	}
+	for _, gvr := range listableResources(resourceLists) {
+		list, err := dynamicClient.Resource(gvr).List(ctx, listOptions)
+		if err != nil {
+			fmt.Fprintf(c.ErrOut, "Skipping %s: %v\n", gvr.GroupResource(), err)
+			continue
+		}
+		ret.objects += len(list.Items)
+	}
+
+	return ret, nil
+}
+
+// locationsOf returns the sorted names of the locations of sync targets selecting the given sync target.
+func locationsOf(syncTarget *workloadv1alpha1.SyncTarget, locations []schedulingv1alpha1.Location) []string {
+	var ret []string
+	for _, location := range locations {
+		if location.Spec.Resource.Group != workloadv1alpha1.SchemeGroupVersion.Group || location.Spec.Resource.Resource != "synctargets" {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(location.Spec.InstanceSelector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(syncTarget.Labels)) {
+			ret = append(ret, location.Name)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// printSyncTargets prints the sync targets as a table, with the number of the given namespaces placed on each.
+func printSyncTargets(out io.Writer, syncTargets []workloadv1alpha1.SyncTarget, namespaces []corev1.Namespace, now time.Time) error {
+	if len(syncTargets) == 0 {
+		_, err := fmt.Fprintln(out, "No sync targets found")
+		return err
+	}
+
+	sort.Slice(syncTargets, func(i, j int) bool {
+		return syncTargets[i].Name < syncTargets[j].Name
+	})
+
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string"},
+			{Name: "Ready", Type: "string"},
+			{Name: "Scheduling", Type: "string"},
+			{Name: "Last Heartbeat", Type: "string"},
+			{Name: "Synced Resources", Type: "string"},
+			{Name: "Workspace Namespaces", Type: "integer"},
+		},
+	}
+	for i := range syncTargets {
+		syncTarget := &syncTargets[i]
+		count := 0
+		for _, ns := range namespaces {
+			if _, found := ns.Labels[workloadv1alpha1.ClusterResourceStateLabelPrefix+syncTarget.Name]; found {
+				count++
+			}
+		}
+		accepted, _, _ := resourceStates(syncTarget)
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []interface{}{
+				syncTarget.Name,
+				conditionStatus(syncTarget, conditionsapi.ReadyCondition),
+				scheduling(syncTarget, now),
+				heartbeatAge(syncTarget, now),
+				fmt.Sprintf("%d/%d", accepted, len(syncTarget.Status.SyncedResources)),
+				count,
+			},
+		})
+	}
+
+	return printers.NewTablePrinter(printers.PrintOptions{}).PrintObj(table, out)
+}
+
+// printSyncTargetStatus prints the status of the sync target.
+func printSyncTargetStatus(out io.Writer, syncTarget *workloadv1alpha1.SyncTarget, placed *placed, now time.Time) error {
+	w := printers.GetNewTabWriter(out)
+	writeSyncTargetStatus(w, syncTarget, placed, now)
+	return w.Flush()
+}
+
+// describeSyncTarget prints the status of the sync target, its synced resources, the given locations and the
+// namespaces placed on it.
+func describeSyncTarget(out io.Writer, syncTarget *workloadv1alpha1.SyncTarget, locations []string, placed *placed, now time.Time) error {
+	w := printers.GetNewTabWriter(out)
+	writeSyncTargetStatus(w, syncTarget, placed, now)
+
+	fmt.Fprintf(w, "Synced Resources:\n")
+	if len(syncTarget.Status.SyncedResources) == 0 {
+		fmt.Fprintf(w, "  <none>\n")
+	} else {
+		fmt.Fprintf(w, "  RESOURCE\tVERSIONS\tSTATE\n")
+		resources := append([]workloadv1alpha1.ResourceToSync{}, syncTarget.Status.SyncedResources...)
+		sort.Slice(resources, func(i, j int) bool {
+			return groupResource(resources[i]) < groupResource(resources[j])
+		})
+		for _, resource := range resources {
+			state := string(resource.State)
+			if state == "" {
+				state = workloadv1alpha1.ResourceSchemaPendingState
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", groupResource(resource), strings.Join(resource.Versions, ","), state)
+		}
+	}
+
+	fmt.Fprintf(w, "Locations:\t%s\n", joinOrNone(locations))
+	fmt.Fprintf(w, "Namespaces (this workspace):\t%s\n", joinOrNone(placed.namespaces))
+
+	return w.Flush()
+}
+
+func writeSyncTargetStatus(w io.Writer, syncTarget *workloadv1alpha1.SyncTarget, placed *placed, now time.Time) {
+	fmt.Fprintf(w, "Name:\t%s\n", syncTarget.Name)
+	fmt.Fprintf(w, "Ready:\t%s\n", conditionStatus(syncTarget, conditionsapi.ReadyCondition))
+	fmt.Fprintf(w, "Scheduling:\t%s\n", scheduling(syncTarget, now))
+	heartbeat := heartbeatAge(syncTarget, now)
+	if syncTarget.Status.ActiveSyncer != "" {
+		heartbeat += fmt.Sprintf(" (syncer %s)", syncTarget.Status.ActiveSyncer)
+	}
+	fmt.Fprintf(w, "Last Heartbeat:\t%s\n", heartbeat)
+	fmt.Fprintf(w, "Conditions:\t\n")
+	for _, conditionType := range syncTargetConditions {
+		status := conditionStatus(syncTarget, conditionType)
+		if reason := conditions.GetReason(syncTarget, conditionType); reason != "" {
+			status += fmt.Sprintf(" (%s: %s)", reason, conditions.GetMessage(syncTarget, conditionType))
+		}
+		fmt.Fprintf(w, "  %s:\t%s\n", conditionType, status)
+	}
+	accepted, incompatible, pending := resourceStates(syncTarget)
+	fmt.Fprintf(w, "Synced Resources:\t%d accepted, %d incompatible, %d pending\n", accepted, incompatible, pending)
+	fmt.Fprintf(w, "Placed (this workspace):\t%d namespaces, %d objects\n", len(placed.namespaces), placed.objects)
+}
+
+// resourceStates counts the synced resources of the sync target by state.
+func resourceStates(syncTarget *workloadv1alpha1.SyncTarget) (accepted, incompatible, pending int) {
+	for _, resource := range syncTarget.Status.SyncedResources {
+		switch resource.State {
+		case workloadv1alpha1.ResourceSchemaAcceptedState:
+			accepted++
+		case workloadv1alpha1.ResourceSchemaIncomptibleState:
+			incompatible++
+		default:
+			pending++
+		}
+	}
+	return accepted, incompatible, pending
+}
+
+// scheduling describes whether new workloads can be scheduled to the sync target, and whether its workloads are
+// being evicted.
+func scheduling(syncTarget *workloadv1alpha1.SyncTarget, now time.Time) string {
+	if !syncTarget.Spec.Unschedulable {
+		return "Schedulable"
+	}
+	if maxUnavailable, found := syncTarget.Annotations[workloadv1alpha1.DrainMaxUnavailableAnnotation]; found {
+		return fmt.Sprintf("Draining (max unavailable %s)", maxUnavailable)
+	}
+	if syncTarget.Spec.EvictAfter != nil {
+		if now.Before(syncTarget.Spec.EvictAfter.Time) {
+			return fmt.Sprintf("Draining in %s", duration.HumanDuration(syncTarget.Spec.EvictAfter.Sub(now)))
+		}
+		return "Draining"
+	}
+	return "Cordoned"
+}
+
+func heartbeatAge(syncTarget *workloadv1alpha1.SyncTarget, now time.Time) string {
+	if syncTarget.Status.LastSyncerHeartbeatTime == nil {
+		return "<never>"
+	}
+	return duration.HumanDuration(now.Sub(syncTarget.Status.LastSyncerHeartbeatTime.Time)) + " ago"
+}
+
+func conditionStatus(syncTarget *workloadv1alpha1.SyncTarget, conditionType conditionsapi.ConditionType) string {
+	condition := conditions.Get(syncTarget, conditionType)
+	if condition == nil {
+		return "Unknown"
+	}
+	return string(condition.Status)
+}
+
+// groupResource returns the resource in the <resource>.<group> form.
+func groupResource(resource workloadv1alpha1.ResourceToSync) string {
+	return schema.GroupResource{Group: resource.Group, Resource: resource.Resource}.String()
+}
+
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "<none>"
+	}
+	return strings.Join(values, ", ")
+}
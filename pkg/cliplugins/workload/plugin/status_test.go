@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	conditionsapi "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+func TestSyncTargetStatus(t *testing.T) {
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	ready := &workloadv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "us-west1",
+			Labels: map[string]string{"region": "us-west"},
+		},
+		Status: workloadv1alpha1.SyncTargetStatus{
+			Conditions: conditionsapi.Conditions{
+				{Type: conditionsapi.ReadyCondition, Status: corev1.ConditionTrue},
+				{Type: workloadv1alpha1.SyncerReady, Status: corev1.ConditionTrue},
+				{Type: workloadv1alpha1.APIImporterReady, Status: corev1.ConditionFalse, Reason: "ErrorStartingAPIImporter", Message: "no access"},
+				{Type: workloadv1alpha1.HeartbeatHealthy, Status: corev1.ConditionTrue},
+			},
+			SyncedResources: []workloadv1alpha1.ResourceToSync{
+				{GroupResource: apisv1alpha1.GroupResource{Group: "apps", Resource: "deployments"}, Versions: []string{"v1"}, State: workloadv1alpha1.ResourceSchemaAcceptedState},
+				{GroupResource: apisv1alpha1.GroupResource{Resource: "services"}, Versions: []string{"v1"}, State: workloadv1alpha1.ResourceSchemaAcceptedState},
+				{GroupResource: apisv1alpha1.GroupResource{Group: "networking.k8s.io", Resource: "ingresses"}, Versions: []string{"v1", "v1beta1"}, State: workloadv1alpha1.ResourceSchemaIncomptibleState},
+			},
+			LastSyncerHeartbeatTime: &metav1.Time{Time: now.Add(-12 * time.Second)},
+			ActiveSyncer:            "kcp-syncer-1",
+		},
+	}
+	cordoned := &workloadv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "us-east1",
+			Annotations: map[string]string{
+				workloadv1alpha1.DrainMaxUnavailableAnnotation: "10%",
+			},
+		},
+		Spec: workloadv1alpha1.SyncTargetSpec{
+			Unschedulable: true,
+		},
+	}
+	namespace := func(name string, syncTargets ...string) corev1.Namespace {
+		ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+		for _, syncTarget := range syncTargets {
+			ns.Labels[workloadv1alpha1.ClusterResourceStateLabelPrefix+syncTarget] = string(workloadv1alpha1.ResourceStateSync)
+		}
+		return ns
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, printSyncTargets(&out, []workloadv1alpha1.SyncTarget{*ready, *cordoned}, []corev1.Namespace{
+		namespace("default", "us-west1"),
+		namespace("shop", "us-west1", "us-east1"),
+		namespace("other"),
+	}, now))
+	require.Equal(t, `NAME       READY     SCHEDULING                       LAST HEARTBEAT   SYNCED RESOURCES   WORKSPACE NAMESPACES
+us-east1   Unknown   Draining (max unavailable 10%)   <never>          0/0                1
+us-west1   True      Schedulable                      12s ago          2/3                2
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, printSyncTargets(&out, nil, nil, now))
+	require.Equal(t, "No sync targets found\n", out.String())
+
+	out.Reset()
+	require.NoError(t, printSyncTargetStatus(&out, ready, &placed{namespaces: []string{"default", "shop"}, objects: 7}, now))
+	require.Equal(t, `Name:                      us-west1
+Ready:                     True
+Scheduling:                Schedulable
+Last Heartbeat:            12s ago (syncer kcp-syncer-1)
+`+"Conditions:                \n"+`  SyncerReady:             True
+  APIImporterReady:        False (ErrorStartingAPIImporter: no access)
+  HeartbeatHealthy:        True
+Synced Resources:          2 accepted, 1 incompatible, 0 pending
+Placed (this workspace):   2 namespaces, 7 objects
+`, out.String())
+
+	locations := locationsOf(ready, []schedulingv1alpha1.Location{
+		newLocation("west", map[string]string{"region": "us-west"}),
+		newLocation("east", map[string]string{"region": "us-east"}),
+		newLocation("all", nil),
+	})
+	require.Equal(t, []string{"all", "west"}, locations)
+
+	out.Reset()
+	require.NoError(t, describeSyncTarget(&out, cordoned, nil, &placed{}, now))
+	require.Equal(t, `Name:                      us-east1
+Ready:                     Unknown
+Scheduling:                Draining (max unavailable 10%)
+Last Heartbeat:            <never>
+`+"Conditions:                \n"+`  SyncerReady:             Unknown
+  APIImporterReady:        Unknown
+  HeartbeatHealthy:        Unknown
+Synced Resources:          0 accepted, 0 incompatible, 0 pending
+Placed (this workspace):   0 namespaces, 0 objects
+Synced Resources:
+  <none>
+Locations:                     <none>
+Namespaces (this workspace):   <none>
+`, out.String())
+}
+
+func newLocation(name string, matchLabels map[string]string) schedulingv1alpha1.Location {
+	return schedulingv1alpha1.Location{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: schedulingv1alpha1.LocationSpec{
+			Resource:         schedulingv1alpha1.GroupVersionResource{Group: "workload.kcp.dev", Version: "v1alpha1", Resource: "synctargets"},
+			InstanceSelector: &metav1.LabelSelector{MatchLabels: matchLabels},
+		},
+	}
+}
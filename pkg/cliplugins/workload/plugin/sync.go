@@ -267,6 +267,11 @@ func (c *Config) enableSyncerForWorkspace(ctx context.Context, config *rest.Conf
 			APIGroups: []string{apiresourcev1alpha1.SchemeGroupVersion.Group},
 			Resources: []string{"apiresourceimports"},
 		},
+		{
+			Verbs:     []string{"create", "update", "patch"},
+			APIGroups: []string{""},
+			Resources: []string{"events"},
+		},
 	}
 
 	cr, err := kubeClient.RbacV1().ClusterRoles().Get(ctx,
@@ -274,7 +279,7 @@ func (c *Config) enableSyncerForWorkspace(ctx context.Context, config *rest.Conf
 		metav1.GetOptions{})
 	switch {
 	case errors.IsNotFound(err):
-		c.ErrOut.Write([]byte(fmt.Sprintf("Creating cluster role %q to give service account %q\n\n 1. write and sync access to the synctarget %q\n 2. write access to apiresourceimports.\n\n", syncerID, syncerID, syncerID))) // nolint: errcheck
+		c.ErrOut.Write([]byte(fmt.Sprintf("Creating cluster role %q to give service account %q\n\n 1. write and sync access to the synctarget %q\n 2. write access to apiresourceimports\n 3. write access to events.\n\n", syncerID, syncerID, syncerID))) // nolint: errcheck
 		if _, err = kubeClient.RbacV1().ClusterRoles().Create(ctx, &rbacv1.ClusterRole{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:            syncerID,
@@ -312,7 +317,7 @@ func (c *Config) enableSyncerForWorkspace(ctx context.Context, config *rest.Conf
 			return "", "", fmt.Errorf("failed to create patch for ClusterRole %s|%s: %w", syncTargetName, syncerID, err)
 		}
 
-		c.ErrOut.Write([]byte(fmt.Sprintf("Updating cluster role %q with\n\n 1. write and sync access to the synctarget %q\n 2. write access to apiresourceimports.\n\n", syncerID, syncerID))) // nolint: errcheck
+		c.ErrOut.Write([]byte(fmt.Sprintf("Updating cluster role %q with\n\n 1. write and sync access to the synctarget %q\n 2. write access to apiresourceimports\n 3. write access to events.\n\n", syncerID, syncerID))) // nolint: errcheck
 		if _, err = kubeClient.RbacV1().ClusterRoles().Patch(ctx, cr.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil {
 			return "", "", fmt.Errorf("failed to patch ClusterRole %s|%s/%s: %w", syncTargetName, syncerID, namespace, err)
 		}
@@ -435,7 +440,8 @@ type templateInput struct {
 	ResourcesToSync []string
 	// Image is the name of the container image that the syncer deployment will use
 	Image string
-	// Replicas is the number of syncer pods to run (should be 0 or 1).
+	// Replicas is the number of syncer pods to run. Only the pod holding the leader
+	// election lease is actively syncing.
 	Replicas int
 	// QPS is the qps the syncer uses when talking to an apiserver.
 	QPS float32
@@ -459,6 +465,12 @@ type templateArgs struct {
 	// ClusterRoleBinding is the name of the cluster role binding to create for the
 	// syncer on the pcluster.
 	ClusterRoleBinding string
+	// Role is the name of the role to create for the syncer in the syncer namespace
+	// on the pcluster, granting access to the leader election lease.
+	Role string
+	// RoleBinding is the name of the role binding to create for the syncer in the
+	// syncer namespace on the pcluster.
+	RoleBinding string
 	// GroupMappings is the mapping of api group to resources that will be used to
 	// define the cluster role rules for the syncer in the pcluster. The syncer will be
 	// granted full permissions for the resources it will synchronize.
@@ -483,29 +495,39 @@ type templateArgs struct {
 // cluster role and role binding would be owned by the namespace to ensure cleanup on deletion
 // of the namespace.
 func renderSyncerResources(input templateInput, syncerID string) ([]byte, error) {
-	tmplArgs := templateArgs{
+	return renderTemplate("syncer.yaml", newTemplateArgs(input, syncerID))
+}
+
+// renderSyncerSecret renders the secret holding the kubeconfig the syncer uses to connect to kcp.
+func renderSyncerSecret(input templateInput, syncerID string) ([]byte, error) {
+	return renderTemplate("kubeconfig-secret", newTemplateArgs(input, syncerID))
+}
+
+func newTemplateArgs(input templateInput, syncerID string) templateArgs {
+	return templateArgs{
 		templateInput:           input,
 		LabelSafeLogicalCluster: strings.ReplaceAll(input.LogicalCluster, ":", "_"),
 		ServiceAccount:          syncerID,
 		ClusterRole:             syncerID,
 		ClusterRoleBinding:      syncerID,
+		Role:                    syncerID,
+		RoleBinding:             syncerID,
 		GroupMappings:           getGroupMappings(input.ResourcesToSync),
 		Secret:                  syncerID,
 		SecretConfigKey:         SyncerSecretConfigKey,
 		Deployment:              syncerID,
 		DeploymentApp:           syncerID,
 	}
+}
 
-	syncerTemplate, err := embeddedResources.ReadFile("syncer.yaml")
-	if err != nil {
-		return nil, err
-	}
-	tmpl, err := template.New("syncerTemplate").Parse(string(syncerTemplate))
+// renderTemplate renders the named template of the embedded resources.
+func renderTemplate(name string, tmplArgs templateArgs) ([]byte, error) {
+	tmpl, err := template.ParseFS(embeddedResources, "*.yaml")
 	if err != nil {
 		return nil, err
 	}
 	buffer := bytes.NewBuffer([]byte{})
-	err = tmpl.Execute(buffer, tmplArgs)
+	err = tmpl.ExecuteTemplate(buffer, name, tmplArgs)
 	if err != nil {
 		return nil, err
 	}
@@ -59,8 +59,11 @@ rules:
   - namespaces
   verbs:
   - "create"
+  - "get"
   - "list"
   - "watch"
+  - "patch"
+  - "delete"
 - apiGroups:
   - "apiextensions.k8s.io"
   resources:
@@ -69,6 +72,20 @@ rules:
   - "get"
   - "watch"
   - "list"
+- apiGroups:
+  - ""
+  resources:
+  - nodes
+  - pods
+  verbs:
+  - "list"
+  - "watch"
+- apiGroups:
+  - ""
+  resources:
+  - persistentvolumes
+  verbs:
+  - "get"
 - apiGroups:
   - ""
   resources:
@@ -90,6 +107,35 @@ subjects:
   name: kcp-syncer-sync-target-name-34b23c4k
   namespace: kcp-syncer-sync-target-name-34b23c4k
 ---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: kcp-syncer-sync-target-name-34b23c4k
+  namespace: kcp-syncer-sync-target-name-34b23c4k
+rules:
+- apiGroups:
+  - "coordination.k8s.io"
+  resources:
+  - leases
+  verbs:
+  - "get"
+  - "create"
+  - "update"
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: kcp-syncer-sync-target-name-34b23c4k
+  namespace: kcp-syncer-sync-target-name-34b23c4k
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: kcp-syncer-sync-target-name-34b23c4k
+subjects:
+- kind: ServiceAccount
+  name: kcp-syncer-sync-target-name-34b23c4k
+  namespace: kcp-syncer-sync-target-name-34b23c4k
+---
 apiVersion: v1
 kind: Secret
 metadata:
@@ -145,9 +191,23 @@ spec:
         - --resources=resource2
         - --qps=123.4
         - --burst=456
+        - --leader-elect
+        - --leader-election-namespace=kcp-syncer-sync-target-name-34b23c4k
+        - --metrics-bind-address=:8080
         image: image
         imagePullPolicy: IfNotPresent
         terminationMessagePolicy: FallbackToLogsOnError
+        ports:
+        - name: metrics
+          containerPort: 8080
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: metrics
+        readinessProbe:
+          httpGet:
+            path: /readyz
+            port: metrics
         volumeMounts:
         - name: kcp-config
           mountPath: /kcp/
@@ -177,6 +237,30 @@ spec:
 	require.Empty(t, cmp.Diff(expectedYAML, string(actualYAML)))
 }
 
+func TestNewSyncerYAMLWithReplicas(t *testing.T) {
+	actualYAML, err := renderSyncerResources(templateInput{
+		ServerURL:       "server-url",
+		Token:           "token",
+		CAData:          "ca-data",
+		KCPNamespace:    "kcp-namespace",
+		Namespace:       "kcp-syncer-sync-target-name-34b23c4k",
+		LogicalCluster:  "root:default:foo",
+		SyncTarget:      "sync-target-name",
+		Image:           "image",
+		Replicas:        3,
+		ResourcesToSync: []string{"resource1", "resource2"},
+		QPS:             123.4,
+		Burst:           456,
+	}, "kcp-syncer-sync-target-name-34b23c4k")
+	require.NoError(t, err)
+	require.Contains(t, string(actualYAML), `
+spec:
+  replicas: 3
+  strategy:
+    type: RollingUpdate
+`)
+}
+
 func TestGetGroupMappings(t *testing.T) {
 	testCases := []struct {
 		name     string
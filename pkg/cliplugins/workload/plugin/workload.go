@@ -18,14 +18,18 @@ package plugin
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 )
 
@@ -92,6 +96,9 @@ func modifyCordon(ctx context.Context, config *rest.Config, syncTargetName strin
 		if syncTarget.Spec.EvictAfter != nil {
 			evict = `,{"op":"remove","path":"/spec/evictAfter"}`
 		}
+		if _, found := syncTarget.Annotations[workloadv1alpha1.DrainMaxUnavailableAnnotation]; found {
+			evict += `,{"op":"remove","path":"/metadata/annotations/` + strings.ReplaceAll(workloadv1alpha1.DrainMaxUnavailableAnnotation, "/", "~1") + `"}`
+		}
 
 		patchBytes = []byte(`[{"op":"replace","path":"/spec/unschedulable","value":false}` + evict + `]`)
 	}
@@ -112,8 +119,16 @@ func modifyCordon(ctx context.Context, config *rest.Config, syncTargetName strin
 	return nil
 }
 
-// Start draining the sync target and mark it as unschedulable
-func (c *Config) Drain(ctx context.Context, syncTargetName string) error {
+// Start draining the sync target and mark it as unschedulable. With a max unavailable budget, the namespaces
+// are moved off the sync target in batches instead of at once.
+func (c *Config) Drain(ctx context.Context, syncTargetName, maxUnavailable string) error {
+	if maxUnavailable != "" {
+		budget := intstr.Parse(maxUnavailable)
+		if _, err := intstr.GetScaledValueFromIntOrPercent(&budget, 100, true); err != nil {
+			return fmt.Errorf("invalid --max-unavailable %q: %w", maxUnavailable, err)
+		}
+	}
+
 	config, err := clientcmd.NewDefaultClientConfig(*c.startingConfig, c.overrides).ClientConfig()
 	if err != nil {
 		return err
@@ -132,6 +147,34 @@ func (c *Config) Drain(ctx context.Context, syncTargetName string) error {
 		return fmt.Errorf("failed to get synctarget %s: %w", syncTargetName, err)
 	}
 
+	if maxUnavailable != "" {
+		// See if there is nothing to do
+		if syncTarget.Spec.Unschedulable && syncTarget.Annotations[workloadv1alpha1.DrainMaxUnavailableAnnotation] == maxUnavailable {
+			fmt.Println(syncTargetName, "already draining gracefully")
+			return nil
+		}
+
+		patchBytes, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					workloadv1alpha1.DrainMaxUnavailableAnnotation: maxUnavailable,
+				},
+			},
+			"spec": map[string]interface{}{
+				"unschedulable": true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := kcpClient.WorkloadV1alpha1().SyncTargets().Patch(ctx, syncTargetName, types.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("failed to update SyncTarget %s: %w", syncTargetName, err)
+		}
+
+		fmt.Println(syncTargetName, "draining gracefully")
+		return nil
+	}
+
 	// See if there is nothing to do
 	if syncTarget.Spec.EvictAfter != nil && syncTarget.Spec.Unschedulable {
 		fmt.Println(syncTargetName, "already draining")
@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+)
+
+const (
+	// MaxScore is the maximum score a ScorePlugin returns for a candidate.
+	MaxScore int64 = 100
+
+	// MaxDecisionCandidates is the maximum number of candidates recorded in a SchedulingDecision.
+	MaxDecisionCandidates = 10
+)
+
+// Plugin is a named scheduling plugin.
+type Plugin interface {
+	Name() string
+}
+
+// FilterPlugin decides whether a candidate, e.g. a Location or a SyncTarget, is feasible for a placement.
+// The reason explains why the candidate is not feasible.
+type FilterPlugin interface {
+	Plugin
+	Filter(placement *schedulingv1alpha1.Placement, candidate metav1.Object) (feasible bool, reason string)
+}
+
+// ScorePlugin scores a feasible candidate for a placement, between 0 and MaxScore. The reason
+// explains the score, and can be empty.
+type ScorePlugin interface {
+	Plugin
+	Score(placement *schedulingv1alpha1.Placement, candidate metav1.Object) (score int64, reason string)
+}
+
+// WeightedScorePlugin is a ScorePlugin whose score is multiplied by a weight.
+type WeightedScorePlugin struct {
+	ScorePlugin
+	Weight int64
+}
+
+// Framework selects a candidate for a placement by running the filter plugins, then the score plugins
+// on the feasible candidates.
+type Framework struct {
+	filters []FilterPlugin
+	scorers []WeightedScorePlugin
+}
+
+// NewFramework returns a Framework running the given plugins.
+func NewFramework(filters []FilterPlugin, scorers []WeightedScorePlugin) *Framework {
+	return &Framework{
+		filters: filters,
+		scorers: scorers,
+	}
+}
+
+// Feasible returns whether the candidate passes all the filter plugins.
+func (f *Framework) Feasible(placement *schedulingv1alpha1.Placement, candidate metav1.Object) bool {
+	for _, filter := range f.filters {
+		if feasible, _ := filter.Filter(placement, candidate); !feasible {
+			return false
+		}
+	}
+	return true
+}
+
+type scoredCandidate struct {
+	candidate metav1.Object
+	score     schedulingv1alpha1.CandidateScore
+	tieBreak  uint64
+}
+
+// Schedule selects the feasible candidate with the highest score. Candidates with the same score are
+// ordered by a hash of the placement and the candidate names, such that equal candidates are spread
+// over placements while the decision for a given placement stays stable. It returns nil as selected
+// candidate if none is feasible. The decision is never nil.
+func (f *Framework) Schedule(placement *schedulingv1alpha1.Placement, candidates []metav1.Object, now time.Time) (metav1.Object, *schedulingv1alpha1.SchedulingDecision) {
+	placementKey := logicalcluster.From(placement).String() + "|" + placement.Name
+
+	scored := make([]scoredCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		s := scoredCandidate{
+			candidate: candidate,
+			score: schedulingv1alpha1.CandidateScore{
+				Name:     candidate.GetName(),
+				Feasible: true,
+			},
+			tieBreak: hash(placementKey, candidate.GetName()),
+		}
+		for _, filter := range f.filters {
+			if feasible, reason := filter.Filter(placement, candidate); !feasible {
+				s.score.Feasible = false
+				s.score.Reasons = append(s.score.Reasons, fmt.Sprintf("%s: %s", filter.Name(), reason))
+			}
+		}
+		if s.score.Feasible {
+			for _, scorer := range f.scorers {
+				score, reason := scorer.Score(placement, candidate)
+				s.score.Score += scorer.Weight * score
+				if reason != "" {
+					s.score.Reasons = append(s.score.Reasons, fmt.Sprintf("%s: %s", scorer.Name(), reason))
+				}
+			}
+		}
+		scored = append(scored, s)
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		a, b := scored[i], scored[j]
+		if a.score.Feasible != b.score.Feasible {
+			return a.score.Feasible
+		}
+		if a.score.Score != b.score.Score {
+			return a.score.Score > b.score.Score
+		}
+		if a.score.Feasible && a.tieBreak != b.tieBreak {
+			return a.tieBreak < b.tieBreak
+		}
+		return strings.Compare(a.score.Name, b.score.Name) < 0
+	})
+
+	decision := &schedulingv1alpha1.SchedulingDecision{
+		DecisionTime: metav1.NewTime(now),
+	}
+	var selected metav1.Object
+	if len(scored) > 0 && scored[0].score.Feasible {
+		selected = scored[0].candidate
+		decision.Selected = selected.GetName()
+	}
+	for i := range scored {
+		if i == MaxDecisionCandidates {
+			break
+		}
+		decision.Candidates = append(decision.Candidates, scored[i].score)
+	}
+
+	return selected, decision
+}
+
+func hash(placementKey, candidateName string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(placementKey))  // nolint: errcheck
+	h.Write([]byte{0})             // nolint: errcheck
+	h.Write([]byte(candidateName)) // nolint: errcheck
+	return h.Sum64()
+}
+
+// SameDecision returns whether two decisions selected the same candidate with the same scores,
+// ignoring the decision time.
+func SameDecision(a, b *schedulingv1alpha1.SchedulingDecision) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	aCopy, bCopy := a.DeepCopy(), b.DeepCopy()
+	aCopy.DecisionTime, bCopy.DecisionTime = metav1.Time{}, metav1.Time{}
+	return equality.Semantic.DeepEqual(aCopy, bCopy)
+}
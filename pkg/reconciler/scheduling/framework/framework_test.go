@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+)
+
+type labelFilter struct{}
+
+func (labelFilter) Name() string { return "Label" }
+
+func (labelFilter) Filter(_ *schedulingv1alpha1.Placement, candidate metav1.Object) (bool, string) {
+	if candidate.GetLabels()["broken"] == "true" {
+		return false, "broken"
+	}
+	return true, ""
+}
+
+type labelScore struct{}
+
+func (labelScore) Name() string { return "Score" }
+
+func (labelScore) Score(_ *schedulingv1alpha1.Placement, candidate metav1.Object) (int64, string) {
+	var score int64
+	if _, err := fmt.Sscan(candidate.GetLabels()["score"], &score); err != nil {
+		return 0, ""
+	}
+	return score, fmt.Sprintf("score %d", score)
+}
+
+func candidate(name string, labels map[string]string) metav1.Object {
+	return &metav1.ObjectMeta{Name: name, Labels: labels}
+}
+
+func TestSchedule(t *testing.T) {
+	now := time.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC)
+	placement := &schedulingv1alpha1.Placement{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+
+	tests := []struct {
+		name         string
+		candidates   []metav1.Object
+		wantSelected string
+		wantScores   []schedulingv1alpha1.CandidateScore
+	}{
+		{
+			name: "no candidates",
+		},
+		{
+			name: "no feasible candidate",
+			candidates: []metav1.Object{
+				candidate("b", map[string]string{"broken": "true"}),
+				candidate("a", map[string]string{"broken": "true"}),
+			},
+			wantScores: []schedulingv1alpha1.CandidateScore{
+				{Name: "a", Reasons: []string{"Label: broken"}},
+				{Name: "b", Reasons: []string{"Label: broken"}},
+			},
+		},
+		{
+			name: "highest weighted score wins",
+			candidates: []metav1.Object{
+				candidate("a", map[string]string{"score": "10"}),
+				candidate("b", map[string]string{"score": "50", "broken": "true"}),
+				candidate("c", map[string]string{"score": "30"}),
+			},
+			wantSelected: "c",
+			wantScores: []schedulingv1alpha1.CandidateScore{
+				{Name: "c", Feasible: true, Score: 60, Reasons: []string{"Score: score 30"}},
+				{Name: "a", Feasible: true, Score: 20, Reasons: []string{"Score: score 10"}},
+				{Name: "b", Reasons: []string{"Label: broken"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFramework([]FilterPlugin{labelFilter{}}, []WeightedScorePlugin{{ScorePlugin: labelScore{}, Weight: 2}})
+			selected, decision := f.Schedule(placement, tt.candidates, now)
+			require.NotNil(t, decision)
+			if tt.wantSelected == "" {
+				require.Nil(t, selected)
+			} else {
+				require.NotNil(t, selected)
+				require.Equal(t, tt.wantSelected, selected.GetName())
+			}
+			require.Equal(t, tt.wantSelected, decision.Selected)
+			require.Equal(t, tt.wantScores, decision.Candidates)
+			require.Equal(t, metav1.NewTime(now), decision.DecisionTime)
+		})
+	}
+}
+
+func TestScheduleTieBreak(t *testing.T) {
+	f := NewFramework(nil, nil)
+	var candidates []metav1.Object
+	for i := 0; i < 20; i++ {
+		candidates = append(candidates, candidate(fmt.Sprintf("c%d", i), nil))
+	}
+
+	selectedNames := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		placement := &schedulingv1alpha1.Placement{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("p%d", i)}}
+		selected, decision := f.Schedule(placement, candidates, time.Now())
+		require.Len(t, decision.Candidates, MaxDecisionCandidates)
+
+		// the decision for a placement is stable, independently from the order of the candidates
+		reversed := make([]metav1.Object, 0, len(candidates))
+		for j := len(candidates) - 1; j >= 0; j-- {
+			reversed = append(reversed, candidates[j])
+		}
+		again, _ := f.Schedule(placement, reversed, time.Now())
+		require.Equal(t, selected.GetName(), again.GetName())
+
+		selectedNames[selected.GetName()] = true
+	}
+	require.Greater(t, len(selectedNames), 1, "equal candidates should be spread over placements")
+}
+
+func TestSameDecision(t *testing.T) {
+	a := &schedulingv1alpha1.SchedulingDecision{Selected: "a", DecisionTime: metav1.NewTime(time.Unix(100, 0))}
+	b := &schedulingv1alpha1.SchedulingDecision{Selected: "a", DecisionTime: metav1.NewTime(time.Unix(200, 0))}
+	require.True(t, SameDecision(a, b))
+	require.True(t, SameDecision(nil, nil))
+	require.False(t, SameDecision(a, nil))
+	b.Selected = "b"
+	require.False(t, SameDecision(a, b))
+}
+
+func TestFeasible(t *testing.T) {
+	f := NewFramework([]FilterPlugin{labelFilter{}}, nil)
+	placement := &schedulingv1alpha1.Placement{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+	require.True(t, f.Feasible(placement, candidate("a", nil)))
+	require.False(t, f.Feasible(placement, candidate("b", map[string]string{"broken": "true"})))
+}
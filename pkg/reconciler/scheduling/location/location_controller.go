@@ -101,10 +101,8 @@ func NewController(
 				return
 			}
 
-			// only enqueue if spec or conditions change.
+			// only enqueue if something else than the heartbeat changes.
 			oldCluster = oldCluster.DeepCopy()
-			oldCluster.Status.Allocatable = objCluster.Status.Allocatable
-			oldCluster.Status.Capacity = objCluster.Status.Capacity
 			oldCluster.Status.LastSyncerHeartbeatTime = objCluster.Status.LastSyncerHeartbeatTime
 
 			if !equality.Semantic.DeepEqual(oldCluster, objCluster) {
@@ -24,10 +24,15 @@ import (
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilserrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	conditionsapi "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 )
 
@@ -89,10 +94,69 @@ func (r *statusReconciler) reconcile(ctx context.Context, location *schedulingv1
 	available := len(FilterReady(locationClusters))
 	location.Status.Instances = uint32Ptr(uint32(len(locationClusters)))
 	location.Status.AvailableInstances = uint32Ptr(uint32(available))
+	setAggregatedStatus(location, locationClusters)
 
 	return reconcileStatusContinue, nil
 }
 
+// setAggregatedStatus sets the unready and cordoned instance counts, the capacity, the allocatable
+// resources and the synced resources of the location, aggregated from its sync targets. Only the
+// available sync targets contribute allocatable resources.
+func setAggregatedStatus(location *schedulingv1alpha1.Location, syncTargets []*workloadv1alpha1.SyncTarget) {
+	var unready, cordoned uint32
+	capacity := corev1.ResourceList{}
+	allocatable := corev1.ResourceList{}
+	syncedResources := sets.NewString()
+	for _, syncTarget := range syncTargets {
+		ready := conditions.IsTrue(syncTarget, conditionsapi.ReadyCondition)
+		if !ready {
+			unready++
+		}
+		if syncTarget.Spec.Unschedulable {
+			cordoned++
+		}
+		if syncTarget.Status.Capacity != nil {
+			addResources(capacity, *syncTarget.Status.Capacity)
+		}
+		if ready && !syncTarget.Spec.Unschedulable && syncTarget.Status.Allocatable != nil {
+			addResources(allocatable, *syncTarget.Status.Allocatable)
+		}
+		for _, resource := range syncTarget.Status.SyncedResources {
+			if resource.State != workloadv1alpha1.ResourceSchemaAcceptedState {
+				continue
+			}
+			syncedResources.Insert(schema.GroupResource{Group: resource.Group, Resource: resource.Resource}.String())
+		}
+	}
+
+	location.Status.UnreadyInstances = uint32Ptr(unready)
+	location.Status.CordonedInstances = uint32Ptr(cordoned)
+	location.Status.Capacity = nil
+	if len(capacity) > 0 {
+		location.Status.Capacity = &capacity
+	}
+	location.Status.Allocatable = nil
+	if len(allocatable) > 0 {
+		location.Status.Allocatable = &allocatable
+	}
+	location.Status.SyncedResources = nil
+	if syncedResources.Len() > 0 {
+		location.Status.SyncedResources = syncedResources.List()
+	}
+}
+
+func addResources(total, resources corev1.ResourceList) {
+	for name, quantity := range resources {
+		current, found := total[name]
+		if !found {
+			total[name] = quantity.DeepCopy()
+			continue
+		}
+		current.Add(quantity)
+		total[name] = current
+	}
+}
+
 func uint32Ptr(i uint32) *uint32 {
 	return &i
 }
@@ -24,9 +24,12 @@ import (
 	"github.com/kcp-dev/logicalcluster/v2"
 	"github.com/stretchr/testify/require"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
@@ -50,6 +53,55 @@ func instances(expected uint32) func(t *testing.T, l *schedulingv1alpha1.Locatio
 	}
 }
 
+func unreadyInstances(expected uint32) func(t *testing.T, l *schedulingv1alpha1.Location) {
+	return func(t *testing.T, got *schedulingv1alpha1.Location) {
+		t.Helper()
+		require.NotNilf(t, got.Status.UnreadyInstances, "expected %d unready instances, not nil", expected)
+		require.Equal(t, expected, *got.Status.UnreadyInstances)
+	}
+}
+
+func cordonedInstances(expected uint32) func(t *testing.T, l *schedulingv1alpha1.Location) {
+	return func(t *testing.T, got *schedulingv1alpha1.Location) {
+		t.Helper()
+		require.NotNilf(t, got.Status.CordonedInstances, "expected %d cordoned instances, not nil", expected)
+		require.Equal(t, expected, *got.Status.CordonedInstances)
+	}
+}
+
+func resources(field string, expected map[corev1.ResourceName]string) func(t *testing.T, l *schedulingv1alpha1.Location) {
+	return func(t *testing.T, got *schedulingv1alpha1.Location) {
+		t.Helper()
+		list := got.Status.Capacity
+		if field == "allocatable" {
+			list = got.Status.Allocatable
+		}
+		if expected == nil {
+			require.Nilf(t, list, "expected no %s", field)
+			return
+		}
+		require.NotNilf(t, list, "expected %s, not nil", field)
+		require.Len(t, *list, len(expected))
+		for name, quantity := range expected {
+			actual, found := (*list)[name]
+			require.Truef(t, found, "missing %s %s", field, name)
+			expectedQuantity := resource.MustParse(quantity)
+			require.Zerof(t, expectedQuantity.Cmp(actual), "%s %s: expected %s, got %s", field, name, quantity, actual.String())
+		}
+	}
+}
+
+func syncedResources(expected ...string) func(t *testing.T, l *schedulingv1alpha1.Location) {
+	return func(t *testing.T, got *schedulingv1alpha1.Location) {
+		t.Helper()
+		if len(expected) == 0 {
+			require.Empty(t, got.Status.SyncedResources)
+			return
+		}
+		require.Equal(t, expected, got.Status.SyncedResources)
+	}
+}
+
 func labelString(expected string) func(t *testing.T, l *schedulingv1alpha1.Location) {
 	return func(t *testing.T, got *schedulingv1alpha1.Location) {
 		t.Helper()
@@ -139,7 +191,31 @@ func TestLocationStatusReconciler(t *testing.T) {
 					cluster("us-east1-2"),
 				},
 			},
-			wantLocation:        and(availableInstances(1), instances(4)),
+			wantLocation:        and(availableInstances(1), instances(4), unreadyInstances(2), cordonedInstances(1), resources("capacity", nil), resources("allocatable", nil), syncedResources()),
+			wantReconcileStatus: reconcileStatusContinue,
+		},
+		"with sync targets reporting resources": {
+			location: usEast1,
+			syncTargets: map[logicalcluster.Name][]*workloadv1alpha1.SyncTarget{
+				logicalcluster.New("root:org:negotiation-workspace"): {
+					withSyncedResources(withResources(withLabels(withConditions(cluster("us-east1-1"), conditionsv1alpha1.Condition{Type: "Ready", Status: "True"}), map[string]string{"region": "us-east1"}), "10", "32Gi", "4", "16Gi"),
+						workloadv1alpha1.ResourceToSync{GroupResource: apisv1alpha1.GroupResource{Group: "apps", Resource: "deployments"}, State: workloadv1alpha1.ResourceSchemaAcceptedState},
+						workloadv1alpha1.ResourceToSync{GroupResource: apisv1alpha1.GroupResource{Resource: "services"}, State: workloadv1alpha1.ResourceSchemaAcceptedState},
+					),
+					withSyncedResources(withResources(withLabels(withConditions(cluster("us-east1-2"), conditionsv1alpha1.Condition{Type: "Ready", Status: "True"}), map[string]string{"region": "us-east1"}), "6", "8Gi", "2", "4Gi"),
+						workloadv1alpha1.ResourceToSync{GroupResource: apisv1alpha1.GroupResource{Resource: "services"}, State: workloadv1alpha1.ResourceSchemaAcceptedState},
+						workloadv1alpha1.ResourceToSync{GroupResource: apisv1alpha1.GroupResource{Group: "networking.k8s.io", Resource: "ingresses"}, State: workloadv1alpha1.ResourceSchemaIncomptibleState},
+					),
+					withResources(withLabels(unschedulable(withConditions(cluster("us-east1-3"), conditionsv1alpha1.Condition{Type: "Ready", Status: "True"})), map[string]string{"region": "us-east1"}), "4", "8Gi", "4", "8Gi"),
+					withResources(withLabels(withConditions(cluster("us-east1-4"), conditionsv1alpha1.Condition{Type: "Ready", Status: "False"}), map[string]string{"region": "us-east1"}), "4", "8Gi", "4", "8Gi"),
+				},
+			},
+			wantLocation: and(
+				availableInstances(2), instances(4), unreadyInstances(1), cordonedInstances(1),
+				resources("capacity", map[corev1.ResourceName]string{"cpu": "24", "memory": "56Gi"}),
+				resources("allocatable", map[corev1.ResourceName]string{"cpu": "6", "memory": "20Gi"}),
+				syncedResources("deployments.apps", "services"),
+			),
 			wantReconcileStatus: reconcileStatusContinue,
 		},
 	}
@@ -218,6 +294,23 @@ func unschedulable(cluster *workloadv1alpha1.SyncTarget) *workloadv1alpha1.SyncT
 	return cluster
 }
 
+func withResources(cluster *workloadv1alpha1.SyncTarget, capacityCPU, capacityMemory, allocatableCPU, allocatableMemory string) *workloadv1alpha1.SyncTarget {
+	cluster.Status.Capacity = &corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(capacityCPU),
+		corev1.ResourceMemory: resource.MustParse(capacityMemory),
+	}
+	cluster.Status.Allocatable = &corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(allocatableCPU),
+		corev1.ResourceMemory: resource.MustParse(allocatableMemory),
+	}
+	return cluster
+}
+
+func withSyncedResources(cluster *workloadv1alpha1.SyncTarget, resources ...workloadv1alpha1.ResourceToSync) *workloadv1alpha1.SyncTarget {
+	cluster.Status.SyncedResources = resources
+	return cluster
+}
+
 func toYaml(obj interface{}) string {
 	bytes, err := yaml.Marshal(obj)
 	if err != nil {
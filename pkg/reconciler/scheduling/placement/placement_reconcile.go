@@ -18,6 +18,7 @@ package placement
 
 import (
 	"context"
+	"time"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
@@ -42,6 +43,8 @@ func (c *controller) reconcile(ctx context.Context, placement *schedulingv1alpha
 	reconcilers := []reconciler{
 		&placementReconciler{
 			listLocations: c.listLocations,
+			scheduler:     newLocationFramework(),
+			now:           time.Now,
 		},
 		&placementNamespaceReconciler{
 			listNamespacesWithAnnotation: c.listNamespacesWithAnnotation,
@@ -18,7 +18,7 @@ package placement
 
 import (
 	"context"
-	"math/rand"
+	"time"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
@@ -29,12 +29,16 @@ import (
 	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	"github.com/kcp-dev/kcp/pkg/reconciler/scheduling/framework"
 )
 
 // placementReconciler watches namespaces within a cluster workspace and assigns those to location from
 // the location domain of the cluster workspace.
 type placementReconciler struct {
 	listLocations func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Location, error)
+
+	scheduler *framework.Framework
+	now       func() time.Time
 }
 
 func (r *placementReconciler) reconcile(ctx context.Context, placement *schedulingv1alpha1.Placement) (reconcileStatus, *schedulingv1alpha1.Placement, error) {
@@ -46,12 +50,17 @@ func (r *placementReconciler) reconcile(ctx context.Context, placement *scheduli
 		locationWorkspace = logicalcluster.From(placement)
 	}
 
-	validLocationNames, err := r.validLocationNames(placement, locationWorkspace)
+	validLocations, err := r.validLocations(placement, locationWorkspace)
 	if err != nil {
 		conditions.MarkFalse(placement, schedulingv1alpha1.PlacementReady, schedulingv1alpha1.LocationNotFoundReason, conditionsv1alpha1.ConditionSeverityError, err.Error())
 		return reconcileStatusContinue, placement, err
 	}
 
+	validLocationNames := sets.NewString()
+	for _, loc := range validLocations {
+		validLocationNames.Insert(loc.Name)
+	}
+
 	switch placement.Status.Phase {
 	case schedulingv1alpha1.PlacementBound:
 		// if selected location becomes invalid when placement is in bound state, set PlacementReady
@@ -90,17 +99,31 @@ func (r *placementReconciler) reconcile(ctx context.Context, placement *scheduli
 		return reconcileStatusContinue, placement, nil
 	}
 
-	candidates := make([]string, 0, validLocationNames.Len())
-	for loc := range validLocationNames {
+	candidates := make([]metav1.Object, 0, len(validLocations))
+	for _, loc := range validLocations {
 		candidates = append(candidates, loc)
 	}
 
 	// TODO(qiujian16): two placements could select the same location. We should
 	// consider whether placements in a workspace should always select different locations.
-	chosenLocation := candidates[rand.Intn(len(candidates))]
+	chosenLocation, decision := r.scheduler.Schedule(placement, candidates, r.now())
+	if !framework.SameDecision(placement.Status.LocationDecision, decision) {
+		placement.Status.LocationDecision = decision
+	}
+	if chosenLocation == nil {
+		placement.Status.Phase = schedulingv1alpha1.PlacementPending
+		placement.Status.SelectedLocation = nil
+		conditions.MarkFalse(
+			placement,
+			schedulingv1alpha1.PlacementReady,
+			schedulingv1alpha1.LocationNotMatchReason,
+			conditionsv1alpha1.ConditionSeverityError,
+			"No feasible location is found")
+		return reconcileStatusContinue, placement, nil
+	}
 	placement.Status.SelectedLocation = &schedulingv1alpha1.LocationReference{
 		Path:         locationWorkspace.String(),
-		LocationName: chosenLocation,
+		LocationName: chosenLocation.GetName(),
 	}
 	placement.Status.Phase = schedulingv1alpha1.PlacementUnbound
 	conditions.MarkTrue(placement, schedulingv1alpha1.PlacementReady)
@@ -108,14 +131,13 @@ func (r *placementReconciler) reconcile(ctx context.Context, placement *scheduli
 	return reconcileStatusContinue, placement, nil
 }
 
-func (r *placementReconciler) validLocationNames(placement *schedulingv1alpha1.Placement, locationWorkspace logicalcluster.Name) (sets.String, error) {
-	selectedLocations := sets.NewString()
-
+func (r *placementReconciler) validLocations(placement *schedulingv1alpha1.Placement, locationWorkspace logicalcluster.Name) ([]*schedulingv1alpha1.Location, error) {
 	locations, err := r.listLocations(locationWorkspace)
 	if err != nil {
-		return selectedLocations, err
+		return nil, err
 	}
 
+	var selectedLocations []*schedulingv1alpha1.Location
 	for _, loc := range locations {
 		if loc.Spec.Resource != placement.Spec.LocationResource {
 			continue
@@ -129,7 +151,8 @@ func (r *placementReconciler) validLocationNames(placement *schedulingv1alpha1.P
 			}
 
 			if selector.Matches(labels.Set(loc.Labels)) {
-				selectedLocations.Insert(loc.Name)
+				selectedLocations = append(selectedLocations, loc)
+				break
 			}
 		}
 	}
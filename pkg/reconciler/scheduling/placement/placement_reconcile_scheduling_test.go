@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 	"github.com/stretchr/testify/require"
@@ -45,6 +46,7 @@ func TestPlacementScheduling(t *testing.T) {
 		wantPhase          schedulingv1alpha1.PlacementPhase
 		wantSelectLocation *schedulingv1alpha1.LocationReference
 		wantStatus         corev1.ConditionStatus
+		wantDecision       *schedulingv1alpha1.SchedulingDecision
 	}{
 		{
 			name:       "no locations",
@@ -160,6 +162,57 @@ func TestPlacementScheduling(t *testing.T) {
 			wantPhase:  schedulingv1alpha1.PlacementPending,
 			wantStatus: corev1.ConditionFalse,
 		},
+		{
+			name:  "select the location with the most available instances",
+			phase: schedulingv1alpha1.PlacementPending,
+			locationSelectors: []metav1.LabelSelector{
+				{
+					MatchLabels: map[string]string{
+						"cloud": "aws",
+					},
+				},
+			},
+			locations: []*schedulingv1alpha1.Location{
+				withInstances(newLocation("aws-1", map[string]string{"cloud": "aws"}), 4, 1),
+				withInstances(newLocation("aws-2", map[string]string{"cloud": "aws"}), 4, 3),
+				withInstances(newLocation("aws-3", map[string]string{"cloud": "aws"}), 4, 0),
+				newLocation("gcp", map[string]string{"cloud": "gcp"}),
+			},
+			wantPhase:  schedulingv1alpha1.PlacementUnbound,
+			wantStatus: corev1.ConditionTrue,
+			wantSelectLocation: &schedulingv1alpha1.LocationReference{
+				LocationName: "aws-2",
+			},
+			wantDecision: &schedulingv1alpha1.SchedulingDecision{
+				Selected: "aws-2",
+				Candidates: []schedulingv1alpha1.CandidateScore{
+					{Name: "aws-2", Feasible: true, Score: 75, Reasons: []string{"Availability: 3/4 instances available"}},
+					{Name: "aws-1", Feasible: true, Score: 25, Reasons: []string{"Availability: 1/4 instances available"}},
+					{Name: "aws-3", Reasons: []string{"AvailableInstances: no available instances"}},
+				},
+			},
+		},
+		{
+			name:  "no feasible location",
+			phase: schedulingv1alpha1.PlacementPending,
+			locationSelectors: []metav1.LabelSelector{
+				{
+					MatchLabels: map[string]string{
+						"cloud": "aws",
+					},
+				},
+			},
+			locations: []*schedulingv1alpha1.Location{
+				withInstances(newLocation("aws", map[string]string{"cloud": "aws"}), 4, 0),
+			},
+			wantPhase:  schedulingv1alpha1.PlacementPending,
+			wantStatus: corev1.ConditionFalse,
+			wantDecision: &schedulingv1alpha1.SchedulingDecision{
+				Candidates: []schedulingv1alpha1.CandidateScore{
+					{Name: "aws", Reasons: []string{"AvailableInstances: no available instances"}},
+				},
+			},
+		},
 		{
 			name:  "get location error",
 			phase: schedulingv1alpha1.PlacementUnbound,
@@ -205,7 +258,11 @@ func TestPlacementScheduling(t *testing.T) {
 				return testCase.locations, testCase.listLocationsError
 			}
 
-			reconciler := &placementReconciler{listLocations: listLoaction}
+			reconciler := &placementReconciler{
+				listLocations: listLoaction,
+				scheduler:     newLocationFramework(),
+				now:           time.Now,
+			}
 			_, updated, err := reconciler.reconcile(context.TODO(), testPlacement)
 
 			if testCase.wantError {
@@ -219,6 +276,11 @@ func TestPlacementScheduling(t *testing.T) {
 			require.NotNil(t, c)
 			require.Equal(t, testCase.wantStatus, c.Status)
 			require.Equal(t, testCase.wantSelectLocation, updated.Status.SelectedLocation)
+			if testCase.wantDecision != nil {
+				require.NotNil(t, updated.Status.LocationDecision)
+				updated.Status.LocationDecision.DecisionTime = metav1.Time{}
+				require.Equal(t, testCase.wantDecision, updated.Status.LocationDecision)
+			}
 
 		})
 	}
@@ -232,3 +294,9 @@ func newLocation(name string, labels map[string]string) *schedulingv1alpha1.Loca
 		},
 	}
 }
+
+func withInstances(location *schedulingv1alpha1.Location, instances, available uint32) *schedulingv1alpha1.Location {
+	location.Status.Instances = &instances
+	location.Status.AvailableInstances = &available
+	return location
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/reconciler/scheduling/framework"
+)
+
+// newLocationFramework returns the scheduling framework selecting a location among the locations
+// matching the location selectors of a placement.
+func newLocationFramework() *framework.Framework {
+	return framework.NewFramework(
+		[]framework.FilterPlugin{
+			&availableInstancesFilter{},
+		},
+		[]framework.WeightedScorePlugin{
+			{ScorePlugin: &availabilityScore{}, Weight: 1},
+		},
+	)
+}
+
+// availableInstancesFilter filters out the locations reporting no available instance.
+// Locations which do not report their instances are feasible.
+type availableInstancesFilter struct{}
+
+func (f *availableInstancesFilter) Name() string { return "AvailableInstances" }
+
+func (f *availableInstancesFilter) Filter(_ *schedulingv1alpha1.Placement, candidate metav1.Object) (bool, string) {
+	location := candidate.(*schedulingv1alpha1.Location)
+	if location.Status.AvailableInstances != nil && *location.Status.AvailableInstances == 0 {
+		return false, "no available instances"
+	}
+	return true, ""
+}
+
+// availabilityScore prefers the locations with the highest ratio of available instances. Locations
+// which do not report their instances get half of the maximum score.
+type availabilityScore struct{}
+
+func (s *availabilityScore) Name() string { return "Availability" }
+
+func (s *availabilityScore) Score(_ *schedulingv1alpha1.Placement, candidate metav1.Object) (int64, string) {
+	location := candidate.(*schedulingv1alpha1.Location)
+	if location.Status.Instances == nil || location.Status.AvailableInstances == nil || *location.Status.Instances == 0 {
+		return framework.MaxScore / 2, "unknown instances"
+	}
+	instances, available := int64(*location.Status.Instances), int64(*location.Status.AvailableInstances)
+	if available > instances {
+		available = instances
+	}
+	return available * framework.MaxScore / instances, fmt.Sprintf("%d/%d instances available", available, instances)
+}
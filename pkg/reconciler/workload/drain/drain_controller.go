@@ -0,0 +1,365 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	kubernetesclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	conditionsapi "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	schedulinginformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/scheduling/v1alpha1"
+	workloadinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/indexers"
+)
+
+const (
+	controllerName = "kcp-workload-drain"
+	byName         = controllerName + "-byName"
+	bySyncTarget   = controllerName + "-bySyncTarget"
+)
+
+// NewController returns a new controller moving the namespaces off the SyncTargets drained gracefully in batches,
+// within the max-unavailable budgets of the SyncTargets and of the placements.
+func NewController(
+	kcpClusterClient kcpclient.Interface,
+	kubeClusterClient kubernetesclient.Interface,
+	syncTargetInformer workloadinformers.SyncTargetInformer,
+	namespaceInformer coreinformers.NamespaceInformer,
+	placementInformer schedulinginformers.PlacementInformer,
+) (*controller, error) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+
+	c := &controller{
+		queue: queue,
+		enqueueAfter: func(syncTarget *workloadv1alpha1.SyncTarget, duration time.Duration) {
+			key, err := cache.MetaNamespaceKeyFunc(syncTarget)
+			if err != nil {
+				runtime.HandleError(err)
+				return
+			}
+			queue.AddAfter(key, duration)
+		},
+
+		kcpClusterClient:  kcpClusterClient,
+		kubeClusterClient: kubeClusterClient,
+
+		syncTargetIndexer: syncTargetInformer.Informer().GetIndexer(),
+		namespaceIndexer:  namespaceInformer.Informer().GetIndexer(),
+		placementIndexer:  placementInformer.Informer().GetIndexer(),
+	}
+
+	if err := syncTargetInformer.Informer().AddIndexers(cache.Indexers{
+		byName: indexByName,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := namespaceInformer.Informer().AddIndexers(cache.Indexers{
+		bySyncTarget: indexBySyncTarget,
+	}); err != nil {
+		return nil, err
+	}
+
+	syncTargetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueSyncTarget,
+		UpdateFunc: func(oldObj, obj interface{}) {
+			c.enqueueSyncTarget(obj)
+
+			// SyncTargets becoming ready or not ready change the availability of the namespaces moved to them.
+			oldSyncTarget, ok := oldObj.(*workloadv1alpha1.SyncTarget)
+			if !ok {
+				return
+			}
+			syncTarget, ok := obj.(*workloadv1alpha1.SyncTarget)
+			if !ok {
+				return
+			}
+			if conditions.IsTrue(oldSyncTarget, conditionsapi.ReadyCondition) != conditions.IsTrue(syncTarget, conditionsapi.ReadyCondition) {
+				c.enqueueDrainingSyncTargets()
+			}
+		},
+	})
+
+	namespaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueNamespace,
+		UpdateFunc: func(oldObj, obj interface{}) {
+			c.enqueueNamespace(oldObj)
+			c.enqueueNamespace(obj)
+		},
+		DeleteFunc: c.enqueueNamespace,
+	})
+
+	return c, nil
+}
+
+// controller moves the namespaces off the SyncTargets drained gracefully in batches. The namespace scheduler
+// holds the namespaces leaving these SyncTargets, and the controller lets them go while the namespaces not yet
+// synced to a ready SyncTarget fit in the max-unavailable budgets. It reports the progress in the Drained
+// condition of the SyncTargets.
+type controller struct {
+	queue        workqueue.RateLimitingInterface
+	enqueueAfter func(*workloadv1alpha1.SyncTarget, time.Duration)
+
+	kcpClusterClient  kcpclient.Interface
+	kubeClusterClient kubernetesclient.Interface
+
+	syncTargetIndexer cache.Indexer
+	namespaceIndexer  cache.Indexer
+	placementIndexer  cache.Indexer
+}
+
+func (c *controller) enqueueSyncTarget(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	klog.V(4).Infof("Queueing SyncTarget %q", key)
+	c.queue.Add(key)
+}
+
+// enqueueDrainingSyncTargets enqueues the SyncTargets drained gracefully, or reporting a drain.
+func (c *controller) enqueueDrainingSyncTargets() {
+	for _, obj := range c.syncTargetIndexer.List() {
+		if isDraining(obj.(*workloadv1alpha1.SyncTarget)) {
+			c.enqueueSyncTarget(obj)
+		}
+	}
+}
+
+// enqueueNamespace enqueues the SyncTargets drained gracefully the namespace is synced to, or held on.
+func (c *controller) enqueueNamespace(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	names, err := indexBySyncTarget(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	for _, name := range names {
+		syncTargets, err := c.syncTargetIndexer.ByIndex(byName, name)
+		if err != nil {
+			runtime.HandleError(err)
+			return
+		}
+		for _, syncTarget := range syncTargets {
+			if isDraining(syncTarget.(*workloadv1alpha1.SyncTarget)) {
+				c.enqueueSyncTarget(syncTarget)
+			}
+		}
+	}
+}
+
+// isDraining returns true if the SyncTarget has a drain budget or reports a drain, so that changes of the
+// namespaces synced to it may change its Drained condition.
+func isDraining(syncTarget *workloadv1alpha1.SyncTarget) bool {
+	_, found := syncTarget.Annotations[workloadv1alpha1.DrainMaxUnavailableAnnotation]
+	return found || conditions.Has(syncTarget, workloadv1alpha1.SyncTargetDrained)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	obj, exists, err := c.syncTargetIndexer.GetByKey(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil // object deleted before we handled it
+	}
+	old := obj.(*workloadv1alpha1.SyncTarget)
+	syncTarget := old.DeepCopy()
+	clusterName := logicalcluster.From(syncTarget)
+
+	reconciler := &drainReconciler{
+		listNamespaces:    c.listNamespaces,
+		listPlacements:    c.listPlacements,
+		isSyncTargetReady: c.isSyncTargetReady,
+		patchNamespace:    c.patchNamespace,
+		enqueueAfter:      c.enqueueAfter,
+		now:               time.Now,
+	}
+	reconcileErr := reconciler.reconcile(ctx, syncTarget)
+
+	// If the conditions changed as a result, update them.
+	if !equality.Semantic.DeepEqual(old.Status.Conditions, syncTarget.Status.Conditions) {
+		oldData, err := json.Marshal(workloadv1alpha1.SyncTarget{
+			Status: old.Status,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to Marshal old data for SyncTarget %s|%s: %w", clusterName, syncTarget.Name, err)
+		}
+
+		newData, err := json.Marshal(workloadv1alpha1.SyncTarget{
+			ObjectMeta: metav1.ObjectMeta{
+				UID:             old.UID,
+				ResourceVersion: old.ResourceVersion,
+			}, // to ensure they appear in the patch as preconditions
+			Status: syncTarget.Status,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to Marshal new data for SyncTarget %s|%s: %w", clusterName, syncTarget.Name, err)
+		}
+
+		patchBytes, err := jsonpatch.CreateMergePatch(oldData, newData)
+		if err != nil {
+			return fmt.Errorf("failed to create patch for SyncTarget %s|%s: %w", clusterName, syncTarget.Name, err)
+		}
+		klog.V(2).Infof("Patching SyncTarget %s|%s with patch %s", clusterName, syncTarget.Name, string(patchBytes))
+		if _, err := c.kcpClusterClient.WorkloadV1alpha1().SyncTargets().Patch(logicalcluster.WithCluster(ctx, clusterName), syncTarget.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status"); err != nil {
+			return err
+		}
+	}
+
+	return reconcileErr
+}
+
+func (c *controller) listNamespaces(syncTargetName string) ([]*corev1.Namespace, error) {
+	items, err := c.namespaceIndexer.ByIndex(bySyncTarget, syncTargetName)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*corev1.Namespace, 0, len(items))
+	for _, item := range items {
+		ret = append(ret, item.(*corev1.Namespace))
+	}
+	return ret, nil
+}
+
+func (c *controller) listPlacements(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Placement, error) {
+	items, err := c.placementIndexer.ByIndex(indexers.ByLogicalCluster, clusterName.String())
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*schedulingv1alpha1.Placement, 0, len(items))
+	for _, item := range items {
+		ret = append(ret, item.(*schedulingv1alpha1.Placement))
+	}
+	return ret, nil
+}
+
+// isSyncTargetReady returns true if a SyncTarget of the given name is ready. Namespaces refer to the SyncTargets
+// they are synced to by name only.
+func (c *controller) isSyncTargetReady(name string) (bool, error) {
+	syncTargets, err := c.syncTargetIndexer.ByIndex(byName, name)
+	if err != nil {
+		return false, err
+	}
+	for _, syncTarget := range syncTargets {
+		if conditions.IsTrue(syncTarget.(*workloadv1alpha1.SyncTarget), conditionsapi.ReadyCondition) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *controller) patchNamespace(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Namespace, error) {
+	klog.V(2).Infof("Patching namespace %s|%s with patch %s", clusterName, name, string(data))
+	return c.kubeClusterClient.CoreV1().Namespaces().Patch(logicalcluster.WithCluster(ctx, clusterName), name, pt, data, opts, subresources...)
+}
+
+func indexByName(obj interface{}) ([]string, error) {
+	syncTarget, ok := obj.(*workloadv1alpha1.SyncTarget)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be a SyncTarget, but is %T", obj)
+	}
+	return []string{syncTarget.Name}, nil
+}
+
+// indexBySyncTarget indexes the namespaces by the names of the SyncTargets they are synced to, or held on.
+func indexBySyncTarget(obj interface{}) ([]string, error) {
+	ns, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be a Namespace, but is %T", obj)
+	}
+	names := sets.NewString()
+	for key := range ns.Labels {
+		if strings.HasPrefix(key, workloadv1alpha1.ClusterResourceStateLabelPrefix) {
+			names.Insert(strings.TrimPrefix(key, workloadv1alpha1.ClusterResourceStateLabelPrefix))
+		}
+	}
+	for key := range ns.Annotations {
+		if strings.HasPrefix(key, workloadv1alpha1.InternalClusterDrainAnnotationPrefix) {
+			names.Insert(strings.TrimPrefix(key, workloadv1alpha1.InternalClusterDrainAnnotationPrefix))
+		}
+	}
+	return names.List(), nil
+}
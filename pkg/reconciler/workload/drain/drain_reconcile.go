@@ -0,0 +1,312 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilserrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	conditionsapi "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+// IsDrainingGracefully returns true if the SyncTarget is drained gracefully at the given time: it is
+// unschedulable, sets a drain max-unavailable budget, and its evictAfter time is not reached.
+func IsDrainingGracefully(syncTarget *workloadv1alpha1.SyncTarget, now time.Time) bool {
+	if !syncTarget.Spec.Unschedulable {
+		return false
+	}
+	if _, found := syncTarget.Annotations[workloadv1alpha1.DrainMaxUnavailableAnnotation]; !found {
+		return false
+	}
+	return syncTarget.Spec.EvictAfter == nil || now.Before(syncTarget.Spec.EvictAfter.Time)
+}
+
+// drainReconciler lets the namespaces held on a SyncTarget drained gracefully go, within the max-unavailable
+// budgets of the SyncTarget and of the placements the namespaces are bound to, and records the progress in the
+// Drained condition of the SyncTarget.
+type drainReconciler struct {
+	listNamespaces    func(syncTargetName string) ([]*corev1.Namespace, error)
+	listPlacements    func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Placement, error)
+	isSyncTargetReady func(name string) (bool, error)
+
+	patchNamespace func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Namespace, error)
+
+	enqueueAfter func(*workloadv1alpha1.SyncTarget, time.Duration)
+
+	now func() time.Time
+}
+
+func (r *drainReconciler) reconcile(ctx context.Context, syncTarget *workloadv1alpha1.SyncTarget) error {
+	drainKey := workloadv1alpha1.InternalClusterDrainAnnotationPrefix + syncTarget.Name
+
+	namespaces, err := r.listNamespaces(syncTarget.Name)
+	if err != nil {
+		return err
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaceKey(namespaces[i]) < namespaceKey(namespaces[j])
+	})
+
+	// 1. release the namespaces if the drain is over, cancelled or forced by the evictAfter time.
+	if !IsDrainingGracefully(syncTarget, r.now()) {
+		conditions.Delete(syncTarget, workloadv1alpha1.SyncTargetDrained)
+
+		var errs []error
+		for _, ns := range namespaces {
+			if _, found := ns.Annotations[drainKey]; found {
+				klog.V(2).Infof("Releasing namespace %s from SyncTarget %s|%s which is not drained gracefully", namespaceKey(ns), logicalcluster.From(syncTarget), syncTarget.Name)
+				errs = append(errs, r.patchDrainState(ctx, ns, drainKey, nil))
+			}
+		}
+		return utilserrors.NewAggregate(errs)
+	}
+	if syncTarget.Spec.EvictAfter != nil {
+		r.enqueueAfter(syncTarget, syncTarget.Spec.EvictAfter.Sub(r.now()))
+	}
+
+	// 2. sort the namespaces into held, unavailable and not yet leaving ones, and forget the available ones.
+	var errs []error
+	var held, unavailable, notLeaving []*corev1.Namespace
+	for _, ns := range namespaces {
+		switch workloadv1alpha1.DrainState(ns.Annotations[drainKey]) {
+		case workloadv1alpha1.DrainStateHeld:
+			held = append(held, ns)
+		case workloadv1alpha1.DrainStateEvicting:
+			available, err := r.isAvailable(ns, syncTarget.Name)
+			if err != nil {
+				errs = append(errs, err)
+				unavailable = append(unavailable, ns)
+				continue
+			}
+			if !available {
+				unavailable = append(unavailable, ns)
+				continue
+			}
+			klog.V(2).Infof("Namespace %s moved off SyncTarget %s|%s is available", namespaceKey(ns), logicalcluster.From(syncTarget), syncTarget.Name)
+			errs = append(errs, r.patchDrainState(ctx, ns, drainKey, nil))
+		default:
+			if isSynced(ns, syncTarget.Name) {
+				notLeaving = append(notLeaving, ns)
+			}
+		}
+	}
+	all := append(append(append([]*corev1.Namespace{}, held...), unavailable...), notLeaving...)
+
+	// 3. let the held namespaces go while they fit in the budgets.
+	budget, err := maxUnavailable(syncTarget.Annotations[workloadv1alpha1.DrainMaxUnavailableAnnotation], len(all))
+	if err != nil {
+		conditions.MarkFalse(syncTarget, workloadv1alpha1.SyncTargetDrained, workloadv1alpha1.DrainInProgressReason, conditionsapi.ConditionSeverityError,
+			"Invalid %s annotation: %v", workloadv1alpha1.DrainMaxUnavailableAnnotation, err)
+		return utilserrors.NewAggregate(errs)
+	}
+	budgets, err := r.placementBudgets(all)
+	if err != nil {
+		return utilserrors.NewAggregate(append(errs, err))
+	}
+	unavailableByPlacement := map[string]int{}
+	for _, ns := range unavailable {
+		for _, placement := range budgets.placements[namespaceKey(ns)] {
+			unavailableByPlacement[placement]++
+		}
+	}
+
+	waiting := 0
+	unavailableCount := len(unavailable)
+	for _, ns := range held {
+		if unavailableCount >= budget || !budgets.fits(ns, unavailableByPlacement) {
+			waiting++
+			continue
+		}
+		klog.V(2).Infof("Letting namespace %s move off SyncTarget %s|%s", namespaceKey(ns), logicalcluster.From(syncTarget), syncTarget.Name)
+		if err := r.patchDrainState(ctx, ns, drainKey, workloadv1alpha1.DrainStateEvicting); err != nil {
+			errs = append(errs, err)
+			waiting++
+			continue
+		}
+		unavailableCount++
+		for _, placement := range budgets.placements[namespaceKey(ns)] {
+			unavailableByPlacement[placement]++
+		}
+	}
+
+	// 4. report the progress.
+	if unavailableCount == 0 && waiting == 0 && len(notLeaving) == 0 {
+		conditions.MarkTrue(syncTarget, workloadv1alpha1.SyncTargetDrained)
+	} else {
+		conditions.MarkFalse(syncTarget, workloadv1alpha1.SyncTargetDrained, workloadv1alpha1.DrainInProgressReason, conditionsapi.ConditionSeverityInfo,
+			"%d namespaces moving (at most %d at once), %d waiting to move, %d not rescheduled", unavailableCount, budget, waiting, len(notLeaving))
+	}
+
+	return utilserrors.NewAggregate(errs)
+}
+
+// isAvailable returns true if the namespace is synced to a ready SyncTarget other than the given one.
+func (r *drainReconciler) isAvailable(ns *corev1.Namespace, syncTargetName string) (bool, error) {
+	for key, value := range ns.Labels {
+		if !strings.HasPrefix(key, workloadv1alpha1.ClusterResourceStateLabelPrefix) || value != string(workloadv1alpha1.ResourceStateSync) {
+			continue
+		}
+		name := strings.TrimPrefix(key, workloadv1alpha1.ClusterResourceStateLabelPrefix)
+		if name == syncTargetName || !isSynced(ns, name) {
+			continue
+		}
+		ready, err := r.isSyncTargetReady(name)
+		if err != nil {
+			return false, err
+		}
+		if ready {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// placementBudgets holds the max-unavailable budgets of the placements setting one, and the placements with a
+// budget each namespace is bound to.
+type placementBudgets struct {
+	budgets    map[string]int
+	placements map[string][]string
+}
+
+func (b *placementBudgets) fits(ns *corev1.Namespace, unavailable map[string]int) bool {
+	for _, placement := range b.placements[namespaceKey(ns)] {
+		if unavailable[placement] >= b.budgets[placement] {
+			return false
+		}
+	}
+	return true
+}
+
+// placementBudgets computes the budgets of the placements of the given namespaces, relative to the number of
+// these namespaces bound to each placement. Placements with an invalid budget are ignored.
+func (r *drainReconciler) placementBudgets(namespaces []*corev1.Namespace) (*placementBudgets, error) {
+	ret := &placementBudgets{
+		budgets:    map[string]int{},
+		placements: map[string][]string{},
+	}
+
+	placementsByCluster := map[logicalcluster.Name][]*schedulingv1alpha1.Placement{}
+	counts := map[string]int{}
+	for _, ns := range namespaces {
+		clusterName := logicalcluster.From(ns)
+		placements, found := placementsByCluster[clusterName]
+		if !found {
+			var err error
+			if placements, err = r.listPlacements(clusterName); err != nil {
+				return nil, err
+			}
+			placementsByCluster[clusterName] = placements
+		}
+		for _, placement := range placements {
+			if _, found := placement.Annotations[workloadv1alpha1.DrainMaxUnavailableAnnotation]; !found || !isBound(ns, placement) {
+				continue
+			}
+			key := fmt.Sprintf("%s|%s", clusterName, placement.Name)
+			ret.placements[namespaceKey(ns)] = append(ret.placements[namespaceKey(ns)], key)
+			counts[key]++
+		}
+	}
+
+	for _, placements := range placementsByCluster {
+		for _, placement := range placements {
+			key := fmt.Sprintf("%s|%s", logicalcluster.From(placement), placement.Name)
+			if counts[key] == 0 {
+				continue
+			}
+			budget, err := maxUnavailable(placement.Annotations[workloadv1alpha1.DrainMaxUnavailableAnnotation], counts[key])
+			if err != nil {
+				klog.V(2).Infof("Ignoring invalid %s annotation of placement %s: %v", workloadv1alpha1.DrainMaxUnavailableAnnotation, key, err)
+				budget = counts[key]
+			}
+			ret.budgets[key] = budget
+		}
+	}
+
+	return ret, nil
+}
+
+func (r *drainReconciler) patchDrainState(ctx context.Context, ns *corev1.Namespace, key string, state interface{}) error {
+	patchBytes, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				key: state,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.patchNamespace(ctx, logicalcluster.From(ns), ns.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{})
+	return err
+}
+
+// maxUnavailable returns the number of unavailable namespaces allowed by the given budget, an integer or a
+// percentage of the total number of namespaces, rounded up. At least one namespace is always allowed to move.
+func maxUnavailable(value string, total int) (int, error) {
+	budget := intstr.Parse(value)
+	n, err := intstr.GetScaledValueFromIntOrPercent(&budget, total, true)
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n, nil
+}
+
+// isSynced returns true if the namespace is synced to the SyncTarget of the given name, and not being removed
+// from it.
+func isSynced(ns *corev1.Namespace, syncTargetName string) bool {
+	if _, found := ns.Labels[workloadv1alpha1.ClusterResourceStateLabelPrefix+syncTargetName]; !found {
+		return false
+	}
+	_, removing := ns.Annotations[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+syncTargetName]
+	return !removing
+}
+
+// isBound returns true if the namespace is selected by the placement.
+func isBound(ns *corev1.Namespace, placement *schedulingv1alpha1.Placement) bool {
+	if _, found := ns.Annotations[schedulingv1alpha1.PlacementAnnotationKey]; !found {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(placement.Spec.NamespaceSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(ns.Labels))
+}
+
+func namespaceKey(ns *corev1.Namespace) string {
+	return fmt.Sprintf("%s|%s", logicalcluster.From(ns), ns.Name)
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	conditionsapi "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+func TestDrain(t *testing.T) {
+	now := time.Now()
+
+	testCases := map[string]struct {
+		syncTarget *workloadv1alpha1.SyncTarget
+		namespaces []*corev1.Namespace
+		placements []*schedulingv1alpha1.Placement
+		ready      []string
+
+		wantPatches      map[string]interface{}
+		wantDrained      corev1.ConditionStatus
+		wantMessage      string
+		wantEnqueueAfter time.Duration
+	}{
+		"not drained gracefully": {
+			syncTarget: newSyncTarget("st1", false, ""),
+			namespaces: []*corev1.Namespace{
+				withDrainState(newNamespace("ns1", "st1"), "st1", workloadv1alpha1.DrainStateHeld),
+				newNamespace("ns2", "st1"),
+			},
+			wantPatches: map[string]interface{}{"ns1": nil},
+		},
+		"let held namespaces go within the budget": {
+			syncTarget: newSyncTarget("st1", true, "1"),
+			namespaces: []*corev1.Namespace{
+				withDrainState(newNamespace("ns2", "st1"), "st1", workloadv1alpha1.DrainStateHeld),
+				withDrainState(newNamespace("ns1", "st1"), "st1", workloadv1alpha1.DrainStateHeld),
+				newNamespace("ns3", "st1"),
+			},
+			wantPatches: map[string]interface{}{"ns1": "Evicting"},
+			wantDrained: corev1.ConditionFalse,
+			wantMessage: "1 namespaces moving (at most 1 at once), 1 waiting to move, 1 not rescheduled",
+		},
+		"let held namespaces go within a percentage budget": {
+			syncTarget: newSyncTarget("st1", true, "50%"),
+			namespaces: []*corev1.Namespace{
+				withDrainState(newNamespace("ns1", "st1"), "st1", workloadv1alpha1.DrainStateHeld),
+				withDrainState(newNamespace("ns2", "st1"), "st1", workloadv1alpha1.DrainStateHeld),
+				withDrainState(newNamespace("ns3", "st1"), "st1", workloadv1alpha1.DrainStateHeld),
+			},
+			wantPatches: map[string]interface{}{"ns1": "Evicting", "ns2": "Evicting"},
+			wantDrained: corev1.ConditionFalse,
+			wantMessage: "2 namespaces moving (at most 2 at once), 1 waiting to move, 0 not rescheduled",
+		},
+		"wait for the replacement synctarget to be ready": {
+			syncTarget: newSyncTarget("st1", true, "1"),
+			namespaces: []*corev1.Namespace{
+				withDrainState(newNamespace("ns1", "st1", "st2"), "st1", workloadv1alpha1.DrainStateEvicting),
+				withDrainState(newNamespace("ns2", "st1"), "st1", workloadv1alpha1.DrainStateHeld),
+			},
+			wantPatches: map[string]interface{}{},
+			wantDrained: corev1.ConditionFalse,
+			wantMessage: "1 namespaces moving (at most 1 at once), 1 waiting to move, 0 not rescheduled",
+		},
+		"continue once the replacement synctarget is ready": {
+			syncTarget: newSyncTarget("st1", true, "1"),
+			namespaces: []*corev1.Namespace{
+				withDrainState(newNamespace("ns1", "st1", "st2"), "st1", workloadv1alpha1.DrainStateEvicting),
+				withDrainState(newNamespace("ns2", "st1"), "st1", workloadv1alpha1.DrainStateHeld),
+			},
+			ready:       []string{"st2"},
+			wantPatches: map[string]interface{}{"ns1": nil, "ns2": "Evicting"},
+			wantDrained: corev1.ConditionFalse,
+			wantMessage: "1 namespaces moving (at most 1 at once), 0 waiting to move, 0 not rescheduled",
+		},
+		"respect the placement budget": {
+			syncTarget: newSyncTarget("st1", true, "10"),
+			namespaces: []*corev1.Namespace{
+				withDrainState(newNamespace("ns1", "st1"), "st1", workloadv1alpha1.DrainStateHeld),
+				withDrainState(newNamespace("ns2", "st1"), "st1", workloadv1alpha1.DrainStateHeld),
+				withDrainState(newNamespace("other", "st1"), "st1", workloadv1alpha1.DrainStateHeld),
+			},
+			placements: []*schedulingv1alpha1.Placement{
+				newPlacement("p1", "1", map[string]string{"app": "ns"}),
+				newPlacement("p2", "", map[string]string{}),
+			},
+			wantPatches: map[string]interface{}{"ns1": "Evicting", "other": "Evicting"},
+			wantDrained: corev1.ConditionFalse,
+			wantMessage: "2 namespaces moving (at most 10 at once), 1 waiting to move, 0 not rescheduled",
+		},
+		"drained": {
+			syncTarget: newSyncTarget("st1", true, "1"),
+			namespaces: []*corev1.Namespace{
+				withDrainState(newNamespace("ns1", "st1", "st2"), "st1", workloadv1alpha1.DrainStateEvicting),
+				withRemoving(newNamespace("ns2", "st1", "st2"), "st1"),
+			},
+			ready:       []string{"st2"},
+			wantPatches: map[string]interface{}{"ns1": nil},
+			wantDrained: corev1.ConditionTrue,
+		},
+		"invalid budget": {
+			syncTarget: newSyncTarget("st1", true, "many"),
+			namespaces: []*corev1.Namespace{
+				withDrainState(newNamespace("ns1", "st1"), "st1", workloadv1alpha1.DrainStateHeld),
+			},
+			wantPatches: map[string]interface{}{},
+			wantDrained: corev1.ConditionFalse,
+			wantMessage: `Invalid experimental.workload.kcp.dev/drain-max-unavailable annotation: invalid value for IntOrString: invalid type: string is not a percentage`,
+		},
+		"requeue at the evictAfter time": {
+			syncTarget:       withEvictAfter(newSyncTarget("st1", true, "1"), now.Add(time.Hour)),
+			wantPatches:      map[string]interface{}{},
+			wantDrained:      corev1.ConditionTrue,
+			wantEnqueueAfter: time.Hour,
+		},
+		"release the namespaces after the evictAfter time": {
+			syncTarget: withEvictAfter(newSyncTarget("st1", true, "1"), now.Add(-time.Minute)),
+			namespaces: []*corev1.Namespace{
+				withDrainState(newNamespace("ns1", "st1"), "st1", workloadv1alpha1.DrainStateHeld),
+			},
+			wantPatches: map[string]interface{}{"ns1": nil},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			patches := map[string]interface{}{}
+			var enqueuedAfter time.Duration
+			reconciler := &drainReconciler{
+				listNamespaces: func(syncTargetName string) ([]*corev1.Namespace, error) {
+					return tc.namespaces, nil
+				},
+				listPlacements: func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Placement, error) {
+					return tc.placements, nil
+				},
+				isSyncTargetReady: func(name string) (bool, error) {
+					return sets.NewString(tc.ready...).Has(name), nil
+				},
+				patchNamespace: func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Namespace, error) {
+					require.Equal(t, types.MergePatchType, pt)
+					var patch struct {
+						Metadata struct {
+							Annotations map[string]interface{} `json:"annotations"`
+						} `json:"metadata"`
+					}
+					require.NoError(t, json.Unmarshal(data, &patch))
+					patches[name] = patch.Metadata.Annotations[workloadv1alpha1.InternalClusterDrainAnnotationPrefix+"st1"]
+					return nil, nil
+				},
+				enqueueAfter: func(_ *workloadv1alpha1.SyncTarget, d time.Duration) { enqueuedAfter = d },
+				now:          func() time.Time { return now },
+			}
+
+			err := reconciler.reconcile(context.Background(), tc.syncTarget)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPatches, patches)
+			require.Equal(t, tc.wantEnqueueAfter, enqueuedAfter)
+
+			condition := conditions.Get(tc.syncTarget, workloadv1alpha1.SyncTargetDrained)
+			if tc.wantDrained == "" {
+				require.Nil(t, condition)
+				return
+			}
+			require.NotNil(t, condition)
+			require.Equal(t, tc.wantDrained, condition.Status)
+			require.Equal(t, tc.wantMessage, condition.Message)
+		})
+	}
+}
+
+func newSyncTarget(name string, unschedulable bool, maxUnavailable string) *workloadv1alpha1.SyncTarget {
+	syncTarget := &workloadv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:                      name,
+			ZZZ_DeprecatedClusterName: "root:org:location",
+		},
+		Spec: workloadv1alpha1.SyncTargetSpec{
+			Unschedulable: unschedulable,
+		},
+	}
+	if maxUnavailable != "" {
+		syncTarget.Annotations = map[string]string{
+			workloadv1alpha1.DrainMaxUnavailableAnnotation: maxUnavailable,
+		}
+	}
+	conditions.MarkTrue(syncTarget, conditionsapi.ReadyCondition)
+	return syncTarget
+}
+
+func withEvictAfter(syncTarget *workloadv1alpha1.SyncTarget, evictAfter time.Time) *workloadv1alpha1.SyncTarget {
+	syncTarget.Spec.EvictAfter = &metav1.Time{Time: evictAfter}
+	return syncTarget
+}
+
+func newNamespace(name string, syncTargets ...string) *corev1.Namespace {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:                      name,
+			ZZZ_DeprecatedClusterName: "root:org:ws",
+			Labels:                    map[string]string{"app": name[:2]},
+			Annotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey: "",
+			},
+		},
+	}
+	for _, syncTarget := range syncTargets {
+		ns.Labels[workloadv1alpha1.ClusterResourceStateLabelPrefix+syncTarget] = string(workloadv1alpha1.ResourceStateSync)
+	}
+	return ns
+}
+
+func withDrainState(ns *corev1.Namespace, syncTarget string, state workloadv1alpha1.DrainState) *corev1.Namespace {
+	ns.Annotations[workloadv1alpha1.InternalClusterDrainAnnotationPrefix+syncTarget] = string(state)
+	return ns
+}
+
+func withRemoving(ns *corev1.Namespace, syncTarget string) *corev1.Namespace {
+	ns.Annotations[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+syncTarget] = time.Now().UTC().Format(time.RFC3339)
+	return ns
+}
+
+func newPlacement(name, maxUnavailable string, matchLabels map[string]string) *schedulingv1alpha1.Placement {
+	placement := &schedulingv1alpha1.Placement{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:                      name,
+			ZZZ_DeprecatedClusterName: "root:org:ws",
+		},
+		Spec: schedulingv1alpha1.PlacementSpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: matchLabels},
+		},
+	}
+	if maxUnavailable != "" {
+		placement.Annotations = map[string]string{
+			workloadv1alpha1.DrainMaxUnavailableAnnotation: maxUnavailable,
+		}
+	}
+	return placement
+}
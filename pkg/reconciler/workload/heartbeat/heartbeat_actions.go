@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package heartbeat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+const (
+	// HeartbeatMissedEventReason is the reason of the Warning event emitted by the Event unhealthy action.
+	HeartbeatMissedEventReason = "HeartbeatMissed"
+	// HeartbeatResumedEventReason is the reason of the event emitted when the heartbeats resume, after the
+	// Event unhealthy action was taken.
+	HeartbeatResumedEventReason = "HeartbeatResumed"
+)
+
+// takeUnhealthyActions takes the unhealthy actions of the sync target due since the latest heartbeat, and records
+// them to be undone when the heartbeats resume, along with the eviction time it set. Actions left to take are
+// enqueued.
+func (c *clusterManager) takeUnhealthyActions(ctx context.Context, cluster *workloadv1alpha1.SyncTarget, latestHeartbeat time.Time) {
+	if cluster.Spec.Heartbeat == nil || len(cluster.Spec.Heartbeat.UnhealthyActions) == 0 {
+		return
+	}
+
+	taken := takenUnhealthyActions(cluster)
+	var next time.Duration
+	for _, action := range cluster.Spec.Heartbeat.UnhealthyActions {
+		if _, found := taken[string(action.Type)]; found {
+			continue
+		}
+		due := latestHeartbeat.Add(action.After.Duration)
+		var value string
+
+		switch action.Type {
+		case workloadv1alpha1.HeartbeatUnhealthyActionEvict:
+			// The eviction timer starts right away, and is left alone when already set.
+			if cluster.Spec.EvictAfter != nil {
+				continue
+			}
+			klog.V(2).Infof("Evicting SyncTarget %s|%s at %s due to a stale heartbeat", logicalcluster.From(cluster), cluster.Name, due)
+			cluster.Spec.EvictAfter = &metav1.Time{Time: due}
+			value = formatEvictAfter(cluster.Spec.EvictAfter)
+		case workloadv1alpha1.HeartbeatUnhealthyActionCordon:
+			if wait := time.Until(due); wait > 0 {
+				if next == 0 || wait < next {
+					next = wait
+				}
+				continue
+			}
+			if cluster.Spec.Unschedulable {
+				continue
+			}
+			klog.V(2).Infof("Cordoning SyncTarget %s|%s due to a stale heartbeat", logicalcluster.From(cluster), cluster.Name)
+			cluster.Spec.Unschedulable = true
+		case workloadv1alpha1.HeartbeatUnhealthyActionEvent:
+			if wait := time.Until(due); wait > 0 {
+				if next == 0 || wait < next {
+					next = wait
+				}
+				continue
+			}
+			c.emitEvent(ctx, cluster, corev1.EventTypeWarning, HeartbeatMissedEventReason, fmt.Sprintf("No heartbeat since %s", latestHeartbeat))
+		default:
+			continue
+		}
+		taken[string(action.Type)] = value
+	}
+	setTakenUnhealthyActions(cluster, taken)
+
+	if next > 0 {
+		c.enqueueClusterAfter(cluster, next)
+	}
+}
+
+// undoUnhealthyActions undoes the unhealthy actions taken while the heartbeats of the sync target were missing.
+// The eviction time is only cleared if it is still the one set by the Evict action, and the sync target is only
+// made schedulable again if it is not being drained by someone else meanwhile.
+func (c *clusterManager) undoUnhealthyActions(ctx context.Context, cluster *workloadv1alpha1.SyncTarget) {
+	taken := takenUnhealthyActions(cluster)
+	if len(taken) == 0 {
+		return
+	}
+
+	klog.V(2).Infof("Undoing the unhealthy actions %s of SyncTarget %s|%s as its heartbeats resumed", cluster.Annotations[workloadv1alpha1.InternalHeartbeatActionsAnnotation], logicalcluster.From(cluster), cluster.Name)
+	evictAfter, evicted := taken[string(workloadv1alpha1.HeartbeatUnhealthyActionEvict)]
+	evictedByAction := evicted && cluster.Spec.EvictAfter != nil && formatEvictAfter(cluster.Spec.EvictAfter) == evictAfter
+	if _, cordoned := taken[string(workloadv1alpha1.HeartbeatUnhealthyActionCordon)]; cordoned {
+		_, drained := cluster.Annotations[workloadv1alpha1.DrainMaxUnavailableAnnotation]
+		if !drained && (cluster.Spec.EvictAfter == nil || evictedByAction) {
+			cluster.Spec.Unschedulable = false
+		} else {
+			klog.V(2).Infof("Keeping SyncTarget %s|%s cordoned, as it is being drained", logicalcluster.From(cluster), cluster.Name)
+		}
+	}
+	if evictedByAction {
+		cluster.Spec.EvictAfter = nil
+	}
+	if _, found := taken[string(workloadv1alpha1.HeartbeatUnhealthyActionEvent)]; found {
+		c.emitEvent(ctx, cluster, corev1.EventTypeNormal, HeartbeatResumedEventReason, "Heartbeats resumed")
+	}
+	setTakenUnhealthyActions(cluster, nil)
+}
+
+// takenUnhealthyActions returns the unhealthy actions recorded on the sync target, with the value they set if any.
+func takenUnhealthyActions(cluster *workloadv1alpha1.SyncTarget) map[string]string {
+	taken := map[string]string{}
+	for _, action := range strings.Split(cluster.Annotations[workloadv1alpha1.InternalHeartbeatActionsAnnotation], ",") {
+		if action == "" {
+			continue
+		}
+		actionType, value, _ := strings.Cut(action, "=")
+		taken[actionType] = value
+	}
+	return taken
+}
+
+// setTakenUnhealthyActions records the unhealthy actions in the <action>[=<value>] form, comma-separated.
+func setTakenUnhealthyActions(cluster *workloadv1alpha1.SyncTarget, taken map[string]string) {
+	if len(taken) == 0 {
+		delete(cluster.Annotations, workloadv1alpha1.InternalHeartbeatActionsAnnotation)
+		return
+	}
+	actions := make([]string, 0, len(taken))
+	for actionType, value := range taken {
+		if value != "" {
+			actionType += "=" + value
+		}
+		actions = append(actions, actionType)
+	}
+	sort.Strings(actions)
+	if cluster.Annotations == nil {
+		cluster.Annotations = map[string]string{}
+	}
+	cluster.Annotations[workloadv1alpha1.InternalHeartbeatActionsAnnotation] = strings.Join(actions, ",")
+}
+
+func formatEvictAfter(evictAfter *metav1.Time) string {
+	return evictAfter.UTC().Format(time.RFC3339)
+}
+
+// unhealthyActionsPatch returns the merge patch of the spec fields and of the annotation changed by the unhealthy
+// actions, and false if they are unchanged.
+func unhealthyActionsPatch(previous, cluster *workloadv1alpha1.SyncTarget) ([]byte, bool, error) {
+	previousTaken, previousFound := previous.Annotations[workloadv1alpha1.InternalHeartbeatActionsAnnotation]
+	taken, found := cluster.Annotations[workloadv1alpha1.InternalHeartbeatActionsAnnotation]
+	if previous.Spec.Unschedulable == cluster.Spec.Unschedulable &&
+		equality.Semantic.DeepEqual(previous.Spec.EvictAfter, cluster.Spec.EvictAfter) &&
+		previousTaken == taken && previousFound == found {
+		return nil, false, nil
+	}
+
+	var takenValue, evictAfterValue interface{}
+	if found {
+		takenValue = taken
+	}
+	if cluster.Spec.EvictAfter != nil {
+		evictAfterValue = cluster.Spec.EvictAfter
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			// to ensure they appear in the patch as preconditions
+			"uid":             previous.UID,
+			"resourceVersion": previous.ResourceVersion,
+			"annotations": map[string]interface{}{
+				workloadv1alpha1.InternalHeartbeatActionsAnnotation: takenValue,
+			},
+		},
+		"spec": map[string]interface{}{
+			"unschedulable": cluster.Spec.Unschedulable,
+			"evictAfter":    evictAfterValue,
+		},
+	})
+	return patch, true, err
+}
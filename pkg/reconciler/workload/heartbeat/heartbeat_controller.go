@@ -17,26 +17,47 @@ limitations under the License.
 package heartbeat
 
 import (
+	"context"
+	"fmt"
 	"time"
 
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	apiresourceinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apiresource/v1alpha1"
 	workloadinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/workload/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/reconciler/workload/basecontroller"
 )
 
+const controllerName = "kcp-cluster-heartbeat-manager"
+
 func NewController(
 	kcpClusterClient kcpclient.Interface,
+	kubeClusterClient kubernetes.Interface,
 	clusterInformer workloadinformer.SyncTargetInformer,
 	apiResourceImportInformer apiresourceinformer.APIResourceImportInformer,
+	heartbeatInterval time.Duration,
 	heartbeatThreshold time.Duration,
 ) (*basecontroller.ClusterReconciler, error) {
 	cm := &clusterManager{
+		heartbeatInterval:  heartbeatInterval,
 		heartbeatThreshold: heartbeatThreshold,
+		emitEvent: func(ctx context.Context, syncTarget *workloadv1alpha1.SyncTarget, eventType, reason, message string) {
+			emitEvent(ctx, kubeClusterClient, syncTarget, eventType, reason, message)
+		},
+		patchSyncTarget: func(ctx context.Context, syncTarget *workloadv1alpha1.SyncTarget, patch []byte) (*workloadv1alpha1.SyncTarget, error) {
+			return kcpClusterClient.WorkloadV1alpha1().SyncTargets().Patch(logicalcluster.WithCluster(ctx, logicalcluster.From(syncTarget)), syncTarget.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		},
 	}
-
 	r, queue, err := basecontroller.NewClusterReconciler(
-		"kcp-cluster-heartbeat-manager",
+		controllerName,
 		cm,
 		kcpClusterClient,
 		clusterInformer,
@@ -48,3 +69,30 @@ func NewController(
 	cm.enqueueClusterAfter = queue.EnqueueAfter
 	return r, nil
 }
+
+// emitEvent records an event on the sync target, in the default namespace of its workspace.
+func emitEvent(ctx context.Context, kubeClusterClient kubernetes.Interface, syncTarget *workloadv1alpha1.SyncTarget, eventType, reason, message string) {
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: syncTarget.Name + ".",
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      workloadv1alpha1.SchemeGroupVersion.String(),
+			Kind:            "SyncTarget",
+			Name:            syncTarget.Name,
+			UID:             syncTarget.UID,
+			ResourceVersion: syncTarget.ResourceVersion,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Source:         corev1.EventSource{Component: controllerName},
+	}
+	if _, err := kubeClusterClient.CoreV1().Events(metav1.NamespaceDefault).Create(logicalcluster.WithCluster(ctx, logicalcluster.From(syncTarget)), event, metav1.CreateOptions{}); err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to emit %s event on SyncTarget %s|%s: %w", reason, logicalcluster.From(syncTarget), syncTarget.Name, err))
+	}
+}
@@ -18,10 +18,13 @@ package heartbeat
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
 	conditionsapi "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
@@ -33,11 +36,36 @@ import (
 var _ basecontroller.ClusterReconcileImpl = (*clusterManager)(nil)
 
 type clusterManager struct {
+	heartbeatInterval   time.Duration
 	heartbeatThreshold  time.Duration
 	enqueueClusterAfter func(*workloadv1alpha1.SyncTarget, time.Duration)
+	emitEvent           func(ctx context.Context, syncTarget *workloadv1alpha1.SyncTarget, eventType, reason, message string)
+	patchSyncTarget     func(ctx context.Context, syncTarget *workloadv1alpha1.SyncTarget, patch []byte) (*workloadv1alpha1.SyncTarget, error)
 }
 
 func (c *clusterManager) Reconcile(ctx context.Context, cluster *workloadv1alpha1.SyncTarget) error {
+	previous := cluster.DeepCopy()
+	if err := c.reconcile(ctx, cluster); err != nil {
+		return err
+	}
+
+	// The base reconciler only updates the status, so the spec fields and the annotation of the unhealthy
+	// actions are patched here.
+	patch, changed, err := unhealthyActionsPatch(previous, cluster)
+	if err != nil || !changed {
+		return err
+	}
+	klog.V(2).Infof("Patching SyncTarget %s|%s with patch %s", logicalcluster.From(cluster), cluster.Name, string(patch))
+	patched, err := c.patchSyncTarget(ctx, cluster, patch)
+	if err != nil {
+		return err
+	}
+	// The status is then updated on top of the patched SyncTarget.
+	cluster.ResourceVersion = patched.ResourceVersion
+	return nil
+}
+
+func (c *clusterManager) reconcile(ctx context.Context, cluster *workloadv1alpha1.SyncTarget) error {
 	clusterClusterName := logicalcluster.From(cluster)
 	defer conditions.SetSummary(
 		cluster,
@@ -48,6 +76,24 @@ func (c *clusterManager) Reconcile(ctx context.Context, cluster *workloadv1alpha
 		),
 	)
 
+	heartbeatInterval, heartbeatThreshold := c.heartbeatInterval, c.heartbeatThreshold
+	if heartbeat := cluster.Spec.Heartbeat; heartbeat != nil {
+		if heartbeat.Interval != nil {
+			heartbeatInterval = heartbeat.Interval.Duration
+		}
+		if heartbeat.Threshold != nil {
+			heartbeatThreshold = heartbeat.Threshold.Duration
+		}
+	}
+	// The overrides are validated against each other on admission, but an override can still exceed the
+	// setting of kcp it is combined with. The syncer must heartbeat more than once within the threshold.
+	if heartbeatInterval >= heartbeatThreshold {
+		klog.V(2).Infof("Heartbeat interval %s of SyncTarget %s|%s is not less than its threshold %s, using %s", heartbeatInterval, clusterClusterName, cluster.Name, heartbeatThreshold, heartbeatThreshold/2)
+		heartbeatInterval = heartbeatThreshold / 2
+	}
+	// Advertise the interval to the syncer.
+	cluster.Status.HeartbeatInterval = &metav1.Duration{Duration: heartbeatInterval}
+
 	latestHeartbeat := time.Time{}
 	if cluster.Status.LastSyncerHeartbeatTime != nil {
 		latestHeartbeat = cluster.Status.LastSyncerHeartbeatTime.Time
@@ -59,19 +105,37 @@ func (c *clusterManager) Reconcile(ctx context.Context, cluster *workloadv1alpha
 			workloadv1alpha1.ErrorHeartbeatMissedReason,
 			conditionsapi.ConditionSeverityWarning,
 			"No heartbeat yet seen")
-	} else if time.Since(latestHeartbeat) > c.heartbeatThreshold {
+	} else if time.Since(latestHeartbeat) > heartbeatThreshold {
 		klog.V(5).Infof("Marking HeartbeatHealthy false for SyncTarget %s|%s due to a stale heartbeat", clusterClusterName, cluster.Name)
 		conditions.MarkFalse(cluster,
 			workloadv1alpha1.HeartbeatHealthy,
 			workloadv1alpha1.ErrorHeartbeatMissedReason,
 			conditionsapi.ConditionSeverityWarning,
 			"No heartbeat since %s", latestHeartbeat)
+		if cluster.Status.ActiveSyncer != "" {
+			conditions.MarkFalse(cluster,
+				workloadv1alpha1.SyncerReady,
+				workloadv1alpha1.ErrorHeartbeatMissedReason,
+				conditionsapi.ConditionSeverityWarning,
+				"No heartbeat from syncer replica %s since %s", cluster.Status.ActiveSyncer, latestHeartbeat)
+			// The lease of the replica expired without any other replica taking over.
+			cluster.Status.ActiveSyncer = ""
+		}
+		c.takeUnhealthyActions(ctx, cluster, latestHeartbeat)
 	} else {
 		klog.V(5).Infof("Marking Heartbeat healthy true for SyncTarget %s|%s", clusterClusterName, cluster.Name)
 		conditions.MarkTrue(cluster, workloadv1alpha1.HeartbeatHealthy)
+		if cluster.Status.ActiveSyncer != "" {
+			conditions.Set(cluster, &conditionsapi.Condition{
+				Type:    workloadv1alpha1.SyncerReady,
+				Status:  corev1.ConditionTrue,
+				Message: fmt.Sprintf("Syncer replica %s is active", cluster.Status.ActiveSyncer),
+			})
+		}
 
+		c.undoUnhealthyActions(ctx, cluster)
 		// Enqueue another check after which the heartbeat should have been updated again.
-		dur := time.Until(latestHeartbeat.Add(c.heartbeatThreshold))
+		dur := time.Until(latestHeartbeat.Add(heartbeatThreshold))
 		c.enqueueClusterAfter(cluster, dur)
 	}
 
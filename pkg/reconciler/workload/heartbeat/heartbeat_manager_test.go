@@ -21,10 +21,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 )
 
@@ -32,8 +35,11 @@ func TestManager(t *testing.T) {
 	for _, c := range []struct {
 		desc              string
 		lastHeartbeatTime time.Time
+		activeSyncer      string
 		wantDur           time.Duration
 		wantReady         bool
+		wantSyncerReady   *conditionsv1alpha1.Condition
+		wantActiveSyncer  string
 	}{{
 		desc:      "no last heartbeat",
 		wantReady: false,
@@ -46,6 +52,29 @@ func TestManager(t *testing.T) {
 		desc:              "not recent enough heartbeat",
 		lastHeartbeatTime: time.Now().Add(-90 * time.Second),
 		wantReady:         false,
+	}, {
+		desc:              "recent enough heartbeat from a syncer replica",
+		lastHeartbeatTime: time.Now().Add(-10 * time.Second),
+		activeSyncer:      "syncer-abc",
+		wantDur:           50 * time.Second,
+		wantReady:         true,
+		wantSyncerReady: &conditionsv1alpha1.Condition{
+			Type:    workloadv1alpha1.SyncerReady,
+			Status:  corev1.ConditionTrue,
+			Message: "Syncer replica syncer-abc is active",
+		},
+		wantActiveSyncer: "syncer-abc",
+	}, {
+		desc:              "not recent enough heartbeat from a syncer replica",
+		lastHeartbeatTime: time.Now().Add(-90 * time.Second),
+		activeSyncer:      "syncer-abc",
+		wantReady:         false,
+		wantSyncerReady: &conditionsv1alpha1.Condition{
+			Type:     workloadv1alpha1.SyncerReady,
+			Status:   corev1.ConditionFalse,
+			Severity: conditionsv1alpha1.ConditionSeverityWarning,
+			Reason:   workloadv1alpha1.ErrorHeartbeatMissedReason,
+		},
 	}} {
 		t.Run(c.desc, func(t *testing.T) {
 			var enqueued time.Duration
@@ -65,6 +94,7 @@ func TestManager(t *testing.T) {
 						Status: corev1.ConditionTrue,
 					}},
 					LastSyncerHeartbeatTime: &heartbeat,
+					ActiveSyncer:            c.activeSyncer,
 				},
 			}
 			if err := mgr.Reconcile(ctx, cl); err != nil {
@@ -81,6 +111,230 @@ func TestManager(t *testing.T) {
 				t.Errorf("cluster Ready; got %t, want %t", isReady, c.wantReady)
 			}
 			// TODO: check wantReady.
+			if cl.Status.ActiveSyncer != c.wantActiveSyncer {
+				t.Errorf("active syncer; got %q, want %q", cl.Status.ActiveSyncer, c.wantActiveSyncer)
+			}
+
+			syncerReady := conditions.Get(cl, workloadv1alpha1.SyncerReady)
+			if c.wantSyncerReady == nil {
+				if syncerReady != nil {
+					t.Errorf("SyncerReady; got %v, want none", syncerReady)
+				}
+				return
+			}
+			if syncerReady == nil {
+				t.Fatalf("SyncerReady; got none, want %v", c.wantSyncerReady)
+			}
+			if syncerReady.Status != c.wantSyncerReady.Status || syncerReady.Reason != c.wantSyncerReady.Reason || syncerReady.Severity != c.wantSyncerReady.Severity {
+				t.Errorf("SyncerReady; got %v, want %v", syncerReady, c.wantSyncerReady)
+			}
+			if c.wantSyncerReady.Message != "" && syncerReady.Message != c.wantSyncerReady.Message {
+				t.Errorf("SyncerReady message; got %q, want %q", syncerReady.Message, c.wantSyncerReady.Message)
+			}
+		})
+	}
+}
+
+func TestHeartbeatSettings(t *testing.T) {
+	var enqueued time.Duration
+	mgr := clusterManager{
+		heartbeatInterval:  20 * time.Second,
+		heartbeatThreshold: time.Minute,
+		enqueueClusterAfter: func(_ *workloadv1alpha1.SyncTarget, dur time.Duration) {
+			enqueued = dur
+		},
+	}
+	heartbeat := metav1.NewTime(time.Now().Add(-90 * time.Second))
+	cl := &workloadv1alpha1.SyncTarget{
+		Spec: workloadv1alpha1.SyncTargetSpec{
+			Heartbeat: &workloadv1alpha1.SyncTargetHeartbeat{
+				Interval:  &metav1.Duration{Duration: time.Minute},
+				Threshold: &metav1.Duration{Duration: 3 * time.Minute},
+			},
+		},
+		Status: workloadv1alpha1.SyncTargetStatus{
+			LastSyncerHeartbeatTime: &heartbeat,
+		},
+	}
+	require.NoError(t, mgr.Reconcile(context.Background(), cl))
+	require.Equal(t, &metav1.Duration{Duration: time.Minute}, cl.Status.HeartbeatInterval)
+	require.True(t, conditions.IsTrue(cl, workloadv1alpha1.HeartbeatHealthy), "the heartbeat should be healthy within the threshold of the SyncTarget")
+	require.InDelta(t, 90*time.Second, enqueued, float64(time.Second))
+
+	cl.Spec.Heartbeat = nil
+	require.NoError(t, mgr.Reconcile(context.Background(), cl))
+	require.Equal(t, &metav1.Duration{Duration: 20 * time.Second}, cl.Status.HeartbeatInterval)
+	require.True(t, conditions.IsFalse(cl, workloadv1alpha1.HeartbeatHealthy), "the heartbeat should be unhealthy after the default threshold")
+
+	cl.Spec.Heartbeat = &workloadv1alpha1.SyncTargetHeartbeat{
+		Interval: &metav1.Duration{Duration: 2 * time.Minute},
+	}
+	require.NoError(t, mgr.Reconcile(context.Background(), cl))
+	require.Equal(t, &metav1.Duration{Duration: 30 * time.Second}, cl.Status.HeartbeatInterval, "the interval should be less than the default threshold")
+}
+
+func TestUnhealthyActions(t *testing.T) {
+	cordon := workloadv1alpha1.HeartbeatUnhealthyAction{Type: workloadv1alpha1.HeartbeatUnhealthyActionCordon, After: metav1.Duration{Duration: 5 * time.Minute}}
+	evict := workloadv1alpha1.HeartbeatUnhealthyAction{Type: workloadv1alpha1.HeartbeatUnhealthyActionEvict, After: metav1.Duration{Duration: time.Hour}}
+	event := workloadv1alpha1.HeartbeatUnhealthyAction{Type: workloadv1alpha1.HeartbeatUnhealthyActionEvent, After: metav1.Duration{Duration: 2 * time.Minute}}
+	now := time.Now()
+	evicted := func(evictAfter time.Duration) string {
+		return "Evict=" + now.Add(evictAfter).UTC().Format(time.RFC3339)
+	}
+
+	for _, c := range []struct {
+		desc              string
+		sinceHeartbeat    time.Duration
+		actions           []workloadv1alpha1.HeartbeatUnhealthyAction
+		unschedulable     bool
+		evictAfter        *time.Duration
+		drained           bool
+		taken             string
+		wantUnschedulable bool
+		wantEvictAfter    *time.Duration
+		wantTaken         string
+		wantEvents        []string
+		wantEnqueued      time.Duration
+	}{{
+		desc:           "healthy heartbeat",
+		sinceHeartbeat: 10 * time.Second,
+		actions:        []workloadv1alpha1.HeartbeatUnhealthyAction{cordon, evict, event},
+		wantEnqueued:   50 * time.Second,
+	}, {
+		desc:           "heartbeat just missed starts the eviction timer",
+		sinceHeartbeat: 90 * time.Second,
+		actions:        []workloadv1alpha1.HeartbeatUnhealthyAction{cordon, evict, event},
+		wantEvictAfter: durationPtr(time.Hour - 90*time.Second),
+		wantTaken:      evicted(time.Hour - 90*time.Second),
+		wantEnqueued:   30 * time.Second,
+	}, {
+		desc:              "heartbeat missed for a while",
+		sinceHeartbeat:    10 * time.Minute,
+		actions:           []workloadv1alpha1.HeartbeatUnhealthyAction{cordon, evict, event},
+		wantUnschedulable: true,
+		wantEvictAfter:    durationPtr(50 * time.Minute),
+		wantTaken:         "Cordon,Event," + evicted(50*time.Minute),
+		wantEvents:        []string{"Warning HeartbeatMissed"},
+	}, {
+		desc:              "actions already taken are not taken again",
+		sinceHeartbeat:    10 * time.Minute,
+		actions:           []workloadv1alpha1.HeartbeatUnhealthyAction{cordon, evict, event},
+		unschedulable:     true,
+		evictAfter:        durationPtr(50 * time.Minute),
+		taken:             "Cordon,Event," + evicted(50*time.Minute),
+		wantUnschedulable: true,
+		wantEvictAfter:    durationPtr(50 * time.Minute),
+		wantTaken:         "Cordon,Event," + evicted(50*time.Minute),
+	}, {
+		desc:              "sync target already cordoned and evicted is left alone",
+		sinceHeartbeat:    10 * time.Minute,
+		actions:           []workloadv1alpha1.HeartbeatUnhealthyAction{cordon, evict},
+		unschedulable:     true,
+		evictAfter:        durationPtr(time.Minute),
+		wantUnschedulable: true,
+		wantEvictAfter:    durationPtr(time.Minute),
+	}, {
+		desc:           "no actions",
+		sinceHeartbeat: 10 * time.Minute,
+	}, {
+		desc:           "heartbeat resumed undoes the actions taken",
+		sinceHeartbeat: 10 * time.Second,
+		actions:        []workloadv1alpha1.HeartbeatUnhealthyAction{cordon, evict, event},
+		unschedulable:  true,
+		evictAfter:     durationPtr(50 * time.Minute),
+		taken:          "Cordon,Event," + evicted(50*time.Minute),
+		wantEvents:     []string{"Normal HeartbeatResumed"},
+		wantEnqueued:   50 * time.Second,
+	}, {
+		desc:              "heartbeat resumed keeps the sync target cordoned by someone else",
+		sinceHeartbeat:    10 * time.Second,
+		actions:           []workloadv1alpha1.HeartbeatUnhealthyAction{cordon, evict},
+		unschedulable:     true,
+		evictAfter:        durationPtr(50 * time.Minute),
+		taken:             evicted(50 * time.Minute),
+		wantUnschedulable: true,
+		wantEnqueued:      50 * time.Second,
+	}, {
+		desc:              "heartbeat resumed keeps the eviction time changed by someone else",
+		sinceHeartbeat:    10 * time.Second,
+		actions:           []workloadv1alpha1.HeartbeatUnhealthyAction{cordon, evict},
+		unschedulable:     true,
+		evictAfter:        durationPtr(10 * time.Minute),
+		taken:             "Cordon," + evicted(50*time.Minute),
+		wantUnschedulable: true,
+		wantEvictAfter:    durationPtr(10 * time.Minute),
+		wantEnqueued:      50 * time.Second,
+	}, {
+		desc:              "heartbeat resumed keeps the sync target drained gracefully",
+		sinceHeartbeat:    10 * time.Second,
+		actions:           []workloadv1alpha1.HeartbeatUnhealthyAction{cordon},
+		unschedulable:     true,
+		drained:           true,
+		taken:             "Cordon",
+		wantUnschedulable: true,
+		wantEnqueued:      50 * time.Second,
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			var enqueued time.Duration
+			var events, patches []string
+			mgr := clusterManager{
+				heartbeatThreshold: time.Minute,
+				enqueueClusterAfter: func(_ *workloadv1alpha1.SyncTarget, dur time.Duration) {
+					enqueued = dur
+				},
+				emitEvent: func(_ context.Context, _ *workloadv1alpha1.SyncTarget, eventType, reason, _ string) {
+					events = append(events, eventType+" "+reason)
+				},
+				patchSyncTarget: func(_ context.Context, syncTarget *workloadv1alpha1.SyncTarget, patch []byte) (*workloadv1alpha1.SyncTarget, error) {
+					patches = append(patches, string(patch))
+					return syncTarget, nil
+				},
+			}
+			heartbeat := metav1.NewTime(now.Add(-c.sinceHeartbeat))
+			cl := &workloadv1alpha1.SyncTarget{
+				Spec: workloadv1alpha1.SyncTargetSpec{
+					Unschedulable: c.unschedulable,
+					Heartbeat: &workloadv1alpha1.SyncTargetHeartbeat{
+						UnhealthyActions: c.actions,
+					},
+				},
+				Status: workloadv1alpha1.SyncTargetStatus{
+					LastSyncerHeartbeatTime: &heartbeat,
+				},
+			}
+			if c.evictAfter != nil {
+				cl.Spec.EvictAfter = &metav1.Time{Time: now.Add(*c.evictAfter)}
+			}
+			cl.Annotations = map[string]string{}
+			if c.taken != "" {
+				cl.Annotations[workloadv1alpha1.InternalHeartbeatActionsAnnotation] = c.taken
+			}
+			if c.drained {
+				cl.Annotations[workloadv1alpha1.DrainMaxUnavailableAnnotation] = "1"
+			}
+
+			require.NoError(t, mgr.Reconcile(context.Background(), cl))
+
+			require.Equal(t, c.wantUnschedulable, cl.Spec.Unschedulable, "unschedulable")
+			if c.wantEvictAfter == nil {
+				require.Nil(t, cl.Spec.EvictAfter, "evictAfter")
+			} else {
+				require.NotNil(t, cl.Spec.EvictAfter, "evictAfter")
+				require.WithinDuration(t, now.Add(*c.wantEvictAfter), cl.Spec.EvictAfter.Time, time.Second, "evictAfter")
+			}
+			require.Equal(t, c.wantTaken, cl.Annotations[workloadv1alpha1.InternalHeartbeatActionsAnnotation], "taken actions")
+			require.Equal(t, c.wantEvents, events, "events")
+			if c.taken == c.wantTaken {
+				require.Empty(t, patches, "patches")
+			} else {
+				require.Len(t, patches, 1, "patches")
+				require.Contains(t, patches[0], workloadv1alpha1.InternalHeartbeatActionsAnnotation, "patch")
+			}
+			require.InDelta(t, c.wantEnqueued, enqueued, float64(time.Second), "enqueued")
 		})
 	}
 }
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
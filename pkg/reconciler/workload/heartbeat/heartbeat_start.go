@@ -25,16 +25,19 @@ import (
 
 func DefaultOptions() *Options {
 	return &Options{
+		HeartbeatInterval:  20 * time.Second,
 		HeartbeatThreshold: time.Minute,
 	}
 }
 
 func BindOptions(o *Options, fs *pflag.FlagSet) *Options {
+	fs.DurationVar(&o.HeartbeatInterval, "sync-target-heartbeat-interval", o.HeartbeatInterval, "Interval at which the syncers heartbeat, for the sync targets not setting their own")
 	fs.DurationVar(&o.HeartbeatThreshold, "sync-target-heartbeat-threshold", o.HeartbeatThreshold, "Amount of time to wait for a successful heartbeat before marking the cluster as not ready")
 	return o
 }
 
 type Options struct {
+	HeartbeatInterval  time.Duration
 	HeartbeatThreshold time.Duration
 }
 
@@ -42,5 +45,11 @@ func (o *Options) Validate() error {
 	if o.HeartbeatThreshold <= 0 {
 		return fmt.Errorf("--sync-target-heartbeat-threshold must be >0 (%s)", o.HeartbeatThreshold)
 	}
+	if o.HeartbeatInterval <= 0 {
+		return fmt.Errorf("--sync-target-heartbeat-interval must be >0 (%s)", o.HeartbeatInterval)
+	}
+	if o.HeartbeatInterval >= o.HeartbeatThreshold {
+		return fmt.Errorf("--sync-target-heartbeat-interval must be less than --sync-target-heartbeat-threshold (%s >= %s)", o.HeartbeatInterval, o.HeartbeatThreshold)
+	}
 	return nil
 }
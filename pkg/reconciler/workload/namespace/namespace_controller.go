@@ -20,6 +20,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
@@ -28,7 +30,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -41,8 +45,12 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/kube-openapi/pkg/util/sets"
 
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 	schedulinginformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/scheduling/v1alpha1"
+	workloadinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/workload/v1alpha1"
 	schedulinglisters "github.com/kcp-dev/kcp/pkg/client/listers/scheduling/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/informer"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
 )
 
 const (
@@ -57,6 +65,9 @@ func NewController(
 	kubeClusterClient kubernetesclient.Interface,
 	namespaceInformer coreinformers.NamespaceInformer,
 	placementInformer schedulinginformers.PlacementInformer,
+	syncTargetInformer workloadinformers.SyncTargetInformer,
+	ddsif *informer.DynamicDiscoverySharedInformerFactory,
+	removalGracePeriod time.Duration,
 ) (*controller, error) {
 	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
 
@@ -74,6 +85,12 @@ func NewController(
 
 		placmentLister:   placementInformer.Lister(),
 		placementIndexer: placementInformer.Informer().GetIndexer(),
+
+		syncTargetIndexer: syncTargetInformer.Informer().GetIndexer(),
+
+		ddsif: ddsif,
+
+		removalGracePeriod: removalGracePeriod,
 	}
 
 	if err := namespaceInformer.Informer().AddIndexers(cache.Indexers{
@@ -115,6 +132,21 @@ func NewController(
 		DeleteFunc: func(obj interface{}) { c.enqueuePlacement(obj, "") },
 	})
 
+	// Resources being removed from a sync target drive the SyncTargetsRemoved condition of their namespace,
+	// and persistent volume claims bound to volumes of a sync target keep their namespace on it.
+	ddsif.AddEventHandler(informer.GVREventHandlerFuncs{
+		UpdateFunc: func(gvr schema.GroupVersionResource, oldObj, obj interface{}) {
+			if isBeingRemoved(oldObj) || isBeingRemoved(obj) || boundVolumes(gvr, oldObj) != boundVolumes(gvr, obj) {
+				c.enqueueResourceNamespace(gvr, obj)
+			}
+		},
+		DeleteFunc: func(gvr schema.GroupVersionResource, obj interface{}) {
+			if isBeingRemoved(obj) || boundVolumes(gvr, obj) != "" {
+				c.enqueueResourceNamespace(gvr, obj)
+			}
+		},
+	})
+
 	return c, nil
 }
 
@@ -130,6 +162,13 @@ type controller struct {
 
 	placmentLister   schedulinglisters.PlacementLister
 	placementIndexer cache.Indexer
+
+	syncTargetIndexer cache.Indexer
+
+	ddsif *informer.DynamicDiscoverySharedInformerFactory
+
+	// removalGracePeriod is the default removal grace period of placements not setting one.
+	removalGracePeriod time.Duration
 }
 
 func (c *controller) enqueueNamespace(obj interface{}) {
@@ -166,6 +205,58 @@ func (c *controller) enqueuePlacement(obj interface{}, logSuffix string) {
 	}
 }
 
+func (c *controller) enqueueResourceNamespace(gvr schema.GroupVersionResource, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	metaObj, err := meta.Accessor(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	if metaObj.GetNamespace() == "" {
+		return
+	}
+
+	nskey := clusters.ToClusterAwareKey(logicalcluster.From(metaObj), metaObj.GetNamespace())
+	klog.V(4).Infof("Queueing namespace %s|%s because of %s %s being removed from sync targets", logicalcluster.From(metaObj), metaObj.GetNamespace(), gvr.String(), metaObj.GetName())
+	c.queue.Add(nskey)
+}
+
+// isBeingRemoved returns true if the object is being removed from at least one sync target.
+func isBeingRemoved(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	metaObj, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+	return len(shared.SyncTargetsBeingRemoved(metaObj)) > 0
+}
+
+// boundVolumes returns the sorted sync targets a persistent volume claim is bound to volumes of, comma-separated.
+func boundVolumes(gvr schema.GroupVersionResource, obj interface{}) string {
+	if gvr.Group != "" || gvr.Resource != "persistentvolumeclaims" {
+		return ""
+	}
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	metaObj, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	var syncTargets []string
+	for key := range metaObj.GetAnnotations() {
+		if strings.HasPrefix(key, workloadv1alpha1.ClusterBoundVolumeAnnotationPrefix) {
+			syncTargets = append(syncTargets, strings.TrimPrefix(key, workloadv1alpha1.ClusterBoundVolumeAnnotationPrefix))
+		}
+	}
+	sort.Strings(syncTargets)
+	return strings.Join(syncTargets, ",")
+}
+
 // Start starts the controller, which stops when ctx.Done() is closed.
 func (c *controller) Start(ctx context.Context, numThreads int) {
 	defer runtime.HandleCrash()
@@ -23,9 +23,15 @@ import (
 	"github.com/kcp-dev/logicalcluster/v2"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
 	utilserrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/clusters"
 
 	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/reconciler/workload/drain"
 )
 
 type reconcileStatus int
@@ -46,13 +52,19 @@ func (c *controller) reconcile(ctx context.Context, ns *corev1.Namespace) error
 			patchNamespace: c.patchNamespace,
 		},
 		&placementSchedulingReconciler{
-			listPlacement:  c.listPlacement,
-			enqueueAfter:   c.enqueueAfter,
-			patchNamespace: c.patchNamespace,
-			now:            time.Now,
+			listPlacement:          c.listPlacement,
+			listNamespaceResources: c.listNamespaceResources,
+			enqueueAfter:           c.enqueueAfter,
+			patchNamespace:         c.patchNamespace,
+			isDrainingGracefully:   c.isDrainingGracefully,
+			now:                    time.Now,
+
+			defaultRemovalGracePeriod: c.removalGracePeriod,
 		},
 		&statusConditionReconciler{
-			patchNamespace: c.patchNamespace,
+			listPlacement:          c.listPlacement,
+			listNamespaceResources: c.listNamespaceResources,
+			patchNamespace:         c.patchNamespace,
 		},
 	}
 
@@ -84,3 +96,40 @@ func (c *controller) listPlacement(clusterName logicalcluster.Name) ([]*scheduli
 	}
 	return ret, nil
 }
+
+// isDrainingGracefully returns true if the synctarget of the given name in the given location workspace is
+// drained gracefully.
+func (c *controller) isDrainingGracefully(locationWorkspace logicalcluster.Name, syncTargetName string) bool {
+	obj, exists, err := c.syncTargetIndexer.GetByKey(clusters.ToClusterAwareKey(locationWorkspace, syncTargetName))
+	if err != nil {
+		runtime.HandleError(err)
+		return false
+	}
+	if !exists {
+		return false
+	}
+	return drain.IsDrainingGracefully(obj.(*workloadv1alpha1.SyncTarget), time.Now())
+}
+
+func (c *controller) listNamespaceResources(clusterName logicalcluster.Name, namespace string) ([]namespacedResource, error) {
+	var ret []namespacedResource
+	listers, _ := c.ddsif.Listers()
+	for gvr, lister := range listers {
+		objs, err := lister.ByNamespace(namespace).List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			metaObj, err := meta.Accessor(obj)
+			if err != nil {
+				return nil, err
+			}
+			// TODO(ncdc): remove this when we have namespaced listers that only return for the scoped cluster (https://github.com/kcp-dev/kcp/issues/685).
+			if logicalcluster.From(metaObj) != clusterName {
+				continue
+			}
+			ret = append(ret, namespacedResource{gvr: gvr, obj: metaObj})
+		}
+	}
+	return ret, nil
+}
@@ -19,6 +19,7 @@ package namespace
 import (
 	"context"
 	"encoding/json"
+	"sort"
 	"strings"
 	"time"
 
@@ -36,75 +37,104 @@ import (
 	placementreconciler "github.com/kcp-dev/kcp/pkg/reconciler/workload/placement"
 )
 
-const removingGracePeriod = 5 * time.Second
-
 // placementSchedulingReconciler reconciles the state.workload.kcp.dev/<syncTarget> labels according the
 // selected synctarget stored in the internal.workload.kcp.dev/synctarget annotation
 // on each placement.
 type placementSchedulingReconciler struct {
-	listPlacement func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Placement, error)
+	listPlacement          func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Placement, error)
+	listNamespaceResources func(clusterName logicalcluster.Name, namespace string) ([]namespacedResource, error)
 
 	patchNamespace func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Namespace, error)
 
 	enqueueAfter func(*corev1.Namespace, time.Duration)
 
+	// isDrainingGracefully returns true if the synctarget of the given name in the given location workspace
+	// is drained gracefully.
+	isDrainingGracefully func(locationWorkspace logicalcluster.Name, syncTargetName string) bool
+
 	now func() time.Time
+
+	// defaultRemovalGracePeriod is used for placements not setting a removal grace period.
+	defaultRemovalGracePeriod time.Duration
 }
 
 func (r *placementSchedulingReconciler) reconcile(ctx context.Context, ns *corev1.Namespace) (reconcileStatus, *corev1.Namespace, error) {
 	clusterName := logicalcluster.From(ns)
 
-	validPlacements := []*schedulingv1alpha1.Placement{}
-	_, foundPlacement := ns.Annotations[schedulingv1alpha1.PlacementAnnotationKey]
+	validPlacements, err := listValidPlacements(ns, r.listPlacement)
+	if err != nil {
+		return reconcileStatusStop, ns, err
+	}
+
+	// 1. pick all synctargets in all bound placements
+	scheduledSyncTargets := scheduledSyncTargets(validPlacements)
+
+	// 2. find the scheduled synctarget to the ns, including synced, removing
+	synced, removing := syncedRemovingCluster(ns)
 
-	if foundPlacement {
-		placements, err := r.listPlacement(clusterName)
+	// Persistent volume claims bound to volumes of a synctarget keep the ns on it, unless forced.
+	var boundVolumes map[string][]string
+	if leaving := synced.Difference(scheduledSyncTargets); leaving.Len() > 0 && !forceMoveBoundVolumes(ns) {
+		resources, err := r.listNamespaceResources(clusterName, ns.Name)
 		if err != nil {
 			return reconcileStatusStop, ns, err
 		}
-
-		validPlacements = filterValidPlacements(ns, placements)
+		boundVolumes = syncTargetsWithBoundVolumes(leaving, resources)
 	}
 
-	// 1. pick all synctargets in all bound placements
-	scheduledSyncTargets := sets.NewString()
-	for _, placement := range validPlacements {
-		currentScheduled, foundScheduled := placement.Annotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey]
-		if !foundScheduled {
+	expectedAnnotations := map[string]interface{}{} // nil means to remove the key
+	expectedLabels := map[string]interface{}{}      // nil means to remove the key
+
+	// Synctargets drained gracefully keep the ns until the drain controller lets it go.
+	held := sets.NewString()
+	for _, cluster := range synced.Difference(scheduledSyncTargets).List() {
+		state := workloadv1alpha1.DrainState(ns.Annotations[workloadv1alpha1.InternalClusterDrainAnnotationPrefix+cluster])
+		if state == workloadv1alpha1.DrainStateEvicting || !r.isDrainingGracefullyForPlacements(validPlacements, cluster) {
 			continue
 		}
-
-		// TODO: location workspace should be considered also
-		_, syncTarget := placementreconciler.ParseCurrentScheduled(currentScheduled)
-		scheduledSyncTargets.Insert(syncTarget)
+		held.Insert(cluster)
+		if state != workloadv1alpha1.DrainStateHeld {
+			expectedAnnotations[workloadv1alpha1.InternalClusterDrainAnnotationPrefix+cluster] = string(workloadv1alpha1.DrainStateHeld)
+		}
 	}
 
-	// 2. find the scheduled synctarget to the ns, including synced, removing
-	synced, removing := syncedRemovingCluster(ns)
-
 	// 3. if the synced synctarget is not in the scheduled synctargets, mark it as removing.
-	expectedAnnotations := map[string]interface{}{} // nil means to remove the key
-	expectedLabels := map[string]interface{}{}      // nil means to remove the key
-
 	for cluster := range synced {
+		if claims, found := boundVolumes[cluster]; found {
+			klog.V(2).Infof("keep cluster %s for ns %s|%s since persistent volume claims %v are bound to its volumes", cluster, clusterName, ns.Name, claims)
+			continue
+		}
+		if held.Has(cluster) {
+			klog.V(2).Infof("keep cluster %s for ns %s|%s since it is drained gracefully", cluster, clusterName, ns.Name)
+			continue
+		}
 		if !scheduledSyncTargets.Has(cluster) {
 			// it is no longer a synced synctarget, mark it as removing.
 			now := r.now().UTC().Format(time.RFC3339)
 			expectedAnnotations[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+cluster] = now
+			expectedAnnotations[workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix+cluster] = r.removalGracePeriod(validPlacements).String()
 			klog.V(4).Infof("set cluster %s removing for ns %s|%s since it is not a valid cluster anymore", cluster, clusterName, ns.Name)
 		}
 	}
 
 	// 4. remove the synctarget after grace period
-	minEnqueueDuration := removingGracePeriod + 1
+	var minEnqueueDuration time.Duration
 	for cluster, removingTime := range removing {
-		if removingTime.Add(removingGracePeriod).Before(r.now()) {
+		gracePeriod := r.defaultRemovalGracePeriod
+		if value, found := ns.Annotations[workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix+cluster]; found {
+			if d, err := time.ParseDuration(value); err == nil {
+				gracePeriod = d
+			}
+		}
+
+		if removingTime.Add(gracePeriod).Before(r.now()) {
 			expectedLabels[workloadv1alpha1.ClusterResourceStateLabelPrefix+cluster] = nil
 			expectedAnnotations[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+cluster] = nil
+			expectedAnnotations[workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix+cluster] = nil
 			klog.V(4).Infof("remove cluster %s for ns %s|%s", cluster, clusterName, ns.Name)
 		} else {
-			enqueuDuration := time.Until(removingTime.Add(removingGracePeriod))
-			if enqueuDuration < minEnqueueDuration {
+			enqueuDuration := removingTime.Add(gracePeriod).Sub(r.now()) + time.Second
+			if minEnqueueDuration == 0 || enqueuDuration < minEnqueueDuration {
 				minEnqueueDuration = enqueuDuration
 			}
 		}
@@ -118,6 +148,16 @@ func (r *placementSchedulingReconciler) reconcile(ctx context.Context, ns *corev
 		if _, ok := removing[scheduledSyncTarget]; ok {
 			continue
 		}
+		if len(boundVolumes) > 0 {
+			// the ns cannot move while it is kept on the synctargets with bound volumes
+			klog.V(2).Infof("not setting cluster %s sync for ns %s|%s since it is kept on clusters with bound volumes", scheduledSyncTarget, clusterName, ns.Name)
+			continue
+		}
+		if held.Len() > 0 {
+			// the ns cannot move while it is held on the synctargets drained gracefully
+			klog.V(2).Infof("not setting cluster %s sync for ns %s|%s since it is held on clusters drained gracefully", scheduledSyncTarget, clusterName, ns.Name)
+			continue
+		}
 
 		expectedLabels[workloadv1alpha1.ClusterResourceStateLabelPrefix+scheduledSyncTarget] = string(workloadv1alpha1.ResourceStateSync)
 		klog.V(4).Infof("set cluster %s sync for ns %s|%s", scheduledSyncTarget, clusterName, ns.Name)
@@ -129,7 +169,7 @@ func (r *placementSchedulingReconciler) reconcile(ctx context.Context, ns *corev
 	}
 
 	// 6. Requeue at last to check if removing cluster should be removed later.
-	if minEnqueueDuration <= removingGracePeriod {
+	if minEnqueueDuration > 0 {
 		klog.V(2).Infof("enqueue ns %s|%s after %s", clusterName, ns.Name, minEnqueueDuration)
 		r.enqueueAfter(ns, minEnqueueDuration)
 	}
@@ -137,6 +177,21 @@ func (r *placementSchedulingReconciler) reconcile(ctx context.Context, ns *corev
 	return reconcileStatusContinue, ns, nil
 }
 
+// isDrainingGracefullyForPlacements returns true if the synctarget of the given name is drained gracefully in the
+// location workspace of one of the given placements. Namespaces refer to the synctargets they are synced to by
+// name only, so they are resolved in the location workspaces the placements schedule the namespace from.
+func (r *placementSchedulingReconciler) isDrainingGracefullyForPlacements(placements []*schedulingv1alpha1.Placement, syncTargetName string) bool {
+	for _, placement := range placements {
+		if placement.Status.SelectedLocation == nil {
+			continue
+		}
+		if r.isDrainingGracefully(logicalcluster.New(placement.Status.SelectedLocation.Path), syncTargetName) {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *placementSchedulingReconciler) patchNamespaceLabelAnnotation(ctx context.Context, clusterName logicalcluster.Name, ns *corev1.Namespace, labels, annotations map[string]interface{}) (*corev1.Namespace, error) {
 	patch := map[string]interface{}{}
 	if len(annotations) > 0 {
@@ -162,6 +217,78 @@ func (r *placementSchedulingReconciler) patchNamespaceLabelAnnotation(ctx contex
 	return updated, nil
 }
 
+// listValidPlacements returns the placements the namespace is bound to.
+func listValidPlacements(ns *corev1.Namespace, listPlacement func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Placement, error)) ([]*schedulingv1alpha1.Placement, error) {
+	if _, foundPlacement := ns.Annotations[schedulingv1alpha1.PlacementAnnotationKey]; !foundPlacement {
+		return []*schedulingv1alpha1.Placement{}, nil
+	}
+	placements, err := listPlacement(logicalcluster.From(ns))
+	if err != nil {
+		return nil, err
+	}
+	return filterValidPlacements(ns, placements), nil
+}
+
+// scheduledSyncTargets returns the synctargets selected by the given placements.
+func scheduledSyncTargets(placements []*schedulingv1alpha1.Placement) sets.String {
+	scheduled := sets.NewString()
+	for _, placement := range placements {
+		currentScheduled, foundScheduled := placement.Annotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey]
+		if !foundScheduled {
+			continue
+		}
+
+		// TODO: location workspace should be considered also
+		_, syncTarget := placementreconciler.ParseCurrentScheduled(currentScheduled)
+		scheduled.Insert(syncTarget)
+	}
+	return scheduled
+}
+
+// forceMoveBoundVolumes returns true if the ns can be moved away from synctargets its persistent volume claims
+// are bound to volumes of.
+func forceMoveBoundVolumes(ns *corev1.Namespace) bool {
+	return ns.Annotations[workloadv1alpha1.ForceMoveBoundVolumesAnnotation] == "true"
+}
+
+// syncTargetsWithBoundVolumes returns, for each of the given synctargets some of the persistent volume claims
+// are bound to volumes of, the sorted names of these claims.
+func syncTargetsWithBoundVolumes(syncTargets sets.String, resources []namespacedResource) map[string][]string {
+	boundVolumes := map[string][]string{}
+	for _, resource := range resources {
+		if resource.gvr.Group != "" || resource.gvr.Resource != "persistentvolumeclaims" {
+			continue
+		}
+		for _, syncTarget := range syncTargets.List() {
+			if _, found := resource.obj.GetAnnotations()[workloadv1alpha1.ClusterBoundVolumeAnnotationPrefix+syncTarget]; found {
+				boundVolumes[syncTarget] = append(boundVolumes[syncTarget], resource.obj.GetName())
+			}
+		}
+	}
+	for _, claims := range boundVolumes {
+		sort.Strings(claims)
+	}
+	return boundVolumes
+}
+
+// removalGracePeriod returns the longest removal grace period of the given placements, or the default one if
+// none of them sets it.
+func (r *placementSchedulingReconciler) removalGracePeriod(placements []*schedulingv1alpha1.Placement) time.Duration {
+	var gracePeriod *time.Duration
+	for _, placement := range placements {
+		if placement.Spec.RemovalGracePeriod == nil {
+			continue
+		}
+		if d := placement.Spec.RemovalGracePeriod.Duration; gracePeriod == nil || d > *gracePeriod {
+			gracePeriod = &d
+		}
+	}
+	if gracePeriod == nil {
+		return r.defaultRemovalGracePeriod
+	}
+	return *gracePeriod
+}
+
 // syncedRemovingCluster finds synced and removing clusters for this ns.
 func syncedRemovingCluster(ns *corev1.Namespace) (sets.String, map[string]time.Time) {
 	synced := sets.NewString()
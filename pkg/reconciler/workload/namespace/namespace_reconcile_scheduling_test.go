@@ -27,11 +27,15 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 )
 
+var testLocationWorkspace = logicalcluster.New("root:org:ws")
+
 func TestScheduling(t *testing.T) {
 	now := time.Now()
 	now3339 := now.UTC().Format(time.RFC3339)
@@ -44,8 +48,11 @@ func TestScheduling(t *testing.T) {
 
 		labels      map[string]string
 		annotations map[string]string
+		resources   []namespacedResource
+		draining    []string
 
 		wantPatch           bool
+		wantEnqueueAtLeast  time.Duration
 		expectedLabels      map[string]string
 		expectedAnnotations map[string]string
 	}{
@@ -60,8 +67,9 @@ func TestScheduling(t *testing.T) {
 			noPlacements: true,
 			wantPatch:    true,
 			expectedAnnotations: map[string]string{
-				schedulingv1alpha1.PlacementAnnotationKey:                                      "",
-				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "cluster1": now3339,
+				schedulingv1alpha1.PlacementAnnotationKey:                                       "",
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "cluster1":  now3339,
+				workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix + "cluster1": "5s",
 			},
 			expectedLabels: map[string]string{
 				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
@@ -109,8 +117,9 @@ func TestScheduling(t *testing.T) {
 			placement: newPlacement("test-placement", "test-location", ""),
 			wantPatch: true,
 			expectedAnnotations: map[string]string{
-				schedulingv1alpha1.PlacementAnnotationKey:                                          "",
-				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "test-cluster": now3339,
+				schedulingv1alpha1.PlacementAnnotationKey:                                           "",
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "test-cluster":  now3339,
+				workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix + "test-cluster": "5s",
 			},
 			expectedLabels: map[string]string{
 				workloadv1alpha1.ClusterResourceStateLabelPrefix + "test-cluster": string(workloadv1alpha1.ResourceStateSync),
@@ -127,14 +136,57 @@ func TestScheduling(t *testing.T) {
 			placement: newPlacement("test-placement", "test-location", "test-cluster-2"),
 			wantPatch: true,
 			expectedAnnotations: map[string]string{
-				schedulingv1alpha1.PlacementAnnotationKey:                                          "",
-				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "test-cluster": now3339,
+				schedulingv1alpha1.PlacementAnnotationKey:                                           "",
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "test-cluster":  now3339,
+				workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix + "test-cluster": "5s",
 			},
 			expectedLabels: map[string]string{
 				workloadv1alpha1.ClusterResourceStateLabelPrefix + "test-cluster":   string(workloadv1alpha1.ResourceStateSync),
 				workloadv1alpha1.ClusterResourceStateLabelPrefix + "test-cluster-2": string(workloadv1alpha1.ResourceStateSync),
 			},
 		},
+		{
+			name: "select a new synctarget with the removal grace period of the placement",
+			annotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey: "",
+			},
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "test-cluster": string(workloadv1alpha1.ResourceStateSync),
+			},
+			placement: withRemovalGracePeriod(newPlacement("test-placement", "test-location", "test-cluster-2"), time.Hour),
+			wantPatch: true,
+			expectedAnnotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:                                           "",
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "test-cluster":  now3339,
+				workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix + "test-cluster": "1h0m0s",
+			},
+			expectedLabels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "test-cluster":   string(workloadv1alpha1.ResourceStateSync),
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "test-cluster-2": string(workloadv1alpha1.ResourceStateSync),
+			},
+		},
+		{
+			name: "keep removing cluster during the removal grace period",
+			annotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:                                           "",
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "test-cluster":  now.Add(-time.Minute).UTC().Format(time.RFC3339),
+				workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix + "test-cluster": "1h0m0s",
+			},
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "test-cluster": string(workloadv1alpha1.ResourceStateSync),
+			},
+			placement:          newPlacement("test-placement", "test-location", ""),
+			wantPatch:          false,
+			wantEnqueueAtLeast: 59 * time.Minute,
+			expectedAnnotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:                                           "",
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "test-cluster":  now.Add(-time.Minute).UTC().Format(time.RFC3339),
+				workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix + "test-cluster": "1h0m0s",
+			},
+			expectedLabels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "test-cluster": string(workloadv1alpha1.ResourceStateSync),
+			},
+		},
 		{
 			name: "scheduled cluster is removing",
 			annotations: map[string]string{
@@ -157,8 +209,9 @@ func TestScheduling(t *testing.T) {
 		{
 			name: "remove clusters which is removing after grace period",
 			annotations: map[string]string{
-				schedulingv1alpha1.PlacementAnnotationKey:                                          "",
-				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "test-cluster": time.Now().Add(-1 * (removingGracePeriod + 1)).UTC().Format(time.RFC3339),
+				schedulingv1alpha1.PlacementAnnotationKey:                                           "",
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "test-cluster":  now.Add(-2 * time.Minute).UTC().Format(time.RFC3339),
+				workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix + "test-cluster": "1m0s",
 			},
 			labels: map[string]string{
 				workloadv1alpha1.ClusterResourceStateLabelPrefix + "test-cluster": string(workloadv1alpha1.ResourceStateSync),
@@ -170,6 +223,179 @@ func TestScheduling(t *testing.T) {
 			},
 			expectedLabels: map[string]string{},
 		},
+		{
+			name: "keep synctarget with bound volumes",
+			annotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey: "",
+			},
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+			resources: []namespacedResource{
+				boundClaim("data", "cluster1"),
+			},
+			placement: newPlacement("test-placement", "test-location", "cluster2"),
+			wantPatch: false,
+			expectedAnnotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey: "",
+			},
+			expectedLabels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+		},
+		{
+			name: "move synctarget with volumes bound to another synctarget",
+			annotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey: "",
+			},
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+			resources: []namespacedResource{
+				boundClaim("data", "cluster3"),
+			},
+			placement: newPlacement("test-placement", "test-location", "cluster2"),
+			wantPatch: true,
+			expectedAnnotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:                                       "",
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "cluster1":  now3339,
+				workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix + "cluster1": "5s",
+			},
+			expectedLabels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster2": string(workloadv1alpha1.ResourceStateSync),
+			},
+		},
+		{
+			name: "force moving synctarget with bound volumes",
+			annotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:        "",
+				workloadv1alpha1.ForceMoveBoundVolumesAnnotation: "true",
+			},
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+			resources: []namespacedResource{
+				boundClaim("data", "cluster1"),
+			},
+			placement: newPlacement("test-placement", "test-location", "cluster2"),
+			wantPatch: true,
+			expectedAnnotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:                                       "",
+				workloadv1alpha1.ForceMoveBoundVolumesAnnotation:                                "true",
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "cluster1":  now3339,
+				workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix + "cluster1": "5s",
+			},
+			expectedLabels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster2": string(workloadv1alpha1.ResourceStateSync),
+			},
+		},
+		{
+			name: "hold synctarget drained gracefully",
+			annotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey: "",
+			},
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+			draining:  []string{"cluster1"},
+			placement: newPlacement("test-placement", "test-location", "cluster2"),
+			wantPatch: true,
+			expectedAnnotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:                          "",
+				workloadv1alpha1.InternalClusterDrainAnnotationPrefix + "cluster1": string(workloadv1alpha1.DrainStateHeld),
+			},
+			expectedLabels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+		},
+		{
+			name: "do not hold synctarget drained gracefully in another location workspace",
+			annotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey: "",
+			},
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+			draining:  []string{"cluster1"},
+			placement: withLocationWorkspace(newPlacement("test-placement", "test-location", "cluster2"), "root:other"),
+			wantPatch: true,
+			expectedAnnotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:                                       "",
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "cluster1":  now3339,
+				workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix + "cluster1": "5s",
+			},
+			expectedLabels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster2": string(workloadv1alpha1.ResourceStateSync),
+			},
+		},
+		{
+			name: "keep holding synctarget drained gracefully",
+			annotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:                          "",
+				workloadv1alpha1.InternalClusterDrainAnnotationPrefix + "cluster1": string(workloadv1alpha1.DrainStateHeld),
+			},
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+			draining:  []string{"cluster1"},
+			placement: newPlacement("test-placement", "test-location", "cluster2"),
+			wantPatch: false,
+			expectedAnnotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:                          "",
+				workloadv1alpha1.InternalClusterDrainAnnotationPrefix + "cluster1": string(workloadv1alpha1.DrainStateHeld),
+			},
+			expectedLabels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+		},
+		{
+			name: "move synctarget drained gracefully when evicting",
+			annotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:                          "",
+				workloadv1alpha1.InternalClusterDrainAnnotationPrefix + "cluster1": string(workloadv1alpha1.DrainStateEvicting),
+			},
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+			draining:  []string{"cluster1"},
+			placement: newPlacement("test-placement", "test-location", "cluster2"),
+			wantPatch: true,
+			expectedAnnotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:                                       "",
+				workloadv1alpha1.InternalClusterDrainAnnotationPrefix + "cluster1":              string(workloadv1alpha1.DrainStateEvicting),
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "cluster1":  now3339,
+				workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix + "cluster1": "5s",
+			},
+			expectedLabels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster2": string(workloadv1alpha1.ResourceStateSync),
+			},
+		},
+		{
+			name: "move held synctarget no longer drained gracefully",
+			annotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:                          "",
+				workloadv1alpha1.InternalClusterDrainAnnotationPrefix + "cluster1": string(workloadv1alpha1.DrainStateHeld),
+			},
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+			placement: newPlacement("test-placement", "test-location", "cluster2"),
+			wantPatch: true,
+			expectedAnnotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey:                                       "",
+				workloadv1alpha1.InternalClusterDrainAnnotationPrefix + "cluster1":              string(workloadv1alpha1.DrainStateHeld),
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "cluster1":  now3339,
+				workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix + "cluster1": "5s",
+			},
+			expectedLabels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster2": string(workloadv1alpha1.ResourceStateSync),
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -189,12 +415,23 @@ func TestScheduling(t *testing.T) {
 				return []*schedulingv1alpha1.Placement{testCase.placement}, nil
 			}
 
+			listNamespaceResources := func(clusterName logicalcluster.Name, namespace string) ([]namespacedResource, error) {
+				return testCase.resources, nil
+			}
+
 			var patched bool
+			var enqueuedAfter time.Duration
 			reconciler := &placementSchedulingReconciler{
-				listPlacement:  listPlacement,
-				patchNamespace: patchNamespaceFunc(&patched, ns),
-				enqueueAfter:   func(*corev1.Namespace, time.Duration) {},
-				now:            func() time.Time { return now },
+				listPlacement:          listPlacement,
+				listNamespaceResources: listNamespaceResources,
+				patchNamespace:         patchNamespaceFunc(&patched, ns),
+				enqueueAfter:           func(_ *corev1.Namespace, d time.Duration) { enqueuedAfter = d },
+				isDrainingGracefully: func(locationWorkspace logicalcluster.Name, syncTargetName string) bool {
+					return locationWorkspace == testLocationWorkspace && sets.NewString(testCase.draining...).Has(syncTargetName)
+				},
+				now: func() time.Time { return now },
+
+				defaultRemovalGracePeriod: 5 * time.Second,
 			}
 
 			_, updated, err := reconciler.reconcile(context.TODO(), ns)
@@ -202,6 +439,7 @@ func TestScheduling(t *testing.T) {
 			require.Equal(t, testCase.wantPatch, patched)
 			require.Equal(t, testCase.expectedAnnotations, updated.Annotations)
 			require.Equal(t, testCase.expectedLabels, updated.Labels)
+			require.GreaterOrEqual(t, enqueuedAfter, testCase.wantEnqueueAtLeast)
 		})
 	}
 }
@@ -322,12 +560,20 @@ func TestMultiplePlacements(t *testing.T) {
 				return testCase.placements, nil
 			}
 
+			listNamespaceResources := func(clusterName logicalcluster.Name, namespace string) ([]namespacedResource, error) {
+				return nil, nil
+			}
+
 			var patched bool
 			reconciler := &placementSchedulingReconciler{
-				listPlacement:  listPlacement,
-				patchNamespace: patchNamespaceFunc(&patched, ns),
-				enqueueAfter:   func(*corev1.Namespace, time.Duration) {},
-				now:            func() time.Time { return now },
+				listPlacement:          listPlacement,
+				listNamespaceResources: listNamespaceResources,
+				patchNamespace:         patchNamespaceFunc(&patched, ns),
+				enqueueAfter:           func(*corev1.Namespace, time.Duration) {},
+				isDrainingGracefully:   func(logicalcluster.Name, string) bool { return false },
+				now:                    func() time.Time { return now },
+
+				defaultRemovalGracePeriod: 5 * time.Second,
 			}
 
 			_, updated, err := reconciler.reconcile(context.TODO(), ns)
@@ -339,6 +585,21 @@ func TestMultiplePlacements(t *testing.T) {
 	}
 }
 
+func boundClaim(name, syncTarget string) namespacedResource {
+	return namespacedResource{
+		gvr: schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"},
+		obj: &metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + syncTarget: string(workloadv1alpha1.ResourceStateSync),
+			},
+			Annotations: map[string]string{
+				workloadv1alpha1.ClusterBoundVolumeAnnotationPrefix + syncTarget: `{"volumeName":"pv-1"}`,
+			},
+		},
+	}
+}
+
 func newPlacement(name, location, synctarget string) *schedulingv1alpha1.Placement {
 	placement := &schedulingv1alpha1.Placement{
 		ObjectMeta: metav1.ObjectMeta{
@@ -349,6 +610,7 @@ func newPlacement(name, location, synctarget string) *schedulingv1alpha1.Placeme
 		},
 		Status: schedulingv1alpha1.PlacementStatus{
 			SelectedLocation: &schedulingv1alpha1.LocationReference{
+				Path:         testLocationWorkspace.String(),
 				LocationName: location,
 			},
 		},
@@ -362,3 +624,13 @@ func newPlacement(name, location, synctarget string) *schedulingv1alpha1.Placeme
 
 	return placement
 }
+
+func withLocationWorkspace(placement *schedulingv1alpha1.Placement, locationWorkspace string) *schedulingv1alpha1.Placement {
+	placement.Status.SelectedLocation.Path = locationWorkspace
+	return placement
+}
+
+func withRemovalGracePeriod(placement *schedulingv1alpha1.Placement, gracePeriod time.Duration) *schedulingv1alpha1.Placement {
+	placement.Spec.RemovalGracePeriod = &metav1.Duration{Duration: gracePeriod}
+	return placement
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/kcp-dev/logicalcluster/v2"
@@ -27,12 +29,15 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 
 	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
 )
 
 const (
@@ -49,11 +54,38 @@ const (
 	// NamespaceReasonPlacementInvalid reason in NamespaceScheduled Namespace Condition
 	// means the placement annotation has invalid value.
 	NamespaceReasonPlacementInvalid = "PlacementInvalid"
+
+	// NamespaceSyncTargetsRemoved represents the progress of the removal of the namespace
+	// and its resources from the sync targets it is not scheduled to anymore. It is only
+	// set while a removal is in progress.
+	NamespaceSyncTargetsRemoved conditionsv1alpha1.ConditionType = "SyncTargetsRemoved"
+	// NamespaceReasonRemovalInProgress reason in SyncTargetsRemoved Namespace Condition
+	// means that resources of the namespace are still synced to some of the sync targets
+	// being removed, e.g. because the syncer has not deleted them downstream yet, or
+	// because finalizers.workload.kcp.dev/<sync-target-name> finalizers hold them.
+	NamespaceReasonRemovalInProgress = "RemovalInProgress"
+	// NamespaceReasonVolumesBound reason in SyncTargetsRemoved Namespace Condition
+	// means that the namespace is kept on some of the sync targets it is not scheduled
+	// to anymore, because persistent volume claims of the namespace are bound to volumes
+	// of these sync targets.
+	NamespaceReasonVolumesBound = "VolumesBound"
+
+	// maxBlockedResourcesInMessage limits the number of blocked resources listed per sync target
+	// in the SyncTargetsRemoved condition message.
+	maxBlockedResourcesInMessage = 5
 )
 
+// namespacedResource is a resource of a namespace, as found in the dynamic informers.
+type namespacedResource struct {
+	gvr schema.GroupVersionResource
+	obj metav1.Object
+}
+
 // statusReconciler updates conditions on the namespace.
 type statusConditionReconciler struct {
-	patchNamespace func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Namespace, error)
+	listPlacement          func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Placement, error)
+	listNamespaceResources func(clusterName logicalcluster.Name, namespace string) ([]namespacedResource, error)
+	patchNamespace         func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Namespace, error)
 }
 
 // ensureScheduledStatus ensures the status of the given namespace reflects the
@@ -61,6 +93,26 @@ type statusConditionReconciler struct {
 func (r *statusConditionReconciler) reconcile(ctx context.Context, ns *corev1.Namespace) (reconcileStatus, *corev1.Namespace, error) {
 	updatedNs := setScheduledCondition(ns)
 
+	validPlacements, err := listValidPlacements(ns, r.listPlacement)
+	if err != nil {
+		return reconcileStatusStop, ns, err
+	}
+	synced, removing := syncedRemovingCluster(ns)
+	leaving := synced.Difference(scheduledSyncTargets(validPlacements))
+	if len(removing) > 0 || (leaving.Len() > 0 && !forceMoveBoundVolumes(ns)) {
+		resources, err := r.listNamespaceResources(logicalcluster.From(ns), ns.Name)
+		if err != nil {
+			return reconcileStatusStop, ns, err
+		}
+		var boundVolumes map[string][]string
+		if !forceMoveBoundVolumes(ns) {
+			boundVolumes = syncTargetsWithBoundVolumes(leaving, resources)
+		}
+		updatedNs = setSyncTargetsRemovedCondition(updatedNs, resources, boundVolumes)
+	} else {
+		updatedNs = setSyncTargetsRemovedCondition(updatedNs, nil, nil)
+	}
+
 	if equality.Semantic.DeepEqual(ns.Status, updatedNs.Status) {
 		return reconcileStatusContinue, ns, nil
 	}
@@ -165,3 +217,71 @@ func setScheduledCondition(ns *corev1.Namespace) *corev1.Namespace {
 	conditions.MarkTrue(conditionsAdapter, NamespaceScheduled)
 	return updatedNs
 }
+
+// setSyncTargetsRemovedCondition reports, for every sync target the namespace is being removed from, how many of
+// the given resources of the namespace are still synced to it, and which are held by cluster finalizers. It also
+// reports the sync targets the namespace is kept on because of the given persistent volume claims bound to their volumes.
+func setSyncTargetsRemovedCondition(ns *corev1.Namespace, resources []namespacedResource, boundVolumes map[string][]string) *corev1.Namespace {
+	updatedNs := ns.DeepCopy()
+	conditionsAdapter := &NamespaceConditionsAdapter{updatedNs}
+
+	_, removing := syncedRemovingCluster(ns)
+	if len(removing) == 0 && len(boundVolumes) == 0 {
+		conditions.Delete(conditionsAdapter, NamespaceSyncTargetsRemoved)
+		return updatedNs
+	}
+
+	syncTargets := make([]string, 0, len(removing))
+	for syncTarget := range removing {
+		syncTargets = append(syncTargets, syncTarget)
+	}
+	sort.Strings(syncTargets)
+
+	messages := make([]string, 0, len(syncTargets))
+	for _, syncTarget := range syncTargets {
+		remaining := 0
+		var blocked []string
+		for _, resource := range resources {
+			if _, found := resource.obj.GetLabels()[workloadv1alpha1.ClusterResourceStateLabelPrefix+syncTarget]; !found {
+				continue
+			}
+			remaining++
+			if finalizers := shared.ClusterFinalizers(resource.obj, syncTarget); len(finalizers) > 0 {
+				blocked = append(blocked, fmt.Sprintf("%s %s (%s)", resource.gvr.GroupResource(), resource.obj.GetName(), strings.Join(finalizers, ",")))
+			}
+		}
+
+		message := fmt.Sprintf("SyncTarget %s: %d resources remaining", syncTarget, remaining)
+		if len(blocked) > 0 {
+			sort.Strings(blocked)
+			if len(blocked) > maxBlockedResourcesInMessage {
+				blocked = append(blocked[:maxBlockedResourcesInMessage], "...")
+			}
+			message += ", held by finalizers on " + strings.Join(blocked, ", ")
+		}
+		messages = append(messages, message)
+	}
+
+	keptOn := make([]string, 0, len(boundVolumes))
+	for syncTarget := range boundVolumes {
+		keptOn = append(keptOn, syncTarget)
+	}
+	sort.Strings(keptOn)
+	for _, syncTarget := range keptOn {
+		claims := boundVolumes[syncTarget]
+		if len(claims) > maxBlockedResourcesInMessage {
+			claims = append(claims[:maxBlockedResourcesInMessage:maxBlockedResourcesInMessage], "...")
+		}
+		messages = append(messages, fmt.Sprintf("SyncTarget %s: kept by persistentvolumeclaims bound to its volumes %s, set the %s annotation to \"true\" to move anyway",
+			syncTarget, strings.Join(claims, ", "), workloadv1alpha1.ForceMoveBoundVolumesAnnotation))
+	}
+
+	reason := NamespaceReasonRemovalInProgress
+	if len(removing) == 0 {
+		reason = NamespaceReasonVolumesBound
+	}
+	conditions.MarkFalse(conditionsAdapter, NamespaceSyncTargetsRemoved, reason,
+		conditionsv1alpha1.ConditionSeverityNone, // NamespaceCondition doesn't support severity
+		"%s", strings.Join(messages, "; "))
+	return updatedNs
+}
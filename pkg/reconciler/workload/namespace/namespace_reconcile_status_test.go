@@ -23,6 +23,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
 	conditionsapi "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
@@ -81,3 +82,106 @@ func TestSetScheduledCondition(t *testing.T) {
 		})
 	}
 }
+
+func TestSetSyncTargetsRemovedCondition(t *testing.T) {
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	configmaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+	resource := func(gvr schema.GroupVersionResource, name string, labels, annotations map[string]string) namespacedResource {
+		return namespacedResource{gvr: gvr, obj: &metav1.ObjectMeta{Name: name, Labels: labels, Annotations: annotations}}
+	}
+
+	testCases := map[string]struct {
+		labels       map[string]string
+		annotations  map[string]string
+		resources    []namespacedResource
+		boundVolumes map[string][]string
+		wantReason   string
+		wantMessage  string
+	}{
+		"not being removed": {
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+		},
+		"no remaining resources": {
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+			annotations: map[string]string{
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "cluster1": "2022-08-01T10:00:00Z",
+			},
+			resources: []namespacedResource{
+				resource(configmaps, "cm", map[string]string{workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster2": string(workloadv1alpha1.ResourceStateSync)}, nil),
+			},
+			wantMessage: "SyncTarget cluster1: 0 resources remaining",
+		},
+		"resources held by cluster finalizers": {
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster2": string(workloadv1alpha1.ResourceStateSync),
+			},
+			annotations: map[string]string{
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "cluster1": "2022-08-01T10:00:00Z",
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "cluster2": "2022-08-01T10:00:00Z",
+			},
+			resources: []namespacedResource{
+				resource(configmaps, "cm", map[string]string{workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync)}, nil),
+				resource(deployments, "app", map[string]string{
+					workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+					workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster2": string(workloadv1alpha1.ResourceStateSync),
+				}, map[string]string{
+					workloadv1alpha1.ClusterFinalizerAnnotationPrefix + "cluster1": "backup.example.com,migration.example.com",
+				}),
+			},
+			wantMessage: "SyncTarget cluster1: 2 resources remaining, held by finalizers on deployments.apps app (backup.example.com,migration.example.com); SyncTarget cluster2: 1 resources remaining",
+		},
+		"kept by bound volumes": {
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+			boundVolumes: map[string][]string{"cluster1": {"data", "logs"}},
+			wantReason:   NamespaceReasonVolumesBound,
+			wantMessage:  `SyncTarget cluster1: kept by persistentvolumeclaims bound to its volumes data, logs, set the experimental.workload.kcp.dev/force-move-bound-volumes annotation to "true" to move anyway`,
+		},
+		"removing and kept by bound volumes": {
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster2": string(workloadv1alpha1.ResourceStateSync),
+			},
+			annotations: map[string]string{
+				workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + "cluster2": "2022-08-01T10:00:00Z",
+			},
+			boundVolumes: map[string][]string{"cluster1": {"data"}},
+			wantMessage:  `SyncTarget cluster2: 0 resources remaining; SyncTarget cluster1: kept by persistentvolumeclaims bound to its volumes data, set the experimental.workload.kcp.dev/force-move-bound-volumes annotation to "true" to move anyway`,
+		},
+	}
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      testCase.labels,
+					Annotations: testCase.annotations,
+				},
+				Status: corev1.NamespaceStatus{
+					Conditions: []corev1.NamespaceCondition{{Type: corev1.NamespaceConditionType(NamespaceSyncTargetsRemoved), Status: corev1.ConditionFalse}},
+				},
+			}
+			updatedNs := setSyncTargetsRemovedCondition(ns, testCase.resources, testCase.boundVolumes)
+
+			c := conditions.Get(&NamespaceConditionsAdapter{updatedNs}, NamespaceSyncTargetsRemoved)
+			if testCase.wantMessage == "" {
+				require.Nil(t, c)
+				return
+			}
+			require.NotNil(t, c)
+			require.Equal(t, corev1.ConditionFalse, c.Status)
+			wantReason := testCase.wantReason
+			if wantReason == "" {
+				wantReason = NamespaceReasonRemovalInProgress
+			}
+			require.Equal(t, wantReason, c.Reason)
+			require.Equal(t, testCase.wantMessage, c.Message)
+		})
+	}
+}
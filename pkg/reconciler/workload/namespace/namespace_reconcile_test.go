@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+func TestIsDrainingGracefully(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexer.Add(&workloadv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:                      "cluster1",
+			ZZZ_DeprecatedClusterName: "root:draining",
+			Annotations: map[string]string{
+				workloadv1alpha1.DrainMaxUnavailableAnnotation: "1",
+			},
+		},
+		Spec: workloadv1alpha1.SyncTargetSpec{Unschedulable: true},
+	}))
+	require.NoError(t, indexer.Add(&workloadv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:                      "cluster1",
+			ZZZ_DeprecatedClusterName: "root:org:ws",
+		},
+	}))
+
+	c := &controller{syncTargetIndexer: indexer}
+	require.True(t, c.isDrainingGracefully(logicalcluster.New("root:draining"), "cluster1"))
+	require.False(t, c.isDrainingGracefully(logicalcluster.New("root:org:ws"), "cluster1"), "same-named synctarget in another workspace")
+	require.False(t, c.isDrainingGracefully(logicalcluster.New("root:other"), "cluster1"), "no synctarget in the location workspace")
+}
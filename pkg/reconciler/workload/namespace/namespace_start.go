@@ -0,0 +1,46 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func DefaultOptions() *Options {
+	return &Options{
+		RemovalGracePeriod: 5 * time.Second,
+	}
+}
+
+func BindOptions(o *Options, fs *pflag.FlagSet) *Options {
+	fs.DurationVar(&o.RemovalGracePeriod, "workload-removal-grace-period", o.RemovalGracePeriod, "Default amount of time a namespace and its resources are kept on a sync target that is not selected anymore, for placements not setting removalGracePeriod")
+	return o
+}
+
+type Options struct {
+	RemovalGracePeriod time.Duration
+}
+
+func (o *Options) Validate() error {
+	if o.RemovalGracePeriod < 0 {
+		return fmt.Errorf("--workload-removal-grace-period must be >=0 (%s)", o.RemovalGracePeriod)
+	}
+	return nil
+}
@@ -43,6 +43,7 @@ const (
 	controllerName      = "kcp-workload-placement"
 	byWorkspace         = controllerName + "-byWorkspace" // will go away with scoping
 	byLocationWorkspace = controllerName + "-byLocationWorkspace"
+	bySyncTarget        = controllerName + "-bySyncTarget"
 )
 
 // NewController returns a new controller starting the process of selecting synctarget for a placement
@@ -84,6 +85,7 @@ func NewController(
 	if err := placementInformer.Informer().AddIndexers(cache.Indexers{
 		byWorkspace:         indexByWorksapce,
 		byLocationWorkspace: indexByLoactionWorkspace,
+		bySyncTarget:        indexBySyncTarget,
 	}); err != nil {
 		return nil, err
 	}
@@ -114,6 +116,7 @@ func NewController(
 				oldClusterCopy.Status.LastSyncerHeartbeatTime = nil
 				oldClusterCopy.Status.VirtualWorkspaces = nil
 				oldClusterCopy.Status.Capacity = nil
+				oldClusterCopy.Status.Allocatable = nil
 
 				newCluster := obj.(*workloadv1alpha1.SyncTarget)
 				newClusterCopy := *newCluster
@@ -121,6 +124,7 @@ func NewController(
 				newClusterCopy.Status.LastSyncerHeartbeatTime = nil
 				newClusterCopy.Status.VirtualWorkspaces = nil
 				newClusterCopy.Status.Capacity = nil
+				newClusterCopy.Status.Allocatable = nil
 
 				// compare ignoring heart-beat
 				if !reflect.DeepEqual(oldClusterCopy, newClusterCopy) {
@@ -24,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 )
 
 func indexByWorksapce(obj interface{}) ([]string, error) {
@@ -48,3 +49,16 @@ func indexByLoactionWorkspace(obj interface{}) ([]string, error) {
 
 	return []string{placement.Status.SelectedLocation.Path}, nil
 }
+
+func indexBySyncTarget(obj interface{}) ([]string, error) {
+	placement, ok := obj.(*schedulingv1alpha1.Placement)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be a Placement, but is %T", obj)
+	}
+
+	value, found := placement.Annotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey]
+	if !found || value == "" {
+		return []string{}, nil
+	}
+	return []string{value}, nil
+}
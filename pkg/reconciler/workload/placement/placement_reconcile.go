@@ -18,6 +18,7 @@ package placement
 
 import (
 	"context"
+	"time"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
@@ -48,6 +49,8 @@ func (c *controller) reconcile(ctx context.Context, placement *schedulingv1alpha
 			listSyncTarget: c.listSyncTarget,
 			getLocation:    c.getLocation,
 			patchPlacement: c.patchPlacement,
+			scheduler:      newSyncTargetFramework(time.Now, c.countPlacements),
+			now:            time.Now,
 		},
 	}
 
@@ -80,6 +83,14 @@ func (c *controller) listSyncTarget(clusterName logicalcluster.Name) ([]*workloa
 	return ret, nil
 }
 
+func (c *controller) countPlacements(syncTargetKey string) (int, error) {
+	items, err := c.placementIndexer.ByIndex(bySyncTarget, syncTargetKey)
+	if err != nil {
+		return 0, err
+	}
+	return len(items), nil
+}
+
 func (c *controller) getLocation(clusterName logicalcluster.Name, name string) (*schedulingv1alpha1.Location, error) {
 	key := clusters.ToClusterAwareKey(clusterName, name)
 	return c.locationLister.Get(key)
@@ -20,8 +20,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
 	"strings"
+	"time"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
@@ -33,16 +33,21 @@ import (
 
 	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/reconciler/scheduling/framework"
 	locationreconciler "github.com/kcp-dev/kcp/pkg/reconciler/scheduling/location"
 )
 
 // placementSchedulingReconciler schedules placments according to the selected locations.
-// It considers only valid SyncTargets and updates the internal.workload.kcp.dev/synctarget
-// annotation with the selected one on the placement object.
+// It scores the SyncTargets of the selected location with the scheduling framework, updates
+// the internal.workload.kcp.dev/synctarget annotation with the selected one on the placement
+// object, and records the decision in the status of the placement.
 type placementSchedulingReconciler struct {
 	listSyncTarget func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.SyncTarget, error)
 	getLocation    func(clusterName logicalcluster.Name, name string) (*schedulingv1alpha1.Location, error)
 	patchPlacement func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*schedulingv1alpha1.Placement, error)
+
+	scheduler *framework.Framework
+	now       func() time.Time
 }
 
 func (r *placementSchedulingReconciler) reconcile(ctx context.Context, placement *schedulingv1alpha1.Placement) (reconcileStatus, *schedulingv1alpha1.Placement, error) {
@@ -52,21 +57,14 @@ func (r *placementSchedulingReconciler) reconcile(ctx context.Context, placement
 	expectedAnnotations := map[string]interface{}{} // nil means to remove the key
 	currentScheduled, foundScheduled := placement.Annotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey]
 
-	// 2. pick all valid synctargets in this placements
-	syncTargetClusterName, syncTargets, err := r.getAllValidSyncTargetsForPlacement(clusterName, placement)
+	// 2. pick all synctargets in the location of this placement
+	syncTargetClusterName, syncTargets, err := r.getLocationSyncTargetsForPlacement(clusterName, placement)
 	if err != nil {
 		return reconcileStatusStop, placement, err
 	}
 
-	// no valid synctarget, clean the annotation.
-	if foundScheduled && len(syncTargets) == 0 {
-		expectedAnnotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey] = nil
-		updated, err := r.patchPlacementAnnotation(ctx, clusterName, placement, expectedAnnotations)
-		return reconcileStatusContinue, updated, err
-	}
-
-	// 2. do nothing if scheduled cluster is in the valid clusters
-	if foundScheduled && len(syncTargets) > 0 {
+	// 3. do nothing if scheduled cluster is still feasible
+	if foundScheduled {
 		scheduledSyncTargeClusterName, scheduledSyncTargeName := ParseCurrentScheduled(currentScheduled)
 		for _, syncTarget := range syncTargets {
 			if syncTargetClusterName != scheduledSyncTargeClusterName {
@@ -75,26 +73,44 @@ func (r *placementSchedulingReconciler) reconcile(ctx context.Context, placement
 			if scheduledSyncTargeName != syncTarget.Name {
 				continue
 			}
-
-			return reconcileStatusContinue, placement, nil
+			if r.scheduler.Feasible(placement, syncTarget) {
+				return reconcileStatusContinue, placement, nil
+			}
 		}
 	}
 
-	// 3. randomly select one as the scheduled cluster
+	// 4. select the feasible synctarget with the best score, and clean the annotation if there is none.
 	// TODO(qiujian16): we currently schedule each in each location independently. It cannot guarantee 1 cluster is scheduled per location
 	// when the same synctargets are in multiple locations, we need to rethink whether we need a better algorithm or we need location
 	// to be exclusive.
-	if len(syncTargets) > 0 {
-		scheduledSyncTarget := syncTargets[rand.Intn(len(syncTargets))]
-		expectedAnnotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey] = fmt.Sprintf("%s/%s", syncTargetClusterName.String(), scheduledSyncTarget.Name)
-		updated, err := r.patchPlacementAnnotation(ctx, clusterName, placement, expectedAnnotations)
-		return reconcileStatusContinue, updated, err
+	candidates := make([]metav1.Object, 0, len(syncTargets))
+	for _, syncTarget := range syncTargets {
+		candidates = append(candidates, syncTarget)
 	}
+	selected, decision := r.scheduler.Schedule(placement, candidates, r.now())
 
-	return reconcileStatusContinue, placement, nil
+	updated := placement
+	switch {
+	case selected != nil:
+		expectedAnnotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey] = fmt.Sprintf("%s/%s", syncTargetClusterName.String(), selected.GetName())
+	case foundScheduled:
+		expectedAnnotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey] = nil
+	}
+	if len(expectedAnnotations) > 0 {
+		if updated, err = r.patchPlacementAnnotation(ctx, clusterName, placement, expectedAnnotations); err != nil {
+			return reconcileStatusContinue, updated, err
+		}
+	}
+
+	// there is nothing to explain without candidates.
+	if len(candidates) == 0 || framework.SameDecision(updated.Status.InstanceDecision, decision) {
+		return reconcileStatusContinue, updated, nil
+	}
+	updated, err = r.patchPlacementInstanceDecision(ctx, clusterName, updated, decision)
+	return reconcileStatusContinue, updated, err
 }
 
-func (r *placementSchedulingReconciler) getAllValidSyncTargetsForPlacement(clusterName logicalcluster.Name, placement *schedulingv1alpha1.Placement) (logicalcluster.Name, []*workloadv1alpha1.SyncTarget, error) {
+func (r *placementSchedulingReconciler) getLocationSyncTargetsForPlacement(clusterName logicalcluster.Name, placement *schedulingv1alpha1.Placement) (logicalcluster.Name, []*workloadv1alpha1.SyncTarget, error) {
 	if placement.Status.Phase == schedulingv1alpha1.PlacementPending || placement.Status.SelectedLocation == nil {
 		return logicalcluster.Name{}, nil, nil
 	}
@@ -122,10 +138,7 @@ func (r *placementSchedulingReconciler) getAllValidSyncTargetsForPlacement(clust
 		return locationWorkspace, nil, err
 	}
 
-	// find all the valid sync targets.
-	validClusters := locationreconciler.FilterNonEvicting(locationreconciler.FilterReady(locationClusters))
-
-	return locationWorkspace, validClusters, nil
+	return locationWorkspace, locationClusters, nil
 }
 
 func (r *placementSchedulingReconciler) patchPlacementAnnotation(ctx context.Context, clusterName logicalcluster.Name, placement *schedulingv1alpha1.Placement, annotations map[string]interface{}) (*schedulingv1alpha1.Placement, error) {
@@ -148,6 +161,28 @@ func (r *placementSchedulingReconciler) patchPlacementAnnotation(ctx context.Con
 	return updated, nil
 }
 
+func (r *placementSchedulingReconciler) patchPlacementInstanceDecision(ctx context.Context, clusterName logicalcluster.Name, placement *schedulingv1alpha1.Placement, decision *schedulingv1alpha1.SchedulingDecision) (*schedulingv1alpha1.Placement, error) {
+	patchBytes, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"instanceDecision": decision,
+		},
+	})
+	if err != nil {
+		return placement, err
+	}
+	klog.V(3).Infof("Patching to record the sync target decision on placement %s|%s: %s",
+		clusterName, placement.Name, string(patchBytes))
+	updated, err := r.patchPlacement(ctx, clusterName, placement.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return placement, err
+	}
+	return updated, nil
+}
+
+func syncTargetKey(syncTarget *workloadv1alpha1.SyncTarget) string {
+	return fmt.Sprintf("%s/%s", logicalcluster.From(syncTarget).String(), syncTarget.Name)
+}
+
 func ParseCurrentScheduled(value string) (logicalcluster.Name, string) {
 	if len(value) == 0 {
 		return logicalcluster.Name{}, ""
@@ -21,12 +21,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/kcp-dev/logicalcluster/v2"
 	"github.com/stretchr/testify/require"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -38,6 +41,8 @@ import (
 )
 
 func TestSchedulingReconcile(t *testing.T) {
+	now := time.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC)
+
 	testCases := []struct {
 		name string
 
@@ -45,8 +50,11 @@ func TestSchedulingReconcile(t *testing.T) {
 		location    *schedulingv1alpha1.Location
 		syncTargets []*workloadv1alpha1.SyncTarget
 
+		placementCounts map[string]int
+
 		wantPatch           bool
 		expectedAnnotations map[string]string
+		expectedDecision    *schedulingv1alpha1.SchedulingDecision
 	}{
 		{
 			name:      "no location",
@@ -66,6 +74,13 @@ func TestSchedulingReconcile(t *testing.T) {
 			expectedAnnotations: map[string]string{
 				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "/c1",
 			},
+			expectedDecision: &schedulingv1alpha1.SchedulingDecision{
+				Selected: "c1",
+				Candidates: []schedulingv1alpha1.CandidateScore{
+					{Name: "c1", Feasible: true, Score: 150, Reasons: []string{"Headroom: unknown capacity", "Spread: 0 placements"}},
+				},
+				DecisionTime: metav1.NewTime(now),
+			},
 		},
 		{
 			name:        "synctarget scheduled",
@@ -77,12 +92,17 @@ func TestSchedulingReconcile(t *testing.T) {
 			},
 		},
 		{
-			name:                "unschedule synctarget",
-			placement:           newPlacement("test", "test-location", "c1"),
-			location:            newLocation("test-location"),
-			syncTargets:         []*workloadv1alpha1.SyncTarget{newSyncTarget("c1", false)},
-			wantPatch:           true,
-			expectedAnnotations: map[string]string{},
+			name:        "unschedule synctarget",
+			placement:   newPlacement("test", "test-location", "c1"),
+			location:    newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{newSyncTarget("c1", false)},
+			wantPatch:   true,
+			expectedDecision: &schedulingv1alpha1.SchedulingDecision{
+				Candidates: []schedulingv1alpha1.CandidateScore{
+					{Name: "c1", Reasons: []string{"Ready: not ready"}},
+				},
+				DecisionTime: metav1.NewTime(now),
+			},
 		},
 		{
 			name:        "reschedule synctarget",
@@ -93,6 +113,104 @@ func TestSchedulingReconcile(t *testing.T) {
 			expectedAnnotations: map[string]string{
 				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "/c2",
 			},
+			expectedDecision: &schedulingv1alpha1.SchedulingDecision{
+				Selected: "c2",
+				Candidates: []schedulingv1alpha1.CandidateScore{
+					{Name: "c2", Feasible: true, Score: 150, Reasons: []string{"Headroom: unknown capacity", "Spread: 0 placements"}},
+					{Name: "c1", Reasons: []string{"Ready: not ready"}},
+				},
+				DecisionTime: metav1.NewTime(now),
+			},
+		},
+		{
+			name:      "reschedule evicting synctarget",
+			placement: newPlacement("test", "test-location", "c1"),
+			location:  newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{
+				withEvictAfter(newSyncTarget("c1", true), now.Add(-time.Minute)),
+				newSyncTarget("c2", true),
+			},
+			wantPatch: true,
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "/c2",
+			},
+			expectedDecision: &schedulingv1alpha1.SchedulingDecision{
+				Selected: "c2",
+				Candidates: []schedulingv1alpha1.CandidateScore{
+					{Name: "c2", Feasible: true, Score: 150, Reasons: []string{"Headroom: unknown capacity", "Spread: 0 placements"}},
+					{Name: "c1", Reasons: []string{"NonEvicting: evicting"}},
+				},
+				DecisionTime: metav1.NewTime(now),
+			},
+		},
+		{
+			name:      "schedule synctarget with most allocatable resources",
+			placement: newPlacement("test", "test-location", ""),
+			location:  newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{
+				withResources(newSyncTarget("c1", true), "1", "10", "1Gi", "10Gi"),
+				withResources(newSyncTarget("c2", true), "6", "10", "6Gi", "10Gi"),
+				withResources(newSyncTarget("c3", true), "0", "10", "6Gi", "10Gi"),
+			},
+			wantPatch: true,
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "/c2",
+			},
+			expectedDecision: &schedulingv1alpha1.SchedulingDecision{
+				Selected: "c2",
+				Candidates: []schedulingv1alpha1.CandidateScore{
+					{Name: "c2", Feasible: true, Score: 160, Reasons: []string{"Headroom: 60% cpu allocatable, 60% memory allocatable", "Spread: 0 placements"}},
+					{Name: "c1", Feasible: true, Score: 110, Reasons: []string{"Headroom: 10% cpu allocatable, 10% memory allocatable", "Spread: 0 placements"}},
+					{Name: "c3", Reasons: []string{"Allocatable: no allocatable cpu"}},
+				},
+				DecisionTime: metav1.NewTime(now),
+			},
+		},
+		{
+			name:      "schedule synctarget with fewest placements",
+			placement: newPlacement("test", "test-location", ""),
+			location:  newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{
+				newSyncTarget("c1", true),
+				newSyncTarget("c2", true),
+			},
+			placementCounts: map[string]int{"/c1": 1, "/c2": 3},
+			wantPatch:       true,
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "/c1",
+			},
+			expectedDecision: &schedulingv1alpha1.SchedulingDecision{
+				Selected: "c1",
+				Candidates: []schedulingv1alpha1.CandidateScore{
+					{Name: "c1", Feasible: true, Score: 100, Reasons: []string{"Headroom: unknown capacity", "Spread: 1 placements"}},
+					{Name: "c2", Feasible: true, Score: 75, Reasons: []string{"Headroom: unknown capacity", "Spread: 3 placements"}},
+				},
+				DecisionTime: metav1.NewTime(now),
+			},
+		},
+		{
+			name: "schedule synctarget matching the instance affinity",
+			placement: withInstanceAffinity(newPlacement("test", "test-location", ""),
+				schedulingv1alpha1.WeightedLabelSelector{Weight: 80, Selector: metav1.LabelSelector{MatchLabels: map[string]string{"region": "eu"}}},
+				schedulingv1alpha1.WeightedLabelSelector{Weight: 10, Selector: metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "true"}}},
+			),
+			location: newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{
+				withLabels(newSyncTarget("c1", true), map[string]string{"region": "us", "gpu": "true"}),
+				withLabels(newSyncTarget("c2", true), map[string]string{"region": "eu"}),
+			},
+			wantPatch: true,
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "/c2",
+			},
+			expectedDecision: &schedulingv1alpha1.SchedulingDecision{
+				Selected: "c2",
+				Candidates: []schedulingv1alpha1.CandidateScore{
+					{Name: "c2", Feasible: true, Score: 230, Reasons: []string{"Headroom: unknown capacity", "Spread: 0 placements", "InstanceAffinity: matching weight 80"}},
+					{Name: "c1", Feasible: true, Score: 160, Reasons: []string{"Headroom: unknown capacity", "Spread: 0 placements", "InstanceAffinity: matching weight 10"}},
+				},
+				DecisionTime: metav1.NewTime(now),
+			},
 		},
 	}
 
@@ -108,9 +226,10 @@ func TestSchedulingReconcile(t *testing.T) {
 				return testCase.location, nil
 			}
 			var patched bool
+			current := testCase.placement
 			patchPlacement := func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*schedulingv1alpha1.Placement, error) {
 				patched = true
-				nsData, _ := json.Marshal(testCase.placement)
+				nsData, _ := json.Marshal(current)
 				updatedData, err := jsonpatch.MergePatch(nsData, data)
 				if err != nil {
 					return nil, err
@@ -119,20 +238,33 @@ func TestSchedulingReconcile(t *testing.T) {
 				var patchedPlacement schedulingv1alpha1.Placement
 				err = json.Unmarshal(updatedData, &patchedPlacement)
 				if err != nil {
-					return testCase.placement, err
+					return current, err
 				}
-				return &patchedPlacement, err
+				current = &patchedPlacement
+				return current, err
+			}
+			countPlacements := func(syncTargetKey string) (int, error) {
+				return testCase.placementCounts[syncTargetKey], nil
 			}
+			nowFunc := func() time.Time { return now }
 			reconciler := &placementSchedulingReconciler{
 				listSyncTarget: listSyncTarget,
 				getLocation:    getLocation,
 				patchPlacement: patchPlacement,
+				scheduler:      newSyncTargetFramework(nowFunc, countPlacements),
+				now:            nowFunc,
 			}
 
 			_, updated, err := reconciler.reconcile(context.TODO(), testCase.placement)
 			require.NoError(t, err)
 			require.Equal(t, testCase.wantPatch, patched)
 			require.Equal(t, testCase.expectedAnnotations, updated.Annotations)
+			if testCase.expectedDecision != nil {
+				require.NotNil(t, updated.Status.InstanceDecision)
+				require.True(t, testCase.expectedDecision.DecisionTime.Equal(&updated.Status.InstanceDecision.DecisionTime))
+				updated.Status.InstanceDecision.DecisionTime = testCase.expectedDecision.DecisionTime
+			}
+			require.Equal(t, testCase.expectedDecision, updated.Status.InstanceDecision)
 		})
 	}
 }
@@ -185,3 +317,30 @@ func newSyncTarget(name string, ready bool) *workloadv1alpha1.SyncTarget {
 
 	return syncTarget
 }
+
+func withEvictAfter(syncTarget *workloadv1alpha1.SyncTarget, evictAfter time.Time) *workloadv1alpha1.SyncTarget {
+	syncTarget.Spec.EvictAfter = &metav1.Time{Time: evictAfter}
+	return syncTarget
+}
+
+func withResources(syncTarget *workloadv1alpha1.SyncTarget, allocatableCPU, capacityCPU, allocatableMemory, capacityMemory string) *workloadv1alpha1.SyncTarget {
+	syncTarget.Status.Allocatable = &corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(allocatableCPU),
+		corev1.ResourceMemory: resource.MustParse(allocatableMemory),
+	}
+	syncTarget.Status.Capacity = &corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(capacityCPU),
+		corev1.ResourceMemory: resource.MustParse(capacityMemory),
+	}
+	return syncTarget
+}
+
+func withLabels(syncTarget *workloadv1alpha1.SyncTarget, labels map[string]string) *workloadv1alpha1.SyncTarget {
+	syncTarget.Labels = labels
+	return syncTarget
+}
+
+func withInstanceAffinity(placement *schedulingv1alpha1.Placement, affinity ...schedulingv1alpha1.WeightedLabelSelector) *schedulingv1alpha1.Placement {
+	placement.Spec.InstanceAffinity = affinity
+	return placement
+}
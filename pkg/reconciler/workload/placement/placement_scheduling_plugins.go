@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	conditionsapi "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/reconciler/scheduling/framework"
+)
+
+// newSyncTargetFramework returns the scheduling framework selecting a SyncTarget in the selected
+// location of a placement. countPlacements returns the number of placements scheduled to a SyncTarget
+// referenced by the value of the internal.workload.kcp.dev/synctarget annotation.
+func newSyncTargetFramework(now func() time.Time, countPlacements func(syncTargetKey string) (int, error)) *framework.Framework {
+	return framework.NewFramework(
+		[]framework.FilterPlugin{
+			&readyFilter{},
+			&nonEvictingFilter{now: now},
+			&allocatableFilter{},
+		},
+		[]framework.WeightedScorePlugin{
+			{ScorePlugin: &headroomScore{}, Weight: 1},
+			{ScorePlugin: &spreadScore{countPlacements: countPlacements}, Weight: 1},
+			{ScorePlugin: &affinityScore{}, Weight: 1},
+		},
+	)
+}
+
+// readyFilter filters out the SyncTargets which are not ready or are unschedulable.
+type readyFilter struct{}
+
+func (f *readyFilter) Name() string { return "Ready" }
+
+func (f *readyFilter) Filter(_ *schedulingv1alpha1.Placement, candidate metav1.Object) (bool, string) {
+	syncTarget := candidate.(*workloadv1alpha1.SyncTarget)
+	if !conditions.IsTrue(syncTarget, conditionsapi.ReadyCondition) {
+		return false, "not ready"
+	}
+	if syncTarget.Spec.Unschedulable {
+		return false, "unschedulable"
+	}
+	return true, ""
+}
+
+// nonEvictingFilter filters out the SyncTargets which are evicting their workloads.
+type nonEvictingFilter struct {
+	now func() time.Time
+}
+
+func (f *nonEvictingFilter) Name() string { return "NonEvicting" }
+
+func (f *nonEvictingFilter) Filter(_ *schedulingv1alpha1.Placement, candidate metav1.Object) (bool, string) {
+	syncTarget := candidate.(*workloadv1alpha1.SyncTarget)
+	if syncTarget.Spec.EvictAfter != nil && !f.now().Before(syncTarget.Spec.EvictAfter.Time) {
+		return false, "evicting"
+	}
+	return true, ""
+}
+
+// allocatableFilter filters out the SyncTargets reporting no allocatable cpu or memory at all.
+// SyncTargets which do not report allocatable resources are feasible.
+type allocatableFilter struct{}
+
+func (f *allocatableFilter) Name() string { return "Allocatable" }
+
+func (f *allocatableFilter) Filter(_ *schedulingv1alpha1.Placement, candidate metav1.Object) (bool, string) {
+	syncTarget := candidate.(*workloadv1alpha1.SyncTarget)
+	if syncTarget.Status.Allocatable == nil {
+		return true, ""
+	}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if quantity, found := (*syncTarget.Status.Allocatable)[name]; found && quantity.Sign() <= 0 {
+			return false, fmt.Sprintf("no allocatable %s", name)
+		}
+	}
+	return true, ""
+}
+
+// headroomScore prefers the SyncTargets with the highest ratio of allocatable to total cpu and memory.
+// SyncTargets which do not report their resources get half of the maximum score.
+type headroomScore struct{}
+
+func (s *headroomScore) Name() string { return "Headroom" }
+
+func (s *headroomScore) Score(_ *schedulingv1alpha1.Placement, candidate metav1.Object) (int64, string) {
+	syncTarget := candidate.(*workloadv1alpha1.SyncTarget)
+	if syncTarget.Status.Allocatable == nil || syncTarget.Status.Capacity == nil {
+		return framework.MaxScore / 2, "unknown capacity"
+	}
+
+	var total, count int64
+	var reasons []string
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		allocatable, foundAllocatable := (*syncTarget.Status.Allocatable)[name]
+		capacity, foundCapacity := (*syncTarget.Status.Capacity)[name]
+		if !foundAllocatable || !foundCapacity || capacity.MilliValue() <= 0 {
+			continue
+		}
+		ratio := allocatable.MilliValue() * framework.MaxScore / capacity.MilliValue()
+		if ratio < 0 {
+			ratio = 0
+		} else if ratio > framework.MaxScore {
+			ratio = framework.MaxScore
+		}
+		total += ratio
+		count++
+		reasons = append(reasons, fmt.Sprintf("%d%% %s allocatable", ratio, name))
+	}
+	if count == 0 {
+		return framework.MaxScore / 2, "unknown capacity"
+	}
+	return total / count, strings.Join(reasons, ", ")
+}
+
+// spreadScore prefers the SyncTargets with the fewest placements scheduled to them.
+type spreadScore struct {
+	countPlacements func(syncTargetKey string) (int, error)
+}
+
+func (s *spreadScore) Name() string { return "Spread" }
+
+func (s *spreadScore) Score(_ *schedulingv1alpha1.Placement, candidate metav1.Object) (int64, string) {
+	syncTarget := candidate.(*workloadv1alpha1.SyncTarget)
+	count, err := s.countPlacements(syncTargetKey(syncTarget))
+	if err != nil {
+		return 0, fmt.Sprintf("failed to count placements: %v", err)
+	}
+	return framework.MaxScore / int64(count+1), fmt.Sprintf("%d placements", count)
+}
+
+// affinityScore prefers the SyncTargets matching the instance affinity of the placement with the
+// highest total weight.
+type affinityScore struct{}
+
+func (s *affinityScore) Name() string { return "InstanceAffinity" }
+
+func (s *affinityScore) Score(placement *schedulingv1alpha1.Placement, candidate metav1.Object) (int64, string) {
+	if len(placement.Spec.InstanceAffinity) == 0 {
+		return 0, ""
+	}
+
+	var total int64
+	for _, affinity := range placement.Spec.InstanceAffinity {
+		selector, err := metav1.LabelSelectorAsSelector(&affinity.Selector)
+		if err != nil {
+			// skip this selector
+			continue
+		}
+		if selector.Matches(labels.Set(candidate.GetLabels())) {
+			total += int64(affinity.Weight)
+		}
+	}
+	if total > framework.MaxScore {
+		total = framework.MaxScore
+	}
+	return total, fmt.Sprintf("matching weight %d", total)
+}
@@ -153,6 +153,16 @@ func (c *Controller) enqueueResource(gvr schema.GroupVersionResource, obj interf
 	c.resourceQueue.Add(gvrstr + "::" + key)
 }
 
+func (c *Controller) enqueueResourceAfter(gvr schema.GroupVersionResource, obj interface{}, duration time.Duration) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	gvrstr := strings.Join([]string{gvr.Resource, gvr.Version, gvr.Group}, ".")
+	c.resourceQueue.AddAfter(gvrstr+"::"+key, duration)
+}
+
 func (c *Controller) enqueueGVR(gvr schema.GroupVersionResource) {
 	gvrstr := strings.Join([]string{gvr.Resource, gvr.Version, gvr.Group}, ".")
 	c.gvrQueue.Add(gvrstr)
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/kcp-dev/logicalcluster/v2"
@@ -38,36 +39,54 @@ import (
 	"github.com/kcp-dev/kcp/pkg/syncer/shared"
 )
 
+var namespacesGR = schema.GroupResource{Resource: "namespaces"}
+
 // reconcileResource is responsible for setting the cluster for a resource of
 // any type, to match the cluster where its namespace is assigned.
 func (c *Controller) reconcileResource(ctx context.Context, lclusterName logicalcluster.Name, obj *unstructured.Unstructured, gvr *schema.GroupVersionResource) error {
 	klog.V(4).Infof("Reconciling GVR %q %s|%s/%s", gvr.String(), lclusterName, obj.GetNamespace(), obj.GetName())
 
-	// If the resource is not namespaced (incl if the resource is itself a
-	// namespace), ignore it.
-	if obj.GetNamespace() == "" {
-		klog.V(4).Infof("GVR %q %s|%s had no namespace; ignoring", gvr.String(), logicalcluster.From(obj), obj.GetName())
+	// Resources upsynced from a sync target are owned by its syncer, not by the namespace placement.
+	if shared.IsUpsynced(obj.GetLabels()) {
+		klog.V(4).Infof("GVR %q %s|%s/%s is upsynced from a sync target; ignoring", gvr.String(), lclusterName, obj.GetNamespace(), obj.GetName())
 		return nil
 	}
 
-	if namespaceBlocklist.Has(obj.GetNamespace()) {
-		klog.V(4).Infof("Skipping syncing namespace %s|%q", logicalcluster.From(obj), obj.GetNamespace())
-		return nil
-	}
+	var ns *corev1.Namespace
+	if obj.GetNamespace() == "" {
+		// Cluster-scoped resources, namespaces apart, are placed according to their own placement
+		// annotation, if any.
+		var placed bool
+		ns, placed = clusterScopedPlacement(obj)
+		if !placed || gvr.GroupResource() == namespacesGR {
+			klog.V(4).Infof("GVR %q %s|%s had no namespace nor placement; ignoring", gvr.String(), logicalcluster.From(obj), obj.GetName())
+			return nil
+		}
+	} else {
+		if namespaceBlocklist.Has(obj.GetNamespace()) {
+			klog.V(4).Infof("Skipping syncing namespace %s|%q", logicalcluster.From(obj), obj.GetNamespace())
+			return nil
+		}
 
-	// Align the resource's assigned cluster with the namespace's assigned
-	// cluster.
-	// First, get the namespace object (from the cached lister).
-	ns, err := c.namespaceLister.Get(clusters.ToClusterAwareKey(lclusterName, obj.GetNamespace()))
-	if apierrors.IsNotFound(err) {
-		// Namespace was deleted; this resource will eventually get deleted too, so ignore
-		return nil
-	}
-	if err != nil {
-		return fmt.Errorf("error reconciling resource %s|%s/%s: error getting namespace: %w", lclusterName, obj.GetNamespace(), obj.GetName(), err)
+		// Align the resource's assigned cluster with the namespace's assigned
+		// cluster.
+		// First, get the namespace object (from the cached lister).
+		var err error
+		ns, err = c.namespaceLister.Get(clusters.ToClusterAwareKey(lclusterName, obj.GetNamespace()))
+		if apierrors.IsNotFound(err) {
+			// Namespace was deleted; this resource will eventually get deleted too, so ignore
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reconciling resource %s|%s/%s: error getting namespace: %w", lclusterName, obj.GetNamespace(), obj.GetName(), err)
+		}
 	}
 
-	annotationPatch, labelPatch := computePlacement(ns, obj)
+	annotationPatch, labelPatch, requeueAfter := computePlacement(ns, obj, time.Now())
+	if requeueAfter > 0 {
+		klog.V(4).Infof("Requeueing %q %s|%s/%s after %s to remove it from sync targets at the end of the removal grace period", gvr, lclusterName, obj.GetNamespace(), obj.GetName(), requeueAfter)
+		c.enqueueResourceAfter(*gvr, obj, requeueAfter)
+	}
 
 	// If the object DeletionTimestamp is set, we should set all locations deletion timestamps annotations to the same value.
 	if obj.GetDeletionTimestamp() != nil {
@@ -98,8 +117,8 @@ func (c *Controller) reconcileResource(ctx context.Context, lclusterName logical
 		return err
 	}
 
-	klog.V(2).Infof("Patching %q %s|%s/%s: %s", gvr, lclusterName, ns.Name, obj.GetName(), string(patchBytes))
-	if _, err := c.dynClusterClient.Resource(*gvr).Namespace(ns.Name).
+	klog.V(2).Infof("Patching %q %s|%s/%s: %s", gvr, lclusterName, obj.GetNamespace(), obj.GetName(), string(patchBytes))
+	if _, err := c.dynClusterClient.Resource(*gvr).Namespace(obj.GetNamespace()).
 		Patch(logicalcluster.WithCluster(ctx, lclusterName), obj.GetName(), types.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil {
 		return err
 	}
@@ -107,6 +126,38 @@ func (c *Controller) reconcileResource(ctx context.Context, lclusterName logical
 	return nil
 }
 
+// clusterScopedPlacement returns a namespace placed on the sync targets listed in the placement annotation of the
+// given cluster-scoped object, for the object to be placed as if it belonged to it, and false if the object has
+// no placement annotation. An object still placed on sync targets once its annotation is removed gets an empty
+// placement, for it to be removed from them.
+func clusterScopedPlacement(obj metav1.Object) (*corev1.Namespace, bool) {
+	value, found := obj.GetAnnotations()[workloadv1alpha1.ClusterScopedPlacementAnnotation]
+	if !found && !hasStateLabels(obj) {
+		return nil, false
+	}
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{},
+		},
+	}
+	for _, syncTarget := range strings.Split(value, ",") {
+		if syncTarget = strings.TrimSpace(syncTarget); syncTarget != "" {
+			ns.Labels[workloadv1alpha1.ClusterResourceStateLabelPrefix+syncTarget] = string(workloadv1alpha1.ResourceStateSync)
+		}
+	}
+	return ns, true
+}
+
+// hasStateLabels returns true if the object has a state.workload.kcp.dev/<sync-target-name> label.
+func hasStateLabels(obj metav1.Object) bool {
+	for k := range obj.GetLabels() {
+		if strings.HasPrefix(k, workloadv1alpha1.ClusterResourceStateLabelPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func propagateDeletionTimestamp(obj metav1.Object, annotationPatch map[string]interface{}) map[string]interface{} {
 	klog.V(3).Infof("Resource is being deleted; setting the deletion per locations timestamps for %s|%s/%s", logicalcluster.From(obj).String(), obj.GetNamespace(), obj.GetName())
 	objAnnotations := obj.GetAnnotations()
@@ -123,7 +174,9 @@ func propagateDeletionTimestamp(obj metav1.Object, annotationPatch map[string]in
 }
 
 // computePlacement computes the patch against annotations and labels. Nil means to remove the key.
-func computePlacement(ns *corev1.Namespace, obj metav1.Object) (annotationPatch map[string]interface{}, labelPatch map[string]interface{}) {
+// The removal of the namespace from a sync target is only propagated to the object when the removal
+// grace period of the namespace has expired. Until then, requeueAfter tells when to check again.
+func computePlacement(ns *corev1.Namespace, obj metav1.Object, now time.Time) (annotationPatch map[string]interface{}, labelPatch map[string]interface{}, requeueAfter time.Duration) {
 	nsLocations, nsDeleting := locations(ns.Annotations, ns.Labels, true)
 	objLocations, objDeleting := locations(obj.GetAnnotations(), obj.GetLabels(), false)
 	if objLocations.Equal(nsLocations) && objDeleting.Equal(nsDeleting) {
@@ -143,12 +196,12 @@ func computePlacement(ns *corev1.Namespace, obj metav1.Object) (annotationPatch
 				hasSyncerFinalizer = true
 			}
 		}
-		if val, exists := obj.GetAnnotations()[workloadv1alpha1.ClusterFinalizerAnnotationPrefix+loc]; exists && val != "" {
+		if shared.HasClusterFinalizers(obj, loc) {
 			hasClusterFinalizer = true
 		}
 		if hasSyncerFinalizer || hasClusterFinalizer {
 			if _, found := obj.GetAnnotations()[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+loc]; !found {
-				annotationPatch[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+loc] = time.Now().Format(time.RFC3339)
+				annotationPatch[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+loc] = now.Format(time.RFC3339)
 			}
 		} else {
 			if _, found := obj.GetAnnotations()[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+loc]; found {
@@ -161,6 +214,13 @@ func computePlacement(ns *corev1.Namespace, obj metav1.Object) (annotationPatch
 		if nsTimestamp, found := ns.Annotations[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+loc]; found && validRFC3339(nsTimestamp) {
 			objTimestamp, found := obj.GetAnnotations()[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+loc]
 			if !found || !validRFC3339(objTimestamp) {
+				if remaining := removalGracePeriodRemaining(ns, loc, nsTimestamp, now); remaining > 0 {
+					// keep the object synced until the end of the removal grace period
+					if requeueAfter == 0 || remaining < requeueAfter {
+						requeueAfter = remaining
+					}
+					continue
+				}
 				annotationPatch[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+loc] = nsTimestamp
 			}
 		}
@@ -199,6 +259,24 @@ func (c *Controller) reconcileGVR(gvr schema.GroupVersionResource) error {
 	return nil
 }
 
+// removalGracePeriodRemaining returns how long the removal of the namespace from the given sync target, requested at
+// the given timestamp, has to wait for its removal grace period to expire.
+func removalGracePeriodRemaining(ns *corev1.Namespace, syncTarget, removalTimestamp string, now time.Time) time.Duration {
+	value, found := ns.Annotations[workloadv1alpha1.InternalClusterRemovalGracePeriodAnnotationPrefix+syncTarget]
+	if !found {
+		return 0
+	}
+	gracePeriod, err := time.ParseDuration(value)
+	if err != nil {
+		return 0
+	}
+	removalTime, err := time.Parse(time.RFC3339, removalTimestamp)
+	if err != nil {
+		return 0
+	}
+	return removalTime.Add(gracePeriod).Sub(now)
+}
+
 func validRFC3339(ts string) bool {
 	_, err := time.Parse(time.RFC3339, ts)
 	return err == nil
@@ -53,6 +53,7 @@ func TestComputePlacement(t *testing.T) {
 		obj                 metav1.Object
 		wantAnnotationPatch map[string]interface{} // nil means delete
 		wantLabelPatch      map[string]interface{} // nil means delete
+		wantRequeueAfter    time.Duration
 	}{
 		{name: "unscheduled namespace and object",
 			ns:  namespace(nil, nil),
@@ -91,6 +92,15 @@ func TestComputePlacement(t *testing.T) {
 				"state.workload.kcp.dev/cluster-2": "Sync",
 			},
 		},
+		{name: "location removed from namespace, object still has the syncer finalizer",
+			ns: namespace(nil, nil),
+			obj: object(nil, map[string]string{
+				"state.workload.kcp.dev/cluster-1": "Sync",
+			}, []string{"workload.kcp.dev/syncer-cluster-1"}, nil),
+			wantAnnotationPatch: map[string]interface{}{
+				"deletion.internal.workload.kcp.dev/cluster-1": "2002-10-02T15:00:30Z",
+			},
+		},
 		{name: "new deletion on namespace",
 			ns: namespace(map[string]string{
 				"deletion.internal.workload.kcp.dev/cluster-4": "2002-10-02T10:00:00-05:00",
@@ -105,6 +115,32 @@ func TestComputePlacement(t *testing.T) {
 				"deletion.internal.workload.kcp.dev/cluster-4": "2002-10-02T10:00:00-05:00",
 			},
 		},
+		{name: "new deletion on namespace during the removal grace period",
+			ns: namespace(map[string]string{
+				"deletion.internal.workload.kcp.dev/cluster-4":             "2002-10-02T10:00:00-05:00",
+				"removal-grace-period.internal.workload.kcp.dev/cluster-4": "1m0s",
+			}, map[string]string{
+				"state.workload.kcp.dev/cluster-4": "Sync",
+			}),
+			obj: object(nil, map[string]string{
+				"state.workload.kcp.dev/cluster-4": "Sync",
+			}, nil, nil),
+			wantRequeueAfter: 30 * time.Second,
+		},
+		{name: "new deletion on namespace after the removal grace period",
+			ns: namespace(map[string]string{
+				"deletion.internal.workload.kcp.dev/cluster-4":             "2002-10-02T10:00:00-05:00",
+				"removal-grace-period.internal.workload.kcp.dev/cluster-4": "10s",
+			}, map[string]string{
+				"state.workload.kcp.dev/cluster-4": "Sync",
+			}),
+			obj: object(nil, map[string]string{
+				"state.workload.kcp.dev/cluster-4": "Sync",
+			}, nil, nil),
+			wantAnnotationPatch: map[string]interface{}{
+				"deletion.internal.workload.kcp.dev/cluster-4": "2002-10-02T10:00:00-05:00",
+			},
+		},
 		{name: "existing deletion on namespace and object",
 			ns: namespace(map[string]string{
 				"deletion.internal.workload.kcp.dev/cluster-3": "2002-10-02T10:00:00-05:00",
@@ -228,13 +264,17 @@ func TestComputePlacement(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotAnnotationPatch, gotLabelPatch := computePlacement(tt.ns, tt.obj)
+			now := time.Date(2002, 10, 2, 15, 0, 30, 0, time.UTC)
+			gotAnnotationPatch, gotLabelPatch, gotRequeueAfter := computePlacement(tt.ns, tt.obj, now)
 			if diff := cmp.Diff(gotAnnotationPatch, tt.wantAnnotationPatch); diff != "" {
 				t.Errorf("incorrect annotation patch: %s", diff)
 			}
 			if diff := cmp.Diff(gotLabelPatch, tt.wantLabelPatch); diff != "" {
 				t.Errorf("incorrect label patch: %s", diff)
 			}
+			if gotRequeueAfter != tt.wantRequeueAfter {
+				t.Errorf("incorrect requeue after: got %s, expected %s", gotRequeueAfter, tt.wantRequeueAfter)
+			}
 		})
 	}
 }
@@ -315,3 +355,63 @@ func TestPropagateDeletionTimestamp(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterScopedPlacement(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		labels      map[string]string
+		wantLabels  map[string]string
+		wantPlaced  bool
+	}{
+		{name: "no placement annotation"},
+		{name: "placement annotation removed from a placed object",
+			labels:     map[string]string{"state.workload.kcp.dev/cluster-3": "Sync"},
+			wantLabels: map[string]string{},
+			wantPlaced: true,
+		},
+		{name: "empty placement",
+			annotations: map[string]string{"experimental.workload.kcp.dev/sync-targets": ""},
+			wantLabels:  map[string]string{},
+			wantPlaced:  true,
+		},
+		{name: "placed on sync targets",
+			annotations: map[string]string{"experimental.workload.kcp.dev/sync-targets": "cluster-1, cluster-2,"},
+			wantLabels: map[string]string{
+				"state.workload.kcp.dev/cluster-1": "Sync",
+				"state.workload.kcp.dev/cluster-2": "Sync",
+			},
+			wantPlaced: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns, placed := clusterScopedPlacement(object(tt.annotations, tt.labels, nil, nil))
+			if placed != tt.wantPlaced {
+				t.Fatalf("unexpected placed: %v", placed)
+			}
+			if !placed {
+				return
+			}
+			if diff := cmp.Diff(tt.wantLabels, ns.Labels); diff != "" {
+				t.Errorf("unexpected labels (-want +got):\n%s", diff)
+			}
+
+			// The object is then placed like the objects of a namespace.
+			annotationPatch, labelPatch, _ := computePlacement(ns, object(tt.annotations, map[string]string{"state.workload.kcp.dev/cluster-3": "Sync"}, []string{"workload.kcp.dev/syncer-cluster-3"}, nil), time.Now())
+			if _, found := annotationPatch["deletion.internal.workload.kcp.dev/cluster-3"]; !found {
+				t.Errorf("expected the object to be removed from cluster-3, got annotation patch %v", annotationPatch)
+			}
+			wantLabelPatch := map[string]interface{}{}
+			for k, v := range tt.wantLabels {
+				wantLabelPatch[k] = v
+			}
+			if len(wantLabelPatch) == 0 {
+				wantLabelPatch = nil
+			}
+			if diff := cmp.Diff(wantLabelPatch, labelPatch); diff != "" {
+				t.Errorf("unexpected label patch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,422 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusaggregator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+const (
+	conditionTrue    = "True"
+	conditionFalse   = "False"
+	conditionUnknown = "Unknown"
+
+	// ConditionNotReportedReason is the reason of an aggregated condition that is Unknown
+	// because some SyncTargets did not report it yet.
+	ConditionNotReportedReason = "ConditionNotReported"
+)
+
+// DefaultAggregators returns the status aggregators of the built-in workload resource types.
+func DefaultAggregators() []shared.StatusAggregator {
+	return []shared.StatusAggregator{
+		NewDeploymentAggregator(),
+		NewStatefulSetAggregator(),
+		NewDaemonSetAggregator(),
+		NewJobAggregator(),
+		NewServiceAggregator(),
+		NewIngressAggregator(),
+	}
+}
+
+// NewDeploymentAggregator returns the status aggregator of Deployments. Replica counts are summed,
+// and the Deployment is only Available and Progressing when it is on all the SyncTargets.
+func NewDeploymentAggregator() shared.StatusAggregator {
+	return &aggregator{
+		gvr:               schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		counters:          []string{"replicas", "updatedReplicas", "readyReplicas", "availableReplicas", "unavailableReplicas"},
+		anyTrueConditions: sets.NewString("ReplicaFailure"),
+	}
+}
+
+// NewStatefulSetAggregator returns the status aggregator of StatefulSets. Replica counts are summed.
+func NewStatefulSetAggregator() shared.StatusAggregator {
+	return &aggregator{
+		gvr:      schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		counters: []string{"replicas", "readyReplicas", "currentReplicas", "updatedReplicas", "availableReplicas"},
+	}
+}
+
+// NewDaemonSetAggregator returns the status aggregator of DaemonSets. Scheduled and available
+// pod counts are summed.
+func NewDaemonSetAggregator() shared.StatusAggregator {
+	return &aggregator{
+		gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"},
+		counters: []string{"currentNumberScheduled", "numberMisscheduled", "desiredNumberScheduled", "numberReady",
+			"updatedNumberScheduled", "numberAvailable", "numberUnavailable"},
+	}
+}
+
+// NewJobAggregator returns the status aggregator of Jobs. Pod counts are summed, the Job is
+// only Complete when it completed on all the SyncTargets, and Failed as soon as it failed on one.
+func NewJobAggregator() shared.StatusAggregator {
+	return &aggregator{
+		gvr:               schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"},
+		counters:          []string{"active", "succeeded", "failed", "ready"},
+		anyTrueConditions: sets.NewString("Failed", "FailureTarget", "Suspended"),
+		aggregateFuncs: []aggregateFunc{
+			aggregateTime("startTime", false, false),
+			aggregateTime("completionTime", true, true),
+		},
+	}
+}
+
+// NewServiceAggregator returns the status aggregator of Services. The load balancer ingress
+// points of all the SyncTargets are merged.
+func NewServiceAggregator() shared.StatusAggregator {
+	return &aggregator{
+		gvr:            schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"},
+		aggregateFuncs: []aggregateFunc{aggregateLoadBalancerIngresses},
+	}
+}
+
+// NewIngressAggregator returns the status aggregator of Ingresses. The load balancer ingress
+// points of all the SyncTargets are merged.
+func NewIngressAggregator() shared.StatusAggregator {
+	return &aggregator{
+		gvr:            schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+		aggregateFuncs: []aggregateFunc{aggregateLoadBalancerIngresses},
+	}
+}
+
+// syncTargetStatus is the status of an object on a given SyncTarget.
+type syncTargetStatus struct {
+	syncTarget string
+	status     map[string]interface{}
+}
+
+// aggregateFunc sets in status the aggregate of a status field over the SyncTarget statuses, or
+// removes the field when no SyncTarget reports it.
+type aggregateFunc func(status map[string]interface{}, statuses []syncTargetStatus) error
+
+// aggregator is a generic shared.StatusAggregator for the resources whose status is made of
+// counters, conditions, and a few specific fields.
+type aggregator struct {
+	gvr schema.GroupVersionResource
+	// counters are the integer fields of the status summed over the SyncTargets.
+	counters []string
+	// anyTrueConditions are the condition types that are True as soon as they are True on one
+	// SyncTarget, typically failures. The other condition types are only True when they are True
+	// on all the SyncTargets.
+	anyTrueConditions sets.String
+	// aggregateFuncs aggregate the other status fields.
+	aggregateFuncs []aggregateFunc
+}
+
+func (a *aggregator) GVR() schema.GroupVersionResource {
+	return a.gvr
+}
+
+// AggregateStatus returns the aggregated status. A nil status of a SyncTarget means that the
+// SyncTarget did not report any status yet. The aggregated status starts from the existing status
+// of the upstream object, so that the fields that are not aggregated, like observedGeneration, are kept.
+func (a *aggregator) AggregateStatus(upstreamObj *unstructured.Unstructured, syncTargetStatuses map[string]interface{}) (interface{}, error) {
+	statuses := make([]syncTargetStatus, 0, len(syncTargetStatuses))
+	for syncTarget, status := range syncTargetStatuses {
+		if status == nil {
+			statuses = append(statuses, syncTargetStatus{syncTarget: syncTarget, status: map[string]interface{}{}})
+			continue
+		}
+		statusMap, ok := status.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("status of SyncTarget %q is a %T, not an object", syncTarget, status)
+		}
+		statuses = append(statuses, syncTargetStatus{syncTarget: syncTarget, status: statusMap})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].syncTarget < statuses[j].syncTarget
+	})
+
+	aggregated, _, err := unstructured.NestedMap(upstreamObj.Object, "status")
+	if err != nil {
+		return nil, err
+	}
+	if aggregated == nil {
+		aggregated = map[string]interface{}{}
+	}
+	for _, counter := range a.counters {
+		if err := sumCounter(aggregated, statuses, counter); err != nil {
+			return nil, err
+		}
+	}
+
+	existingConditions, _, err := unstructured.NestedSlice(upstreamObj.Object, "status", "conditions")
+	if err != nil {
+		return nil, err
+	}
+	conditions, err := aggregateConditions(existingConditions, statuses, a.anyTrueConditions)
+	if err != nil {
+		return nil, err
+	}
+	if len(conditions) > 0 {
+		aggregated["conditions"] = conditions
+	} else {
+		delete(aggregated, "conditions")
+	}
+
+	for _, aggregate := range a.aggregateFuncs {
+		if err := aggregate(aggregated, statuses); err != nil {
+			return nil, err
+		}
+	}
+
+	return aggregated, nil
+}
+
+func sumCounter(aggregated map[string]interface{}, statuses []syncTargetStatus, counter string) error {
+	var sum int64
+	var found bool
+	for _, s := range statuses {
+		value, ok, err := unstructured.NestedInt64(s.status, counter)
+		if err != nil {
+			return fmt.Errorf("invalid status of SyncTarget %q: %w", s.syncTarget, err)
+		}
+		if ok {
+			sum += value
+			found = true
+		}
+	}
+	if found {
+		aggregated[counter] = sum
+	} else {
+		delete(aggregated, counter)
+	}
+	return nil
+}
+
+// aggregateConditions merges the conditions of all the SyncTargets, sorted by type. The last
+// transition time of an existing condition is kept as long as its aggregated status does not change.
+func aggregateConditions(existing []interface{}, statuses []syncTargetStatus, anyTrueConditions sets.String) ([]interface{}, error) {
+	conditionsByTarget := make([]map[string]map[string]interface{}, len(statuses))
+	conditionTypes := sets.NewString()
+	for i, s := range statuses {
+		conditions, err := conditionsByType(s.status)
+		if err != nil {
+			return nil, fmt.Errorf("invalid conditions of SyncTarget %q: %w", s.syncTarget, err)
+		}
+		conditionsByTarget[i] = conditions
+		for conditionType := range conditions {
+			conditionTypes.Insert(conditionType)
+		}
+	}
+	existingConditions, err := conditionsByType(map[string]interface{}{"conditions": existing})
+	if err != nil {
+		return nil, fmt.Errorf("invalid existing conditions: %w", err)
+	}
+
+	var aggregated []interface{}
+	for _, conditionType := range conditionTypes.List() {
+		var condition map[string]interface{}
+		if anyTrueConditions.Has(conditionType) {
+			condition = anyTrueCondition(conditionType, statuses, conditionsByTarget)
+		} else {
+			condition = allTrueCondition(conditionType, statuses, conditionsByTarget)
+		}
+		if existing, ok := existingConditions[conditionType]; ok && existing["status"] == condition["status"] {
+			if lastTransitionTime, ok := existing["lastTransitionTime"]; ok {
+				condition["lastTransitionTime"] = lastTransitionTime
+			}
+		}
+		aggregated = append(aggregated, condition)
+	}
+	return aggregated, nil
+}
+
+// anyTrueCondition returns a condition that is True when it is True on at least one SyncTarget.
+func anyTrueCondition(conditionType string, statuses []syncTargetStatus, conditionsByTarget []map[string]map[string]interface{}) map[string]interface{} {
+	var falseCondition map[string]interface{}
+	for i, conditions := range conditionsByTarget {
+		condition, ok := conditions[conditionType]
+		if !ok {
+			continue
+		}
+		if condition["status"] == conditionTrue {
+			return fromSyncTarget(condition, statuses[i].syncTarget)
+		}
+		if falseCondition == nil {
+			falseCondition = condition
+		}
+	}
+	condition := runtime.DeepCopyJSONValue(falseCondition).(map[string]interface{})
+	condition["status"] = conditionFalse
+	return condition
+}
+
+// allTrueCondition returns a condition that is True when it is True on all the SyncTargets,
+// and False as soon as it is False on one of them.
+func allTrueCondition(conditionType string, statuses []syncTargetStatus, conditionsByTarget []map[string]map[string]interface{}) map[string]interface{} {
+	var trueCondition, unknownCondition map[string]interface{}
+	var unknownTarget string
+	var notReported []string
+	for i, conditions := range conditionsByTarget {
+		condition, ok := conditions[conditionType]
+		switch {
+		case !ok:
+			notReported = append(notReported, statuses[i].syncTarget)
+		case condition["status"] == conditionFalse:
+			return fromSyncTarget(condition, statuses[i].syncTarget)
+		case condition["status"] == conditionTrue:
+			if trueCondition == nil {
+				trueCondition = condition
+			}
+		default:
+			if unknownCondition == nil {
+				unknownCondition = condition
+				unknownTarget = statuses[i].syncTarget
+			}
+		}
+	}
+	if unknownCondition != nil {
+		condition := fromSyncTarget(unknownCondition, unknownTarget)
+		condition["status"] = conditionUnknown
+		return condition
+	}
+	if len(notReported) > 0 {
+		return map[string]interface{}{
+			"type":    conditionType,
+			"status":  conditionUnknown,
+			"reason":  ConditionNotReportedReason,
+			"message": fmt.Sprintf("Condition is not reported by SyncTarget(s) %s", strings.Join(notReported, ", ")),
+		}
+	}
+	return runtime.DeepCopyJSONValue(trueCondition).(map[string]interface{})
+}
+
+// fromSyncTarget returns a copy of the condition of a SyncTarget, with the SyncTarget name in the message.
+func fromSyncTarget(condition map[string]interface{}, syncTarget string) map[string]interface{} {
+	condition = runtime.DeepCopyJSONValue(condition).(map[string]interface{})
+	if message, ok := condition["message"].(string); ok && message != "" {
+		condition["message"] = fmt.Sprintf("SyncTarget %s: %s", syncTarget, message)
+	} else {
+		condition["message"] = fmt.Sprintf("SyncTarget %s", syncTarget)
+	}
+	return condition
+}
+
+func conditionsByType(status map[string]interface{}) (map[string]map[string]interface{}, error) {
+	conditions, _, err := unstructured.NestedSlice(status, "conditions")
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[string]map[string]interface{}, len(conditions))
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("condition is a %T, not an object", c)
+		}
+		conditionType, ok := condition["type"].(string)
+		if !ok || conditionType == "" {
+			return nil, fmt.Errorf("condition has no type")
+		}
+		byType[conditionType] = condition
+	}
+	return byType, nil
+}
+
+// aggregateTime returns an aggregateFunc keeping the earliest, or the latest, value of a timestamp
+// field. If requireAll is true, the field is only set when all the SyncTargets report it.
+func aggregateTime(field string, latest, requireAll bool) aggregateFunc {
+	return func(aggregated map[string]interface{}, statuses []syncTargetStatus) error {
+		var result string
+		var resultTime time.Time
+		for _, s := range statuses {
+			value, ok, err := unstructured.NestedString(s.status, field)
+			if err != nil {
+				return fmt.Errorf("invalid status of SyncTarget %q: %w", s.syncTarget, err)
+			}
+			if !ok {
+				if requireAll {
+					delete(aggregated, field)
+					return nil
+				}
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return fmt.Errorf("invalid %s of SyncTarget %q: %w", field, s.syncTarget, err)
+			}
+			if result == "" || (latest && t.After(resultTime)) || (!latest && t.Before(resultTime)) {
+				result, resultTime = value, t
+			}
+		}
+		if result != "" {
+			aggregated[field] = result
+		} else {
+			delete(aggregated, field)
+		}
+		return nil
+	}
+}
+
+// aggregateLoadBalancerIngresses merges the load balancer ingress points of all the SyncTargets.
+func aggregateLoadBalancerIngresses(aggregated map[string]interface{}, statuses []syncTargetStatus) error {
+	var ingresses []interface{}
+	var found bool
+	for _, s := range statuses {
+		loadBalancer, ok, err := unstructured.NestedMap(s.status, "loadBalancer")
+		if err != nil {
+			return fmt.Errorf("invalid status of SyncTarget %q: %w", s.syncTarget, err)
+		}
+		if !ok {
+			continue
+		}
+		found = true
+		targetIngresses, _, err := unstructured.NestedSlice(loadBalancer, "ingress")
+		if err != nil {
+			return fmt.Errorf("invalid status of SyncTarget %q: %w", s.syncTarget, err)
+		}
+	nextIngress:
+		for _, ingress := range targetIngresses {
+			for _, existing := range ingresses {
+				if equality.Semantic.DeepEqual(existing, ingress) {
+					continue nextIngress
+				}
+			}
+			ingresses = append(ingresses, ingress)
+		}
+	}
+	if !found {
+		delete(aggregated, "loadBalancer")
+		return nil
+	}
+	loadBalancer := map[string]interface{}{}
+	if len(ingresses) > 0 {
+		loadBalancer["ingress"] = ingresses
+	}
+	aggregated["loadBalancer"] = loadBalancer
+	return nil
+}
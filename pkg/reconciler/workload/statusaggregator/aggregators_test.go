@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusaggregator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/json"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+func fromJSON(t *testing.T, s string) interface{} {
+	t.Helper()
+	if s == "" {
+		return nil
+	}
+	var v interface{}
+	require.NoError(t, json.Unmarshal([]byte(s), &v))
+	return v
+}
+
+func TestAggregateStatus(t *testing.T) {
+	tests := []struct {
+		name               string
+		aggregator         shared.StatusAggregator
+		existingStatus     string
+		syncTargetStatuses map[string]string
+		want               string
+		wantErr            bool
+	}{
+		{
+			name:       "deployment available on all SyncTargets",
+			aggregator: NewDeploymentAggregator(),
+			syncTargetStatuses: map[string]string{
+				"us-west1": `{"replicas":2,"readyReplicas":2,"availableReplicas":2,"conditions":[{"type":"Available","status":"True","reason":"MinimumReplicasAvailable","lastTransitionTime":"2022-08-01T10:00:00Z"}]}`,
+				"us-east1": `{"replicas":3,"readyReplicas":3,"availableReplicas":3,"conditions":[{"type":"Available","status":"True","reason":"MinimumReplicasAvailable","lastTransitionTime":"2022-08-01T11:00:00Z"}]}`,
+			},
+			want: `{"replicas":5,"readyReplicas":5,"availableReplicas":5,"conditions":[{"type":"Available","status":"True","reason":"MinimumReplicasAvailable","lastTransitionTime":"2022-08-01T11:00:00Z"}]}`,
+		},
+		{
+			name:       "deployment unavailable on one SyncTarget",
+			aggregator: NewDeploymentAggregator(),
+			syncTargetStatuses: map[string]string{
+				"us-west1": `{"replicas":2,"availableReplicas":2,"conditions":[{"type":"Available","status":"True","reason":"MinimumReplicasAvailable"}]}`,
+				"us-east1": `{"replicas":3,"availableReplicas":0,"unavailableReplicas":3,"conditions":[{"type":"Available","status":"False","reason":"MinimumReplicasUnavailable","message":"Deployment does not have minimum availability."}]}`,
+			},
+			want: `{"replicas":5,"availableReplicas":2,"unavailableReplicas":3,"conditions":[{"type":"Available","status":"False","reason":"MinimumReplicasUnavailable","message":"SyncTarget us-east1: Deployment does not have minimum availability."}]}`,
+		},
+		{
+			name:       "deployment not reported yet by one SyncTarget",
+			aggregator: NewDeploymentAggregator(),
+			syncTargetStatuses: map[string]string{
+				"us-west1": `{"replicas":2,"conditions":[{"type":"Available","status":"True","reason":"MinimumReplicasAvailable"}]}`,
+				"us-east1": ``,
+			},
+			want: `{"replicas":2,"conditions":[{"type":"Available","status":"Unknown","reason":"ConditionNotReported","message":"Condition is not reported by SyncTarget(s) us-east1"}]}`,
+		},
+		{
+			name:           "deployment replica failure on one SyncTarget, keeping the existing transition time",
+			aggregator:     NewDeploymentAggregator(),
+			existingStatus: `{"conditions":[{"type":"ReplicaFailure","status":"True","lastTransitionTime":"2022-08-01T09:00:00Z"}]}`,
+			syncTargetStatuses: map[string]string{
+				"us-west1": `{"conditions":[{"type":"ReplicaFailure","status":"False"}]}`,
+				"us-east1": `{"conditions":[{"type":"ReplicaFailure","status":"True","reason":"FailedCreate","message":"quota exceeded","lastTransitionTime":"2022-08-01T10:00:00Z"}]}`,
+			},
+			want: `{"conditions":[{"type":"ReplicaFailure","status":"True","reason":"FailedCreate","message":"SyncTarget us-east1: quota exceeded","lastTransitionTime":"2022-08-01T09:00:00Z"}]}`,
+		},
+		{
+			name:           "deployment keeping the existing fields that are not aggregated",
+			aggregator:     NewDeploymentAggregator(),
+			existingStatus: `{"observedGeneration":3,"collisionCount":1,"replicas":4,"unavailableReplicas":1}`,
+			syncTargetStatuses: map[string]string{
+				"us-west1": `{"observedGeneration":1,"replicas":2}`,
+				"us-east1": `{"observedGeneration":2,"replicas":3}`,
+			},
+			want: `{"observedGeneration":3,"collisionCount":1,"replicas":5}`,
+		},
+		{
+			name:       "job complete on one SyncTarget only",
+			aggregator: NewJobAggregator(),
+			syncTargetStatuses: map[string]string{
+				"us-west1": `{"succeeded":1,"startTime":"2022-08-01T10:00:00Z","completionTime":"2022-08-01T10:05:00Z","conditions":[{"type":"Complete","status":"True"}]}`,
+				"us-east1": `{"active":1,"startTime":"2022-08-01T09:00:00Z"}`,
+			},
+			want: `{"active":1,"succeeded":1,"startTime":"2022-08-01T09:00:00Z","conditions":[{"type":"Complete","status":"Unknown","reason":"ConditionNotReported","message":"Condition is not reported by SyncTarget(s) us-east1"}]}`,
+		},
+		{
+			name:       "job complete on all SyncTargets",
+			aggregator: NewJobAggregator(),
+			syncTargetStatuses: map[string]string{
+				"us-west1": `{"succeeded":1,"startTime":"2022-08-01T10:00:00Z","completionTime":"2022-08-01T10:05:00Z","conditions":[{"type":"Complete","status":"True"}]}`,
+				"us-east1": `{"succeeded":1,"startTime":"2022-08-01T09:00:00Z","completionTime":"2022-08-01T09:30:00Z","conditions":[{"type":"Complete","status":"True"}]}`,
+			},
+			want: `{"succeeded":2,"startTime":"2022-08-01T09:00:00Z","completionTime":"2022-08-01T10:05:00Z","conditions":[{"type":"Complete","status":"True"}]}`,
+		},
+		{
+			name:       "service load balancer ingresses are merged",
+			aggregator: NewServiceAggregator(),
+			syncTargetStatuses: map[string]string{
+				"us-west1": `{"loadBalancer":{"ingress":[{"ip":"1.2.3.4"}]}}`,
+				"us-east1": `{"loadBalancer":{"ingress":[{"hostname":"east.example.com"},{"ip":"1.2.3.4"}]}}`,
+				"eu-west1": `{"loadBalancer":{}}`,
+			},
+			want: `{"loadBalancer":{"ingress":[{"hostname":"east.example.com"},{"ip":"1.2.3.4"}]}}`,
+		},
+		{
+			name:       "invalid status",
+			aggregator: NewStatefulSetAggregator(),
+			syncTargetStatuses: map[string]string{
+				"us-west1": `["garbage"]`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstreamObj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			if tt.existingStatus != "" {
+				upstreamObj.Object["status"] = fromJSON(t, tt.existingStatus)
+			}
+			syncTargetStatuses := map[string]interface{}{}
+			for syncTarget, status := range tt.syncTargetStatuses {
+				syncTargetStatuses[syncTarget] = fromJSON(t, status)
+			}
+
+			got, err := tt.aggregator.AggregateStatus(upstreamObj, syncTargetStatuses)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, fromJSON(t, tt.want), got)
+		})
+	}
+}
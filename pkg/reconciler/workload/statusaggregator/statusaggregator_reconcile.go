@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusaggregator
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+// reconcile sets the status of the resource to the aggregate of the statuses reported by the
+// SyncTargets it is synced to, in the experimental.status.workload.kcp.dev/<sync-target-name> annotations.
+// Resources whose syncers do not report their status in annotations are left untouched. This controller
+// is the only writer of the status of the other resources, which the syncers never set directly.
+func (c *Controller) reconcile(ctx context.Context, lclusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+	aggregator, found := c.aggregators[gvr]
+	if !found {
+		return nil
+	}
+
+	klog.V(4).Infof("Aggregating status of GVR %q %s|%s/%s", gvr.String(), lclusterName, obj.GetNamespace(), obj.GetName())
+
+	targets := syncTargets(obj)
+	if len(targets) == 0 {
+		return nil
+	}
+
+	statuses, err := shared.SyncTargetStatuses(obj)
+	if err != nil {
+		klog.Errorf("Cannot aggregate status of GVR %q %s|%s/%s: %v", gvr.String(), lclusterName, obj.GetNamespace(), obj.GetName(), err)
+		return nil // nothing we can do until the syncers report a valid status
+	}
+	if len(statuses) == 0 {
+		return nil
+	}
+
+	syncTargetStatuses := make(map[string]interface{}, len(targets))
+	for _, target := range targets.List() {
+		syncTargetStatuses[target] = statuses[target]
+	}
+
+	aggregated, err := aggregator.AggregateStatus(obj, syncTargetStatuses)
+	if err != nil {
+		klog.Errorf("Cannot aggregate status of GVR %q %s|%s/%s: %v", gvr.String(), lclusterName, obj.GetNamespace(), obj.GetName(), err)
+		return nil // nothing we can do until the syncers report a valid status
+	}
+
+	if existing, found := obj.Object["status"]; found && equality.Semantic.DeepEqual(existing, aggregated) {
+		return nil
+	}
+	obj.Object["status"] = aggregated
+
+	klog.V(2).Infof("Updating aggregated status of GVR %q %s|%s/%s", gvr.String(), lclusterName, obj.GetNamespace(), obj.GetName())
+	_, err = c.dynClusterClient.Resource(gvr).Namespace(obj.GetNamespace()).
+		UpdateStatus(logicalcluster.WithCluster(ctx, lclusterName), obj, metav1.UpdateOptions{})
+	return err
+}
+
+// syncTargets returns the names of the SyncTargets the object is synced to, and not being removed from.
+func syncTargets(obj *unstructured.Unstructured) sets.String {
+	targets := sets.NewString()
+	for k, v := range obj.GetLabels() {
+		if strings.HasPrefix(k, workloadv1alpha1.ClusterResourceStateLabelPrefix) && v == string(workloadv1alpha1.ResourceStateSync) {
+			targets.Insert(strings.TrimPrefix(k, workloadv1alpha1.ClusterResourceStateLabelPrefix))
+		}
+	}
+	for k := range obj.GetAnnotations() {
+		if strings.HasPrefix(k, workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix) {
+			targets.Delete(strings.TrimPrefix(k, workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix))
+		}
+	}
+	return targets
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusaggregator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/json"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+func deployment(labels, annotations map[string]string, status string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	obj.SetNamespace("test")
+	obj.SetName("theDeployment")
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+	if status != "" {
+		var s interface{}
+		if err := json.Unmarshal([]byte(status), &s); err != nil {
+			panic(err)
+		}
+		obj.Object["status"] = s
+	}
+	return obj
+}
+
+func TestReconcile(t *testing.T) {
+	deploymentsGVR := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+	tests := []struct {
+		name       string
+		obj        *unstructured.Unstructured
+		wantStatus string // empty means no update
+	}{
+		{
+			name: "not synced",
+			obj:  deployment(nil, nil, ""),
+		},
+		{
+			name: "synced without status annotations",
+			obj: deployment(map[string]string{
+				"state.workload.kcp.dev/us-west1": "Sync",
+			}, nil, `{"replicas":2}`),
+		},
+		{
+			name: "synced to two SyncTargets",
+			obj: deployment(map[string]string{
+				"state.workload.kcp.dev/us-west1": "Sync",
+				"state.workload.kcp.dev/us-east1": "Sync",
+			}, map[string]string{
+				"experimental.status.workload.kcp.dev/us-west1": `{"replicas":2}`,
+				"experimental.status.workload.kcp.dev/us-east1": `{"replicas":3}`,
+			}, ""),
+			wantStatus: `{"replicas":5}`,
+		},
+		{
+			name: "status of a SyncTarget being removed is ignored",
+			obj: deployment(map[string]string{
+				"state.workload.kcp.dev/us-west1": "Sync",
+				"state.workload.kcp.dev/us-east1": "Sync",
+			}, map[string]string{
+				"experimental.status.workload.kcp.dev/us-west1": `{"replicas":2}`,
+				"experimental.status.workload.kcp.dev/us-east1": `{"replicas":3}`,
+				"deletion.internal.workload.kcp.dev/us-east1":   "2022-08-01T10:00:00Z",
+			}, `{"replicas":5}`),
+			wantStatus: `{"replicas":2}`,
+		},
+		{
+			name: "observedGeneration is kept",
+			obj: deployment(map[string]string{
+				"state.workload.kcp.dev/us-west1": "Sync",
+				"state.workload.kcp.dev/us-east1": "Sync",
+			}, map[string]string{
+				"experimental.status.workload.kcp.dev/us-west1": `{"replicas":2}`,
+				"experimental.status.workload.kcp.dev/us-east1": `{"replicas":3}`,
+			}, `{"observedGeneration":2,"replicas":4}`),
+			wantStatus: `{"observedGeneration":2,"replicas":5}`,
+		},
+		{
+			name: "aggregated status is up to date",
+			obj: deployment(map[string]string{
+				"state.workload.kcp.dev/us-west1": "Sync",
+				"state.workload.kcp.dev/us-east1": "Sync",
+			}, map[string]string{
+				"experimental.status.workload.kcp.dev/us-west1": `{"replicas":2}`,
+				"experimental.status.workload.kcp.dev/us-east1": `{"replicas":3}`,
+			}, `{"replicas":5}`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{deploymentsGVR: "DeploymentList"}, tt.obj.DeepCopy())
+			c := &Controller{
+				dynClusterClient: client,
+				aggregators: map[schema.GroupVersionResource]shared.StatusAggregator{
+					deploymentsGVR: NewDeploymentAggregator(),
+				},
+			}
+
+			err := c.reconcile(context.Background(), logicalcluster.New("root:org:ws"), deploymentsGVR, tt.obj)
+			require.NoError(t, err)
+
+			var updates []clienttesting.UpdateAction
+			for _, action := range client.Actions() {
+				if update, ok := action.(clienttesting.UpdateAction); ok {
+					updates = append(updates, update)
+				}
+			}
+			if tt.wantStatus == "" {
+				require.Empty(t, updates)
+				return
+			}
+			require.Len(t, updates, 1)
+			require.Equal(t, "status", updates[0].GetSubresource())
+			updated := updates[0].GetObject().(*unstructured.Unstructured)
+			require.Equal(t, deployment(nil, nil, tt.wantStatus).Object["status"], updated.Object["status"])
+		})
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenrevocation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	kubernetesclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+const controllerName = "kcp-workload-syncer-token-revocation"
+
+// NewController returns a new controller revoking the previous syncer tokens marked by
+// kubectl kcp workload rotate-credentials, once their overlap has elapsed.
+func NewController(
+	kubeClusterClient kubernetesclient.Interface,
+	secretInformer coreinformers.SecretInformer,
+) *controller {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+
+	c := &controller{
+		queue: queue,
+		enqueueAfter: func(key string, duration time.Duration) {
+			queue.AddAfter(key, duration)
+		},
+
+		kubeClusterClient: kubeClusterClient,
+		secretIndexer:     secretInformer.Informer().GetIndexer(),
+		now:               time.Now,
+	}
+
+	secretInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: isMarkedForRevocation,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.enqueueSecret,
+			UpdateFunc: func(_, obj interface{}) { c.enqueueSecret(obj) },
+		},
+	})
+
+	return c
+}
+
+// controller deletes the service account token Secrets having the workload.kcp.dev/revoke-token-after
+// annotation once its time has passed, which revokes their token.
+type controller struct {
+	queue        workqueue.RateLimitingInterface
+	enqueueAfter func(key string, duration time.Duration)
+
+	kubeClusterClient kubernetesclient.Interface
+	secretIndexer     cache.Indexer
+
+	now func() time.Time
+}
+
+// isMarkedForRevocation returns true if the object is a service account token Secret marked for revocation.
+func isMarkedForRevocation(obj interface{}) bool {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return false
+	}
+	_, found := secret.Annotations[workloadv1alpha1.RevokeTokenAfterAnnotation]
+	return found && secret.Type == corev1.SecretTypeServiceAccountToken
+}
+
+func (c *controller) enqueueSecret(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	klog.V(4).Infof("Queueing Secret %q", key)
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	obj, exists, err := c.secretIndexer.GetByKey(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil // object deleted before we handled it
+	}
+	secret := obj.(*corev1.Secret)
+	if !isMarkedForRevocation(secret) || secret.DeletionTimestamp != nil {
+		return nil
+	}
+	clusterName := logicalcluster.From(secret)
+
+	value := secret.Annotations[workloadv1alpha1.RevokeTokenAfterAnnotation]
+	revokeAfter, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		// The token was meant to be revoked, an unreadable time must not keep it valid.
+		klog.Warningf("Invalid %s annotation %q on Secret %s|%s/%s, revoking its token: %v", workloadv1alpha1.RevokeTokenAfterAnnotation, value, clusterName, secret.Namespace, secret.Name, err)
+	} else if remaining := revokeAfter.Sub(c.now()); remaining > 0 {
+		klog.V(4).Infof("Token of Secret %s|%s/%s to be revoked in %s", clusterName, secret.Namespace, secret.Name, remaining)
+		c.enqueueAfter(key, remaining)
+		return nil
+	}
+
+	klog.Infof("Revoking the token of Secret %s|%s/%s", clusterName, secret.Namespace, secret.Name)
+	uid := secret.UID
+	err = c.kubeClusterClient.CoreV1().Secrets(secret.Namespace).Delete(logicalcluster.WithCluster(ctx, clusterName), secret.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &uid},
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
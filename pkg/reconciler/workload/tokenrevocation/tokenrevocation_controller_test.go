@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenrevocation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+func TestProcess(t *testing.T) {
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		secretType  corev1.SecretType
+		annotations map[string]string
+
+		wantRevoked      bool
+		wantRequeueAfter time.Duration
+	}{
+		"token revoked once the overlap has elapsed": {
+			secretType:  corev1.SecretTypeServiceAccountToken,
+			annotations: map[string]string{workloadv1alpha1.RevokeTokenAfterAnnotation: "2022-08-01T09:55:00Z"},
+			wantRevoked: true,
+		},
+		"token kept during the overlap": {
+			secretType:       corev1.SecretTypeServiceAccountToken,
+			annotations:      map[string]string{workloadv1alpha1.RevokeTokenAfterAnnotation: "2022-08-01T10:05:00Z"},
+			wantRequeueAfter: 5 * time.Minute,
+		},
+		"token with an invalid revocation time revoked": {
+			secretType:  corev1.SecretTypeServiceAccountToken,
+			annotations: map[string]string{workloadv1alpha1.RevokeTokenAfterAnnotation: "tomorrow"},
+			wantRevoked: true,
+		},
+		"token not marked for revocation kept": {
+			secretType: corev1.SecretTypeServiceAccountToken,
+		},
+		"other Secret kept": {
+			secretType:  corev1.SecretTypeOpaque,
+			annotations: map[string]string{workloadv1alpha1.RevokeTokenAfterAnnotation: "2022-08-01T09:55:00Z"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "default",
+					Name:        "kcp-syncer-us-west1-1234-token-abcde",
+					Annotations: tc.annotations,
+				},
+				Type: tc.secretType,
+			}
+			kubeClient := kubefake.NewSimpleClientset(secret)
+			secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			require.NoError(t, secretIndexer.Add(secret))
+
+			var requeueAfter time.Duration
+			c := &controller{
+				enqueueAfter:      func(_ string, duration time.Duration) { requeueAfter = duration },
+				kubeClusterClient: kubeClient,
+				secretIndexer:     secretIndexer,
+				now:               func() time.Time { return now },
+			}
+
+			key, err := cache.MetaNamespaceKeyFunc(secret)
+			require.NoError(t, err)
+			require.NoError(t, c.process(context.Background(), key))
+			require.Equal(t, tc.wantRequeueAfter, requeueAfter)
+
+			_, err = kubeClient.CoreV1().Secrets("default").Get(context.Background(), secret.Name, metav1.GetOptions{})
+			if tc.wantRevoked {
+				require.True(t, apierrors.IsNotFound(err), "expected the Secret to be deleted, got %v", err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -68,10 +68,13 @@ import (
 	workloadsapiexport "github.com/kcp-dev/kcp/pkg/reconciler/workload/apiexport"
 	workloadsapiexportcreate "github.com/kcp-dev/kcp/pkg/reconciler/workload/apiexportcreate"
 	"github.com/kcp-dev/kcp/pkg/reconciler/workload/defaultplacement"
+	workloaddrain "github.com/kcp-dev/kcp/pkg/reconciler/workload/drain"
 	"github.com/kcp-dev/kcp/pkg/reconciler/workload/heartbeat"
 	workloadnamespace "github.com/kcp-dev/kcp/pkg/reconciler/workload/namespace"
 	workloadplacement "github.com/kcp-dev/kcp/pkg/reconciler/workload/placement"
 	workloadresource "github.com/kcp-dev/kcp/pkg/reconciler/workload/resource"
+	workloadstatusaggregator "github.com/kcp-dev/kcp/pkg/reconciler/workload/statusaggregator"
+	"github.com/kcp-dev/kcp/pkg/reconciler/workload/tokenrevocation"
 	virtualworkspaceurlscontroller "github.com/kcp-dev/kcp/pkg/reconciler/workload/virtualworkspaceurls"
 )
 
@@ -344,6 +347,34 @@ func (s *Server) installWorkloadResourceScheduler(ctx context.Context, config *r
 	})
 }
 
+func (s *Server) installWorkloadStatusAggregator(ctx context.Context, config *rest.Config, ddsif *informer.DynamicDiscoverySharedInformerFactory) error {
+	config = kcpclienthelper.NewClusterConfig(rest.AddUserAgent(rest.CopyConfig(config), "kcp-workload-status-aggregator"))
+	dynamicClusterClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	statusAggregator, err := workloadstatusaggregator.NewController(
+		dynamicClusterClient,
+		ddsif,
+		workloadstatusaggregator.DefaultAggregators()...,
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.AddPostStartHook("kcp-install-workload-status-aggregator", func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook kcp-install-workload-status-aggregator: %v", err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go statusAggregator.Start(ctx, 2)
+		return nil
+	})
+}
+
 func (s *Server) installWorkspaceScheduler(ctx context.Context, config *rest.Config) error {
 	config = kcpclienthelper.NewClusterConfig(rest.AddUserAgent(rest.CopyConfig(config), "kcp-workspace-scheduler"))
 
@@ -525,10 +556,17 @@ func (s *Server) installSyncTargetHeartbeatController(ctx context.Context, confi
 		return err
 	}
 
+	kubeClusterClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
 	c, err := heartbeat.NewController(
 		kcpClusterClient,
+		kubeClusterClient,
 		s.KcpSharedInformerFactory.Workload().V1alpha1().SyncTargets(),
 		s.KcpSharedInformerFactory.Apiresource().V1alpha1().APIResourceImports(),
+		s.Options.Controllers.SyncTargetHeartbeat.HeartbeatInterval,
 		s.Options.Controllers.SyncTargetHeartbeat.HeartbeatThreshold,
 	)
 	if err != nil {
@@ -548,6 +586,32 @@ func (s *Server) installSyncTargetHeartbeatController(ctx context.Context, confi
 	})
 }
 
+func (s *Server) installSyncerTokenRevocationController(ctx context.Context, config *rest.Config) error {
+	controllerName := "kcp-workload-syncer-token-revocation"
+	config = kcpclienthelper.NewClusterConfig(rest.AddUserAgent(rest.CopyConfig(config), controllerName))
+	kubeClusterClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c := tokenrevocation.NewController(
+		kubeClusterClient,
+		s.KubeSharedInformerFactory.Core().V1().Secrets(),
+	)
+
+	return s.AddPostStartHook(controllerName, func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook %s: %v", controllerName, err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 2)
+
+		return nil
+	})
+}
+
 func (s *Server) installAPIBindingController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer, ddsif *informer.DynamicDiscoverySharedInformerFactory) error {
 	config = kcpclienthelper.NewClusterConfig(rest.AddUserAgent(rest.CopyConfig(config), "kcp-apibinding-controller"))
 
@@ -746,6 +810,9 @@ func (s *Server) installWorkloadNamespaceScheduler(ctx context.Context, config *
 		kubeClusterClient,
 		s.KubeSharedInformerFactory.Core().V1().Namespaces(),
 		s.KcpSharedInformerFactory.Scheduling().V1alpha1().Placements(),
+		s.KcpSharedInformerFactory.Workload().V1alpha1().SyncTargets(),
+		s.DynamicDiscoverySharedInformerFactory,
+		s.Options.Controllers.WorkloadNamespace.RemovalGracePeriod,
 	)
 	if err != nil {
 		return err
@@ -799,6 +866,42 @@ func (s *Server) installWorkloadPlacementScheduler(ctx context.Context, config *
 	})
 }
 
+func (s *Server) installWorkloadDrainController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	controllerName := "kcp-workload-drain"
+	config = kcpclienthelper.NewClusterConfig(rest.AddUserAgent(rest.CopyConfig(config), controllerName))
+	kcpClusterClient, err := kcpclient.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	kubeClusterClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := workloaddrain.NewController(
+		kcpClusterClient,
+		kubeClusterClient,
+		s.KcpSharedInformerFactory.Workload().V1alpha1().SyncTargets(),
+		s.KubeSharedInformerFactory.Core().V1().Namespaces(),
+		s.KcpSharedInformerFactory.Scheduling().V1alpha1().Placements(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return server.AddPostStartHook(controllerName, func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook %s: %v", controllerName, err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 2)
+
+		return nil
+	})
+}
+
 func (s *Server) installSchedulingPlacementController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
 	controllerName := "kcp-scheduling-placement-controller"
 	config = kcpclienthelper.NewClusterConfig(rest.AddUserAgent(rest.CopyConfig(config), controllerName))
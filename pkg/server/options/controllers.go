@@ -31,6 +31,7 @@ import (
 
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apiresource"
 	"github.com/kcp-dev/kcp/pkg/reconciler/workload/heartbeat"
+	workloadnamespace "github.com/kcp-dev/kcp/pkg/reconciler/workload/namespace"
 )
 
 type Controllers struct {
@@ -38,11 +39,13 @@ type Controllers struct {
 	IndividuallyEnabled []string
 	ApiResource         ApiResourceController
 	SyncTargetHeartbeat SyncTargetHeartbeatController
+	WorkloadNamespace   WorkloadNamespaceController
 	SAController        kcmoptions.SAControllerOptions
 }
 
 type ApiResourceController = apiresource.Options
 type SyncTargetHeartbeatController = heartbeat.Options
+type WorkloadNamespaceController = workloadnamespace.Options
 
 var kcmDefaults *kcmoptions.KubeControllerManagerOptions
 
@@ -61,6 +64,7 @@ func NewControllers() *Controllers {
 
 		ApiResource:         *apiresource.DefaultOptions(),
 		SyncTargetHeartbeat: *heartbeat.DefaultOptions(),
+		WorkloadNamespace:   *workloadnamespace.DefaultOptions(),
 		SAController:        *kcmDefaults.SAController,
 	}
 }
@@ -73,6 +77,7 @@ func (c *Controllers) AddFlags(fs *pflag.FlagSet) {
 
 	apiresource.BindOptions(&c.ApiResource, fs)
 	heartbeat.BindOptions(&c.SyncTargetHeartbeat, fs)
+	workloadnamespace.BindOptions(&c.WorkloadNamespace, fs)
 
 	c.SAController.AddFlags(fs)
 }
@@ -112,6 +117,9 @@ func (c *Controllers) Validate() []error {
 	if err := c.SyncTargetHeartbeat.Validate(); err != nil {
 		errs = append(errs, err)
 	}
+	if err := c.WorkloadNamespace.Validate(); err != nil {
+		errs = append(errs, err)
+	}
 	if saErrs := c.SAController.Validate(); saErrs != nil {
 		errs = append(errs, saErrs...)
 	}
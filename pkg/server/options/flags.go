@@ -173,7 +173,9 @@ var (
 		"run-controllers",                        // Run the controllers in-process
 		"run-virtual-workspaces",                 // Run the virtual workspaces apiservers in-process
 		"unsupported-run-individual-controllers", // Run individual controllers in-process. The controller names can change at any time.
+		"sync-target-heartbeat-interval",         // Interval at which the syncers heartbeat, for the sync targets not setting their own
 		"sync-target-heartbeat-threshold",        // Amount of time to wait for a successful heartbeat before marking the cluster as not ready.
+		"workload-removal-grace-period",          // Default amount of time a namespace and its resources are kept on a sync target that is not selected anymore, for placements not setting removalGracePeriod
 
 		// generic flags
 		"cors-allowed-origins",                 // List of allowed origins for CORS, comma separated.  An allowed origin can be a regular expression to support subdomain matching. If this list is empty CORS will not be enabled.
@@ -271,6 +271,9 @@ func (s *Server) Run(ctx context.Context) error {
 		if err := s.installSyncTargetHeartbeatController(ctx, controllerConfig); err != nil {
 			return err
 		}
+		if err := s.installSyncerTokenRevocationController(ctx, controllerConfig); err != nil {
+			return err
+		}
 		if err := s.installVirtualWorkspaceURLsController(ctx, controllerConfig, delegationChainHead); err != nil {
 			return err
 		}
@@ -297,6 +300,12 @@ func (s *Server) Run(ctx context.Context) error {
 		}
 	}
 
+	if s.Options.Controllers.EnableAll || enabled.Has("status-aggregator") {
+		if err := s.installWorkloadStatusAggregator(ctx, controllerConfig, s.DynamicDiscoverySharedInformerFactory); err != nil {
+			return err
+		}
+	}
+
 	if s.Options.Controllers.EnableAll || enabled.Has("apibinding") {
 		if err := s.installAPIBindingController(ctx, controllerConfig, delegationChainHead, s.DynamicDiscoverySharedInformerFactory); err != nil {
 			return err
@@ -317,6 +326,9 @@ func (s *Server) Run(ctx context.Context) error {
 			if err := s.installWorkloadPlacementScheduler(ctx, controllerConfig, delegationChainHead); err != nil {
 				return err
 			}
+			if err := s.installWorkloadDrainController(ctx, controllerConfig, delegationChainHead); err != nil {
+				return err
+			}
 			if err := s.installSchedulingLocationStatusController(ctx, controllerConfig, delegationChainHead); err != nil {
 				return err
 			}
@@ -20,18 +20,28 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
 	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
@@ -42,6 +52,16 @@ import (
 	clusterctl "github.com/kcp-dev/kcp/pkg/reconciler/workload/basecontroller"
 )
 
+const (
+	apiImporterControllerName = "kcp-workload-api-importer"
+
+	// The failed imports are retried with an exponential backoff, jittered so that the syncers failing at the same
+	// time, e.g. during an outage of kcp, don't retry in lockstep.
+	apiImportRetryBaseDelay = 1 * time.Second
+	apiImportRetryMaxDelay  = 5 * time.Minute
+	apiImportRetryJitter    = 0.5
+)
+
 var clusterKind = reflect.TypeOf(workloadv1alpha1.SyncTarget{}).Name()
 
 func clusterAsOwnerReference(obj *workloadv1alpha1.SyncTarget, controller bool) metav1.OwnerReference {
@@ -60,7 +80,7 @@ func NewAPIImporter(
 	logicalClusterName logicalcluster.Name,
 	location string,
 ) (*APIImporter, error) {
-	agent := fmt.Sprintf("kcp-workload-api-importer-%s-%s", logicalClusterName, location)
+	agent := fmt.Sprintf("%s-%s-%s", apiImporterControllerName, logicalClusterName, location)
 	upstreamConfig = rest.AddUserAgent(rest.CopyConfig(upstreamConfig), agent)
 	downstreamConfig = rest.AddUserAgent(rest.CopyConfig(downstreamConfig), agent)
 
@@ -72,7 +92,8 @@ func NewAPIImporter(
 	kcpClient := kcpClusterClient.Cluster(logicalClusterName)
 	kcpInformerFactory := kcpexternalversions.NewSharedInformerFactoryWithOptions(kcpClient, resyncPeriod)
 	clusterIndexer := kcpInformerFactory.Workload().V1alpha1().SyncTargets().Informer().GetIndexer()
-	importIndexer := kcpInformerFactory.Apiresource().V1alpha1().APIResourceImports().Informer().GetIndexer()
+	importInformer := kcpInformerFactory.Apiresource().V1alpha1().APIResourceImports().Informer()
+	importIndexer := importInformer.GetIndexer()
 
 	indexers := map[string]cache.IndexFunc{
 		clusterctl.GVRForLocationInLogicalClusterIndexName: func(obj interface{}) ([]string, error) {
@@ -103,45 +124,123 @@ func NewAPIImporter(
 	if err != nil {
 		return nil, err
 	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(downstreamConfig)
+	if err != nil {
+		return nil, err
+	}
+	crdClient, err := apiextensionsclient.NewForConfig(downstreamConfig)
+	if err != nil {
+		return nil, err
+	}
+	crdInformerFactory := apiextensionsinformers.NewSharedInformerFactory(crdClient, resyncPeriod)
 
-	return &APIImporter{
+	i := &APIImporter{
 		kcpInformerFactory:       kcpInformerFactory,
 		kcpClusterClient:         kcpClusterClient,
 		resourcesToSync:          resourcesToSync,
 		apiresourceImportIndexer: importIndexer,
 		clusterIndexer:           clusterIndexer,
+		crdInformerFactory:       crdInformerFactory,
+		discoveryClient:          discoveryClient,
 
 		location:           location,
 		logicalClusterName: logicalClusterName,
 		schemaPuller:       schemaPuller,
-	}, nil
+
+		queue: workqueue.NewNamedRateLimitingQueue(
+			&jitteredRateLimiter{
+				RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(apiImportRetryBaseDelay, apiImportRetryMaxDelay),
+				maxFactor:   apiImportRetryJitter,
+			},
+			apiImporterControllerName,
+		),
+		discoveredResources: map[string]string{},
+	}
+
+	crdInformerFactory.Apiextensions().V1().CustomResourceDefinitions().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { i.enqueueCRD(obj) },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldCRD, ok := oldObj.(*apiextensionsv1.CustomResourceDefinition)
+			if !ok {
+				return
+			}
+			newCRD, ok := newObj.(*apiextensionsv1.CustomResourceDefinition)
+			if !ok {
+				return
+			}
+			// Only the spec is imported: skip the status updates and the resyncs.
+			if equality.Semantic.DeepEqual(oldCRD.Spec, newCRD.Spec) {
+				return
+			}
+			i.enqueueCRD(newObj)
+		},
+		DeleteFunc: func(obj interface{}) { i.enqueueCRD(obj) },
+	})
+
+	// Import again the resources whose APIResourceImport is deleted from under the syncer.
+	importInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			apiResourceImport, ok := obj.(*apiresourcev1alpha1.APIResourceImport)
+			if !ok || apiResourceImport.Spec.Location != i.location {
+				return
+			}
+			i.enqueueGroupResource(schema.GroupResource{
+				Group:    apiResourceImport.Spec.GroupVersion.Group,
+				Resource: apiResourceImport.Spec.Plural,
+			})
+		},
+	})
+
+	return i, nil
 }
 
+// APIImporter imports the schemas of the resources to sync from the downstream cluster, as APIResourceImports
+// of the SyncTarget in kcp.
+//
+// The resources are imported again when their CRD changes in the downstream cluster, or when their discovery
+// changes, which is checked at the interval passed to Start. Only the changed resources are imported, one at a
+// time, and failed imports are retried with a jittered exponential backoff.
 type APIImporter struct {
 	kcpInformerFactory       kcpexternalversions.SharedInformerFactory
 	kcpClusterClient         *kcpclient.Cluster
 	resourcesToSync          []string
 	apiresourceImportIndexer cache.Indexer
 	clusterIndexer           cache.Indexer
+	crdInformerFactory       apiextensionsinformers.SharedInformerFactory
+	discoveryClient          discovery.DiscoveryInterface
 
 	location           string
 	logicalClusterName logicalcluster.Name
 	schemaPuller       crdpuller.SchemaPuller
-	SyncedGVRs         map[string]metav1.GroupVersionResource
+
+	// queue is keyed by the entries of resourcesToSync.
+	queue workqueue.RateLimitingInterface
+
+	discoveredResourcesLock sync.Mutex
+	// discoveredResources maps the entries of resourcesToSync to a digest of their discovery.
+	discoveredResources map[string]string
 }
 
-func (i *APIImporter) Start(ctx context.Context, pollInterval time.Duration) {
+// Start imports the resources to sync, then keeps them up to date until ctx is done. The discovery of the
+// downstream cluster is checked for changes every discoveryInterval, with jitter.
+func (i *APIImporter) Start(ctx context.Context, discoveryInterval time.Duration) {
 	defer runtime.HandleCrash()
+	defer i.queue.ShutDown()
 
 	i.kcpInformerFactory.Start(ctx.Done())
+	i.crdInformerFactory.Start(ctx.Done())
 	i.kcpInformerFactory.WaitForCacheSync(ctx.Done())
+	i.crdInformerFactory.WaitForCacheSync(ctx.Done())
 
 	klog.Infof("Starting API Importer for location %s in cluster %s", i.location, i.logicalClusterName)
 
 	clusterContext := request.WithCluster(ctx, request.Cluster{Name: i.logicalClusterName})
-	go wait.UntilWithContext(clusterContext, func(innerCtx context.Context) {
-		i.ImportAPIs(innerCtx)
-	}, pollInterval)
+	// The first discovery check finds all the resources changed, which triggers the initial import.
+	go wait.JitterUntilWithContext(clusterContext, i.checkDiscovery, discoveryInterval, 0.5, true)
+	go wait.UntilWithContext(clusterContext, i.startWorker, time.Second)
 
 	<-ctx.Done()
 	i.Stop()
@@ -166,148 +265,285 @@ func (i *APIImporter) Stop() {
 	}
 }
 
-func (i *APIImporter) ImportAPIs(ctx context.Context) {
-	klog.Infof("Importing APIs from location %s in logical cluster %s (resources=%v)", i.location, i.logicalClusterName, i.resourcesToSync)
-	crds, err := i.schemaPuller.PullCRDs(ctx, i.resourcesToSync...)
-	if err != nil {
-		klog.Errorf("error pulling CRDs: %v", err)
+func (i *APIImporter) enqueueCRD(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok {
 		return
 	}
+	i.enqueueGroupResource(schema.GroupResource{Group: crd.Spec.Group, Resource: crd.Spec.Names.Plural})
+}
 
-	gvrsToSync := map[string]metav1.GroupVersionResource{}
-	for groupResource, pulledCrd := range crds {
-		crdVersion := pulledCrd.Spec.Versions[0]
-		gvr := metav1.GroupVersionResource{
-			Group:    pulledCrd.Spec.Group,
-			Version:  crdVersion.Name,
-			Resource: groupResource.Resource,
+func (i *APIImporter) enqueueGroupResource(gr schema.GroupResource) {
+	for _, resourceToSync := range i.resourcesToSync {
+		if resourceMatches(resourceToSync, gr) {
+			klog.V(2).Infof("Queueing the import of resource %s for location %s in logical cluster %s", resourceToSync, i.location, i.logicalClusterName)
+			i.queue.Add(resourceToSync)
+		}
+	}
+}
+
+// checkDiscovery enqueues the resources to sync whose discovery changed since the previous check.
+func (i *APIImporter) checkDiscovery(ctx context.Context) {
+	apiResourceLists, err := i.discoveryClient.ServerPreferredResources()
+	if err != nil && len(apiResourceLists) == 0 {
+		klog.Errorf("error checking the discovery of location %s in logical cluster %s: %v", i.location, i.logicalClusterName, err)
+		return
+	}
+	// Some groups might have failed discovery: only their resources are checked again next time.
+	discovered := discoveryDigests(apiResourceLists, i.resourcesToSync)
+	failedGroups := sets.NewString()
+	if groupFailed, ok := err.(*discovery.ErrGroupDiscoveryFailed); ok {
+		for gv := range groupFailed.Groups {
+			failedGroups.Insert(gv.Group)
+		}
+	}
+
+	i.discoveredResourcesLock.Lock()
+	defer i.discoveredResourcesLock.Unlock()
+
+	for _, resourceToSync := range i.resourcesToSync {
+		if failedGroups.Has(schema.ParseGroupResource(resourceToSync).Group) {
+			continue
+		}
+		// The resources not found get an empty digest, so that the first check imports all the resources.
+		digest := discovered[resourceToSync]
+		if previous, seen := i.discoveredResources[resourceToSync]; seen && previous == digest {
+			continue
 		}
+		klog.V(2).Infof("Discovery of resource %s changed for location %s in logical cluster %s", resourceToSync, i.location, i.logicalClusterName)
+		i.discoveredResources[resourceToSync] = digest
+		i.queue.Add(resourceToSync)
+	}
+}
+
+func (i *APIImporter) startWorker(ctx context.Context) {
+	for i.processNextWorkItem(ctx) {
+	}
+}
+
+func (i *APIImporter) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := i.queue.Get()
+	if quit {
+		return false
+	}
+	resourceToSync := k.(string)
 
-		objs, err := i.apiresourceImportIndexer.ByIndex(
-			clusterctl.GVRForLocationInLogicalClusterIndexName,
-			clusterctl.GetGVRForLocationInLogicalClusterIndexKey(i.location, i.logicalClusterName, gvr),
-		)
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer i.queue.Done(k)
+
+	if err := i.importAPI(ctx, resourceToSync); err != nil {
+		runtime.HandleError(fmt.Errorf("failed to import resource %s for location %s in logical cluster %s, retrying: %w", resourceToSync, i.location, i.logicalClusterName, err))
+		i.queue.AddRateLimited(k)
+		return true
+	}
+	i.queue.Forget(k)
+	return true
+}
+
+// importAPI creates or updates the APIResourceImport of the given resource to sync, and deletes its
+// APIResourceImports for other versions, or all of them when the resource is not found downstream anymore.
+func (i *APIImporter) importAPI(ctx context.Context, resourceToSync string) error {
+	klog.Infof("Importing resource %s from location %s in logical cluster %s", resourceToSync, i.location, i.logicalClusterName)
+	crds, err := i.schemaPuller.PullCRDs(ctx, resourceToSync)
+	if err != nil {
+		return fmt.Errorf("error pulling CRDs: %w", err)
+	}
+
+	var errs []error
+	importedGVRs := sets.NewString()
+	for groupResource, pulledCrd := range crds {
+		gvr, err := i.createOrUpdateAPIResourceImport(ctx, groupResource, pulledCrd)
 		if err != nil {
-			klog.Errorf("error pulling CRDs: %v", err)
+			errs = append(errs, err)
 			continue
 		}
-		if len(objs) > 1 {
-			klog.Errorf("There should be only one APIResourceImport of GVR %s for location %s in logical cluster %s, but there was %d", gvr.String(), i.location, i.logicalClusterName, len(objs))
+		importedGVRs.Insert(gvr.String())
+	}
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+
+	objs, err := i.apiresourceImportIndexer.ByIndex(
+		clusterctl.LocationInLogicalClusterIndexName,
+		clusterctl.GetLocationInLogicalClusterIndexKey(i.location, i.logicalClusterName),
+	)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		apiResourceImport := obj.(*apiresourcev1alpha1.APIResourceImport)
+		gr := schema.GroupResource{Group: apiResourceImport.Spec.GroupVersion.Group, Resource: apiResourceImport.Spec.Plural}
+		gvr := apiResourceImport.GVR()
+		if !resourceMatches(resourceToSync, gr) || importedGVRs.Has(gvr.String()) {
 			continue
 		}
-		if len(objs) == 1 {
-			apiResourceImport := objs[0].(*apiresourcev1alpha1.APIResourceImport).DeepCopy()
-			if err := apiResourceImport.Spec.SetSchema(crdVersion.Schema.OpenAPIV3Schema); err != nil {
-				klog.Errorf("Error setting schema: %v", err)
-				continue
-			}
-			klog.Infof("Updating APIResourceImport %s|%s for SyncTarget %s", i.logicalClusterName, apiResourceImport.Name, i.location)
-			if _, err := i.kcpClusterClient.Cluster(i.logicalClusterName).ApiresourceV1alpha1().APIResourceImports().Update(ctx, apiResourceImport, metav1.UpdateOptions{}); err != nil {
-				klog.Errorf("error updating APIResourceImport %s: %v", apiResourceImport.Name, err)
-				continue
-			}
-		} else {
-			apiResourceImportName := gvr.Resource + "." + i.location + "." + gvr.Version + "."
-			if gvr.Group == "" {
-				apiResourceImportName = apiResourceImportName + "core"
-			} else {
-				apiResourceImportName = apiResourceImportName + gvr.Group
-			}
+		klog.Infof("Deleting APIResourceImport %s|%s", i.logicalClusterName, apiResourceImport.Name)
+		if err := i.kcpClusterClient.Cluster(i.logicalClusterName).ApiresourceV1alpha1().APIResourceImports().Delete(ctx, apiResourceImport.Name, metav1.DeleteOptions{}); err != nil {
+			errs = append(errs, fmt.Errorf("error deleting APIResourceImport %s: %w", apiResourceImport.Name, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
 
-			clusterKey, err := cache.MetaNamespaceKeyFunc(&metav1.PartialObjectMetadata{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:                      i.location,
-					ZZZ_DeprecatedClusterName: i.logicalClusterName.String(),
-				},
-			})
-			if err != nil {
-				klog.Errorf("error creating APIResourceImport %s: %v", apiResourceImportName, err)
-				continue
-			}
-			clusterObj, exists, err := i.clusterIndexer.GetByKey(clusterKey)
-			if err != nil {
-				klog.Errorf("error creating APIResourceImport %s: %v", apiResourceImportName, err)
-				continue
-			}
-			if !exists {
-				klog.Errorf("error creating APIResourceImport %s: the cluster object should exist in the index for location %s in logical cluster %s", apiResourceImportName, i.location, i.logicalClusterName)
-				continue
-			}
-			cluster, isCluster := clusterObj.(*workloadv1alpha1.SyncTarget)
-			if !isCluster {
-				klog.Errorf("error creating APIResourceImport %s: the object retrieved from the cluster index for location %s in logical cluster %s should be a cluster object, but is of type: %T", apiResourceImportName, i.location, i.logicalClusterName, clusterObj)
-				continue
-			}
-			groupVersion := apiresourcev1alpha1.GroupVersion{
-				Group:   gvr.Group,
-				Version: gvr.Version,
-			}
-			apiResourceImport := &apiresourcev1alpha1.APIResourceImport{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:                      apiResourceImportName,
-					ZZZ_DeprecatedClusterName: i.logicalClusterName.String(),
-					OwnerReferences: []metav1.OwnerReference{
-						clusterAsOwnerReference(cluster, true),
-					},
-					Annotations: map[string]string{
-						apiresourcev1alpha1.APIVersionAnnotation: groupVersion.APIVersion(),
-					},
-				},
-				Spec: apiresourcev1alpha1.APIResourceImportSpec{
-					Location:             i.location,
-					SchemaUpdateStrategy: apiresourcev1alpha1.UpdateUnpublished,
-					CommonAPIResourceSpec: apiresourcev1alpha1.CommonAPIResourceSpec{
-						GroupVersion: apiresourcev1alpha1.GroupVersion{
-							Group:   gvr.Group,
-							Version: gvr.Version,
-						},
-						Scope:                         pulledCrd.Spec.Scope,
-						CustomResourceDefinitionNames: pulledCrd.Spec.Names,
-						SubResources:                  *(&apiresourcev1alpha1.SubResources{}).ImportFromCRDVersion(&crdVersion),
-						ColumnDefinitions:             *(&apiresourcev1alpha1.ColumnDefinitions{}).ImportFromCRDVersion(&crdVersion),
-					},
-				},
-			}
-			if err := apiResourceImport.Spec.SetSchema(crdVersion.Schema.OpenAPIV3Schema); err != nil {
-				klog.Errorf("Error setting schema: %v", err)
-				continue
-			}
-			if value, found := pulledCrd.Annotations[apiextensionsv1.KubeAPIApprovedAnnotation]; found {
-				apiResourceImport.Annotations[apiextensionsv1.KubeAPIApprovedAnnotation] = value
-			}
+func (i *APIImporter) createOrUpdateAPIResourceImport(ctx context.Context, groupResource schema.GroupResource, pulledCrd *apiextensionsv1.CustomResourceDefinition) (metav1.GroupVersionResource, error) {
+	crdVersion := pulledCrd.Spec.Versions[0]
+	gvr := metav1.GroupVersionResource{
+		Group:    pulledCrd.Spec.Group,
+		Version:  crdVersion.Name,
+		Resource: groupResource.Resource,
+	}
 
-			klog.Infof("Creating APIResourceImport %s|%s", i.logicalClusterName, apiResourceImportName)
-			if _, err := i.kcpClusterClient.Cluster(i.logicalClusterName).ApiresourceV1alpha1().APIResourceImports().Create(ctx, apiResourceImport, metav1.CreateOptions{}); err != nil {
-				klog.Errorf("error creating APIResourceImport %s: %v", apiResourceImport.Name, err)
-				continue
-			}
+	objs, err := i.apiresourceImportIndexer.ByIndex(
+		clusterctl.GVRForLocationInLogicalClusterIndexName,
+		clusterctl.GetGVRForLocationInLogicalClusterIndexKey(i.location, i.logicalClusterName, gvr),
+	)
+	if err != nil {
+		return gvr, err
+	}
+	if len(objs) > 1 {
+		return gvr, fmt.Errorf("there should be only one APIResourceImport of GVR %s for location %s in logical cluster %s, but there was %d", gvr.String(), i.location, i.logicalClusterName, len(objs))
+	}
+	if len(objs) == 1 {
+		existing := objs[0].(*apiresourcev1alpha1.APIResourceImport)
+		apiResourceImport := existing.DeepCopy()
+		if err := apiResourceImport.Spec.SetSchema(crdVersion.Schema.OpenAPIV3Schema); err != nil {
+			return gvr, fmt.Errorf("error setting schema: %w", err)
 		}
-		gvrsToSync[gvr.String()] = gvr
+		if equality.Semantic.DeepEqual(existing.Spec, apiResourceImport.Spec) {
+			klog.V(4).Infof("APIResourceImport %s|%s for SyncTarget %s is up to date", i.logicalClusterName, apiResourceImport.Name, i.location)
+			return gvr, nil
+		}
+		klog.Infof("Updating APIResourceImport %s|%s for SyncTarget %s", i.logicalClusterName, apiResourceImport.Name, i.location)
+		if _, err := i.kcpClusterClient.Cluster(i.logicalClusterName).ApiresourceV1alpha1().APIResourceImports().Update(ctx, apiResourceImport, metav1.UpdateOptions{}); err != nil {
+			return gvr, fmt.Errorf("error updating APIResourceImport %s: %w", apiResourceImport.Name, err)
+		}
+		return gvr, nil
 	}
 
-	gvrsToRemove := sets.StringKeySet(i.SyncedGVRs).Difference(sets.StringKeySet(gvrsToSync))
-	for _, gvrToRemove := range gvrsToRemove.UnsortedList() {
-		gvr := i.SyncedGVRs[gvrToRemove]
-		objs, err := i.apiresourceImportIndexer.ByIndex(
-			clusterctl.GVRForLocationInLogicalClusterIndexName,
-			clusterctl.GetGVRForLocationInLogicalClusterIndexKey(i.location, i.logicalClusterName, gvr),
-		)
-		if err != nil {
-			klog.Errorf("error pulling CRDs: %v", err)
+	apiResourceImportName := gvr.Resource + "." + i.location + "." + gvr.Version + "."
+	if gvr.Group == "" {
+		apiResourceImportName = apiResourceImportName + "core"
+	} else {
+		apiResourceImportName = apiResourceImportName + gvr.Group
+	}
+
+	clusterKey, err := cache.MetaNamespaceKeyFunc(&metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:                      i.location,
+			ZZZ_DeprecatedClusterName: i.logicalClusterName.String(),
+		},
+	})
+	if err != nil {
+		return gvr, fmt.Errorf("error creating APIResourceImport %s: %w", apiResourceImportName, err)
+	}
+	clusterObj, exists, err := i.clusterIndexer.GetByKey(clusterKey)
+	if err != nil {
+		return gvr, fmt.Errorf("error creating APIResourceImport %s: %w", apiResourceImportName, err)
+	}
+	if !exists {
+		return gvr, fmt.Errorf("error creating APIResourceImport %s: the cluster object should exist in the index for location %s in logical cluster %s", apiResourceImportName, i.location, i.logicalClusterName)
+	}
+	cluster, isCluster := clusterObj.(*workloadv1alpha1.SyncTarget)
+	if !isCluster {
+		return gvr, fmt.Errorf("error creating APIResourceImport %s: the object retrieved from the cluster index for location %s in logical cluster %s should be a cluster object, but is of type: %T", apiResourceImportName, i.location, i.logicalClusterName, clusterObj)
+	}
+	groupVersion := apiresourcev1alpha1.GroupVersion{
+		Group:   gvr.Group,
+		Version: gvr.Version,
+	}
+	apiResourceImport := &apiresourcev1alpha1.APIResourceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:                      apiResourceImportName,
+			ZZZ_DeprecatedClusterName: i.logicalClusterName.String(),
+			OwnerReferences: []metav1.OwnerReference{
+				clusterAsOwnerReference(cluster, true),
+			},
+			Annotations: map[string]string{
+				apiresourcev1alpha1.APIVersionAnnotation: groupVersion.APIVersion(),
+			},
+		},
+		Spec: apiresourcev1alpha1.APIResourceImportSpec{
+			Location:             i.location,
+			SchemaUpdateStrategy: apiresourcev1alpha1.UpdateUnpublished,
+			CommonAPIResourceSpec: apiresourcev1alpha1.CommonAPIResourceSpec{
+				GroupVersion: apiresourcev1alpha1.GroupVersion{
+					Group:   gvr.Group,
+					Version: gvr.Version,
+				},
+				Scope:                         pulledCrd.Spec.Scope,
+				CustomResourceDefinitionNames: pulledCrd.Spec.Names,
+				SubResources:                  *(&apiresourcev1alpha1.SubResources{}).ImportFromCRDVersion(&crdVersion),
+				ColumnDefinitions:             *(&apiresourcev1alpha1.ColumnDefinitions{}).ImportFromCRDVersion(&crdVersion),
+			},
+		},
+	}
+	if err := apiResourceImport.Spec.SetSchema(crdVersion.Schema.OpenAPIV3Schema); err != nil {
+		return gvr, fmt.Errorf("error setting schema: %w", err)
+	}
+	if value, found := pulledCrd.Annotations[apiextensionsv1.KubeAPIApprovedAnnotation]; found {
+		apiResourceImport.Annotations[apiextensionsv1.KubeAPIApprovedAnnotation] = value
+	}
+
+	klog.Infof("Creating APIResourceImport %s|%s", i.logicalClusterName, apiResourceImportName)
+	if _, err := i.kcpClusterClient.Cluster(i.logicalClusterName).ApiresourceV1alpha1().APIResourceImports().Create(ctx, apiResourceImport, metav1.CreateOptions{}); err != nil {
+		return gvr, fmt.Errorf("error creating APIResourceImport %s: %w", apiResourceImport.Name, err)
+	}
+	return gvr, nil
+}
+
+// resourceMatches returns whether the resource to sync, as passed to the syncer, designates the given
+// GroupResource. A resource to sync without group, e.g. "services", matches the resource in any group, as it
+// is resolved by the REST mapper when pulled.
+func resourceMatches(resourceToSync string, gr schema.GroupResource) bool {
+	parsed := schema.ParseGroupResource(resourceToSync)
+	if parsed.Resource != gr.Resource {
+		return false
+	}
+	return parsed.Group == "" || parsed.Group == gr.Group
+}
+
+// discoveryDigests returns a digest of the discovery of each resource to sync found in the given preferred
+// resources, which changes when its preferred version, kind, scope, storage version or subresources change.
+func discoveryDigests(apiResourceLists []*metav1.APIResourceList, resourcesToSync []string) map[string]string {
+	parts := map[string][]string{}
+	for _, apiResourceList := range apiResourceLists {
+		if apiResourceList == nil {
 			continue
 		}
-		if len(objs) > 1 {
-			klog.Errorf("There should be only one APIResourceImport of GVR %s for location %s in logical cluster %s, but there was %d", gvr.String(), i.location, i.logicalClusterName, len(objs))
+		gv, err := schema.ParseGroupVersion(apiResourceList.GroupVersion)
+		if err != nil {
 			continue
 		}
-		if len(objs) == 1 {
-			apiResourceImportToRemove := objs[0].(*apiresourcev1alpha1.APIResourceImport)
-			klog.Infof("Deleting APIResourceImport %s|%s", i.logicalClusterName, apiResourceImportToRemove.Name)
-			err := i.kcpClusterClient.Cluster(i.logicalClusterName).ApiresourceV1alpha1().APIResourceImports().Delete(ctx, apiResourceImportToRemove.Name, metav1.DeleteOptions{})
-			if err != nil {
-				klog.Errorf("error deleting APIResourceImport %s: %v", apiResourceImportToRemove.Name, err)
-				continue
+		for _, apiResource := range apiResourceList.APIResources {
+			resource := strings.SplitN(apiResource.Name, "/", 2)[0]
+			for _, resourceToSync := range resourcesToSync {
+				if !resourceMatches(resourceToSync, gv.WithResource(resource).GroupResource()) {
+					continue
+				}
+				parts[resourceToSync] = append(parts[resourceToSync], fmt.Sprintf("%s/%s:%s:%t:%s", gv.String(), apiResource.Name, apiResource.Kind, apiResource.Namespaced, apiResource.StorageVersionHash))
 			}
 		}
 	}
+
+	digests := make(map[string]string, len(parts))
+	for resourceToSync, p := range parts {
+		sort.Strings(p)
+		digests[resourceToSync] = strings.Join(p, ",")
+	}
+	return digests
+}
+
+// jitteredRateLimiter jitters the delays of the wrapped rate limiter, up to maxFactor times longer.
+type jitteredRateLimiter struct {
+	workqueue.RateLimiter
+	maxFactor float64
+}
+
+func (r *jitteredRateLimiter) When(item interface{}) time.Duration {
+	return wait.Jitter(r.RateLimiter.When(item), r.maxFactor)
 }
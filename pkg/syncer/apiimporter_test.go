@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestResourceMatches(t *testing.T) {
+	tests := []struct {
+		resourceToSync string
+		gr             schema.GroupResource
+		want           bool
+	}{
+		{resourceToSync: "deployments.apps", gr: schema.GroupResource{Group: "apps", Resource: "deployments"}, want: true},
+		{resourceToSync: "deployments.apps", gr: schema.GroupResource{Group: "extensions", Resource: "deployments"}, want: false},
+		{resourceToSync: "services", gr: schema.GroupResource{Resource: "services"}, want: true},
+		{resourceToSync: "services", gr: schema.GroupResource{Group: "serving.knative.dev", Resource: "services"}, want: true},
+		{resourceToSync: "services", gr: schema.GroupResource{Resource: "secrets"}, want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.resourceToSync+" "+tc.gr.String(), func(t *testing.T) {
+			require.Equal(t, tc.want, resourceMatches(tc.resourceToSync, tc.gr))
+		})
+	}
+}
+
+type fakePreferredResourcesDiscovery struct {
+	discovery.DiscoveryInterface
+	lists []*metav1.APIResourceList
+}
+
+func (d *fakePreferredResourcesDiscovery) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	return d.lists, nil
+}
+
+func TestCheckDiscovery(t *testing.T) {
+	apps := func(version, storageVersionHash string) *metav1.APIResourceList {
+		return &metav1.APIResourceList{
+			GroupVersion: "apps/" + version,
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true, StorageVersionHash: storageVersionHash},
+				{Name: "deployments/status", Kind: "Deployment", Namespaced: true},
+			},
+		}
+	}
+	core := &metav1.APIResourceList{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "services", Kind: "Service", Namespaced: true, StorageVersionHash: "svc"},
+			{Name: "secrets", Kind: "Secret", Namespaced: true, StorageVersionHash: "secret"},
+		},
+	}
+
+	fakeDiscovery := &fakePreferredResourcesDiscovery{lists: []*metav1.APIResourceList{core, apps("v1", "a")}}
+	importer := &APIImporter{
+		resourcesToSync:     []string{"services", "deployments.apps", "widgets.example.com"},
+		discoveryClient:     fakeDiscovery,
+		queue:               workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		discoveredResources: map[string]string{},
+	}
+	defer importer.queue.ShutDown()
+
+	drain := func() []string {
+		var keys []string
+		for importer.queue.Len() > 0 {
+			key, _ := importer.queue.Get()
+			keys = append(keys, key.(string))
+			importer.queue.Done(key)
+			importer.queue.Forget(key)
+		}
+		return keys
+	}
+
+	importer.checkDiscovery(context.Background())
+	require.Equal(t, []string{"services", "deployments.apps", "widgets.example.com"}, drain(), "all the resources should be imported first")
+
+	importer.checkDiscovery(context.Background())
+	require.Empty(t, drain(), "nothing changed")
+
+	fakeDiscovery.lists = []*metav1.APIResourceList{core, apps("v1", "b")}
+	importer.checkDiscovery(context.Background())
+	require.Equal(t, []string{"deployments.apps"}, drain(), "the storage version of deployments changed")
+
+	fakeDiscovery.lists = []*metav1.APIResourceList{core, apps("v1", "b"), {
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "widgets", Kind: "Widget"}},
+	}}
+	importer.checkDiscovery(context.Background())
+	require.Equal(t, []string{"widgets.example.com"}, drain(), "widgets appeared")
+
+	fakeDiscovery.lists = []*metav1.APIResourceList{core, apps("v1", "b")}
+	importer.checkDiscovery(context.Background())
+	require.Equal(t, []string{"widgets.example.com"}, drain(), "widgets disappeared")
+}
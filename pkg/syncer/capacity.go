@@ -0,0 +1,179 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// capacityReporter computes the capacity and the allocatable resources of the downstream cluster,
+// from its nodes and the resource requests of the pods running on them.
+type capacityReporter struct {
+	nodeLister corelisters.NodeLister
+	podLister  corelisters.PodLister
+
+	start  func(stopCh <-chan struct{})
+	synced []cache.InformerSynced
+}
+
+func newCapacityReporter(kubeClient kubernetes.Interface) *capacityReporter {
+	nodeInformerFactory := informers.NewSharedInformerFactory(kubeClient, resyncPeriod)
+	// terminated pods do not hold resources anymore.
+	podInformerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, resyncPeriod, informers.WithTweakListOptions(
+		func(listOptions *metav1.ListOptions) {
+			listOptions.FieldSelector = fields.AndSelectors(
+				fields.OneTermNotEqualSelector("status.phase", string(corev1.PodSucceeded)),
+				fields.OneTermNotEqualSelector("status.phase", string(corev1.PodFailed)),
+			).String()
+		},
+	))
+	nodeInformer := nodeInformerFactory.Core().V1().Nodes()
+	podInformer := podInformerFactory.Core().V1().Pods()
+
+	return &capacityReporter{
+		nodeLister: nodeInformer.Lister(),
+		podLister:  podInformer.Lister(),
+		start: func(stopCh <-chan struct{}) {
+			nodeInformerFactory.Start(stopCh)
+			podInformerFactory.Start(stopCh)
+		},
+		synced: []cache.InformerSynced{nodeInformer.Informer().HasSynced, podInformer.Informer().HasSynced},
+	}
+}
+
+// Start starts the node and pod informers. They run until ctx is done.
+func (r *capacityReporter) Start(ctx context.Context) {
+	r.start(ctx.Done())
+}
+
+// Resources returns the capacity and the allocatable resources of the downstream cluster. It returns false
+// until the informers have synced. When cordoned, no resource is allocatable.
+func (r *capacityReporter) Resources(cordoned bool) (capacity, allocatable corev1.ResourceList, ok bool) {
+	for _, synced := range r.synced {
+		if !synced() {
+			return nil, nil, false
+		}
+	}
+	nodes, err := r.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, nil, false
+	}
+	pods, err := r.podLister.List(labels.Everything())
+	if err != nil {
+		return nil, nil, false
+	}
+	capacity, allocatable = computeResources(nodes, pods, cordoned)
+	return capacity, allocatable, true
+}
+
+// computeResources sums the capacity of all the nodes, and the allocatable resources of the ready and
+// schedulable nodes minus the requests of the pods running on them. When cordoned, all the allocatable
+// resources are zero.
+func computeResources(nodes []*corev1.Node, pods []*corev1.Pod, cordoned bool) (capacity, allocatable corev1.ResourceList) {
+	podsByNode := map[string][]*corev1.Pod{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+	}
+
+	capacity = corev1.ResourceList{}
+	allocatable = corev1.ResourceList{}
+	for _, node := range nodes {
+		addResources(capacity, node.Status.Capacity)
+
+		free := corev1.ResourceList{}
+		for name, quantity := range node.Status.Allocatable {
+			free[name] = quantity.DeepCopy()
+		}
+		if cordoned || node.Spec.Unschedulable || !isNodeReady(node) {
+			for name := range free {
+				free[name] = *resource.NewQuantity(0, free[name].Format)
+			}
+			addResources(allocatable, free)
+			continue
+		}
+		for _, pod := range podsByNode[node.Name] {
+			requests := podRequests(pod)
+			requests[corev1.ResourcePods] = *resource.NewQuantity(1, resource.DecimalSI)
+			for name, request := range requests {
+				quantity, found := free[name]
+				if !found {
+					continue
+				}
+				quantity.Sub(request)
+				if quantity.Sign() < 0 {
+					quantity = *resource.NewQuantity(0, quantity.Format)
+				}
+				free[name] = quantity
+			}
+		}
+		addResources(allocatable, free)
+	}
+
+	return capacity, allocatable
+}
+
+// podRequests returns the resources requested by a pod: the sum of the requests of its containers, or the
+// largest request of its init containers if higher, plus the pod overhead.
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResources(requests, container.Resources.Requests)
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if current, found := requests[name]; !found || quantity.Cmp(current) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	addResources(requests, pod.Spec.Overhead)
+	return requests
+}
+
+func addResources(total, resources corev1.ResourceList) {
+	for name, quantity := range resources {
+		current, found := total[name]
+		if !found {
+			total[name] = quantity.DeepCopy()
+			continue
+		}
+		current.Add(quantity)
+		total[name] = current
+	}
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
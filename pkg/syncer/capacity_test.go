@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func node(name string, ready, unschedulable bool, cpu, memory string) *corev1.Node {
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+		corev1.ResourcePods:   resource.MustParse("10"),
+	}
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		Status: corev1.NodeStatus{
+			Capacity:    resources,
+			Allocatable: resources,
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: readyStatus}},
+		},
+	}
+}
+
+func pod(nodeName string, phase corev1.PodPhase, cpu, memory string) *corev1.Pod {
+	return &corev1.Pod{
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse(cpu),
+						corev1.ResourceMemory: resource.MustParse(memory),
+					},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func requireResources(t *testing.T, expected map[corev1.ResourceName]string, actual corev1.ResourceList) {
+	t.Helper()
+	require.Len(t, actual, len(expected))
+	for name, quantity := range expected {
+		actualQuantity, found := actual[name]
+		require.True(t, found, "missing resource %s", name)
+		expectedQuantity := resource.MustParse(quantity)
+		require.Zero(t, expectedQuantity.Cmp(actualQuantity), "resource %s: expected %s, got %s", name, quantity, actualQuantity.String())
+	}
+}
+
+func TestComputeResources(t *testing.T) {
+	tests := []struct {
+		name            string
+		nodes           []*corev1.Node
+		pods            []*corev1.Pod
+		cordoned        bool
+		wantCapacity    map[corev1.ResourceName]string
+		wantAllocatable map[corev1.ResourceName]string
+	}{
+		{
+			name:            "no nodes",
+			wantCapacity:    map[corev1.ResourceName]string{},
+			wantAllocatable: map[corev1.ResourceName]string{},
+		},
+		{
+			name:  "requests of running pods are not allocatable",
+			nodes: []*corev1.Node{node("n1", true, false, "4", "8Gi"), node("n2", true, false, "2", "4Gi")},
+			pods: []*corev1.Pod{
+				pod("n1", corev1.PodRunning, "1", "2Gi"),
+				pod("n2", corev1.PodPending, "500m", "1Gi"),
+				pod("n2", corev1.PodSucceeded, "1", "1Gi"),
+				pod("", corev1.PodPending, "1", "1Gi"),
+			},
+			wantCapacity:    map[corev1.ResourceName]string{"cpu": "6", "memory": "12Gi", "pods": "20"},
+			wantAllocatable: map[corev1.ResourceName]string{"cpu": "4500m", "memory": "9Gi", "pods": "18"},
+		},
+		{
+			name:            "overcommitted node has no allocatable resources",
+			nodes:           []*corev1.Node{node("n1", true, false, "1", "1Gi")},
+			pods:            []*corev1.Pod{pod("n1", corev1.PodRunning, "2", "512Mi")},
+			wantCapacity:    map[corev1.ResourceName]string{"cpu": "1", "memory": "1Gi", "pods": "10"},
+			wantAllocatable: map[corev1.ResourceName]string{"cpu": "0", "memory": "512Mi", "pods": "9"},
+		},
+		{
+			name:            "cordoned and not ready nodes have no allocatable resources",
+			nodes:           []*corev1.Node{node("n1", true, true, "4", "8Gi"), node("n2", false, false, "2", "4Gi"), node("n3", true, false, "1", "1Gi")},
+			wantCapacity:    map[corev1.ResourceName]string{"cpu": "7", "memory": "13Gi", "pods": "30"},
+			wantAllocatable: map[corev1.ResourceName]string{"cpu": "1", "memory": "1Gi", "pods": "10"},
+		},
+		{
+			name:            "cordoned sync target has no allocatable resources",
+			nodes:           []*corev1.Node{node("n1", true, false, "4", "8Gi")},
+			cordoned:        true,
+			wantCapacity:    map[corev1.ResourceName]string{"cpu": "4", "memory": "8Gi", "pods": "10"},
+			wantAllocatable: map[corev1.ResourceName]string{"cpu": "0", "memory": "0", "pods": "0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capacity, allocatable := computeResources(tt.nodes, tt.pods, tt.cordoned)
+			requireResources(t, tt.wantCapacity, capacity)
+			requireResources(t, tt.wantAllocatable, allocatable)
+		})
+	}
+}
+
+func TestPodRequests(t *testing.T) {
+	p := pod("n1", corev1.PodRunning, "500m", "1Gi")
+	p.Spec.InitContainers = []corev1.Container{{
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+		},
+	}}
+	p.Spec.Overhead = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}
+
+	requireResources(t, map[corev1.ResourceName]string{"cpu": "2100m", "memory": "1Gi"}, podRequests(p))
+}
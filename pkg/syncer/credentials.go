@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/transport"
+)
+
+// kubeconfigTokenReloadPeriod is how often the token is read again from the kubeconfig file. Secret volumes are
+// updated by the kubelet with a delay of about a minute.
+const kubeconfigTokenReloadPeriod = time.Minute
+
+// WithKubeconfigTokenReload returns a copy of the config authenticating with the token of the given kubeconfig file,
+// read again from the file when it changes, e.g. when the secret it is mounted from is updated with rotated
+// credentials. A config authenticating otherwise is returned unchanged.
+func WithKubeconfigTokenReload(config *rest.Config, kubeconfigPath, context string) *rest.Config {
+	if config.BearerToken == "" || config.BearerTokenFile != "" {
+		return config
+	}
+
+	config = rest.CopyConfig(config)
+	tokenSource := transport.NewCachedTokenSource(&kubeconfigTokenSource{
+		path:    kubeconfigPath,
+		context: context,
+		period:  kubeconfigTokenReloadPeriod,
+	})
+	config.BearerToken = ""
+	config.Wrap(transport.ResettableTokenSourceWrapTransport(tokenSource))
+	return config
+}
+
+// kubeconfigTokenSource reads the token of a kubeconfig file. The token is refreshed every period, and right away
+// when kcp rejects it.
+type kubeconfigTokenSource struct {
+	path    string
+	context string
+	period  time.Duration
+}
+
+var _ oauth2.TokenSource = &kubeconfigTokenSource{}
+
+func (s *kubeconfigTokenSource) Token() (*oauth2.Token, error) {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: s.path},
+		&clientcmd.ConfigOverrides{CurrentContext: s.context}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig %q: %w", s.path, err)
+	}
+	if config.BearerToken == "" {
+		return nil, fmt.Errorf("kubeconfig %q has no token", s.path)
+	}
+	return &oauth2.Token{
+		AccessToken: config.BearerToken,
+		Expiry:      time.Now().Add(s.period),
+	}, nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestWithKubeconfigTokenReload(t *testing.T) {
+	var lock sync.Mutex
+	validToken := "old-token"
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		if r.Header.Get("Authorization") != "Bearer "+validToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	writeKubeconfig := func(token string) {
+		require.NoError(t, os.WriteFile(kubeconfigPath, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: default-cluster
+  cluster:
+    server: %s
+    insecure-skip-tls-verify: true
+contexts:
+- name: default-context
+  context:
+    cluster: default-cluster
+    user: default-user
+current-context: default-context
+users:
+- name: default-user
+  user:
+    token: %s
+`, server.URL, token)), 0600))
+	}
+	writeKubeconfig("old-token")
+
+	loadedConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath}, &clientcmd.ConfigOverrides{}).ClientConfig()
+	require.NoError(t, err)
+
+	config := WithKubeconfigTokenReload(loadedConfig, kubeconfigPath, "")
+	require.Empty(t, config.BearerToken)
+	require.Equal(t, "old-token", loadedConfig.BearerToken, "the given config should not be modified")
+
+	client, err := rest.HTTPClientFor(config)
+	require.NoError(t, err)
+	get := func() int {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+	require.Equal(t, http.StatusOK, get())
+
+	// the token is rotated
+	writeKubeconfig("new-token")
+	lock.Lock()
+	validToken = "new-token"
+	lock.Unlock()
+
+	require.Equal(t, http.StatusUnauthorized, get(), "the cached token should be used until rejected")
+	require.Equal(t, http.StatusOK, get(), "the token should be read again after being rejected")
+
+	certConfig := &rest.Config{Host: server.URL, TLSClientConfig: rest.TLSClientConfig{CertData: []byte("cert")}}
+	require.Same(t, certConfig, WithKubeconfigTokenReload(certConfig, kubeconfigPath, ""))
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+)
+
+// NewDownstreamClient returns a dynamic client sending the writes to the downstream API server in dry-run mode,
+// so that they are validated and admitted, but not persisted. The objects that would be created, updated or
+// deleted are logged as diffs against the current downstream objects. Reads are delegated to the given client.
+func NewDownstreamClient(delegate dynamic.Interface) dynamic.Interface {
+	return &downstreamClient{delegate: delegate}
+}
+
+// NewUpstreamClusterClient returns a dynamic cluster client skipping the writes, which return the object
+// as it is, or the current object for patches. Reads are delegated to the given client.
+func NewUpstreamClusterClient(delegate dynamic.ClusterInterface) dynamic.ClusterInterface {
+	return &upstreamClusterClient{delegate: delegate}
+}
+
+type downstreamClient struct {
+	delegate dynamic.Interface
+}
+
+func (c *downstreamClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	delegate := c.delegate.Resource(gvr)
+	return &downstreamResourceClient{ResourceInterface: delegate, delegate: delegate, gvr: gvr}
+}
+
+type downstreamResourceClient struct {
+	dynamic.ResourceInterface
+
+	delegate  dynamic.NamespaceableResourceInterface
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+func (c *downstreamResourceClient) Namespace(namespace string) dynamic.ResourceInterface {
+	return &downstreamResourceClient{ResourceInterface: c.delegate.Namespace(namespace), delegate: c.delegate, gvr: c.gvr, namespace: namespace}
+}
+
+func (c *downstreamResourceClient) Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	options.DryRun = []string{metav1.DryRunAll}
+	created, err := c.ResourceInterface.Create(ctx, obj, options, subresources...)
+	if c.isNamespaceNotFound(err) {
+		// The namespace would have been created before.
+		created, err = obj, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.report("create", obj.GetName(), nil, created)
+	return created, nil
+}
+
+func (c *downstreamResourceClient) Update(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	current, err := c.current(ctx, obj.GetName())
+	if err != nil {
+		return nil, err
+	}
+	options.DryRun = []string{metav1.DryRunAll}
+	updated, err := c.ResourceInterface.Update(ctx, obj, options, subresources...)
+	if err != nil {
+		return nil, err
+	}
+	c.report("update", obj.GetName(), current, updated)
+	return updated, nil
+}
+
+func (c *downstreamResourceClient) UpdateStatus(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions) (*unstructured.Unstructured, error) {
+	return c.Update(ctx, obj, options, "status")
+}
+
+func (c *downstreamResourceClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	current, err := c.current(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	options.DryRun = []string{metav1.DryRunAll}
+	patched, err := c.ResourceInterface.Patch(ctx, name, pt, data, options, subresources...)
+	if c.isNamespaceNotFound(err) && pt == types.ApplyPatchType {
+		// The namespace would have been created before, and the applied object would be created in it.
+		patched = &unstructured.Unstructured{}
+		err = json.Unmarshal(data, &patched.Object)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.report("apply", name, current, patched)
+	return patched, nil
+}
+
+func (c *downstreamResourceClient) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	current, err := c.current(ctx, name)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return apierrors.NewNotFound(c.gvr.GroupResource(), name)
+	}
+	options.DryRun = []string{metav1.DryRunAll}
+	if err := c.ResourceInterface.Delete(ctx, name, options, subresources...); err != nil {
+		return err
+	}
+	c.report("delete", name, current, nil)
+	return nil
+}
+
+func (c *downstreamResourceClient) DeleteCollection(ctx context.Context, options metav1.DeleteOptions, listOptions metav1.ListOptions) error {
+	options.DryRun = []string{metav1.DryRunAll}
+	if err := c.ResourceInterface.DeleteCollection(ctx, options, listOptions); err != nil {
+		return err
+	}
+	klog.Infof("Dry run: would delete the %s matching %q in namespace %q downstream", c.gvr.GroupResource(), listOptions.LabelSelector, c.namespace)
+	return nil
+}
+
+// current returns the current downstream object, or nil if it doesn't exist.
+func (c *downstreamResourceClient) current(ctx context.Context, name string) (*unstructured.Unstructured, error) {
+	current, err := c.ResourceInterface.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return current, err
+}
+
+// isNamespaceNotFound returns true if the error reports that the namespace of the request doesn't exist.
+func (c *downstreamResourceClient) isNamespaceNotFound(err error) bool {
+	if c.namespace == "" || !apierrors.IsNotFound(err) {
+		return false
+	}
+	status, ok := err.(apierrors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return false
+	}
+	details := status.Status().Details
+	return details.Kind == "namespaces" && details.Name == c.namespace
+}
+
+func (c *downstreamResourceClient) report(verb, name string, current, desired *unstructured.Unstructured) {
+	diff := Diff(current, desired)
+	if diff == "" {
+		klog.V(4).Infof("Dry run: %s %s/%s unchanged downstream", c.gvr.GroupResource(), c.namespace, name)
+		return
+	}
+	klog.Infof("Dry run: would %s %s %s/%s downstream (-current +desired):\n%s", verb, c.gvr.GroupResource(), c.namespace, name, diff)
+}
+
+// Diff returns the differences between the current and the desired object, apart from their status and
+// the metadata fields set by the API server. A nil object stands for a missing object.
+func Diff(current, desired *unstructured.Unstructured) string {
+	return cmp.Diff(comparable(current), comparable(desired))
+}
+
+func comparable(obj *unstructured.Unstructured) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "status")
+	for _, field := range []string{"managedFields", "resourceVersion", "uid", "creationTimestamp", "generation", "selfLink"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	return obj.Object
+}
+
+type upstreamClusterClient struct {
+	delegate dynamic.ClusterInterface
+}
+
+func (c *upstreamClusterClient) Cluster(name logicalcluster.Name) dynamic.Interface {
+	return &upstreamClient{delegate: c.delegate.Cluster(name), cluster: name}
+}
+
+type upstreamClient struct {
+	delegate dynamic.Interface
+	cluster  logicalcluster.Name
+}
+
+func (c *upstreamClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	delegate := c.delegate.Resource(gvr)
+	return &upstreamResourceClient{ResourceInterface: delegate, delegate: delegate, cluster: c.cluster, gvr: gvr}
+}
+
+type upstreamResourceClient struct {
+	dynamic.ResourceInterface
+
+	delegate  dynamic.NamespaceableResourceInterface
+	cluster   logicalcluster.Name
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+func (c *upstreamResourceClient) Namespace(namespace string) dynamic.ResourceInterface {
+	return &upstreamResourceClient{ResourceInterface: c.delegate.Namespace(namespace), delegate: c.delegate, cluster: c.cluster, gvr: c.gvr, namespace: namespace}
+}
+
+func (c *upstreamResourceClient) Create(_ context.Context, obj *unstructured.Unstructured, _ metav1.CreateOptions, _ ...string) (*unstructured.Unstructured, error) {
+	c.skip("create", obj.GetName())
+	return obj, nil
+}
+
+func (c *upstreamResourceClient) Update(_ context.Context, obj *unstructured.Unstructured, _ metav1.UpdateOptions, _ ...string) (*unstructured.Unstructured, error) {
+	c.skip("update", obj.GetName())
+	return obj, nil
+}
+
+func (c *upstreamResourceClient) UpdateStatus(_ context.Context, obj *unstructured.Unstructured, _ metav1.UpdateOptions) (*unstructured.Unstructured, error) {
+	c.skip("update the status of", obj.GetName())
+	return obj, nil
+}
+
+func (c *upstreamResourceClient) Patch(ctx context.Context, name string, _ types.PatchType, _ []byte, _ metav1.PatchOptions, _ ...string) (*unstructured.Unstructured, error) {
+	c.skip("patch", name)
+	return c.ResourceInterface.Get(ctx, name, metav1.GetOptions{})
+}
+
+func (c *upstreamResourceClient) Delete(_ context.Context, name string, _ metav1.DeleteOptions, _ ...string) error {
+	c.skip("delete", name)
+	return nil
+}
+
+func (c *upstreamResourceClient) DeleteCollection(_ context.Context, _ metav1.DeleteOptions, listOptions metav1.ListOptions) error {
+	c.skip("delete the collection", listOptions.LabelSelector)
+	return nil
+}
+
+func (c *upstreamResourceClient) skip(verb, name string) {
+	klog.V(2).Infof("Dry run: skipping %s %s %s|%s/%s upstream", verb, c.gvr.GroupResource(), c.cluster, c.namespace, name)
+}
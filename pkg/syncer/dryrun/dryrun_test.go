@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func configMap(namespace, name string, data map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		},
+	}}
+	if data != nil {
+		obj.Object["data"] = data
+	}
+	return obj
+}
+
+// dryRunRecordingClient records the dryRun options of the write requests.
+type dryRunRecordingClient struct {
+	dynamic.Interface
+	dryRuns map[string][]string
+}
+
+func (c *dryRunRecordingClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &dryRunRecordingResourceClient{NamespaceableResourceInterface: c.Interface.Resource(gvr), dryRuns: c.dryRuns}
+}
+
+type dryRunRecordingResourceClient struct {
+	dynamic.NamespaceableResourceInterface
+	dryRuns map[string][]string
+}
+
+func (c *dryRunRecordingResourceClient) Namespace(namespace string) dynamic.ResourceInterface {
+	return &dryRunRecordingResourceClient{NamespaceableResourceInterface: c.NamespaceableResourceInterface.Namespace(namespace).(dynamic.NamespaceableResourceInterface), dryRuns: c.dryRuns}
+}
+
+func (c *dryRunRecordingResourceClient) Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	c.dryRuns["create"] = options.DryRun
+	return c.NamespaceableResourceInterface.Create(ctx, obj, options, subresources...)
+}
+
+func (c *dryRunRecordingResourceClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	c.dryRuns["patch"] = options.DryRun
+	return c.NamespaceableResourceInterface.Patch(ctx, name, pt, data, options, subresources...)
+}
+
+func (c *dryRunRecordingResourceClient) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	c.dryRuns["delete"] = options.DryRun
+	return c.NamespaceableResourceInterface.Delete(ctx, name, options, subresources...)
+}
+
+func TestDownstreamClient(t *testing.T) {
+	ctx := context.Background()
+
+	fake := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), configMap("existing", "cm", map[string]interface{}{"a": "b"}))
+	// The dry-run requests are handled by the API server, without persisting anything.
+	fake.PrependReactor("patch", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patch := action.(clienttesting.PatchAction)
+		if patch.GetNamespace() == "missing" {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "missing")
+		}
+		return true, configMap(patch.GetNamespace(), patch.GetName(), map[string]interface{}{"a": "c"}), nil
+	})
+	fake.PrependReactor("delete", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+	recording := &dryRunRecordingClient{Interface: fake, dryRuns: map[string][]string{}}
+	client := NewDownstreamClient(recording)
+
+	patched, err := client.Resource(configMapsGVR).Namespace("existing").Patch(ctx, "cm", types.ApplyPatchType, []byte(`{}`), metav1.PatchOptions{})
+	require.NoError(t, err)
+	require.Equal(t, configMap("existing", "cm", map[string]interface{}{"a": "c"}), patched)
+	require.Equal(t, []string{metav1.DryRunAll}, recording.dryRuns["patch"])
+
+	// Objects applied in a namespace that would be created are created as applied.
+	applied := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"missing","name":"cm"},"data":{"a":"b"}}`
+	patched, err = client.Resource(configMapsGVR).Namespace("missing").Patch(ctx, "cm", types.ApplyPatchType, []byte(applied), metav1.PatchOptions{})
+	require.NoError(t, err)
+	require.Equal(t, configMap("missing", "cm", map[string]interface{}{"a": "b"}), patched)
+
+	err = client.Resource(configMapsGVR).Namespace("existing").Delete(ctx, "cm", metav1.DeleteOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{metav1.DryRunAll}, recording.dryRuns["delete"])
+
+	err = client.Resource(configMapsGVR).Namespace("existing").Delete(ctx, "unknown", metav1.DeleteOptions{})
+	require.True(t, apierrors.IsNotFound(err), "deleting a missing object fails as it would without dry run")
+
+	// Nothing has been written.
+	current, err := fake.Resource(configMapsGVR).Namespace("existing").Get(ctx, "cm", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, configMap("existing", "cm", map[string]interface{}{"a": "b"}), current)
+}
+
+func TestUpstreamClusterClient(t *testing.T) {
+	ctx := context.Background()
+
+	fake := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), configMap("ns", "cm", map[string]interface{}{"a": "b"}))
+	client := NewUpstreamClusterClient(&fakeCluster{client: fake}).Cluster(logicalcluster.New("root:org:ws"))
+
+	updated, err := client.Resource(configMapsGVR).Namespace("ns").Update(ctx, configMap("ns", "cm", map[string]interface{}{"a": "c"}), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Equal(t, configMap("ns", "cm", map[string]interface{}{"a": "c"}), updated)
+
+	patched, err := client.Resource(configMapsGVR).Namespace("ns").Patch(ctx, "cm", types.MergePatchType, []byte(`{"data":{"a":"c"}}`), metav1.PatchOptions{})
+	require.NoError(t, err)
+	require.Equal(t, configMap("ns", "cm", map[string]interface{}{"a": "b"}), patched, "patches return the current object")
+
+	require.NoError(t, client.Resource(configMapsGVR).Namespace("ns").Delete(ctx, "cm", metav1.DeleteOptions{}))
+
+	for _, action := range fake.Actions() {
+		require.Equal(t, "get", action.GetVerb(), "only reads are sent upstream")
+	}
+}
+
+type fakeCluster struct {
+	client dynamic.Interface
+}
+
+func (c *fakeCluster) Cluster(_ logicalcluster.Name) dynamic.Interface {
+	return c.client
+}
+
+func TestDiff(t *testing.T) {
+	tests := map[string]struct {
+		current, desired *unstructured.Unstructured
+		wantEmpty        bool
+	}{
+		"creation": {
+			desired: configMap("ns", "cm", nil),
+		},
+		"deletion": {
+			current: configMap("ns", "cm", nil),
+		},
+		"change": {
+			current: configMap("ns", "cm", map[string]interface{}{"a": "b"}),
+			desired: configMap("ns", "cm", map[string]interface{}{"a": "c"}),
+		},
+		"server-set fields and status are ignored": {
+			current: func() *unstructured.Unstructured {
+				obj := configMap("ns", "cm", map[string]interface{}{"a": "b"})
+				obj.SetResourceVersion("1")
+				obj.SetUID("uid")
+				obj.Object["status"] = map[string]interface{}{"phase": "Ready"}
+				return obj
+			}(),
+			desired:   configMap("ns", "cm", map[string]interface{}{"a": "b"}),
+			wantEmpty: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			diff := Diff(tc.current, tc.desired)
+			if tc.wantEmpty {
+				require.Empty(t, diff)
+			} else {
+				require.NotEmpty(t, diff)
+			}
+		})
+	}
+}
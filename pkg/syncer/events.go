@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	kubernetesscheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// clusterEventRecorders records Events on upstream objects, in their logical cluster, as the syncer
+// virtual workspace doesn't serve Events. The recorder of a logical cluster is created on first use.
+type clusterEventRecorders struct {
+	ctx    context.Context
+	events func(clusterName logicalcluster.Name) typedcorev1.EventInterface
+	source corev1.EventSource
+	dryRun bool
+
+	lock      sync.Mutex
+	recorders map[logicalcluster.Name]record.EventRecorder
+}
+
+func newClusterEventRecorders(ctx context.Context, events func(clusterName logicalcluster.Name) typedcorev1.EventInterface, source corev1.EventSource, dryRun bool) *clusterEventRecorders {
+	return &clusterEventRecorders{
+		ctx:       ctx,
+		events:    events,
+		source:    source,
+		dryRun:    dryRun,
+		recorders: map[logicalcluster.Name]record.EventRecorder{},
+	}
+}
+
+// recorderFor returns the recorder of the Events on the objects of the given logical cluster.
+func (r *clusterEventRecorders) recorderFor(clusterName logicalcluster.Name) record.EventRecorder {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if recorder, ok := r.recorders[clusterName]; ok {
+		return recorder
+	}
+
+	eventBroadcaster := record.NewBroadcaster()
+	if !r.dryRun {
+		eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: r.events(clusterName)})
+	}
+	go func() {
+		<-r.ctx.Done()
+		eventBroadcaster.Shutdown()
+	}()
+	recorder := eventBroadcaster.NewRecorder(kubernetesscheme.Scheme, r.source)
+	r.recorders[clusterName] = recorder
+	return recorder
+}
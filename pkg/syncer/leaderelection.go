@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+)
+
+// LeaderElectionConfig configures the election of the active replica among
+// several syncer replicas running for the same SyncTarget.
+type LeaderElectionConfig struct {
+	// Namespace is the downstream namespace of the leader election lease.
+	Namespace string
+	// Name is the name of the leader election lease.
+	Name string
+	// Identity uniquely identifies this syncer replica. It is published in the
+	// SyncTarget status while this replica is active.
+	Identity string
+
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+
+	// OnStoppedLeading is called after this replica lost the lease and all the syncing has stopped.
+	// The replica never becomes active again, so OnStoppedLeading would typically exit the process.
+	OnStoppedLeading func()
+}
+
+// startLeaderElection starts competing for the leader election lease. The returned channel is
+// closed when this replica becomes active. When the lease is lost, stop is called before
+// cfg.OnStoppedLeading.
+func startLeaderElection(ctx context.Context, cfg *LeaderElectionConfig, downstreamConfig *rest.Config, stop context.CancelFunc) (<-chan struct{}, error) {
+	if cfg.Namespace == "" || cfg.Name == "" || cfg.Identity == "" {
+		return nil, errors.New("leader election requires a namespace, a name and an identity")
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(rest.AddUserAgent(rest.CopyConfig(downstreamConfig), "kcp#syncer-leader-election"))
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := resourcelock.New(resourcelock.LeasesResourceLock, cfg.Namespace, cfg.Name,
+		kubeClient.CoreV1(), kubeClient.CoordinationV1(), resourcelock.ResourceLockConfig{Identity: cfg.Identity})
+	if err != nil {
+		return nil, err
+	}
+
+	leading := make(chan struct{})
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   cfg.LeaseDuration,
+		RenewDeadline:   cfg.RenewDeadline,
+		RetryPeriod:     cfg.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            cfg.Namespace + "/" + cfg.Name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				klog.Infof("Syncer replica %s became active", cfg.Identity)
+				close(leading)
+			},
+			OnStoppedLeading: func() {
+				if ctx.Err() != nil {
+					// The syncer is shutting down.
+					return
+				}
+				klog.Infof("Syncer replica %s is not active anymore", cfg.Identity)
+				stop()
+				if cfg.OnStoppedLeading != nil {
+					cfg.OnStoppedLeading()
+				}
+			},
+			OnNewLeader: func(identity string) {
+				if identity != cfg.Identity {
+					klog.Infof("Syncer replica %s is active, %s is on standby", identity, cfg.Identity)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go elector.Run(ctx)
+
+	return leading, nil
+}
+
+// waitForLeading blocks until this replica is active, and returns false
+// if ctx is done before.
+func waitForLeading(ctx context.Context, leading <-chan struct{}) bool {
+	select {
+	case <-leading:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	namespace = "kcp"
+	subsystem = "syncer"
+
+	// DirectionSpec labels the metrics of the spec syncer, syncing upstream objects downstream.
+	DirectionSpec = "spec"
+	// DirectionStatus labels the metrics of the status syncer, syncing the status of downstream objects upstream.
+	DirectionStatus = "status"
+	// DirectionUpsync labels the metrics of the upsyncer, syncing objects created downstream upstream.
+	DirectionUpsync = "upsync"
+)
+
+var (
+	syncDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "sync_duration_seconds",
+			Help:           "Duration in seconds of the sync of a single object, by SyncTarget, direction and resource.",
+			Buckets:        metrics.ExponentialBuckets(0.001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"sync_target", "direction", "resource"},
+	)
+
+	queueDepth = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "queue_depth",
+			Help:           "Number of objects waiting to be synced, by SyncTarget, direction and resource.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"sync_target", "direction", "resource"},
+	)
+
+	retries = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "retries_total",
+			Help:           "Number of failed syncs requeued to be retried, by SyncTarget, direction and resource.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"sync_target", "direction", "resource"},
+	)
+
+	downstreamErrors = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "downstream_api_errors_total",
+			Help:           "Number of failed requests to the downstream cluster API server, by SyncTarget, direction, resource and HTTP status code.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"sync_target", "direction", "resource", "code"},
+	)
+
+	registerOnce sync.Once
+)
+
+// Register registers the syncer metrics in the legacy registry, served by legacyregistry.Handler().
+func Register() {
+	registerOnce.Do(func() {
+		legacyregistry.MustRegister(syncDuration, queueDepth, retries, downstreamErrors)
+	})
+}
+
+// ResourceLabel returns the value of the resource label for the given resource type,
+// in the <resource>.<version>.<group> form.
+func ResourceLabel(gvr schema.GroupVersionResource) string {
+	return gvr.Resource + "." + gvr.Version + "." + gvr.Group
+}
+
+// ControllerMetrics records the metrics of a syncer controller. The queue depth is tracked per resource type,
+// by following the keys added to the queue of the controller and taken out of it by the workers.
+type ControllerMetrics struct {
+	syncTargetName string
+	direction      string
+
+	lock    sync.Mutex
+	pending map[schema.GroupVersionResource]sets.String
+}
+
+// NewControllerMetrics returns the metrics of the syncer controller syncing in the given direction for the given SyncTarget.
+func NewControllerMetrics(syncTargetName, direction string) *ControllerMetrics {
+	return &ControllerMetrics{
+		syncTargetName: syncTargetName,
+		direction:      direction,
+		pending:        map[schema.GroupVersionResource]sets.String{},
+	}
+}
+
+// Enqueued records that the given key is about to be added to the queue. It must be called before adding
+// the key, so that a worker picking the key up immediately finds it pending.
+func (m *ControllerMetrics) Enqueued(gvr schema.GroupVersionResource, key string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.pending[gvr] == nil {
+		m.pending[gvr] = sets.NewString()
+	}
+	m.pending[gvr].Insert(key)
+	queueDepth.WithLabelValues(m.syncTargetName, m.direction, ResourceLabel(gvr)).Set(float64(m.pending[gvr].Len()))
+}
+
+// Dequeued records that the given key has been taken out of the queue by a worker.
+func (m *ControllerMetrics) Dequeued(gvr schema.GroupVersionResource, key string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.pending[gvr].Delete(key)
+	queueDepth.WithLabelValues(m.syncTargetName, m.direction, ResourceLabel(gvr)).Set(float64(m.pending[gvr].Len()))
+}
+
+// Synced records the duration of the sync of an object of the given resource type, started at the given time.
+func (m *ControllerMetrics) Synced(gvr schema.GroupVersionResource, start time.Time) {
+	syncDuration.WithLabelValues(m.syncTargetName, m.direction, ResourceLabel(gvr)).Observe(time.Since(start).Seconds())
+}
+
+// Retried records that the sync of the given key failed, and that the key is requeued to be retried.
+func (m *ControllerMetrics) Retried(gvr schema.GroupVersionResource, key string) {
+	retries.WithLabelValues(m.syncTargetName, m.direction, ResourceLabel(gvr)).Inc()
+	m.Enqueued(gvr, key)
+}
+
+// CountDownstreamErrors wraps the transport of the given downstream cluster config to count the failed requests,
+// by resource type and HTTP status code. Requests failing without a response are counted with the "error" code.
+func CountDownstreamErrors(config *rest.Config, syncTargetName, direction string) {
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &errorCountingRoundTripper{
+			delegate:       rt,
+			syncTargetName: syncTargetName,
+			direction:      direction,
+		}
+	})
+}
+
+type errorCountingRoundTripper struct {
+	delegate       http.RoundTripper
+	syncTargetName string
+	direction      string
+}
+
+func (rt *errorCountingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.delegate.RoundTrip(req)
+	if err != nil {
+		downstreamErrors.WithLabelValues(rt.syncTargetName, rt.direction, resourceFromPath(req.URL.Path), "error").Inc()
+		return resp, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		downstreamErrors.WithLabelValues(rt.syncTargetName, rt.direction, resourceFromPath(req.URL.Path), strconv.Itoa(resp.StatusCode)).Inc()
+	}
+	return resp, nil
+}
+
+// resourceFromPath returns the resource label of the resource type targeted by the given API server request path,
+// or "unknown" if the path isn't a resource path.
+func resourceFromPath(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	var gvr schema.GroupVersionResource
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		gvr.Version, parts = parts[1], parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		gvr.Group, gvr.Version, parts = parts[1], parts[2], parts[3:]
+	default:
+		return "unknown"
+	}
+
+	// /namespaces/<namespace>/<resource> for namespaced resources, /namespaces[/<namespace>] for namespaces.
+	if parts[0] == "namespaces" && len(parts) >= 3 {
+		gvr.Resource = parts[2]
+	} else {
+		gvr.Resource = parts[0]
+	}
+	return ResourceLabel(gvr)
+}
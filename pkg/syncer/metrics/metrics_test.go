@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestResourceFromPath(t *testing.T) {
+	tests := map[string]struct {
+		path string
+		want string
+	}{
+		"core namespaced resource":     {path: "/api/v1/namespaces/ns/configmaps/cm", want: "configmaps.v1."},
+		"core namespaced collection":   {path: "/api/v1/namespaces/ns/configmaps", want: "configmaps.v1."},
+		"namespace":                    {path: "/api/v1/namespaces/ns", want: "namespaces.v1."},
+		"namespaces":                   {path: "/api/v1/namespaces", want: "namespaces.v1."},
+		"group namespaced subresource": {path: "/apis/apps/v1/namespaces/ns/deployments/d/status", want: "deployments.v1.apps"},
+		"group cluster-scoped":         {path: "/apis/storage.k8s.io/v1/storageclasses/standard", want: "storageclasses.v1.storage.k8s.io"},
+		"discovery":                    {path: "/apis/apps/v1", want: "unknown"},
+		"non resource":                 {path: "/healthz", want: "unknown"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, resourceFromPath(tc.path))
+		})
+	}
+}
+
+func TestControllerMetrics(t *testing.T) {
+	registry := metrics.NewKubeRegistry()
+	registry.MustRegister(syncDuration, queueDepth, retries)
+
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	m := NewControllerMetrics("test-metrics", DirectionSpec)
+
+	depth := func(gvr schema.GroupVersionResource) float64 {
+		value, err := testutil.GetGaugeMetricValue(queueDepth.WithLabelValues("test-metrics", DirectionSpec, ResourceLabel(gvr)))
+		require.NoError(t, err)
+		return value
+	}
+
+	m.Enqueued(configMaps, "ns/a")
+	m.Enqueued(configMaps, "ns/b")
+	m.Enqueued(configMaps, "ns/a")
+	m.Enqueued(deployments, "ns/a")
+	require.Equal(t, float64(2), depth(configMaps), "keys queued twice are counted once")
+	require.Equal(t, float64(1), depth(deployments))
+
+	m.Dequeued(configMaps, "ns/a")
+	m.Synced(configMaps, time.Now())
+	require.Equal(t, float64(1), depth(configMaps))
+
+	m.Dequeued(deployments, "ns/a")
+	m.Synced(deployments, time.Now())
+	m.Retried(deployments, "ns/a")
+	require.Equal(t, float64(1), depth(deployments), "retried keys are pending again")
+
+	retried, err := testutil.GetCounterMetricValue(retries.WithLabelValues("test-metrics", DirectionSpec, ResourceLabel(deployments)))
+	require.NoError(t, err)
+	require.Equal(t, float64(1), retried)
+
+	synced, err := testutil.GetHistogramMetricCount(syncDuration.WithLabelValues("test-metrics", DirectionSpec, ResourceLabel(configMaps)))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), synced)
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/informer"
+	"github.com/kcp-dev/kcp/pkg/syncer/resourcesync"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+const (
+	controllerName = "kcp-workload-syncer-namespace"
+
+	byUpstreamNamespaceIndexName = "syncer-namespace-ByUpstreamNamespace"
+
+	// orphanedGracePeriod is how long a downstream namespace must have been orphaned before being deleted.
+	// It gives the informers of newly synced resource types the time to catch up, and absorbs
+	// transient rescheduling of the upstream namespace.
+	orphanedGracePeriod = 5 * time.Minute
+)
+
+// Controller migrates the namespace locators of the downstream namespaces created by the syncer to
+// the current locator version, and deletes the downstream namespaces that are orphaned, i.e. whose
+// upstream namespace no longer holds any object synced to the SyncTarget, once they have been
+// emptied from their synced objects by the spec syncer and hold no upsynced object.
+//
+// A single controller runs for all the syncer virtual workspaces of the SyncTarget: each of them only
+// serves the workspaces of its shard, while the downstream namespaces are shared by all of them.
+type Controller struct {
+	queue workqueue.RateLimitingInterface
+
+	downstreamClient           dynamic.Interface
+	downstreamNamespaceLister  cache.GenericLister
+	downstreamNamespaceIndexer cache.Indexer
+	// syncedIndexers returns the upstream and downstream indexers of the synced resource types, and the
+	// downstream indexers of the upsynced resource types, for all the syncer virtual workspaces. It returns
+	// false if their informers have not all synced yet.
+	syncedIndexers func() (upstream, downstream []cache.Indexer, synced bool)
+
+	syncTargetName      string
+	syncTargetWorkspace logicalcluster.Name
+	syncTargetUID       types.UID
+
+	gracePeriod time.Duration
+	now         func() time.Time
+
+	// orphanedSinceLock protects orphanedSince, holding when downstream namespaces were first seen orphaned.
+	orphanedSinceLock sync.Mutex
+	orphanedSince     map[string]time.Time
+}
+
+// NewNamespaceController returns a downstream namespace controller. It must be created before the
+// downstream namespace informer is started.
+func NewNamespaceController(syncTargetWorkspace logicalcluster.Name, syncTargetName string, syncTargetUID types.UID,
+	downstreamClient dynamic.Interface, downstreamNamespaceInformer informers.GenericInformer,
+	syncedIndexers func() (upstream, downstream []cache.Indexer, synced bool)) (*Controller, error) {
+
+	c := &Controller{
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+
+		downstreamClient:           downstreamClient,
+		downstreamNamespaceLister:  downstreamNamespaceInformer.Lister(),
+		downstreamNamespaceIndexer: downstreamNamespaceInformer.Informer().GetIndexer(),
+		syncedIndexers:             syncedIndexers,
+
+		syncTargetName:      syncTargetName,
+		syncTargetWorkspace: syncTargetWorkspace,
+		syncTargetUID:       syncTargetUID,
+
+		gracePeriod:   orphanedGracePeriod,
+		now:           time.Now,
+		orphanedSince: map[string]time.Time{},
+	}
+
+	namespaceInformer := downstreamNamespaceInformer.Informer()
+	if err := namespaceInformer.AddIndexers(cache.Indexers{byUpstreamNamespaceIndexName: indexByUpstreamNamespace}); err != nil {
+		return nil, err
+	}
+	namespaceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.AddToQueue,
+		UpdateFunc: func(_, obj interface{}) { c.AddToQueue(obj) },
+		DeleteFunc: c.AddToQueue,
+	})
+	klog.V(2).InfoS("Set up downstream namespace event handlers", "SyncTarget Workspace", syncTargetWorkspace, "SyncTarget Name", syncTargetName)
+
+	return c, nil
+}
+
+// AddSyncerInformers makes the events of the informers of the syncers of a syncer virtual workspace
+// requeue the downstream namespaces they may have orphaned. It must be called before the syncer
+// informers are started.
+func (c *Controller) AddSyncerInformers(syncerInformers *resourcesync.SyncerInformerFactory) {
+	// An upstream namespace may become orphaned when one of its objects is deleted or unscheduled.
+	syncerInformers.AddUpstreamEventHandler(informer.GVREventHandlerFuncs{
+		DeleteFunc: func(gvr schema.GroupVersionResource, obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			metaObj, ok := obj.(metav1.Object)
+			if !ok || metaObj.GetNamespace() == "" {
+				return
+			}
+			namespaces, err := c.downstreamNamespaceIndexer.ByIndex(byUpstreamNamespaceIndexName, upstreamNamespaceIndexKey(logicalcluster.From(metaObj), metaObj.GetNamespace()))
+			if err != nil {
+				utilruntime.HandleError(err)
+				return
+			}
+			for _, ns := range namespaces {
+				c.AddToQueue(ns)
+			}
+		},
+	})
+	// A downstream namespace may only be deleted once the spec syncer has deleted its synced objects.
+	syncerInformers.AddDownstreamEventHandler(informer.GVREventHandlerFuncs{
+		DeleteFunc: func(gvr schema.GroupVersionResource, obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			metaObj, ok := obj.(metav1.Object)
+			if !ok || metaObj.GetNamespace() == "" {
+				return
+			}
+			klog.V(4).Infof("%s queueing downstream namespace %s", controllerName, metaObj.GetNamespace())
+			c.queue.Add(metaObj.GetNamespace())
+		},
+	})
+}
+
+// AddUpsyncedInformers makes the deletions of the objects of the given downstream informers of upsynced
+// resource types requeue their downstream namespace. It must be called before the informers are started.
+// The indexers of these informers are expected to be returned among the downstream syncedIndexers.
+func (c *Controller) AddUpsyncedInformers(informers ...cache.SharedIndexInformer) {
+	for _, informer := range informers {
+		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				metaObj, ok := obj.(metav1.Object)
+				if !ok || metaObj.GetNamespace() == "" {
+					return
+				}
+				klog.V(4).Infof("%s queueing downstream namespace %s", controllerName, metaObj.GetNamespace())
+				c.queue.Add(metaObj.GetNamespace())
+			},
+		})
+	}
+}
+
+// indexByUpstreamNamespace is a cache.IndexFunc that indexes downstream namespaces by the
+// workspace and namespace of their namespace locator, whatever the locator version.
+func indexByUpstreamNamespace(obj interface{}) ([]string, error) {
+	metaObj, ok := obj.(metav1.Object)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be a metav1.Object, but is %T", obj)
+	}
+	locator, exists, err := shared.LocatorFromAnnotations(metaObj.GetAnnotations())
+	if err != nil || !exists {
+		// Invalid locators are reported when the namespace is processed.
+		return []string{}, nil
+	}
+	return []string{upstreamNamespaceIndexKey(locator.Workspace, locator.Namespace)}, nil
+}
+
+func upstreamNamespaceIndexKey(workspace logicalcluster.Name, namespace string) string {
+	return workspace.String() + "/" + namespace
+}
+
+func (c *Controller) AddToQueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	klog.V(4).Infof("%s queueing downstream namespace %s", controllerName, key)
+	c.queue.Add(key)
+}
+
+// Start starts N worker processes processing work items.
+func (c *Controller) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.InfoS("Starting syncer workers", "controller", controllerName)
+	defer klog.InfoS("Stopping syncer workers", "controller", controllerName)
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+// startWorker processes work items until stopCh is closed.
+func (c *Controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *Controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	requeueAfter, err := c.process(ctx, key.(string))
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("%s failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+
+	c.queue.Forget(key)
+	if requeueAfter > 0 {
+		c.queue.AddAfter(key, requeueAfter)
+	}
+
+	return true
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+var namespacesGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// process migrates the namespace locator of the given downstream namespace, and deletes the namespace
+// once it has been orphaned for the grace period. It returns when the namespace should be processed again.
+func (c *Controller) process(ctx context.Context, name string) (time.Duration, error) {
+	obj, err := c.downstreamNamespaceLister.Get(name)
+	if apierrors.IsNotFound(err) {
+		c.forgetOrphaned(name)
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	namespace, ok := obj.(metav1.Object)
+	if !ok {
+		return 0, fmt.Errorf("unexpected object type: %T", obj)
+	}
+	if namespace.GetDeletionTimestamp() != nil {
+		return 0, nil
+	}
+
+	locator, exists, err := shared.LocatorFromAnnotations(namespace.GetAnnotations())
+	if err != nil {
+		// Retrying would not help, and an unreadable locator does not prove the namespace to be orphaned.
+		utilruntime.HandleError(fmt.Errorf("invalid namespace locator of downstream namespace %s: %w", name, err))
+		return 0, nil
+	}
+	if !exists || locator.SyncTarget.UID != c.syncTargetUID {
+		klog.V(4).Infof("Downstream namespace %s is not synced from SyncTarget %s|%s, skipping", name, c.syncTargetWorkspace, c.syncTargetName)
+		return 0, nil
+	}
+
+	// Older locator versions are rewritten in place, so that the namespace keeps its name, which was
+	// derived from the original locator, and is found by the spec syncer with the current locator.
+	migrated, err := json.Marshal(locator)
+	if err != nil {
+		return 0, err
+	}
+	if string(migrated) != namespace.GetAnnotations()[shared.NamespaceLocatorAnnotation] {
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]string{shared.NamespaceLocatorAnnotation: string(migrated)},
+			},
+		})
+		if err != nil {
+			return 0, err
+		}
+		klog.Infof("Migrating the namespace locator of downstream namespace %s", name)
+		if _, err := c.downstreamClient.Resource(namespacesGVR).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return 0, err
+		}
+		// The update of the namespace requeues it.
+		return 0, nil
+	}
+
+	upstreamIndexers, downstreamIndexers, synced := c.syncedIndexers()
+	if !synced {
+		return time.Second, nil
+	}
+	orphaned, err := isOrphaned(name, locator, upstreamIndexers, downstreamIndexers)
+	if err != nil {
+		return 0, err
+	}
+	if !orphaned {
+		c.forgetOrphaned(name)
+		return 0, nil
+	}
+
+	if remaining := c.gracePeriod - c.now().Sub(c.markOrphaned(name)); remaining > 0 {
+		klog.V(2).Infof("Downstream namespace %s for upstream namespace %s|%s is orphaned, deleting it in %s", name, locator.Workspace, locator.Namespace, remaining)
+		return remaining, nil
+	}
+
+	klog.Infof("Deleting orphaned downstream namespace %s for upstream namespace %s|%s", name, locator.Workspace, locator.Namespace)
+	uid, resourceVersion := namespace.GetUID(), namespace.GetResourceVersion()
+	err = c.downstreamClient.Resource(namespacesGVR).Delete(ctx, name, metav1.DeleteOptions{
+		// The namespace must not have changed since it was found orphaned.
+		Preconditions: &metav1.Preconditions{UID: &uid, ResourceVersion: &resourceVersion},
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return 0, err
+	}
+	c.forgetOrphaned(name)
+	return 0, nil
+}
+
+// isOrphaned returns true if no upstream object synced to the SyncTarget remains in the upstream namespace of
+// the locator, and no synced or upsynced object remains in the downstream namespace.
+func isOrphaned(downstreamNamespace string, locator *shared.NamespaceLocator, upstreamIndexers, downstreamIndexers []cache.Indexer) (bool, error) {
+	for _, indexer := range upstreamIndexers {
+		objs, err := indexer.ByIndex(cache.NamespaceIndex, locator.Namespace)
+		if err != nil {
+			return false, err
+		}
+		for _, obj := range objs {
+			metaObj, ok := obj.(metav1.Object)
+			if ok && logicalcluster.From(metaObj) == locator.Workspace {
+				return false, nil
+			}
+		}
+	}
+	for _, indexer := range downstreamIndexers {
+		objs, err := indexer.ByIndex(cache.NamespaceIndex, downstreamNamespace)
+		if err != nil {
+			return false, err
+		}
+		if len(objs) > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// markOrphaned returns when the downstream namespace was first seen orphaned, recording it now if it was not.
+func (c *Controller) markOrphaned(name string) time.Time {
+	c.orphanedSinceLock.Lock()
+	defer c.orphanedSinceLock.Unlock()
+
+	since, ok := c.orphanedSince[name]
+	if !ok {
+		since = c.now()
+		c.orphanedSince[name] = since
+	}
+	return since
+}
+
+func (c *Controller) forgetOrphaned(name string) {
+	c.orphanedSinceLock.Lock()
+	defer c.orphanedSinceLock.Unlock()
+
+	delete(c.orphanedSince, name)
+}
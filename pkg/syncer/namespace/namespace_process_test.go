@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+func downstreamNamespace(t *testing.T, locator shared.NamespaceLocator) *unstructured.Unstructured {
+	locatorJSON, err := json.Marshal(locator)
+	require.NoError(t, err)
+
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName("kcp-abc")
+	ns.SetAnnotations(map[string]string{shared.NamespaceLocatorAnnotation: string(locatorJSON)})
+	return ns
+}
+
+func configMap(workspace, namespace string) *unstructured.Unstructured {
+	cm := &unstructured.Unstructured{}
+	cm.SetAPIVersion("v1")
+	cm.SetKind("ConfigMap")
+	cm.SetZZZ_DeprecatedClusterName(workspace)
+	cm.SetNamespace(namespace)
+	cm.SetName("cm")
+	return cm
+}
+
+func TestNamespaceProcess(t *testing.T) {
+	workspace := logicalcluster.New("root:org:ws")
+	locator := shared.NewNamespaceLocator(workspace, workspace, "syncTargetUID", "us-west1", "test")
+	locatorV060 := shared.NewNamespaceLocatorV060(workspace, workspace, "syncTargetUID", "us-west1", "test")
+	otherLocator := shared.NewNamespaceLocator(workspace, workspace, "otherUID", "us-west1", "test")
+	now := time.Now()
+
+	tests := map[string]struct {
+		locator          shared.NamespaceLocator
+		upstreamObject   *unstructured.Unstructured
+		downstreamObject *unstructured.Unstructured
+		upsyncedObject   *unstructured.Unstructured
+		notSynced        bool
+		orphanedSince    time.Time
+
+		wantLocator      shared.NamespaceLocator
+		wantDeleted      bool
+		wantRequeueAfter time.Duration
+		wantOrphaned     bool
+	}{
+		"v0.6.0 locator is migrated": {
+			locator:     locatorV060,
+			wantLocator: locator,
+		},
+		"upstream objects remain": {
+			locator:        locator,
+			upstreamObject: configMap("root:org:ws", "test"),
+			orphanedSince:  now.Add(-time.Hour),
+			wantLocator:    locator,
+		},
+		"orphaned namespace is kept for the grace period": {
+			locator:          locator,
+			upstreamObject:   configMap("root:org:other", "test"),
+			wantLocator:      locator,
+			wantRequeueAfter: time.Minute,
+			wantOrphaned:     true,
+		},
+		"orphaned namespace is deleted after the grace period": {
+			locator:       locator,
+			orphanedSince: now.Add(-time.Minute),
+			wantDeleted:   true,
+		},
+		"downstream synced objects remain": {
+			locator:          locator,
+			downstreamObject: configMap("", "kcp-abc"),
+			orphanedSince:    now.Add(-time.Hour),
+			wantLocator:      locator,
+		},
+		"downstream upsynced objects remain": {
+			locator:        locator,
+			upsyncedObject: configMap("", "kcp-abc"),
+			orphanedSince:  now.Add(-time.Hour),
+			wantLocator:    locator,
+		},
+		"informers not synced": {
+			locator:          locator,
+			notSynced:        true,
+			orphanedSince:    now.Add(-time.Hour),
+			wantLocator:      locator,
+			wantRequeueAfter: time.Second,
+			wantOrphaned:     true,
+		},
+		"namespace of another SyncTarget is ignored": {
+			locator:     otherLocator,
+			wantLocator: otherLocator,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, corev1.AddToScheme(scheme))
+
+			ns := downstreamNamespace(t, tc.locator)
+			downstreamClient := dynamicfake.NewSimpleDynamicClient(scheme, ns)
+			namespaceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			require.NoError(t, namespaceIndexer.Add(ns))
+
+			upstreamIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if tc.upstreamObject != nil {
+				require.NoError(t, upstreamIndexer.Add(tc.upstreamObject))
+			}
+			downstreamIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if tc.downstreamObject != nil {
+				require.NoError(t, downstreamIndexer.Add(tc.downstreamObject))
+			}
+			upsyncedIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if tc.upsyncedObject != nil {
+				require.NoError(t, upsyncedIndexer.Add(tc.upsyncedObject))
+			}
+
+			c := &Controller{
+				downstreamClient:          downstreamClient,
+				downstreamNamespaceLister: cache.NewGenericLister(namespaceIndexer, corev1.Resource("namespaces")),
+				syncedIndexers: func() ([]cache.Indexer, []cache.Indexer, bool) {
+					return []cache.Indexer{upstreamIndexer}, []cache.Indexer{downstreamIndexer, upsyncedIndexer}, !tc.notSynced
+				},
+				syncTargetName:      "us-west1",
+				syncTargetWorkspace: workspace,
+				syncTargetUID:       types.UID("syncTargetUID"),
+				gracePeriod:         time.Minute,
+				now:                 func() time.Time { return now },
+				orphanedSince:       map[string]time.Time{},
+			}
+			if !tc.orphanedSince.IsZero() {
+				c.orphanedSince["kcp-abc"] = tc.orphanedSince
+			}
+
+			requeueAfter, err := c.process(context.Background(), "kcp-abc")
+			require.NoError(t, err)
+			require.Equal(t, tc.wantRequeueAfter, requeueAfter)
+			_, orphaned := c.orphanedSince["kcp-abc"]
+			require.Equal(t, tc.wantOrphaned, orphaned)
+
+			got, err := downstreamClient.Resource(namespacesGVR).Get(context.Background(), "kcp-abc", metav1.GetOptions{})
+			if tc.wantDeleted {
+				require.True(t, apierrors.IsNotFound(err), "expected the namespace to be deleted, got %v", got)
+				return
+			}
+			require.NoError(t, err)
+			wantLocatorJSON, err := json.Marshal(tc.wantLocator)
+			require.NoError(t, err)
+			require.Equal(t, string(wantLocatorJSON), got.GetAnnotations()[shared.NamespaceLocatorAnnotation])
+		})
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// InformersSyncedCheck is a readiness check passing once the informers of the syncers have synced,
+// for all the syncer virtual workspaces of the SyncTarget. It fails until the syncer is started.
+// It passes on standby replicas as well, which keep their informers warm.
+type InformersSyncedCheck struct {
+	lock    sync.RWMutex
+	syncers *virtualWorkspaceSyncers
+}
+
+// NewInformersSyncedCheck returns a readiness check to be set in the SyncerConfig of the syncer.
+func NewInformersSyncedCheck() *InformersSyncedCheck {
+	return &InformersSyncedCheck{}
+}
+
+// Name implements healthz.HealthChecker.
+func (c *InformersSyncedCheck) Name() string {
+	return "informer-sync"
+}
+
+// Check implements healthz.HealthChecker.
+func (c *InformersSyncedCheck) Check(_ *http.Request) error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.syncers == nil {
+		return errors.New("syncer not started")
+	}
+	return c.syncers.informersSynced()
+}
+
+func (c *InformersSyncedCheck) setSyncers(syncers *virtualWorkspaceSyncers) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.syncers = syncers
+}
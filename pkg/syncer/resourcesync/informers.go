@@ -0,0 +1,389 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcesync
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/informer"
+	"github.com/kcp-dev/kcp/third_party/keyfunctions"
+)
+
+// gvrQueryBackoff is the backoff of the retries of the initial retrieval of the resource types to sync. It is
+// jittered to avoid thundering herds of syncers retrying in lockstep.
+var gvrQueryBackoff = wait.Backoff{
+	Duration: 1 * time.Second,
+	Factor:   2,
+	Jitter:   0.5,
+	Steps:    math.MaxInt32,
+	Cap:      1 * time.Minute,
+}
+
+var namespacesGVR = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+
+// GVRSource returns the resource types that should currently be synced.
+type GVRSource func(ctx context.Context) ([]schema.GroupVersionResource, error)
+
+// SyncerInformerFactory maintains a pair of upstream and downstream informers for each resource type
+// synced by a syncer. The set of resource types is retrieved from a GVRSource, and informers are started
+// and stopped at runtime as it changes, in the same way the informer.DynamicDiscoverySharedInformerFactory
+// does on the kcp server side.
+//
+// Event handlers are registered once for all resource types, and are called with the
+// GroupVersionResource of the resource being handled.
+//
+// The downstream namespaces are always informed on, independently of the synced resource types.
+type SyncerInformerFactory struct {
+	upstreamClient, downstreamClient                     dynamic.Interface
+	upstreamTweakListOptions, downstreamTweakListOptions dynamicinformer.TweakListOptionsFunc
+	resyncPeriod                                         time.Duration
+
+	gvrSource         GVRSource
+	discoveryInterval time.Duration
+
+	downstreamNamespaceInformer informers.GenericInformer
+
+	// updateCh receives notifications that the set of synced resource types might have changed.
+	updateCh chan struct{}
+
+	// handlersLock protects multiple writers racing to update handlers.
+	handlersLock       sync.Mutex
+	upstreamHandlers   atomic.Value
+	downstreamHandlers atomic.Value
+
+	// indexersLock protects the indexers to be added to informers when they are created.
+	indexersLock       sync.Mutex
+	upstreamIndexers   map[schema.GroupVersionResource]cache.Indexers
+	downstreamIndexers map[schema.GroupVersionResource]cache.Indexers
+
+	informersLock sync.RWMutex
+	informers     map[schema.GroupVersionResource]*syncerInformers
+}
+
+// syncerInformers are the running informers for a single resource type.
+type syncerInformers struct {
+	upstream   informers.GenericInformer
+	downstream informers.GenericInformer
+	cancel     context.CancelFunc
+}
+
+// NewSyncerInformerFactory returns a SyncerInformerFactory informing on the resource types returned by the gvrSource,
+// which is called again every discoveryInterval, or as soon as NotifyUpdateNeeded is called.
+func NewSyncerInformerFactory(
+	upstreamClient dynamic.Interface, upstreamTweakListOptions dynamicinformer.TweakListOptionsFunc,
+	downstreamClient dynamic.Interface, downstreamTweakListOptions dynamicinformer.TweakListOptionsFunc,
+	gvrSource GVRSource, resyncPeriod, discoveryInterval time.Duration,
+) *SyncerInformerFactory {
+	f := &SyncerInformerFactory{
+		upstreamClient:             upstreamClient,
+		upstreamTweakListOptions:   upstreamTweakListOptions,
+		downstreamClient:           downstreamClient,
+		downstreamTweakListOptions: downstreamTweakListOptions,
+		resyncPeriod:               resyncPeriod,
+
+		gvrSource:         gvrSource,
+		discoveryInterval: discoveryInterval,
+
+		downstreamNamespaceInformer: dynamicinformer.NewFilteredDynamicInformerWithOptions(downstreamClient, namespacesGVR, metav1.NamespaceAll, downstreamTweakListOptions,
+			cache.WithResyncPeriod(resyncPeriod), cache.WithKeyFunction(keyfunctions.DeletionHandlingMetaNamespaceKeyFunc)),
+
+		// Use a buffered channel of size 1 to allow enqueuing 1 update notification
+		updateCh: make(chan struct{}, 1),
+
+		upstreamIndexers:   map[schema.GroupVersionResource]cache.Indexers{},
+		downstreamIndexers: map[schema.GroupVersionResource]cache.Indexers{},
+
+		informers: map[schema.GroupVersionResource]*syncerInformers{},
+	}
+
+	f.upstreamHandlers.Store([]informer.GVREventHandler{})
+	f.downstreamHandlers.Store([]informer.GVREventHandler{})
+
+	return f
+}
+
+// AddUpstreamEventHandler registers a handler called for events of the upstream informers of all the synced resource types.
+func (f *SyncerInformerFactory) AddUpstreamEventHandler(handler informer.GVREventHandler) {
+	f.addEventHandler(&f.upstreamHandlers, handler)
+}
+
+// AddDownstreamEventHandler registers a handler called for events of the downstream informers of all the synced resource types.
+func (f *SyncerInformerFactory) AddDownstreamEventHandler(handler informer.GVREventHandler) {
+	f.addEventHandler(&f.downstreamHandlers, handler)
+}
+
+func (f *SyncerInformerFactory) addEventHandler(handlers *atomic.Value, handler informer.GVREventHandler) {
+	f.handlersLock.Lock()
+	defer f.handlersLock.Unlock()
+
+	existing := handlers.Load().([]informer.GVREventHandler)
+
+	newHandlers := make([]informer.GVREventHandler, len(existing), len(existing)+1)
+	copy(newHandlers, existing)
+	newHandlers = append(newHandlers, handler)
+
+	handlers.Store(newHandlers)
+}
+
+// AddUpstreamIndexers registers indexers added to the upstream informer of the given resource type
+// whenever it is created. It should be called before Start.
+func (f *SyncerInformerFactory) AddUpstreamIndexers(gvr schema.GroupVersionResource, indexers cache.Indexers) {
+	f.addIndexers(f.upstreamIndexers, gvr, indexers)
+}
+
+// AddDownstreamIndexers registers indexers added to the downstream informer of the given resource type
+// whenever it is created. It should be called before Start.
+func (f *SyncerInformerFactory) AddDownstreamIndexers(gvr schema.GroupVersionResource, indexers cache.Indexers) {
+	f.addIndexers(f.downstreamIndexers, gvr, indexers)
+}
+
+func (f *SyncerInformerFactory) addIndexers(all map[schema.GroupVersionResource]cache.Indexers, gvr schema.GroupVersionResource, indexers cache.Indexers) {
+	f.indexersLock.Lock()
+	defer f.indexersLock.Unlock()
+
+	if all[gvr] == nil {
+		all[gvr] = cache.Indexers{}
+	}
+	for name, indexFunc := range indexers {
+		all[gvr][name] = indexFunc
+	}
+}
+
+// DownstreamNamespaceInformer returns the informer for downstream namespaces.
+func (f *SyncerInformerFactory) DownstreamNamespaceInformer() informers.GenericInformer {
+	return f.downstreamNamespaceInformer
+}
+
+// UpstreamInformer returns the upstream informer for the given resource type,
+// and false if the resource type is not currently synced.
+func (f *SyncerInformerFactory) UpstreamInformer(gvr schema.GroupVersionResource) (informers.GenericInformer, bool) {
+	f.informersLock.RLock()
+	defer f.informersLock.RUnlock()
+
+	inf, ok := f.informers[gvr]
+	if !ok {
+		return nil, false
+	}
+	return inf.upstream, true
+}
+
+// DownstreamInformer returns the downstream informer for the given resource type,
+// and false if the resource type is not currently synced.
+func (f *SyncerInformerFactory) DownstreamInformer(gvr schema.GroupVersionResource) (informers.GenericInformer, bool) {
+	if gvr == namespacesGVR {
+		return f.downstreamNamespaceInformer, true
+	}
+
+	f.informersLock.RLock()
+	defer f.informersLock.RUnlock()
+
+	inf, ok := f.informers[gvr]
+	if !ok {
+		return nil, false
+	}
+	return inf.downstream, true
+}
+
+// SyncedGVRs returns the resource types currently synced, sorted by their string representation.
+func (f *SyncerInformerFactory) SyncedGVRs() []schema.GroupVersionResource {
+	f.informersLock.RLock()
+	defer f.informersLock.RUnlock()
+
+	gvrs := make([]schema.GroupVersionResource, 0, len(f.informers))
+	for gvr := range f.informers {
+		gvrs = append(gvrs, gvr)
+	}
+	sort.Slice(gvrs, func(i, j int) bool {
+		return gvrs[i].String() < gvrs[j].String()
+	})
+	return gvrs
+}
+
+// NotifyUpdateNeeded requests the set of synced resource types to be recalculated
+// without waiting for the discovery interval.
+func (f *SyncerInformerFactory) NotifyUpdateNeeded() {
+	select {
+	case f.updateCh <- struct{}{}:
+		klog.V(4).InfoS("Enqueued update notification for syncer informer recalculation")
+	default:
+		klog.V(5).InfoS("Dropping update notification for syncer informer recalculation because a notification is already pending")
+	}
+}
+
+// Start blocks until the GVR source has successfully returned the initial set of resource types,
+// and starts the corresponding informers. It then keeps updating the informers in the background,
+// until ctx is done.
+//
+// TODO(ncdc): we need to provide user-facing details if this polling goes on forever. Blocking here is a bad UX.
+// TODO(ncdc): Also, any regressions in our code will make any e2e test that starts a syncer (at least in-process)
+// TODO(ncdc): block until it hits the 10 minute overall test timeout.
+func (f *SyncerInformerFactory) Start(ctx context.Context) error {
+	go f.downstreamNamespaceInformer.Informer().Run(ctx.Done())
+
+	backoff := gvrQueryBackoff
+	for {
+		gvrs, err := f.gvrSource(ctx)
+		if err == nil {
+			f.updateInformers(ctx, gvrs)
+			break
+		}
+		// TODO(marun) Should some of these errors be fatal?
+		klog.Errorf("Failed to retrieve the resource types to sync: %v", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff.Step()):
+		}
+	}
+
+	// Use UntilWithContext here so that we only check updateCh at most once every second. This effectively
+	// "batches" a flurry of notifications, so we aren't recalculating the informers for each of them.
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-f.updateCh:
+		case <-time.After(wait.Jitter(f.discoveryInterval, 0.5)):
+		}
+
+		gvrs, err := f.gvrSource(ctx)
+		if err != nil {
+			klog.Errorf("Failed to retrieve the resource types to sync, keeping the current ones: %v", err)
+			return
+		}
+		f.updateInformers(ctx, gvrs)
+	}, time.Second)
+
+	return nil
+}
+
+// WaitForCacheSync waits for the informers of all the currently synced resource types to sync.
+func (f *SyncerInformerFactory) WaitForCacheSync(stopCh <-chan struct{}) map[schema.GroupVersionResource]bool {
+	f.informersLock.RLock()
+	cacheSyncs := map[schema.GroupVersionResource][]cache.InformerSynced{
+		namespacesGVR: {f.downstreamNamespaceInformer.Informer().HasSynced},
+	}
+	for gvr, inf := range f.informers {
+		cacheSyncs[gvr] = append(cacheSyncs[gvr], inf.upstream.Informer().HasSynced, inf.downstream.Informer().HasSynced)
+	}
+	f.informersLock.RUnlock()
+
+	res := map[schema.GroupVersionResource]bool{}
+	for gvr, synced := range cacheSyncs {
+		res[gvr] = cache.WaitForCacheSync(stopCh, synced...)
+	}
+	return res
+}
+
+func (f *SyncerInformerFactory) updateInformers(ctx context.Context, gvrs []schema.GroupVersionResource) {
+	latest := map[schema.GroupVersionResource]bool{}
+	for _, gvr := range gvrs {
+		latest[gvr] = true
+	}
+
+	f.informersLock.Lock()
+	defer f.informersLock.Unlock()
+
+	for gvr := range latest {
+		if _, found := f.informers[gvr]; found {
+			continue
+		}
+
+		klog.V(2).Infof("Adding syncer informers for %q", gvr)
+
+		f.indexersLock.Lock()
+		upstreamIndexers, downstreamIndexers := f.upstreamIndexers[gvr], f.downstreamIndexers[gvr]
+		f.indexersLock.Unlock()
+
+		upstream := dynamicinformer.NewFilteredDynamicInformerWithOptions(f.upstreamClient, gvr, metav1.NamespaceAll, f.upstreamTweakListOptions,
+			cache.WithResyncPeriod(f.resyncPeriod), cache.WithIndexers(withNamespaceIndex(upstreamIndexers)))
+		upstream.Informer().AddEventHandler(gvrEventHandler(gvr, &f.upstreamHandlers))
+
+		// Downstream namespaces are always informed on with a dedicated informer.
+		downstream := f.downstreamNamespaceInformer
+		if gvr != namespacesGVR {
+			downstream = dynamicinformer.NewFilteredDynamicInformerWithOptions(f.downstreamClient, gvr, metav1.NamespaceAll, f.downstreamTweakListOptions,
+				cache.WithResyncPeriod(f.resyncPeriod), cache.WithIndexers(withNamespaceIndex(downstreamIndexers)), cache.WithKeyFunction(keyfunctions.DeletionHandlingMetaNamespaceKeyFunc))
+			downstream.Informer().AddEventHandler(gvrEventHandler(gvr, &f.downstreamHandlers))
+		}
+
+		informerCtx, cancel := context.WithCancel(ctx)
+		go upstream.Informer().Run(informerCtx.Done())
+		if gvr != namespacesGVR {
+			go downstream.Informer().Run(informerCtx.Done())
+		}
+
+		f.informers[gvr] = &syncerInformers{
+			upstream:   upstream,
+			downstream: downstream,
+			cancel:     cancel,
+		}
+	}
+
+	for gvr, inf := range f.informers {
+		if latest[gvr] {
+			continue
+		}
+
+		klog.V(2).Infof("Removing syncer informers for %q", gvr)
+		inf.cancel()
+		delete(f.informers, gvr)
+	}
+}
+
+func withNamespaceIndex(indexers cache.Indexers) cache.Indexers {
+	result := cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+	for name, indexFunc := range indexers {
+		result[name] = indexFunc
+	}
+	return result
+}
+
+func gvrEventHandler(gvr schema.GroupVersionResource, handlers *atomic.Value) cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			for _, h := range handlers.Load().([]informer.GVREventHandler) {
+				h.OnAdd(gvr, obj)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			for _, h := range handlers.Load().([]informer.GVREventHandler) {
+				h.OnUpdate(gvr, oldObj, newObj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			for _, h := range handlers.Load().([]informer.GVREventHandler) {
+				h.OnDelete(gvr, obj)
+			}
+		},
+	}
+}
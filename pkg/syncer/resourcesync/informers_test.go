@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcesync
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestSyncerInformerFactory(t *testing.T) {
+	secretsGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}
+	deploymentsGVR := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+	listKinds := map[schema.GroupVersionResource]string{
+		namespacesGVR:  "NamespaceList",
+		secretsGVR:     "SecretList",
+		deploymentsGVR: "DeploymentList",
+	}
+	upstreamClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	downstreamClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+
+	var lock sync.Mutex
+	gvrs := []schema.GroupVersionResource{namespacesGVR, secretsGVR}
+	gvrSource := func(ctx context.Context) ([]schema.GroupVersionResource, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		return gvrs, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	factory := NewSyncerInformerFactory(upstreamClient, nil, downstreamClient, nil, gvrSource, time.Hour, time.Hour)
+	require.NoError(t, factory.Start(ctx))
+	factory.WaitForCacheSync(ctx.Done())
+
+	require.Equal(t, []schema.GroupVersionResource{namespacesGVR, secretsGVR}, factory.SyncedGVRs())
+
+	_, found := factory.UpstreamInformer(deploymentsGVR)
+	require.False(t, found, "deployments should not be informed on")
+	downstreamNamespaceInformer, found := factory.DownstreamInformer(namespacesGVR)
+	require.True(t, found)
+	require.Equal(t, factory.DownstreamNamespaceInformer(), downstreamNamespaceInformer, "downstream namespaces should use the dedicated informer")
+
+	lock.Lock()
+	gvrs = []schema.GroupVersionResource{namespacesGVR, deploymentsGVR}
+	lock.Unlock()
+	factory.NotifyUpdateNeeded()
+
+	require.Eventually(t, func() bool {
+		synced := factory.SyncedGVRs()
+		return len(synced) == 2 && synced[0] == namespacesGVR && synced[1] == deploymentsGVR
+	}, wait.ForeverTestTimeout, 100*time.Millisecond, "expected deployments to replace secrets")
+
+	_, found = factory.UpstreamInformer(secretsGVR)
+	require.False(t, found, "secrets should not be informed on anymore")
+	upstreamDeploymentsInformer, found := factory.UpstreamInformer(deploymentsGVR)
+	require.True(t, found)
+	require.Eventually(t, upstreamDeploymentsInformer.Informer().HasSynced, wait.ForeverTestTimeout, 100*time.Millisecond)
+}
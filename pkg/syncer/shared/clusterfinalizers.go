@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+// ClusterFinalizers returns the finalizers holding the removal of the object from the given SyncTarget, as found
+// in its finalizers.workload.kcp.dev/<sync-target-name> annotation. As long as this annotation holds at least one
+// finalizer name, the syncer does not remove the resource from the SyncTarget, even when the resource is not
+// scheduled there anymore or is deleted upstream.
+func ClusterFinalizers(obj metav1.Object, syncTargetName string) []string {
+	return parseFinalizers(obj.GetAnnotations()[workloadv1alpha1.ClusterFinalizerAnnotationPrefix+syncTargetName])
+}
+
+// HasClusterFinalizers returns true if at least one finalizer holds the removal of the object from the given SyncTarget.
+func HasClusterFinalizers(obj metav1.Object, syncTargetName string) bool {
+	return len(ClusterFinalizers(obj, syncTargetName)) > 0
+}
+
+// HasClusterFinalizer returns true if the given finalizer holds the removal of the object from the given SyncTarget.
+func HasClusterFinalizer(obj metav1.Object, syncTargetName, finalizer string) bool {
+	for _, f := range ClusterFinalizers(obj, syncTargetName) {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// EnsureClusterFinalizerPresent adds the finalizer to the object for the given SyncTarget, if not already there.
+// It returns true if the object has been changed.
+func EnsureClusterFinalizerPresent(obj metav1.Object, syncTargetName, finalizer string) bool {
+	finalizers := ClusterFinalizers(obj, syncTargetName)
+	for _, f := range finalizers {
+		if f == finalizer {
+			return false
+		}
+	}
+	setFinalizers(obj, syncTargetName, append(finalizers, finalizer))
+	return true
+}
+
+// EnsureClusterFinalizerAbsent removes the finalizer from the object for the given SyncTarget. The annotation is
+// removed when no finalizer is left. It returns true if the object has been changed.
+func EnsureClusterFinalizerAbsent(obj metav1.Object, syncTargetName, finalizer string) bool {
+	finalizers := ClusterFinalizers(obj, syncTargetName)
+	remaining := make([]string, 0, len(finalizers))
+	for _, f := range finalizers {
+		if f != finalizer {
+			remaining = append(remaining, f)
+		}
+	}
+	if len(remaining) == len(finalizers) {
+		return false
+	}
+	setFinalizers(obj, syncTargetName, remaining)
+	return true
+}
+
+// SyncTargetsBeingRemoved returns the SyncTargets the object is being removed from, mapped to the
+// RFC3339 timestamp when the removal was requested, as found in the
+// deletion.internal.workload.kcp.dev/<sync-target-name> annotations.
+func SyncTargetsBeingRemoved(obj metav1.Object) map[string]string {
+	removals := map[string]string{}
+	for k, v := range obj.GetAnnotations() {
+		if v == "" || !strings.HasPrefix(k, workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix) {
+			continue
+		}
+		removals[strings.TrimPrefix(k, workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix)] = v
+	}
+	return removals
+}
+
+func parseFinalizers(value string) []string {
+	var finalizers []string
+	for _, f := range strings.Split(value, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			finalizers = append(finalizers, f)
+		}
+	}
+	return finalizers
+}
+
+func setFinalizers(obj metav1.Object, syncTargetName string, finalizers []string) {
+	annotations := obj.GetAnnotations()
+	if len(finalizers) == 0 {
+		delete(annotations, workloadv1alpha1.ClusterFinalizerAnnotationPrefix+syncTargetName)
+		obj.SetAnnotations(annotations)
+		return
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[workloadv1alpha1.ClusterFinalizerAnnotationPrefix+syncTargetName] = strings.Join(finalizers, ",")
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEnsureClusterFinalizer(t *testing.T) {
+	obj := &metav1.ObjectMeta{}
+
+	require.False(t, HasClusterFinalizers(obj, "us-west1"))
+	require.True(t, EnsureClusterFinalizerPresent(obj, "us-west1", "backup.example.com"))
+	require.False(t, EnsureClusterFinalizerPresent(obj, "us-west1", "backup.example.com"))
+	require.True(t, EnsureClusterFinalizerPresent(obj, "us-west1", "migration.example.com"))
+	require.Equal(t, "backup.example.com,migration.example.com", obj.Annotations["finalizers.workload.kcp.dev/us-west1"])
+	require.True(t, HasClusterFinalizer(obj, "us-west1", "migration.example.com"))
+	require.False(t, HasClusterFinalizers(obj, "us-east1"))
+
+	require.False(t, EnsureClusterFinalizerAbsent(obj, "us-west1", "unknown"))
+	require.True(t, EnsureClusterFinalizerAbsent(obj, "us-west1", "backup.example.com"))
+	require.Equal(t, []string{"migration.example.com"}, ClusterFinalizers(obj, "us-west1"))
+	require.True(t, EnsureClusterFinalizerAbsent(obj, "us-west1", "migration.example.com"))
+	require.NotContains(t, obj.Annotations, "finalizers.workload.kcp.dev/us-west1")
+}
+
+func TestClusterFinalizers(t *testing.T) {
+	obj := &metav1.ObjectMeta{Annotations: map[string]string{
+		"finalizers.workload.kcp.dev/us-west1": " a, ,b,",
+		"finalizers.workload.kcp.dev/us-east1": "",
+	}}
+	require.Equal(t, []string{"a", "b"}, ClusterFinalizers(obj, "us-west1"))
+	require.False(t, HasClusterFinalizers(obj, "us-east1"))
+}
+
+func TestSyncTargetsBeingRemoved(t *testing.T) {
+	obj := &metav1.ObjectMeta{Annotations: map[string]string{
+		"deletion.internal.workload.kcp.dev/us-west1": "2022-08-01T10:00:00Z",
+		"deletion.internal.workload.kcp.dev/us-east1": "",
+		"finalizers.workload.kcp.dev/us-west1":        "a",
+	}}
+	require.Equal(t, map[string]string{"us-west1": "2022-08-01T10:00:00Z"}, SyncTargetsBeingRemoved(obj))
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"crypto/sha256"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/martinlindhe/base36"
+)
+
+const (
+	// DownstreamConflictEventReason is the reason of the Events recorded on a cluster-scoped upstream resource
+	// when it is not synced because its name is taken in the cluster by an object owned by another
+	// workspace, or not created by the syncer.
+	DownstreamConflictEventReason = "DownstreamConflict"
+)
+
+// DownstreamOwnerLabelValue returns the value of the internal.workload.kcp.dev/owner label of the downstream
+// cluster-scoped objects owned by the given workspace. Workspace names can't be used as label values as is.
+func DownstreamOwnerLabelValue(workspace logicalcluster.Name) string {
+	hash := sha256.Sum224([]byte(workspace.String()))
+	return strings.ToLower(base36.EncodeBytes(hash[:]))[:12]
+}
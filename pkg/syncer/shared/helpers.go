@@ -34,3 +34,14 @@ func DeprecatedGetAssignedSyncTarget(labels map[string]string) string {
 	}
 	return ""
 }
+
+// IsUpsynced returns true if the labels put the resource in the Upsync state for some SyncTarget,
+// i.e. the resource is owned by this SyncTarget.
+func IsUpsynced(labels map[string]string) bool {
+	for k, v := range labels {
+		if strings.HasPrefix(k, workloadv1alpha1.ClusterResourceStateLabelPrefix) && v == string(workloadv1alpha1.ResourceStateUpsync) {
+			return true
+		}
+	}
+	return false
+}
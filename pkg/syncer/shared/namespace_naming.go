@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// NamespaceNamingHash names the downstream namespaces from the hash of their locator.
+	NamespaceNamingHash = "hash"
+	// NamespaceNamingReadable names the downstream namespaces <workspace>-<namespace>.
+	NamespaceNamingReadable = "readable"
+	// NamespaceNamingTemplatePrefix prefixes the Go template naming the downstream namespaces.
+	NamespaceNamingTemplatePrefix = "template:"
+)
+
+// NamespaceNamer names the downstream namespaces according to the experimental.workload.kcp.dev/namespace-naming
+// annotation of a SyncTarget. A nil NamespaceNamer uses the hash strategy.
+type NamespaceNamer struct {
+	template *template.Template
+}
+
+// NamespaceNamerFunc returns the current NamespaceNamer of the SyncTarget.
+type NamespaceNamerFunc func() *NamespaceNamer
+
+// namespaceNameData holds the fields available to the namespace naming templates.
+type namespaceNameData struct {
+	Workspace      string
+	Namespace      string
+	SyncTargetName string
+	Hash           string
+}
+
+// ParseNamespaceNaming parses the value of the experimental.workload.kcp.dev/namespace-naming annotation
+// of a SyncTarget.
+func ParseNamespaceNaming(value string) (*NamespaceNamer, error) {
+	switch {
+	case value == "" || value == NamespaceNamingHash:
+		return nil, nil
+	case value == NamespaceNamingReadable:
+		return &NamespaceNamer{template: template.Must(template.New("readable").Parse("{{.Workspace}}-{{.Namespace}}"))}, nil
+	case strings.HasPrefix(value, NamespaceNamingTemplatePrefix):
+		tmpl, err := template.New("namespace").Option("missingkey=error").Parse(strings.TrimPrefix(value, NamespaceNamingTemplatePrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace naming template: %w", err)
+		}
+		// Catch the references to unknown fields now rather than when naming namespaces.
+		if err := tmpl.Execute(io.Discard, namespaceNameData{}); err != nil {
+			return nil, fmt.Errorf("invalid namespace naming template: %w", err)
+		}
+		return &NamespaceNamer{template: tmpl}, nil
+	default:
+		return nil, fmt.Errorf("invalid namespace naming %q, expected %q, %q or %q<template>", value, NamespaceNamingHash, NamespaceNamingReadable, NamespaceNamingTemplatePrefix)
+	}
+}
+
+// DownstreamNamespaceNames returns the candidate names, in order of preference, of the downstream namespace
+// of the given locator. The candidates taken by the namespaces of other locators are to be skipped. The
+// last candidate contains the hash of the locator, and is expected to be free.
+func (n *NamespaceNamer) DownstreamNamespaceNames(l NamespaceLocator) ([]string, error) {
+	hashed, err := PhysicalClusterNamespaceName(l)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		return []string{hashed}, nil
+	}
+
+	hash := strings.TrimPrefix(hashed, "kcp-")
+	var b strings.Builder
+	if err := n.template.Execute(&b, namespaceNameData{
+		Workspace:      l.Workspace.String(),
+		Namespace:      l.Namespace,
+		SyncTargetName: l.SyncTarget.Name,
+		Hash:           hash,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to execute the namespace naming template: %w", err)
+	}
+	name := sanitizeNamespaceName(b.String(), validation.DNS1123LabelMaxLength)
+	if name == "" {
+		return []string{hashed}, nil
+	}
+	return []string{
+		name,
+		sanitizeNamespaceName(name, validation.DNS1123LabelMaxLength-len(hash)-1) + "-" + hash,
+	}, nil
+}
+
+// sanitizeNamespaceName turns name into a DNS label of at most maxLength characters, replacing the
+// invalid characters with dashes.
+func sanitizeNamespaceName(name string, maxLength int) string {
+	sanitized := []byte(strings.ToLower(name))
+	for i, c := range sanitized {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			sanitized[i] = '-'
+		}
+	}
+	name = strings.Trim(string(sanitized), "-")
+	if len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], "-")
+	}
+	return name
+}
@@ -99,3 +99,94 @@ func TestLocatorFromAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestDownstreamNamespaceNames(t *testing.T) {
+	locator := NewNamespaceLocator(logicalcluster.New("root:org:ws"), logicalcluster.New("root:org"), "uid", "us-west1", "test")
+	hashed, err := PhysicalClusterNamespaceName(locator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := strings.TrimPrefix(hashed, "kcp-")
+	longLocator := NewNamespaceLocator(logicalcluster.New("root:org:ws"), logicalcluster.New("root:org"), "uid", "us-west1", strings.Repeat("a", 60))
+	longHashed, err := PhysicalClusterNamespaceName(longLocator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	longHash := strings.TrimPrefix(longHashed, "kcp-")
+
+	tests := []struct {
+		name     string
+		naming   string
+		locator  NamespaceLocator
+		want     []string
+		wantErrs []string
+	}{
+		{
+			name:    "default",
+			locator: locator,
+			want:    []string{hashed},
+		},
+		{
+			name:    "hash",
+			naming:  "hash",
+			locator: locator,
+			want:    []string{hashed},
+		},
+		{
+			name:    "readable",
+			naming:  "readable",
+			locator: locator,
+			want:    []string{"root-org-ws-test", "root-org-ws-test-" + hash},
+		},
+		{
+			name:    "template",
+			naming:  "template:{{.SyncTargetName}}-{{.Namespace}}-{{.Hash}}",
+			locator: locator,
+			want:    []string{"us-west1-test-" + hash, "us-west1-test-" + hash + "-" + hash},
+		},
+		{
+			name:    "long names are truncated",
+			naming:  "readable",
+			locator: longLocator,
+			want:    []string{"root-org-ws-" + strings.Repeat("a", 51), "root-org-ws-" + strings.Repeat("a", 38) + "-" + longHash},
+		},
+		{
+			name:    "template without valid characters",
+			naming:  "template:__",
+			locator: locator,
+			want:    []string{hashed},
+		},
+		{
+			name:     "unknown strategy",
+			naming:   "pretty",
+			wantErrs: []string{"invalid namespace naming"},
+		},
+		{
+			name:     "unknown template field",
+			naming:   "template:{{.Cluster}}",
+			wantErrs: []string{"can't evaluate field Cluster"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namer, err := ParseNamespaceNaming(tt.naming)
+			if (err != nil) != (len(tt.wantErrs) > 0) {
+				t.Fatalf("ParseNamespaceNaming() error = %v, wantErrs %v", err, tt.wantErrs)
+			} else if err != nil {
+				for _, wantErr := range tt.wantErrs {
+					if !strings.Contains(err.Error(), wantErr) {
+						t.Errorf("ParseNamespaceNaming() error = %q, wantErrs %q", err.Error(), wantErr)
+					}
+				}
+				return
+			}
+			got, err := namer.DownstreamNamespaceNames(tt.locator)
+			if err != nil {
+				t.Fatalf("DownstreamNamespaceNames() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DownstreamNamespaceNames() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
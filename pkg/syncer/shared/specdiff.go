@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// InvalidSpecDiffReason is the reason reported in the experimental.spec-diff-error.workload.kcp.dev/<sync-target-name>
+// annotation when the spec diff cannot be decoded or applied to the spec of the upstream resource.
+const InvalidSpecDiffReason = "InvalidSpecDiff"
+
+var specDiffOperations = sets.NewString("add", "remove", "replace", "move", "copy", "test")
+
+// DecodeSpecDiff decodes the JSON patch of a experimental.spec-diff.workload.kcp.dev/<sync-target-name>
+// annotation, and validates its operations.
+func DecodeSpecDiff(specDiff string) (jsonpatch.Patch, error) {
+	patch, err := jsonpatch.DecodePatch([]byte(specDiff))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON patch: %w", err)
+	}
+	for i, operation := range patch {
+		if op := operation.Kind(); !specDiffOperations.Has(op) {
+			return nil, fmt.Errorf("invalid JSON patch operation %d: unsupported op %q", i, op)
+		}
+		if _, err := operation.Path(); err != nil {
+			return nil, fmt.Errorf("invalid JSON patch operation %d: %w", i, err)
+		}
+		if op := operation.Kind(); op == "move" || op == "copy" {
+			if _, err := operation.From(); err != nil {
+				return nil, fmt.Errorf("invalid JSON patch operation %d: %w", i, err)
+			}
+		}
+	}
+	return patch, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeSpecDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		specDiff string
+		wantErr  string
+	}{
+		{
+			name:     "valid",
+			specDiff: `[{"op":"replace","path":"/replicas","value":3},{"op":"copy","from":"/a","path":"/b"}]`,
+		},
+		{
+			name:     "not a JSON patch",
+			specDiff: `{"replicas":3}`,
+			wantErr:  "invalid JSON patch",
+		},
+		{
+			name:     "unsupported operation",
+			specDiff: `[{"op":"merge","path":"/replicas","value":3}]`,
+			wantErr:  `unsupported op "merge"`,
+		},
+		{
+			name:     "missing path",
+			specDiff: `[{"op":"remove"}]`,
+			wantErr:  "invalid JSON patch operation 0",
+		},
+		{
+			name:     "move without from",
+			specDiff: `[{"op":"replace","path":"/replicas","value":3},{"op":"move","path":"/b"}]`,
+			wantErr:  "invalid JSON patch operation 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeSpecDiff(tt.specDiff)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/json"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+// StatusAggregator computes the status of an upstream resource synced to several SyncTargets,
+// from the status of the resource on each of these SyncTargets.
+type StatusAggregator interface {
+	// GVR returns the resource type whose status is aggregated.
+	GVR() schema.GroupVersionResource
+	// AggregateStatus returns the status of the upstream object, given its status on each SyncTarget,
+	// keyed by SyncTarget name.
+	AggregateStatus(upstreamObj *unstructured.Unstructured, syncTargetStatuses map[string]interface{}) (interface{}, error)
+}
+
+// SyncTargetStatuses returns the status of the upstream object on each SyncTarget, keyed by SyncTarget name,
+// as found in the experimental.status.workload.kcp.dev/<sync-target-name> annotations.
+func SyncTargetStatuses(upstreamObj *unstructured.Unstructured) (map[string]interface{}, error) {
+	statuses := map[string]interface{}{}
+	for key, value := range upstreamObj.GetAnnotations() {
+		if !strings.HasPrefix(key, workloadv1alpha1.InternalClusterStatusAnnotationPrefix) {
+			continue
+		}
+		syncTargetName := strings.TrimPrefix(key, workloadv1alpha1.InternalClusterStatusAnnotationPrefix)
+		var status interface{}
+		if err := json.Unmarshal([]byte(value), &status); err != nil {
+			return nil, fmt.Errorf("invalid status of SyncTarget %q in annotation %q: %w", syncTargetName, key, err)
+		}
+		statuses[syncTargetName] = status
+	}
+	return statuses, nil
+}
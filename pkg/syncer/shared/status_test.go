@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSyncTargetStatuses(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        map[string]interface{}
+		wantErr     bool
+	}{
+		{
+			name: "no annotation",
+			want: map[string]interface{}{},
+		},
+		{
+			name: "several SyncTargets",
+			annotations: map[string]string{
+				"experimental.status.workload.kcp.dev/us-west1": `{"replicas":15}`,
+				"experimental.status.workload.kcp.dev/us-east1": `{"replicas":5}`,
+				"state.workload.kcp.dev/us-west1":               "Sync",
+			},
+			want: map[string]interface{}{
+				"us-west1": map[string]interface{}{"replicas": int64(15)},
+				"us-east1": map[string]interface{}{"replicas": int64(5)},
+			},
+		},
+		{
+			name: "garbage",
+			annotations: map[string]string{
+				"experimental.status.workload.kcp.dev/us-west1": "garbage",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			obj.SetAnnotations(tt.annotations)
+
+			got, err := SyncTargetStatuses(obj)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
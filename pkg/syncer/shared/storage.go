@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// BoundVolume holds the details, published upstream in the experimental.bound-volume.workload.kcp.dev/<sync-target-name>
+// annotation of a persistent volume claim, of the persistent volume the claim is bound to downstream.
+type BoundVolume struct {
+	VolumeName       string                               `json:"volumeName"`
+	StorageClassName string                               `json:"storageClassName,omitempty"`
+	Capacity         corev1.ResourceList                  `json:"capacity,omitempty"`
+	AccessModes      []corev1.PersistentVolumeAccessMode  `json:"accessModes,omitempty"`
+	VolumeMode       *corev1.PersistentVolumeMode         `json:"volumeMode,omitempty"`
+	ReclaimPolicy    corev1.PersistentVolumeReclaimPolicy `json:"reclaimPolicy,omitempty"`
+	NodeAffinity     *corev1.VolumeNodeAffinity           `json:"nodeAffinity,omitempty"`
+}
+
+// NewBoundVolume returns the details of the given persistent volume.
+func NewBoundVolume(pv *corev1.PersistentVolume) *BoundVolume {
+	return &BoundVolume{
+		VolumeName:       pv.Name,
+		StorageClassName: pv.Spec.StorageClassName,
+		Capacity:         pv.Spec.Capacity,
+		AccessModes:      pv.Spec.AccessModes,
+		VolumeMode:       pv.Spec.VolumeMode,
+		ReclaimPolicy:    pv.Spec.PersistentVolumeReclaimPolicy,
+		NodeAffinity:     pv.Spec.NodeAffinity,
+	}
+}
+
+// ParseStorageClassMapping parses the value of the experimental.workload.kcp.dev/storage-class-mapping
+// annotation of a SyncTarget into a map from the upstream storage classes to the downstream ones.
+func ParseStorageClassMapping(value string) (map[string]string, error) {
+	mapping := map[string]string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid storage class mapping %q, expected <upstream-class>=<downstream-class>", entry)
+		}
+		upstreamClass := strings.TrimSpace(parts[0])
+		if _, found := mapping[upstreamClass]; found {
+			return nil, fmt.Errorf("storage class %q is mapped several times", upstreamClass)
+		}
+		mapping[upstreamClass] = strings.TrimSpace(parts[1])
+	}
+	return mapping, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStorageClassMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:  "empty",
+			value: "",
+			want:  map[string]string{},
+		},
+		{
+			name:  "several classes",
+			value: "standard=gp2, fast=io1",
+			want:  map[string]string{"standard": "gp2", "fast": "io1"},
+		},
+		{
+			name:  "default class",
+			value: "=gp2,",
+			want:  map[string]string{"": "gp2"},
+		},
+		{
+			name:    "missing downstream class",
+			value:   "standard=",
+			wantErr: `invalid storage class mapping "standard="`,
+		},
+		{
+			name:    "not a mapping",
+			value:   "standard",
+			wantErr: `invalid storage class mapping "standard"`,
+		},
+		{
+			name:    "mapped twice",
+			value:   "standard=gp2,standard=gp3",
+			wantErr: `storage class "standard" is mapped several times`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStorageClassMapping(tt.value)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
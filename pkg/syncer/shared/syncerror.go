@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DownstreamRejectedEventReason is the reason of the Events recorded on an upstream resource when
+	// the cluster of a SyncTarget rejects it.
+	DownstreamRejectedEventReason = "DownstreamRejected"
+)
+
+// SyncError is the value, in JSON, of the experimental.sync-error.workload.kcp.dev/<sync-target-name>
+// annotation of upstream resources.
+type SyncError struct {
+	Reason             string      `json:"reason"`
+	Message            string      `json:"message"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// IsDownstreamRejection returns true if the error returned by the downstream cluster when applying a resource
+// means that the resource was rejected, for instance on admission, quota or validation, rather than that the
+// request failed transiently.
+func IsDownstreamRejection(err error) bool {
+	return apierrors.IsInvalid(err) ||
+		apierrors.IsForbidden(err) ||
+		apierrors.IsBadRequest(err) ||
+		apierrors.IsRequestEntityTooLargeError(err) ||
+		apierrors.IsMethodNotSupported(err) ||
+		apierrors.IsUnsupportedMediaType(err)
+}
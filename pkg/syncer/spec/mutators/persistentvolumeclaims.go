@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutators
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// StorageClassMappingFunc returns the current mapping from the upstream storage classes to the downstream ones.
+type StorageClassMappingFunc func() map[string]string
+
+// PersistentVolumeClaimMutator maps the storage class of the persistent volume claims to the storage
+// classes of the SyncTarget.
+type PersistentVolumeClaimMutator struct {
+	storageClassMapping StorageClassMappingFunc
+}
+
+func (pm *PersistentVolumeClaimMutator) GVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "persistentvolumeclaims",
+	}
+}
+
+func NewPersistentVolumeClaimMutator(storageClassMapping StorageClassMappingFunc) *PersistentVolumeClaimMutator {
+	return &PersistentVolumeClaimMutator{
+		storageClassMapping: storageClassMapping,
+	}
+}
+
+// Mutate applies the mutator changes to the object.
+func (pm *PersistentVolumeClaimMutator) Mutate(obj *unstructured.Unstructured) error {
+	storageClassName, found, err := unstructured.NestedString(obj.UnstructuredContent(), "spec", "storageClassName")
+	if err != nil {
+		return err
+	}
+	if !found {
+		// An empty upstream class maps the claims relying on the default storage class. Claims explicitly
+		// setting an empty storage class request a volume without class, and are kept as they are.
+		storageClassName = ""
+	} else if storageClassName == "" {
+		return nil
+	}
+
+	downstreamStorageClassName, mapped := pm.storageClassMapping()[storageClassName]
+	if !mapped {
+		return nil
+	}
+	return unstructured.SetNestedField(obj.UnstructuredContent(), downstreamStorageClassName, "spec", "storageClassName")
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPersistentVolumeClaimMutate(t *testing.T) {
+	mapping := map[string]string{"standard": "gp2", "": "gp3"}
+
+	for _, c := range []struct {
+		desc                 string
+		spec                 map[string]interface{}
+		wantStorageClassName string
+	}{
+		{
+			desc:                 "mapped storage class",
+			spec:                 map[string]interface{}{"storageClassName": "standard"},
+			wantStorageClassName: "gp2",
+		},
+		{
+			desc:                 "storage class that is not mapped is kept",
+			spec:                 map[string]interface{}{"storageClassName": "fast"},
+			wantStorageClassName: "fast",
+		},
+		{
+			desc:                 "default storage class is mapped",
+			spec:                 map[string]interface{}{},
+			wantStorageClassName: "gp3",
+		},
+		{
+			desc:                 "explicitly empty storage class is kept",
+			spec:                 map[string]interface{}{"storageClassName": ""},
+			wantStorageClassName: "",
+		},
+	} {
+		t.Run(c.desc, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": c.spec}}
+			mutator := NewPersistentVolumeClaimMutator(func() map[string]string { return mapping })
+			require.NoError(t, mutator.Mutate(obj))
+
+			storageClassName, found, err := unstructured.NestedString(obj.Object, "spec", "storageClassName")
+			require.NoError(t, err)
+			require.True(t, found)
+			require.Equal(t, c.wantStorageClassName, storageClassName)
+		})
+	}
+
+	t.Run("default storage class is kept without mapping", func(t *testing.T) {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}}
+		mutator := NewPersistentVolumeClaimMutator(func() map[string]string { return nil })
+		require.NoError(t, mutator.Mutate(obj))
+
+		_, found, err := unstructured.NestedString(obj.Object, "spec", "storageClassName")
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/kcp-dev/logicalcluster/v2"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,50 +34,145 @@ import (
 
 type ListSecretFunc func(clusterName logicalcluster.Name, namespace string) ([]*unstructured.Unstructured, error)
 
-type DeploymentMutator struct {
+// PodSpecMutator rewires the pods of a resource carrying a pod spec, so that they access the
+// kcp API of their workspace instead of the API of the physical cluster they run on.
+type PodSpecMutator struct {
+	gvr       schema.GroupVersionResource
+	newObject func() runtime.Object
+	podSpec   func(obj runtime.Object) *corev1.PodSpec
+
 	upstreamURL *url.URL
 	listSecrets ListSecretFunc
 }
 
-func (dm *DeploymentMutator) GVR() schema.GroupVersionResource {
-	return schema.GroupVersionResource{
-		Group:    "apps",
-		Version:  "v1",
-		Resource: "deployments",
+var _ Mutator = (*PodSpecMutator)(nil)
+
+func (m *PodSpecMutator) GVR() schema.GroupVersionResource {
+	return m.gvr
+}
+
+func NewDeploymentMutator(upstreamURL *url.URL, secretLister ListSecretFunc) *PodSpecMutator {
+	return &PodSpecMutator{
+		gvr:       appsv1.SchemeGroupVersion.WithResource("deployments"),
+		newObject: func() runtime.Object { return &appsv1.Deployment{} },
+		podSpec: func(obj runtime.Object) *corev1.PodSpec {
+			return &obj.(*appsv1.Deployment).Spec.Template.Spec
+		},
+		upstreamURL: upstreamURL,
+		listSecrets: secretLister,
 	}
 }
 
-func NewDeploymentMutator(upstreamURL *url.URL, secretLister ListSecretFunc) *DeploymentMutator {
-	return &DeploymentMutator{
+func NewReplicaSetMutator(upstreamURL *url.URL, secretLister ListSecretFunc) *PodSpecMutator {
+	return &PodSpecMutator{
+		gvr:       appsv1.SchemeGroupVersion.WithResource("replicasets"),
+		newObject: func() runtime.Object { return &appsv1.ReplicaSet{} },
+		podSpec: func(obj runtime.Object) *corev1.PodSpec {
+			return &obj.(*appsv1.ReplicaSet).Spec.Template.Spec
+		},
 		upstreamURL: upstreamURL,
 		listSecrets: secretLister,
 	}
 }
 
+func NewStatefulSetMutator(upstreamURL *url.URL, secretLister ListSecretFunc) *PodSpecMutator {
+	return &PodSpecMutator{
+		gvr:       appsv1.SchemeGroupVersion.WithResource("statefulsets"),
+		newObject: func() runtime.Object { return &appsv1.StatefulSet{} },
+		podSpec: func(obj runtime.Object) *corev1.PodSpec {
+			return &obj.(*appsv1.StatefulSet).Spec.Template.Spec
+		},
+		upstreamURL: upstreamURL,
+		listSecrets: secretLister,
+	}
+}
+
+func NewDaemonSetMutator(upstreamURL *url.URL, secretLister ListSecretFunc) *PodSpecMutator {
+	return &PodSpecMutator{
+		gvr:       appsv1.SchemeGroupVersion.WithResource("daemonsets"),
+		newObject: func() runtime.Object { return &appsv1.DaemonSet{} },
+		podSpec: func(obj runtime.Object) *corev1.PodSpec {
+			return &obj.(*appsv1.DaemonSet).Spec.Template.Spec
+		},
+		upstreamURL: upstreamURL,
+		listSecrets: secretLister,
+	}
+}
+
+func NewJobMutator(upstreamURL *url.URL, secretLister ListSecretFunc) *PodSpecMutator {
+	return &PodSpecMutator{
+		gvr:       batchv1.SchemeGroupVersion.WithResource("jobs"),
+		newObject: func() runtime.Object { return &batchv1.Job{} },
+		podSpec: func(obj runtime.Object) *corev1.PodSpec {
+			return &obj.(*batchv1.Job).Spec.Template.Spec
+		},
+		upstreamURL: upstreamURL,
+		listSecrets: secretLister,
+	}
+}
+
+func NewCronJobMutator(upstreamURL *url.URL, secretLister ListSecretFunc) *PodSpecMutator {
+	return &PodSpecMutator{
+		gvr:       batchv1.SchemeGroupVersion.WithResource("cronjobs"),
+		newObject: func() runtime.Object { return &batchv1.CronJob{} },
+		podSpec: func(obj runtime.Object) *corev1.PodSpec {
+			return &obj.(*batchv1.CronJob).Spec.JobTemplate.Spec.Template.Spec
+		},
+		upstreamURL: upstreamURL,
+		listSecrets: secretLister,
+	}
+}
+
+func NewPodMutator(upstreamURL *url.URL, secretLister ListSecretFunc) *PodSpecMutator {
+	return &PodSpecMutator{
+		gvr:       corev1.SchemeGroupVersion.WithResource("pods"),
+		newObject: func() runtime.Object { return &corev1.Pod{} },
+		podSpec: func(obj runtime.Object) *corev1.PodSpec {
+			return &obj.(*corev1.Pod).Spec
+		},
+		upstreamURL: upstreamURL,
+		listSecrets: secretLister,
+	}
+}
+
+// NewPodSpecMutators returns the PodSpecMutators of all the built-in resources carrying a pod spec.
+func NewPodSpecMutators(upstreamURL *url.URL, secretLister ListSecretFunc) []Mutator {
+	return []Mutator{
+		NewDeploymentMutator(upstreamURL, secretLister),
+		NewReplicaSetMutator(upstreamURL, secretLister),
+		NewStatefulSetMutator(upstreamURL, secretLister),
+		NewDaemonSetMutator(upstreamURL, secretLister),
+		NewJobMutator(upstreamURL, secretLister),
+		NewCronJobMutator(upstreamURL, secretLister),
+		NewPodMutator(upstreamURL, secretLister),
+	}
+}
+
 // Mutate applies the mutator changes to the object.
-func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
-	var deployment appsv1.Deployment
+func (m *PodSpecMutator) Mutate(obj *unstructured.Unstructured) error {
+	typedObj := m.newObject()
 	err := runtime.DefaultUnstructuredConverter.FromUnstructured(
 		obj.UnstructuredContent(),
-		&deployment)
+		typedObj)
 	if err != nil {
 		return err
 	}
 	upstreamLogicalName := logicalcluster.From(obj)
+	namespace := obj.GetNamespace()
 
-	templateSpec := &deployment.Spec.Template.Spec
+	templateSpec := m.podSpec(typedObj)
 
 	desiredServiceAccountName := "default"
 	if templateSpec.ServiceAccountName != "" && templateSpec.ServiceAccountName != "default" {
 		desiredServiceAccountName = templateSpec.ServiceAccountName
 	}
 
-	secretList, err := dm.listSecrets(upstreamLogicalName, deployment.Namespace)
+	secretList, err := m.listSecrets(upstreamLogicalName, namespace)
 	if err != nil {
 		return fmt.Errorf("error listing secrets for workspace %s: %w", upstreamLogicalName.String(), err)
 	}
 
-	// In order to avoid triggering a deployment update on resyncs, we need to make sure that the list
+	// In order to avoid triggering a workload update on resyncs, we need to make sure that the list
 	// of secrets is sorted by creationTimsestamp. So if the user creates a new token for a given serviceaccount
 	// the first one will be picked always.
 	sort.Slice(secretList, func(i, j int) bool {
@@ -99,7 +195,7 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 	}
 
 	if desiredSecretName == "" {
-		return fmt.Errorf("couldn't find a token upstream for the serviceaccount %s/%s in workspace %s", desiredServiceAccountName, deployment.Namespace, upstreamLogicalName.String())
+		return fmt.Errorf("couldn't find a token upstream for the serviceaccount %s/%s in workspace %s", desiredServiceAccountName, namespace, upstreamLogicalName.String())
 	}
 
 	// Setting AutomountServiceAccountToken to false allow us to control the ServiceAccount
@@ -108,8 +204,8 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 	// Set to empty the serviceAccountName on podTemplate as we are not syncing the serviceAccount down to the workload cluster.
 	templateSpec.ServiceAccountName = ""
 
-	kcpExternalHost := dm.upstreamURL.Hostname()
-	kcpExternalPort := dm.upstreamURL.Port()
+	kcpExternalHost := m.upstreamURL.Hostname()
+	kcpExternalPort := m.upstreamURL.Port()
 
 	overrideEnvs := []corev1.EnvVar{
 		{Name: "KUBERNETES_SERVICE_PORT", Value: kcpExternalPort},
@@ -117,14 +213,14 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 		{Name: "KUBERNETES_SERVICE_HOST", Value: kcpExternalHost},
 	}
 
-	// This is the VolumeMount that we will append to all the containers of the deployment
+	// This is the VolumeMount that we will append to all the containers of the pod spec
 	serviceAccountMount := corev1.VolumeMount{
 		Name:      "kcp-api-access",
 		MountPath: "/var/run/secrets/kubernetes.io/serviceaccount",
 		ReadOnly:  true,
 	}
 
-	// This is the Volume that we will add to the Deployment in order to control
+	// This is the Volume that we will add to the pod spec in order to control
 	// the name of the ca.crt references (kcp-root-ca.crt vs kube-root-ca.crt)
 	// and the serviceaccount reference.
 	serviceAccountVolume := corev1.Volume{
@@ -169,11 +265,11 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 	}
 
 	// Override Envs, resolve downwardAPI FieldRef and add the VolumeMount to all the containers
-	for i := range deployment.Spec.Template.Spec.Containers {
+	for i := range templateSpec.Containers {
 		for _, overrideEnv := range overrideEnvs {
 			templateSpec.Containers[i].Env = updateEnv(templateSpec.Containers[i].Env, overrideEnv)
 		}
-		templateSpec.Containers[i].Env = resolveDownwardAPIFieldRefEnv(templateSpec.Containers[i].Env, deployment)
+		templateSpec.Containers[i].Env = resolveDownwardAPIFieldRefEnv(templateSpec.Containers[i].Env, namespace)
 		templateSpec.Containers[i].VolumeMounts = updateVolumeMount(templateSpec.Containers[i].VolumeMounts, serviceAccountMount)
 	}
 
@@ -182,7 +278,7 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 		for _, overrideEnv := range overrideEnvs {
 			templateSpec.InitContainers[i].Env = updateEnv(templateSpec.InitContainers[i].Env, overrideEnv)
 		}
-		templateSpec.InitContainers[i].Env = resolveDownwardAPIFieldRefEnv(templateSpec.InitContainers[i].Env, deployment)
+		templateSpec.InitContainers[i].Env = resolveDownwardAPIFieldRefEnv(templateSpec.InitContainers[i].Env, namespace)
 		templateSpec.InitContainers[i].VolumeMounts = updateVolumeMount(templateSpec.InitContainers[i].VolumeMounts, serviceAccountMount)
 	}
 
@@ -191,7 +287,7 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 		for _, overrideEnv := range overrideEnvs {
 			templateSpec.EphemeralContainers[i].Env = updateEnv(templateSpec.EphemeralContainers[i].Env, overrideEnv)
 		}
-		templateSpec.EphemeralContainers[i].Env = resolveDownwardAPIFieldRefEnv(templateSpec.EphemeralContainers[i].Env, deployment)
+		templateSpec.EphemeralContainers[i].Env = resolveDownwardAPIFieldRefEnv(templateSpec.EphemeralContainers[i].Env, namespace)
 		templateSpec.EphemeralContainers[i].VolumeMounts = updateVolumeMount(templateSpec.EphemeralContainers[i].VolumeMounts, serviceAccountMount)
 	}
 
@@ -207,7 +303,7 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 		templateSpec.Volumes = append(templateSpec.Volumes, serviceAccountVolume)
 	}
 
-	unstructured, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&deployment)
+	unstructured, err := runtime.DefaultUnstructuredConverter.ToUnstructured(typedObj)
 	if err != nil {
 		return err
 	}
@@ -218,14 +314,14 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 	return nil
 }
 
-// resolveDownwardAPIFieldRefEnv replaces the downwardAPI FieldRef EnvVars with the value from the deployment, right now it only replaces the metadata.namespace
-func resolveDownwardAPIFieldRefEnv(envs []corev1.EnvVar, deployment appsv1.Deployment) []corev1.EnvVar {
+// resolveDownwardAPIFieldRefEnv replaces the downwardAPI FieldRef EnvVars with the value from the upstream object, right now it only replaces the metadata.namespace
+func resolveDownwardAPIFieldRefEnv(envs []corev1.EnvVar, namespace string) []corev1.EnvVar {
 	var result []corev1.EnvVar
 	for _, env := range envs {
 		if env.ValueFrom != nil && env.ValueFrom.FieldRef != nil && env.ValueFrom.FieldRef.FieldPath == "metadata.namespace" {
 			result = append(result, corev1.EnvVar{
 				Name:  env.Name,
-				Value: deployment.Namespace,
+				Value: namespace,
 			})
 		} else {
 			result = append(result, env)
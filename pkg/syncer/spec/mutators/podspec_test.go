@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutators
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestPodSpecMutators(t *testing.T) {
+	objectMeta := metav1.ObjectMeta{
+		Name:                      "test",
+		Namespace:                 "namespace",
+		ZZZ_DeprecatedClusterName: "root:default:testing",
+	}
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name:  "test",
+			Image: "image",
+		}},
+	}
+	podTemplate := corev1.PodTemplateSpec{Spec: podSpec}
+
+	for _, c := range []struct {
+		desc       string
+		newMutator func(*url.URL, ListSecretFunc) *PodSpecMutator
+		obj        runtime.Object
+		podSpec    []string
+	}{{
+		desc:       "ReplicaSet",
+		newMutator: NewReplicaSetMutator,
+		obj:        &appsv1.ReplicaSet{TypeMeta: metav1.TypeMeta{Kind: "ReplicaSet", APIVersion: "apps/v1"}, ObjectMeta: objectMeta, Spec: appsv1.ReplicaSetSpec{Template: podTemplate}},
+		podSpec:    []string{"spec", "template", "spec"},
+	}, {
+		desc:       "StatefulSet",
+		newMutator: NewStatefulSetMutator,
+		obj:        &appsv1.StatefulSet{TypeMeta: metav1.TypeMeta{Kind: "StatefulSet", APIVersion: "apps/v1"}, ObjectMeta: objectMeta, Spec: appsv1.StatefulSetSpec{Template: podTemplate}},
+		podSpec:    []string{"spec", "template", "spec"},
+	}, {
+		desc:       "DaemonSet",
+		newMutator: NewDaemonSetMutator,
+		obj:        &appsv1.DaemonSet{TypeMeta: metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"}, ObjectMeta: objectMeta, Spec: appsv1.DaemonSetSpec{Template: podTemplate}},
+		podSpec:    []string{"spec", "template", "spec"},
+	}, {
+		desc:       "Job",
+		newMutator: NewJobMutator,
+		obj:        &batchv1.Job{TypeMeta: metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"}, ObjectMeta: objectMeta, Spec: batchv1.JobSpec{Template: podTemplate}},
+		podSpec:    []string{"spec", "template", "spec"},
+	}, {
+		desc:       "CronJob",
+		newMutator: NewCronJobMutator,
+		obj: &batchv1.CronJob{TypeMeta: metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1"}, ObjectMeta: objectMeta, Spec: batchv1.CronJobSpec{
+			JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: podTemplate}},
+		}},
+		podSpec: []string{"spec", "jobTemplate", "spec", "template", "spec"},
+	}, {
+		desc:       "Pod",
+		newMutator: NewPodMutator,
+		obj:        &corev1.Pod{TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"}, ObjectMeta: objectMeta, Spec: podSpec},
+		podSpec:    []string{"spec"},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			upstreamURL, err := url.Parse("https://4.5.6.7:12345")
+			require.NoError(t, err)
+
+			secret, err := toUnstructured(&corev1.Secret{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Secret",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:                      "default-token-1234",
+					Namespace:                 "namespace",
+					ZZZ_DeprecatedClusterName: "root:default:testing",
+					Annotations: map[string]string{
+						corev1.ServiceAccountNameKey: "default",
+					},
+				},
+			})
+			require.NoError(t, err)
+
+			m := c.newMutator(upstreamURL, func(clusterName logicalcluster.Name, namespace string) ([]*unstructured.Unstructured, error) {
+				return []*unstructured.Unstructured{secret}, nil
+			})
+
+			obj, err := toUnstructured(c.obj)
+			require.NoError(t, err)
+			require.NoError(t, m.Mutate(obj))
+
+			unstructuredPodSpec, found, err := unstructured.NestedMap(obj.Object, c.podSpec...)
+			require.NoError(t, err)
+			require.True(t, found, "pod spec not found")
+			var mutatedPodSpec corev1.PodSpec
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredPodSpec, &mutatedPodSpec))
+
+			require.Equal(t, []corev1.Volume{kcpApiAccessVolume}, mutatedPodSpec.Volumes)
+			require.Len(t, mutatedPodSpec.Containers, 1)
+			require.Equal(t, []corev1.VolumeMount{kcpApiAccessVolumeMount}, mutatedPodSpec.Containers[0].VolumeMounts)
+			require.Equal(t, []corev1.EnvVar{
+				{Name: "KUBERNETES_SERVICE_PORT", Value: "12345"},
+				{Name: "KUBERNETES_SERVICE_PORT_HTTPS", Value: "12345"},
+				{Name: "KUBERNETES_SERVICE_HOST", Value: "4.5.6.7"},
+			}, mutatedPodSpec.Containers[0].Env)
+			require.NotNil(t, mutatedPodSpec.AutomountServiceAccountToken)
+			require.False(t, *mutatedPodSpec.AutomountServiceAccountToken)
+		})
+	}
+}
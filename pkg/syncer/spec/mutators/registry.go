@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutators
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Mutator transforms the objects of a resource type on their way down from kcp to the physical cluster.
+type Mutator interface {
+	// GVR returns the resource type whose objects are mutated.
+	GVR() schema.GroupVersionResource
+	// Mutate applies the mutator changes to the downstream object.
+	Mutate(obj *unstructured.Unstructured) error
+}
+
+// StatusMutator is optionally implemented by a Mutator that also needs to transform the objects
+// on their way up from the physical cluster to kcp, typically to revert in the status some of the
+// changes applied by Mutate.
+type StatusMutator interface {
+	Mutator
+	// StatusMutate applies the mutator changes to the upstream object, before its status is updated in kcp.
+	StatusMutate(obj *unstructured.Unstructured) error
+}
+
+// Registry holds the mutators of the synced resource types, at most one per resource type.
+type Registry struct {
+	lock     sync.RWMutex
+	mutators map[schema.GroupVersionResource]Mutator
+}
+
+// NewRegistry returns a Registry holding the given mutators.
+func NewRegistry(mutators ...Mutator) *Registry {
+	r := &Registry{
+		mutators: map[schema.GroupVersionResource]Mutator{},
+	}
+	r.Register(mutators...)
+	return r
+}
+
+// Register adds the given mutators to the registry. A mutator replaces the one
+// previously registered for the same resource type, if any.
+func (r *Registry) Register(mutators ...Mutator) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, mutator := range mutators {
+		r.mutators[mutator.GVR()] = mutator
+	}
+}
+
+// Mutator returns the mutator registered for the given resource type.
+func (r *Registry) Mutator(gvr schema.GroupVersionResource) (Mutator, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	mutator, ok := r.mutators[gvr]
+	return mutator, ok
+}
+
+// StatusMutator returns the mutator registered for the given resource type,
+// if it also transforms the objects on their way up to kcp.
+func (r *Registry) StatusMutator(gvr schema.GroupVersionResource) (StatusMutator, bool) {
+	mutator, ok := r.Mutator(gvr)
+	if !ok {
+		return nil, false
+	}
+	statusMutator, ok := mutator.(StatusMutator)
+	return statusMutator, ok
+}
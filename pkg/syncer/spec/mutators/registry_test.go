@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type fakeMutator struct {
+	gvr schema.GroupVersionResource
+}
+
+func (m *fakeMutator) GVR() schema.GroupVersionResource            { return m.gvr }
+func (m *fakeMutator) Mutate(obj *unstructured.Unstructured) error { return nil }
+
+type fakeStatusMutator struct {
+	fakeMutator
+}
+
+func (m *fakeStatusMutator) StatusMutate(obj *unstructured.Unstructured) error { return nil }
+
+func TestRegistry(t *testing.T) {
+	ingressesGVR := schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	servicesGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"}
+
+	secretMutator := NewSecretMutator()
+	registry := NewRegistry(secretMutator)
+
+	mutator, found := registry.Mutator(secretMutator.GVR())
+	require.True(t, found)
+	require.Equal(t, secretMutator, mutator)
+	_, found = registry.StatusMutator(secretMutator.GVR())
+	require.False(t, found, "the secret mutator does not mutate the status")
+
+	_, found = registry.Mutator(ingressesGVR)
+	require.False(t, found)
+
+	ingressMutator := &fakeStatusMutator{fakeMutator{gvr: ingressesGVR}}
+	registry.Register(ingressMutator, &fakeMutator{gvr: servicesGVR})
+	statusMutator, found := registry.StatusMutator(ingressesGVR)
+	require.True(t, found)
+	require.Equal(t, ingressMutator, statusMutator)
+	_, found = registry.Mutator(servicesGVR)
+	require.True(t, found)
+
+	overridingMutator := &fakeMutator{gvr: secretMutator.GVR()}
+	registry.Register(overridingMutator)
+	mutator, found = registry.Mutator(secretMutator.GVR())
+	require.True(t, found)
+	require.Equal(t, overridingMutator, mutator, "the last registered mutator should win")
+}
@@ -32,11 +32,13 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	"github.com/kcp-dev/kcp/pkg/informer"
+	syncermetrics "github.com/kcp-dev/kcp/pkg/syncer/metrics"
+	"github.com/kcp-dev/kcp/pkg/syncer/resourcesync"
 	"github.com/kcp-dev/kcp/pkg/syncer/shared"
 	specmutators "github.com/kcp-dev/kcp/pkg/syncer/spec/mutators"
 	"github.com/kcp-dev/kcp/third_party/keyfunctions"
@@ -48,132 +50,133 @@ const (
 	byWorkspaceAndNamespaceIndexName = "syncer-spec-WorkspaceNamespace" // will go away with scoping
 )
 
+var (
+	secretsGVR    = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}
+	namespacesGVR = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+)
+
 type Controller struct {
-	queue workqueue.RateLimitingInterface
+	queue   workqueue.RateLimitingInterface
+	metrics *syncermetrics.ControllerMetrics
 
-	mutators mutatorGvrMap
+	mutators *specmutators.Registry
 
-	upstreamClient                         dynamic.ClusterInterface
-	downstreamClient                       dynamic.Interface
-	upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory
+	upstreamClient   dynamic.ClusterInterface
+	downstreamClient dynamic.Interface
+	syncerInformers  *resourcesync.SyncerInformerFactory
 
 	syncTargetName            string
 	syncTargetWorkspace       logicalcluster.Name
 	syncTargetUID             types.UID
 	advancedSchedulingEnabled bool
+
+	// eventRecorderForCluster returns the recorder of the Events on the upstream objects of a workspace.
+	eventRecorderForCluster shared.EventRecorderForCluster
+	// namespaceNamer names the downstream namespaces created for the upstream namespaces.
+	namespaceNamer shared.NamespaceNamerFunc
+
+	now func() time.Time
 }
 
-func NewSpecSyncer(gvrs []schema.GroupVersionResource, syncTargetWorkspace logicalcluster.Name, syncTargetName string, upstreamURL *url.URL, advancedSchedulingEnabled bool,
-	upstreamClient dynamic.ClusterInterface, downstreamClient dynamic.Interface, upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory, syncTargetUID types.UID) (*Controller, error) {
+func NewSpecSyncer(syncTargetWorkspace logicalcluster.Name, syncTargetName string, advancedSchedulingEnabled bool,
+	upstreamClient dynamic.ClusterInterface, downstreamClient dynamic.Interface, syncerInformers *resourcesync.SyncerInformerFactory,
+	mutators *specmutators.Registry, syncTargetUID types.UID, eventRecorderForCluster shared.EventRecorderForCluster, namespaceNamer shared.NamespaceNamerFunc) (*Controller, error) {
 
 	c := Controller{
-		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+		queue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+		metrics: syncermetrics.NewControllerMetrics(syncTargetName, syncermetrics.DirectionSpec),
 
-		upstreamClient:      upstreamClient,
-		downstreamClient:    downstreamClient,
-		upstreamInformers:   upstreamInformers,
-		downstreamInformers: downstreamInformers,
+		upstreamClient:   upstreamClient,
+		downstreamClient: downstreamClient,
+		syncerInformers:  syncerInformers,
+		mutators:         mutators,
 
 		syncTargetName:            syncTargetName,
 		syncTargetWorkspace:       syncTargetWorkspace,
 		syncTargetUID:             syncTargetUID,
 		advancedSchedulingEnabled: advancedSchedulingEnabled,
-	}
 
-	namespaceGVR := schema.GroupVersionResource{
-		Group:    "",
-		Version:  "v1",
-		Resource: "namespaces",
+		eventRecorderForCluster: eventRecorderForCluster,
+		namespaceNamer:          namespaceNamer,
+		now:                     time.Now,
 	}
-	namespaceLister := downstreamInformers.ForResource(namespaceGVR).Lister()
 
-	err := downstreamInformers.ForResource(namespaceGVR).Informer().AddIndexers(cache.Indexers{byNamespaceLocatorIndexName: indexByNamespaceLocator})
+	namespaceLister := syncerInformers.DownstreamNamespaceInformer().Lister()
+
+	err := syncerInformers.DownstreamNamespaceInformer().Informer().AddIndexers(cache.Indexers{byNamespaceLocatorIndexName: indexByNamespaceLocator})
 	if err != nil {
 		return nil, err
 	}
 
-	for _, gvr := range gvrs {
-		gvr := gvr // because used in closure
-
-		upstreamInformers.ForResource(gvr).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-			AddFunc: func(obj interface{}) {
-				c.AddToQueue(gvr, obj)
-			},
-			UpdateFunc: func(oldObj, newObj interface{}) {
-				oldUnstrob := oldObj.(*unstructured.Unstructured)
-				newUnstrob := newObj.(*unstructured.Unstructured)
-
-				if !deepEqualApartFromStatus(oldUnstrob, newUnstrob) {
-					c.AddToQueue(gvr, newUnstrob)
-				}
-			},
-			DeleteFunc: func(obj interface{}) {
-				c.AddToQueue(gvr, obj)
-			},
-		})
-		klog.V(2).InfoS("Set up upstream informer", "syncTarget_workspace", syncTargetWorkspace, "synctarget_name", syncTargetName, "gvr", gvr.String())
-
-		downstreamInformers.ForResource(gvr).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-			DeleteFunc: func(obj interface{}) {
-				key, err := keyfunctions.DeletionHandlingMetaNamespaceKeyFunc(obj)
-				if err != nil {
-					utilruntime.HandleError(fmt.Errorf("error getting key for type %T: %w", obj, err))
-					return
-				}
-				namespace, name, err := cache.SplitMetaNamespaceKey(key)
-				if err != nil {
-					utilruntime.HandleError(fmt.Errorf("error splitting key %q: %w", key, err))
-				}
-				klog.V(3).InfoS("processing  delete event", "key", key, "gvr", gvr, "namespace", namespace, "name", name)
-
-				// Use namespace lister
-				nsObj, err := namespaceLister.Get(namespace)
-				if err != nil {
-					utilruntime.HandleError(err)
-					return
-				}
-				ns, ok := nsObj.(*unstructured.Unstructured)
-				if !ok {
-					utilruntime.HandleError(fmt.Errorf("unexpected object type: %T", nsObj))
-					return
-				}
-				locator, ok := ns.GetAnnotations()[shared.NamespaceLocatorAnnotation]
-				if !ok {
-					utilruntime.HandleError(fmt.Errorf("unable to find the locator annotation in namespace %s", namespace))
-					return
-				}
-				nsLocator := &shared.NamespaceLocator{}
-				err = json.Unmarshal([]byte(locator), nsLocator)
-				if err != nil {
-					utilruntime.HandleError(err)
-					return
-				}
-				klog.V(4).InfoS("found", "NamespaceLocator", nsLocator)
-				m := &metav1.ObjectMeta{
-					ZZZ_DeprecatedClusterName: nsLocator.Workspace.String(),
-					Namespace:                 nsLocator.Namespace,
-					Name:                      name,
-				}
-				c.AddToQueue(gvr, m)
-			},
-		})
-		klog.V(2).InfoS("Set up downstream informer", "SyncTarget Workspace", syncTargetWorkspace, "SyncTarget Name", syncTargetName, "gvr", gvr.String())
-	}
-
-	secretMutator := specmutators.NewSecretMutator()
-
-	upstreamSecretIndexer := upstreamInformers.ForResource(schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}).Informer().GetIndexer()
-	deploymentMutator := specmutators.NewDeploymentMutator(upstreamURL, newSecretLister(upstreamSecretIndexer))
+	syncerInformers.AddUpstreamEventHandler(informer.GVREventHandlerFuncs{
+		AddFunc: func(gvr schema.GroupVersionResource, obj interface{}) {
+			c.AddToQueue(gvr, obj)
+		},
+		UpdateFunc: func(gvr schema.GroupVersionResource, oldObj, newObj interface{}) {
+			oldUnstrob := oldObj.(*unstructured.Unstructured)
+			newUnstrob := newObj.(*unstructured.Unstructured)
 
-	if err := upstreamSecretIndexer.AddIndexers(cache.Indexers{
-		byWorkspaceAndNamespaceIndexName: indexByWorkspaceAndNamespace,
-	}); err != nil {
-		return nil, err
-	}
-	c.mutators = mutatorGvrMap{
-		deploymentMutator.GVR(): deploymentMutator.Mutate,
-		secretMutator.GVR():     secretMutator.Mutate,
-	}
+			if !deepEqualApartFromStatus(oldUnstrob, newUnstrob) {
+				c.AddToQueue(gvr, newUnstrob)
+			}
+		},
+		DeleteFunc: func(gvr schema.GroupVersionResource, obj interface{}) {
+			c.AddToQueue(gvr, obj)
+		},
+	})
+	klog.V(2).InfoS("Set up upstream event handler", "syncTarget_workspace", syncTargetWorkspace, "synctarget_name", syncTargetName)
+
+	syncerInformers.AddDownstreamEventHandler(informer.GVREventHandlerFuncs{
+		DeleteFunc: func(gvr schema.GroupVersionResource, obj interface{}) {
+			key, err := keyfunctions.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			if err != nil {
+				utilruntime.HandleError(fmt.Errorf("error getting key for type %T: %w", obj, err))
+				return
+			}
+			namespace, name, err := cache.SplitMetaNamespaceKey(key)
+			if err != nil {
+				utilruntime.HandleError(fmt.Errorf("error splitting key %q: %w", key, err))
+			}
+			klog.V(3).InfoS("processing  delete event", "key", key, "gvr", gvr, "namespace", namespace, "name", name)
+
+			// The upstream cluster-scoped objects of the same name, in any workspace, may be waiting for the name to be free.
+			if namespace == "" {
+				c.enqueueClusterScoped(gvr, name)
+				return
+			}
+
+			// Use namespace lister
+			nsObj, err := namespaceLister.Get(namespace)
+			if err != nil {
+				utilruntime.HandleError(err)
+				return
+			}
+			ns, ok := nsObj.(*unstructured.Unstructured)
+			if !ok {
+				utilruntime.HandleError(fmt.Errorf("unexpected object type: %T", nsObj))
+				return
+			}
+			locator, ok := ns.GetAnnotations()[shared.NamespaceLocatorAnnotation]
+			if !ok {
+				utilruntime.HandleError(fmt.Errorf("unable to find the locator annotation in namespace %s", namespace))
+				return
+			}
+			nsLocator := &shared.NamespaceLocator{}
+			err = json.Unmarshal([]byte(locator), nsLocator)
+			if err != nil {
+				utilruntime.HandleError(err)
+				return
+			}
+			klog.V(4).InfoS("found", "NamespaceLocator", nsLocator)
+			m := &metav1.ObjectMeta{
+				ZZZ_DeprecatedClusterName: nsLocator.Workspace.String(),
+				Namespace:                 nsLocator.Namespace,
+				Name:                      name,
+			}
+			c.AddToQueue(gvr, m)
+		},
+	})
+	klog.V(2).InfoS("Set up downstream event handler", "SyncTarget Workspace", syncTargetWorkspace, "SyncTarget Name", syncTargetName)
 
 	return &c, nil
 }
@@ -191,6 +194,7 @@ func (c *Controller) AddToQueue(gvr schema.GroupVersionResource, obj interface{}
 	}
 
 	klog.Infof("%s queueing GVR %q %s", controllerName, gvr.String(), key)
+	c.metrics.Enqueued(gvr, key)
 	c.queue.Add(
 		queueKey{
 			gvr: gvr,
@@ -199,6 +203,19 @@ func (c *Controller) AddToQueue(gvr schema.GroupVersionResource, obj interface{}
 	)
 }
 
+// enqueueClusterScoped queues the upstream cluster-scoped objects of the given name in all the workspaces.
+func (c *Controller) enqueueClusterScoped(gvr schema.GroupVersionResource, name string) {
+	upstreamInformer, ok := c.syncerInformers.UpstreamInformer(gvr)
+	if !ok {
+		return
+	}
+	for _, obj := range upstreamInformer.Informer().GetIndexer().List() {
+		if metaObj, ok := obj.(metav1.Object); ok && metaObj.GetNamespace() == "" && metaObj.GetName() == name {
+			c.AddToQueue(gvr, obj)
+		}
+	}
+}
+
 // Start starts N worker processes processing work items.
 func (c *Controller) Start(ctx context.Context, numThreads int) {
 	defer utilruntime.HandleCrash()
@@ -226,13 +243,18 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 		return false
 	}
 	qk := key.(queueKey)
+	c.metrics.Dequeued(qk.gvr, qk.key)
 
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
 	defer c.queue.Done(key)
 
-	if err := c.process(ctx, qk.gvr, qk.key); err != nil {
+	start := time.Now()
+	err := c.process(ctx, qk.gvr, qk.key)
+	c.metrics.Synced(qk.gvr, start)
+	if err != nil {
 		utilruntime.HandleError(fmt.Errorf("%s failed to sync %q, err: %w", controllerName, key, err))
+		c.metrics.Retried(qk.gvr, qk.key)
 		c.queue.AddRateLimited(key)
 		return true
 	}
@@ -242,9 +264,28 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	return true
 }
 
-func newSecretLister(secretIndexer cache.Indexer) specmutators.ListSecretFunc {
+// NewMutatorRegistry returns a registry holding the built-in mutators of the spec syncer, and the given
+// mutators, which take precedence over the built-in ones.
+func NewMutatorRegistry(upstreamURL *url.URL, syncerInformers *resourcesync.SyncerInformerFactory, mutators ...specmutators.Mutator) *specmutators.Registry {
+	// The mutators of the resources carrying a pod spec look up the service account tokens
+	// of the upstream namespaces.
+	syncerInformers.AddUpstreamIndexers(secretsGVR, cache.Indexers{
+		byWorkspaceAndNamespaceIndexName: indexByWorkspaceAndNamespace,
+	})
+
+	registry := specmutators.NewRegistry(specmutators.NewSecretMutator())
+	registry.Register(specmutators.NewPodSpecMutators(upstreamURL, newSecretLister(syncerInformers))...)
+	registry.Register(mutators...)
+	return registry
+}
+
+func newSecretLister(syncerInformers *resourcesync.SyncerInformerFactory) specmutators.ListSecretFunc {
 	return func(clusterName logicalcluster.Name, namespace string) ([]*unstructured.Unstructured, error) {
-		secretList, err := secretIndexer.ByIndex(byWorkspaceAndNamespaceIndexName, workspaceAndNamespaceIndexKey(clusterName, namespace))
+		secretInformer, ok := syncerInformers.UpstreamInformer(secretsGVR)
+		if !ok {
+			return nil, fmt.Errorf("secrets are not synced for workspace %s", clusterName)
+		}
+		secretList, err := secretInformer.Informer().GetIndexer().ByIndex(byWorkspaceAndNamespaceIndexName, workspaceAndNamespaceIndexKey(clusterName, namespace))
 		if err != nil {
 			return nil, fmt.Errorf("error listing secrets for workspace %s: %w", clusterName, err)
 		}
@@ -23,7 +23,6 @@ import (
 	"reflect"
 	"strings"
 
-	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/kcp-dev/logicalcluster/v2"
 
 	corev1 "k8s.io/api/core/v1"
@@ -33,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clusters"
@@ -47,18 +47,17 @@ const (
 	syncerApplyManager = "syncer"
 )
 
-type mutatorGvrMap map[schema.GroupVersionResource]func(obj *unstructured.Unstructured) error
-
 func deepEqualApartFromStatus(oldUnstrob, newUnstrob *unstructured.Unstructured) bool {
 	// TODO(jmprusi): Remove this after switching to virtual workspaces.
-	// remove status annotation from oldObj and newObj before comparing
+	// remove status, sync error and spec diff error annotations from oldObj and newObj before comparing
 	oldAnnotations, _, err := unstructured.NestedStringMap(oldUnstrob.Object, "metadata", "annotations")
 	if err != nil {
 		klog.Errorf("failed to get annotations from object: %v", err)
 		return false
 	}
 	for k := range oldAnnotations {
-		if strings.HasPrefix(k, workloadv1alpha1.InternalClusterStatusAnnotationPrefix) {
+		if strings.HasPrefix(k, workloadv1alpha1.InternalClusterStatusAnnotationPrefix) || strings.HasPrefix(k, workloadv1alpha1.ClusterSyncErrorAnnotationPrefix) ||
+			strings.HasPrefix(k, workloadv1alpha1.ClusterSpecDiffErrorAnnotationPrefix) {
 			delete(oldAnnotations, k)
 		}
 	}
@@ -69,7 +68,8 @@ func deepEqualApartFromStatus(oldUnstrob, newUnstrob *unstructured.Unstructured)
 		return false
 	}
 	for k := range newAnnotations {
-		if strings.HasPrefix(k, workloadv1alpha1.InternalClusterStatusAnnotationPrefix) {
+		if strings.HasPrefix(k, workloadv1alpha1.InternalClusterStatusAnnotationPrefix) || strings.HasPrefix(k, workloadv1alpha1.ClusterSyncErrorAnnotationPrefix) ||
+			strings.HasPrefix(k, workloadv1alpha1.ClusterSpecDiffErrorAnnotationPrefix) {
 			delete(newAnnotations, k)
 		}
 	}
@@ -114,13 +114,16 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 	}
 	clusterName, name := clusters.SplitClusterAwareKey(clusterAwareName)
 
-	namespaceGvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+	if upstreamNamespace == "" {
+		return c.processClusterScoped(ctx, gvr, key, clusterName, name)
+	}
+
 	desiredNSLocator := shared.NewNamespaceLocator(clusterName, c.syncTargetWorkspace, c.syncTargetUID, c.syncTargetName, upstreamNamespace)
 	jsonNSLocator, err := json.Marshal(desiredNSLocator)
 	if err != nil {
 		return err
 	}
-	downstreamNamespaces, err := c.downstreamInformers.ForResource(namespaceGvr).Informer().GetIndexer().ByIndex(byNamespaceLocatorIndexName, string(jsonNSLocator))
+	downstreamNamespaces, err := c.syncerInformers.DownstreamNamespaceInformer().Informer().GetIndexer().ByIndex(byNamespaceLocatorIndexName, string(jsonNSLocator))
 	if err != nil {
 		return err
 	}
@@ -131,7 +134,7 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 		if err != nil {
 			return err
 		}
-		downstreamNamespaces, err = c.downstreamInformers.ForResource(namespaceGvr).Informer().GetIndexer().ByIndex(byNamespaceLocatorIndexName, string(jsonNSLocator))
+		downstreamNamespaces, err = c.syncerInformers.DownstreamNamespaceInformer().Informer().GetIndexer().ByIndex(byNamespaceLocatorIndexName, string(jsonNSLocator))
 		if err != nil {
 			return err
 		}
@@ -152,15 +155,19 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 		return fmt.Errorf("(namespace collision) found multiple downstream namespaces: %s for upstream namespace %s|%s", strings.Join(namespacesCollisions, ","), clusterName, upstreamNamespace)
 	} else {
 		klog.V(4).Infof("No downstream namespaces found for %s", key)
-		downstreamNamespace, err = shared.PhysicalClusterNamespaceName(desiredNSLocator)
+		downstreamNamespace, err = c.newDownstreamNamespaceName(ctx, desiredNSLocator)
 		if err != nil {
-			klog.Errorf("Error hashing namespace %s|%s: %v", clusterName, upstreamNamespace, err)
-			return nil
+			return err
 		}
 	}
 
 	// get the upstream object
-	obj, exists, err := c.upstreamInformers.ForResource(gvr).Informer().GetIndexer().GetByKey(key)
+	upstreamInformer, ok := c.syncerInformers.UpstreamInformer(gvr)
+	if !ok {
+		klog.V(3).Infof("GVR %q is not synced anymore, skipping %s", gvr.String(), key)
+		return nil
+	}
+	obj, exists, err := upstreamInformer.Informer().GetIndexer().GetByKey(key)
 	if err != nil {
 		return err
 	}
@@ -181,8 +188,39 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 	return c.applyToDownstream(ctx, gvr, downstreamNamespace, u)
 }
 
+// newDownstreamNamespaceName returns the name of the downstream namespace to create for the given locator,
+// according to the naming strategy of the SyncTarget. Names already taken by other namespaces are skipped.
+func (c *Controller) newDownstreamNamespaceName(ctx context.Context, locator shared.NamespaceLocator) (string, error) {
+	candidates, err := c.namespaceNamer().DownstreamNamespaceNames(locator)
+	if err != nil {
+		return "", fmt.Errorf("error naming the downstream namespace of %s|%s: %w", locator.Workspace, locator.Namespace, err)
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	// The downstream namespace informer only holds the namespaces of the SyncTarget, which is not enough to
+	// find all the namespaces taken.
+	for _, candidate := range candidates {
+		existing, err := c.downstreamClient.Resource(namespacesGVR).Get(ctx, candidate, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		existingLocator, exists, err := shared.LocatorFromAnnotations(existing.GetAnnotations())
+		if err == nil && exists && reflect.DeepEqual(locator, *existingLocator) {
+			return candidate, nil
+		}
+		klog.V(2).Infof("Downstream namespace %s is taken, not using it for upstream namespace %s|%s", candidate, locator.Workspace, locator.Namespace)
+	}
+	return "", fmt.Errorf("(namespace collision) downstream namespaces %s are all taken for upstream namespace %s|%s", strings.Join(candidates, ","), locator.Workspace, locator.Namespace)
+}
+
 // TODO: This function is there as a quick and dirty implementation of namespace creation.
-//       In fact We should also be getting notifications about namespaces created upstream and be creating downstream equivalents.
+//
+//	In fact We should also be getting notifications about namespaces created upstream and be creating downstream equivalents.
 func (c *Controller) ensureDownstreamNamespaceExists(ctx context.Context, downstreamNamespace string, upstreamObj *unstructured.Unstructured) error {
 	namespaces := c.downstreamClient.Resource(schema.GroupVersionResource{
 		Group:    "",
@@ -215,7 +253,7 @@ func (c *Controller) ensureDownstreamNamespaceExists(ctx context.Context, downst
 	}
 
 	// Check if the namespace already exists, if not create it.
-	namespace, err := c.downstreamInformers.ForResource(schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}).Lister().Get(newNamespace.GetName())
+	namespace, err := c.syncerInformers.DownstreamNamespaceInformer().Lister().Get(newNamespace.GetName())
 	if err != nil && apierrors.IsNotFound(err) {
 		if _, err := namespaces.Create(ctx, newNamespace, metav1.CreateOptions{}); err != nil {
 			return err
@@ -268,9 +306,13 @@ func (c *Controller) ensureSyncerFinalizer(ctx context.Context, gvr schema.Group
 	return nil
 }
 
+// applyToDownstream applies the upstream object to the given downstream namespace, or to the cluster scope
+// if downstreamNamespace is empty.
 func (c *Controller) applyToDownstream(ctx context.Context, gvr schema.GroupVersionResource, downstreamNamespace string, upstreamObj *unstructured.Unstructured) error {
-	if err := c.ensureDownstreamNamespaceExists(ctx, downstreamNamespace, upstreamObj); err != nil {
-		return err
+	if downstreamNamespace != "" {
+		if err := c.ensureDownstreamNamespaceExists(ctx, downstreamNamespace, upstreamObj); err != nil {
+			return err
+		}
 	}
 
 	if err := c.ensureSyncerFinalizer(ctx, gvr, upstreamObj); err != nil {
@@ -283,9 +325,43 @@ func (c *Controller) applyToDownstream(ctx context.Context, gvr schema.GroupVers
 	// Run name transformations on the downstreamObj.
 	transformedName := getTransformedName(downstreamObj)
 
+	// TODO(jmprusi): When using syncer virtual workspace we would check the DeletionTimestamp on the upstream object, instead of the DeletionTimestamp annotation,
+	//                as the virtual workspace will set the the deletionTimestamp() on the location view by a transformation.
+	intendedToBeRemovedFromLocation := upstreamObj.GetAnnotations()[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+c.syncTargetName] != ""
+
+	// TODO(jmprusi): When using syncer virtual workspace this condition would not be necessary anymore, since directly tested on the virtual workspace side.
+	stillOwnedByExternalActorForLocation := shared.HasClusterFinalizers(upstreamObj, c.syncTargetName)
+
+	if intendedToBeRemovedFromLocation && !stillOwnedByExternalActorForLocation {
+		if err := c.downstreamClient.Resource(gvr).Namespace(downstreamNamespace).Delete(ctx, transformedName, metav1.DeleteOptions{}); err != nil {
+			if apierrors.IsNotFound(err) {
+				// That's not an error.
+				// Just think about removing the finalizer from the KCP location-specific resource:
+				if err := shared.EnsureUpstreamFinalizerRemoved(ctx, gvr, c.upstreamClient, upstreamObj.GetNamespace(), c.syncTargetName, upstreamObjLogicalCluster, upstreamObj.GetName()); err != nil {
+					return err
+				}
+				return nil
+			}
+			klog.Errorf("Error deleting %s %s/%s from downstream %s|%s/%s: %v", gvr.Resource, upstreamObj.GetNamespace(), upstreamObj.GetName(), logicalcluster.From(upstreamObj), downstreamNamespace, transformedName, err)
+			return err
+		}
+		klog.V(2).Infof("Deleted %s %s/%s from downstream %s|%s/%s", gvr.Resource, upstreamObj.GetNamespace(), transformedName, logicalcluster.From(upstreamObj), downstreamNamespace, transformedName)
+		return nil
+	}
+
+	// Apply the spec diff of the SyncTarget before any other transformation, as it is expressed
+	// against the upstream spec.
+	if c.advancedSchedulingEnabled {
+		if err := c.applySpecDiff(upstreamObj, downstreamObj); err != nil {
+			klog.Errorf("Failed to apply the spec diff of %s %s|%s/%s for SyncTarget %s: %v", gvr.Resource, upstreamObjLogicalCluster, upstreamObj.GetNamespace(), upstreamObj.GetName(), c.syncTargetName, err)
+			// The spec diff will not apply until the upstream object changes, so only retry recording the failure.
+			return c.updateSpecDiffError(ctx, gvr, upstreamObj, err)
+		}
+	}
+
 	// Run any transformations on the object before we apply it to the downstream cluster.
-	if mutator, ok := c.mutators[gvr]; ok {
-		if err := mutator(downstreamObj); err != nil {
+	if mutator, ok := c.mutators.Mutator(gvr); ok {
+		if err := mutator.Mutate(downstreamObj); err != nil {
 			return err
 		}
 	}
@@ -304,6 +380,18 @@ func (c *Controller) applyToDownstream(ctx context.Context, gvr schema.GroupVers
 	downstreamObj.SetOwnerReferences(nil)
 	// Strip finalizers to avoid the deletion of the downstream resource from being blocked.
 	downstreamObj.SetFinalizers(nil)
+	// The sync and spec diff errors are only meant for the upstream users.
+	if annotations := downstreamObj.GetAnnotations(); annotations != nil {
+		for k := range annotations {
+			if strings.HasPrefix(k, workloadv1alpha1.ClusterSyncErrorAnnotationPrefix) || strings.HasPrefix(k, workloadv1alpha1.ClusterSpecDiffErrorAnnotationPrefix) {
+				delete(annotations, k)
+			}
+		}
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		downstreamObj.SetAnnotations(annotations)
+	}
 
 	// replace upstream state label with downstream cluster label. We don't want to leak upstream state machine
 	// state to downstream, and also we don't need downstream updates every time the upstream state machine changes.
@@ -312,65 +400,15 @@ func (c *Controller) applyToDownstream(ctx context.Context, gvr schema.GroupVers
 	labels[workloadv1alpha1.InternalDownstreamClusterLabel] = c.syncTargetName
 	downstreamObj.SetLabels(labels)
 
-	if c.advancedSchedulingEnabled {
-		specDiffPatch := upstreamObj.GetAnnotations()[workloadv1alpha1.ClusterSpecDiffAnnotationPrefix+c.syncTargetName]
-		if specDiffPatch != "" {
-			upstreamSpec, specExists, err := unstructured.NestedFieldCopy(upstreamObj.UnstructuredContent(), "spec")
-			if err != nil {
-				return err
-			}
-			if specExists {
-				// TODO(jmprusi): Surface those errors to the user.
-				patch, err := jsonpatch.DecodePatch([]byte(specDiffPatch))
-				if err != nil {
-					klog.Errorf("Failed to decode spec diff patch: %v", err)
-					return err
-				}
-				upstreamSpecJSON, err := json.Marshal(upstreamSpec)
-				if err != nil {
-					return err
-				}
-				patchedUpstreamSpecJSON, err := patch.Apply(upstreamSpecJSON)
-				if err != nil {
-					return err
-				}
-				var newSpec map[string]interface{}
-				if err := json.Unmarshal(patchedUpstreamSpecJSON, &newSpec); err != nil {
-					return err
-				}
-				if err := unstructured.SetNestedMap(downstreamObj.UnstructuredContent(), newSpec, "spec"); err != nil {
-					return err
-				}
-			}
+	if downstreamNamespace == "" {
+		if err := c.setDownstreamOwner(downstreamObj, upstreamObjLogicalCluster); err != nil {
+			return err
 		}
 	}
+
 	// TODO: wipe things like finalizers, owner-refs and any other life-cycle fields. The life-cycle
 	//       should exclusively owned by the syncer. Let's not some Kubernetes magic interfere with it.
 
-	// TODO(jmprusi): When using syncer virtual workspace we would check the DeletionTimestamp on the upstream object, instead of the DeletionTimestamp annotation,
-	//                as the virtual workspace will set the the deletionTimestamp() on the location view by a transformation.
-	intendedToBeRemovedFromLocation := upstreamObj.GetAnnotations()[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+c.syncTargetName] != ""
-
-	// TODO(jmprusi): When using syncer virtual workspace this condition would not be necessary anymore, since directly tested on the virtual workspace side.
-	stillOwnedByExternalActorForLocation := upstreamObj.GetAnnotations()[workloadv1alpha1.ClusterFinalizerAnnotationPrefix+c.syncTargetName] != ""
-
-	if intendedToBeRemovedFromLocation && !stillOwnedByExternalActorForLocation {
-		if err := c.downstreamClient.Resource(gvr).Namespace(downstreamNamespace).Delete(ctx, downstreamObj.GetName(), metav1.DeleteOptions{}); err != nil {
-			if apierrors.IsNotFound(err) {
-				// That's not an error.
-				// Just think about removing the finalizer from the KCP location-specific resource:
-				if err := shared.EnsureUpstreamFinalizerRemoved(ctx, gvr, c.upstreamClient, upstreamObj.GetNamespace(), c.syncTargetName, upstreamObjLogicalCluster, upstreamObj.GetName()); err != nil {
-					return err
-				}
-				return nil
-			}
-			klog.Errorf("Error deleting %s %s/%s from downstream %s|%s/%s: %v", gvr.Resource, upstreamObj.GetNamespace(), upstreamObj.GetName(), logicalcluster.From(upstreamObj), downstreamNamespace, downstreamObj.GetName(), err)
-			return err
-		}
-		klog.V(2).Infof("Deleted %s %s/%s from downstream %s|%s/%s", gvr.Resource, upstreamObj.GetNamespace(), downstreamObj.GetName(), logicalcluster.From(upstreamObj), downstreamNamespace, downstreamObj.GetName())
-		return nil
-	}
-
 	// Marshalling the unstructured object is good enough as SSA patch
 	data, err := json.Marshal(downstreamObj)
 	if err != nil {
@@ -379,10 +417,24 @@ func (c *Controller) applyToDownstream(ctx context.Context, gvr schema.GroupVers
 
 	if _, err := c.downstreamClient.Resource(gvr).Namespace(downstreamNamespace).Patch(ctx, downstreamObj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: syncerApplyManager, Force: pointer.Bool(true)}); err != nil {
 		klog.Errorf("Error upserting %s %s/%s from upstream %s|%s/%s: %v", gvr.Resource, downstreamObj.GetNamespace(), downstreamObj.GetName(), logicalcluster.From(upstreamObj), upstreamObj.GetNamespace(), upstreamObj.GetName(), err)
+		// Let the user know why the object doesn't show up downstream. The apply is still retried, as the
+		// rejection may not last, for instance when quota frees up.
+		if shared.IsDownstreamRejection(err) {
+			if err := c.updateSyncError(ctx, gvr, upstreamObj, err); err != nil {
+				utilruntime.HandleError(err)
+			}
+		}
 		return err
 	}
 	klog.Infof("Upserted %s %s/%s from upstream %s|%s/%s", gvr.Resource, downstreamObj.GetNamespace(), downstreamObj.GetName(), logicalcluster.From(upstreamObj), upstreamObj.GetNamespace(), upstreamObj.GetName())
 
+	if err := c.updateSyncError(ctx, gvr, upstreamObj, nil); err != nil {
+		return err
+	}
+
+	if c.advancedSchedulingEnabled {
+		return c.updateSpecDiffError(ctx, gvr, upstreamObj, nil)
+	}
 	return nil
 }
 
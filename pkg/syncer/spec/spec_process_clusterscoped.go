@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+// processClusterScoped syncs a cluster-scoped upstream object. Cluster-scoped objects keep their name
+// downstream, so the objects of the same name in different workspaces collide. The downstream object
+// records the workspace owning it in its namespace locator annotation, and in the owner label. Objects
+// owned by another workspace, or not created by the syncer, are neither overwritten nor deleted, and the
+// conflict is reported on the upstream object and on the SyncTarget.
+func (c *Controller) processClusterScoped(ctx context.Context, gvr schema.GroupVersionResource, key string, clusterName logicalcluster.Name, name string) error {
+	upstreamInformer, ok := c.syncerInformers.UpstreamInformer(gvr)
+	if !ok {
+		klog.V(3).Infof("GVR %q is not synced anymore, skipping %s", gvr.String(), key)
+		return nil
+	}
+	obj, exists, err := upstreamInformer.Informer().GetIndexer().GetByKey(key)
+	if err != nil {
+		return err
+	}
+
+	downstreamObj, owner, err := c.getClusterScopedDownstream(ctx, gvr, name)
+	if err != nil {
+		return err
+	}
+	owned := downstreamObj == nil || ownedBy(owner, clusterName)
+
+	if !exists {
+		// deleted upstream => delete downstream, if owned by the workspace
+		if downstreamObj == nil || !owned {
+			return nil
+		}
+		klog.Infof("Deleting downstream GVR %q object %s for upstream cluster %q", gvr.String(), name, clusterName)
+		if err := c.downstreamClient.Resource(gvr).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	upstreamObj, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("object to synchronize is expected to be Unstructured, but is %T", obj)
+	}
+	if owned {
+		return c.applyToDownstream(ctx, gvr, "", upstreamObj)
+	}
+
+	ownerDescription := "which was not created by the syncer"
+	if owner != nil {
+		ownerDescription = fmt.Sprintf("owned by workspace %s", owner.Workspace)
+	}
+	klog.V(2).Infof("Not syncing GVR %q object %s|%s, the cluster already has an object of the same name %s", gvr.String(), clusterName, name, ownerDescription)
+
+	// Nothing was synced downstream, so there is nothing to wait for before removing the object from the SyncTarget.
+	if upstreamObj.GetAnnotations()[workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix+c.syncTargetName] != "" {
+		return shared.EnsureUpstreamFinalizerRemoved(ctx, gvr, c.upstreamClient, "", c.syncTargetName, clusterName, name)
+	}
+	conflict := apierrors.NewConflict(gvr.GroupResource(), name, fmt.Errorf("the cluster already has an object of the same name %s", ownerDescription))
+	return c.updateSyncError(ctx, gvr, upstreamObj, conflict)
+}
+
+// getClusterScopedDownstream returns the downstream cluster-scoped object of the given name, and the locator
+// of the workspace owning it, which is nil if the object was not created by the syncer for the SyncTarget.
+// It returns a nil object if it doesn't exist downstream.
+func (c *Controller) getClusterScopedDownstream(ctx context.Context, gvr schema.GroupVersionResource, name string) (*unstructured.Unstructured, *shared.NamespaceLocator, error) {
+	var downstreamObj *unstructured.Unstructured
+	if downstreamInformer, ok := c.syncerInformers.DownstreamInformer(gvr); ok {
+		obj, exists, err := downstreamInformer.Informer().GetIndexer().GetByKey(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if exists {
+			downstreamObj, ok = obj.(*unstructured.Unstructured)
+			if !ok {
+				return nil, nil, fmt.Errorf("downstream object is expected to be Unstructured, but is %T", obj)
+			}
+		}
+	}
+	if downstreamObj == nil {
+		// The downstream informer only holds the objects of the SyncTarget, which is not enough to find the
+		// objects taking the name.
+		var err error
+		downstreamObj, err = c.downstreamClient.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if downstreamObj.GetLabels()[workloadv1alpha1.InternalDownstreamClusterLabel] != c.syncTargetName {
+		return downstreamObj, nil, nil
+	}
+	if locator, exists, err := shared.LocatorFromAnnotations(downstreamObj.GetAnnotations()); err == nil && exists && locator.SyncTarget.UID == c.syncTargetUID {
+		return downstreamObj, locator, nil
+	}
+	return downstreamObj, nil, nil
+}
+
+// ownedBy returns true if the downstream owner locator is the one of the given workspace.
+func ownedBy(owner *shared.NamespaceLocator, workspace logicalcluster.Name) bool {
+	return owner != nil && owner.Workspace == workspace
+}
+
+// setDownstreamOwner records the workspace owning the downstream cluster-scoped object in its namespace locator
+// annotation and its owner label.
+func (c *Controller) setDownstreamOwner(downstreamObj *unstructured.Unstructured, workspace logicalcluster.Name) error {
+	locator := shared.NewNamespaceLocator(workspace, c.syncTargetWorkspace, c.syncTargetUID, c.syncTargetName, "")
+	locatorJSON, err := json.Marshal(locator)
+	if err != nil {
+		return err
+	}
+
+	annotations := downstreamObj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[shared.NamespaceLocatorAnnotation] = string(locatorJSON)
+	downstreamObj.SetAnnotations(annotations)
+
+	labels := downstreamObj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[workloadv1alpha1.InternalDownstreamOwnerLabel] = shared.DownstreamOwnerLabelValue(workspace)
+	downstreamObj.SetLabels(labels)
+	return nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/client-go/tools/record"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/resourcesync"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+var priorityClassesGVR = schema.GroupVersionResource{Group: "scheduling.k8s.io", Version: "v1", Resource: "priorityclasses"}
+
+func priorityClass(name, clusterName string, labels, annotations map[string]string) *schedulingv1.PriorityClass {
+	return &schedulingv1.PriorityClass{
+		TypeMeta: metav1.TypeMeta{APIVersion: "scheduling.k8s.io/v1", Kind: "PriorityClass"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:                      name,
+			ZZZ_DeprecatedClusterName: clusterName,
+			Labels:                    labels,
+			Annotations:               annotations,
+		},
+		Value: 1000,
+	}
+}
+
+func downstreamOwnerAnnotations(t *testing.T, workspace string) map[string]string {
+	locator := shared.NewNamespaceLocator(logicalcluster.New(workspace), logicalcluster.New("root:org:ws"), "syncTargetUID", "us-west1", "")
+	locatorJSON, err := json.Marshal(locator)
+	require.NoError(t, err)
+	return map[string]string{shared.NamespaceLocatorAnnotation: string(locatorJSON)}
+}
+
+func TestSyncerProcessClusterScoped(t *testing.T) {
+	syncedLabels := map[string]string{"state.workload.kcp.dev/us-west1": "Sync"}
+	downstreamLabels := map[string]string{"internal.workload.kcp.dev/cluster": "us-west1"}
+
+	tests := map[string]struct {
+		upstreamObject   runtime.Object
+		downstreamObject runtime.Object
+
+		wantDownstreamVerbs []string
+		wantApplied         bool
+		wantDeleted         bool
+		wantSyncError       string
+		wantEvents          []string
+	}{
+		"created downstream with its owner": {
+			upstreamObject:      priorityClass("high", "root:org:ws", syncedLabels, nil),
+			wantDownstreamVerbs: []string{"get", "patch"},
+			wantApplied:         true,
+		},
+		"updated downstream when owned by the workspace": {
+			upstreamObject:      priorityClass("high", "root:org:ws", syncedLabels, nil),
+			downstreamObject:    priorityClass("high", "", downstreamLabels, downstreamOwnerAnnotations(t, "root:org:ws")),
+			wantDownstreamVerbs: []string{"patch"},
+			wantApplied:         true,
+		},
+		"conflict with an object not created by the syncer": {
+			upstreamObject:      priorityClass("high", "root:org:ws", syncedLabels, nil),
+			downstreamObject:    priorityClass("high", "", nil, nil),
+			wantDownstreamVerbs: []string{"get"},
+			wantSyncError:       "Conflict",
+			wantEvents: []string{
+				`Warning DownstreamConflict priorityclasses.scheduling.k8s.io conflicts with the cluster of SyncTarget us-west1: Operation cannot be fulfilled on priorityclasses.scheduling.k8s.io "high": the cluster already has an object of the same name which was not created by the syncer involvedObject{kind=PriorityClass,apiVersion=scheduling.k8s.io/v1}`,
+			},
+		},
+		"conflict with an object owned by another workspace": {
+			upstreamObject:   priorityClass("high", "root:org:ws", syncedLabels, nil),
+			downstreamObject: priorityClass("high", "", downstreamLabels, downstreamOwnerAnnotations(t, "root:org:other")),
+			wantSyncError:    "Conflict",
+			wantEvents: []string{
+				`Warning DownstreamConflict priorityclasses.scheduling.k8s.io conflicts with the cluster of SyncTarget us-west1: Operation cannot be fulfilled on priorityclasses.scheduling.k8s.io "high": the cluster already has an object of the same name owned by workspace root:org:other involvedObject{kind=PriorityClass,apiVersion=scheduling.k8s.io/v1}`,
+			},
+		},
+		"deleted downstream when deleted upstream": {
+			downstreamObject:    priorityClass("high", "", downstreamLabels, downstreamOwnerAnnotations(t, "root:org:ws")),
+			wantDownstreamVerbs: []string{"delete"},
+			wantDeleted:         true,
+		},
+		"not deleted downstream when owned by another workspace": {
+			downstreamObject: priorityClass("high", "", downstreamLabels, downstreamOwnerAnnotations(t, "root:org:other")),
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var upstreamObjects, downstreamObjects []runtime.Object
+			if tc.upstreamObject != nil {
+				upstreamObjects = append(upstreamObjects, tc.upstreamObject)
+			}
+			if tc.downstreamObject != nil {
+				downstreamObjects = append(downstreamObjects, tc.downstreamObject)
+			}
+			fromClient := dynamicfake.NewSimpleDynamicClient(scheme, upstreamObjects...)
+			fromClusterClient := &mockedDynamicCluster{client: fromClient}
+			toClient := dynamicfake.NewSimpleDynamicClient(scheme, downstreamObjects...)
+			setupServersideApplyPatchReactor(toClient)
+			resourceWatcherStarted := setupWatchReactor(priorityClassesGVR.Resource, fromClient)
+
+			syncerInformers := resourcesync.NewSyncerInformerFactory(
+				fromClusterClient.Cluster(logicalcluster.Wildcard), func(o *metav1.ListOptions) {
+					o.LabelSelector = workloadv1alpha1.ClusterResourceStateLabelPrefix + "us-west1=" + string(workloadv1alpha1.ResourceStateSync)
+				},
+				toClient, func(o *metav1.ListOptions) {
+					o.LabelSelector = workloadv1alpha1.InternalDownstreamClusterLabel + "=us-west1"
+				},
+				func(ctx context.Context) ([]schema.GroupVersionResource, error) {
+					return []schema.GroupVersionResource{priorityClassesGVR}, nil
+				},
+				time.Hour, time.Hour,
+			)
+
+			upstreamURL, err := url.Parse("https://kcp.dev:6443")
+			require.NoError(t, err)
+			eventRecorder := record.NewFakeRecorder(10)
+			eventRecorder.IncludeObject = true
+			eventRecorderForCluster := func(clusterName logicalcluster.Name) record.EventRecorder {
+				assert.Equal(t, logicalcluster.New("root:org:ws"), clusterName)
+				return eventRecorder
+			}
+			controller, err := NewSpecSyncer(logicalcluster.New("root:org:ws"), "us-west1", false, fromClusterClient, toClient, syncerInformers,
+				NewMutatorRegistry(upstreamURL, syncerInformers), "syncTargetUID", eventRecorderForCluster, func() *shared.NamespaceNamer { return nil })
+			require.NoError(t, err)
+
+			require.NoError(t, syncerInformers.Start(ctx))
+			syncerInformers.WaitForCacheSync(ctx.Done())
+			<-resourceWatcherStarted
+
+			fromClient.ClearActions()
+			toClient.ClearActions()
+
+			err = controller.process(ctx, priorityClassesGVR, clusters.ToClusterAwareKey(logicalcluster.New("root:org:ws"), "high"))
+			require.NoError(t, err)
+
+			var verbs []string
+			for _, action := range toClient.Actions() {
+				verbs = append(verbs, action.GetVerb())
+				switch action := action.(type) {
+				case clienttesting.PatchAction:
+					require.True(t, tc.wantApplied, "unexpected apply")
+					require.Equal(t, types.ApplyPatchType, action.GetPatchType())
+					applied := &unstructured.Unstructured{}
+					require.NoError(t, json.Unmarshal(action.GetPatch(), applied))
+					require.Equal(t, map[string]string{
+						"internal.workload.kcp.dev/cluster": "us-west1",
+						"internal.workload.kcp.dev/owner":   shared.DownstreamOwnerLabelValue(logicalcluster.New("root:org:ws")),
+					}, applied.GetLabels())
+					require.Equal(t, downstreamOwnerAnnotations(t, "root:org:ws"), applied.GetAnnotations())
+				case clienttesting.DeleteAction:
+					require.True(t, tc.wantDeleted, "unexpected delete")
+				}
+			}
+			require.Equal(t, tc.wantDownstreamVerbs, verbs)
+
+			if tc.upstreamObject != nil {
+				upstreamObj, err := fromClient.Resource(priorityClassesGVR).Get(ctx, "high", metav1.GetOptions{})
+				require.NoError(t, err)
+				var syncError shared.SyncError
+				if value, found := upstreamObj.GetAnnotations()[workloadv1alpha1.ClusterSyncErrorAnnotationPrefix+"us-west1"]; found {
+					require.NoError(t, json.Unmarshal([]byte(value), &syncError))
+				}
+				require.Equal(t, tc.wantSyncError, syncError.Reason)
+			}
+
+			var events []string
+			for len(eventRecorder.Events) > 0 {
+				events = append(events, <-eventRecorder.Events)
+			}
+			require.Equal(t, tc.wantEvents, events)
+		})
+	}
+}
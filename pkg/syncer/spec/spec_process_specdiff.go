@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog/v2"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+// applySpecDiff applies to the spec of the downstream object the JSON patch found in the
+// experimental.spec-diff.workload.kcp.dev/<sync-target-name> annotation of the upstream object, if any.
+func (c *Controller) applySpecDiff(upstreamObj, downstreamObj *unstructured.Unstructured) error {
+	specDiff := upstreamObj.GetAnnotations()[workloadv1alpha1.ClusterSpecDiffAnnotationPrefix+c.syncTargetName]
+	if specDiff == "" {
+		return nil
+	}
+	upstreamSpec, specExists, err := unstructured.NestedFieldCopy(upstreamObj.UnstructuredContent(), "spec")
+	if err != nil {
+		return err
+	}
+	if !specExists {
+		return nil
+	}
+
+	patch, err := shared.DecodeSpecDiff(specDiff)
+	if err != nil {
+		return err
+	}
+	upstreamSpecJSON, err := json.Marshal(upstreamSpec)
+	if err != nil {
+		return err
+	}
+	patchedSpecJSON, err := patch.Apply(upstreamSpecJSON)
+	if err != nil {
+		return err
+	}
+	var newSpec map[string]interface{}
+	if err := utiljson.Unmarshal(patchedSpecJSON, &newSpec); err != nil {
+		return err
+	}
+	return unstructured.SetNestedMap(downstreamObj.UnstructuredContent(), newSpec, "spec")
+}
+
+// updateSpecDiffError records why the spec diff of the upstream object does not apply, in the
+// experimental.spec-diff-error.workload.kcp.dev/<sync-target-name> annotation of the upstream object. A nil
+// specDiffErr removes the annotation. The annotation is only updated when the failure changes.
+func (c *Controller) updateSpecDiffError(ctx context.Context, gvr schema.GroupVersionResource, upstreamObj *unstructured.Unstructured, specDiffErr error) error {
+	specDiffErrorAnnotation := workloadv1alpha1.ClusterSpecDiffErrorAnnotationPrefix + c.syncTargetName
+	value, found := upstreamObj.GetAnnotations()[specDiffErrorAnnotation]
+
+	// A nil value removes the annotation.
+	var annotationValue interface{}
+	if specDiffErr == nil {
+		if !found {
+			return nil
+		}
+	} else {
+		specDiffError := shared.SyncError{
+			Reason:             shared.InvalidSpecDiffReason,
+			Message:            specDiffErr.Error(),
+			LastTransitionTime: metav1.NewTime(c.now()),
+		}
+		var existing shared.SyncError
+		if found && json.Unmarshal([]byte(value), &existing) == nil && existing.Reason == specDiffError.Reason && existing.Message == specDiffError.Message {
+			return nil
+		}
+		specDiffErrorJSON, err := json.Marshal(specDiffError)
+		if err != nil {
+			return err
+		}
+		annotationValue = string(specDiffErrorJSON)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				specDiffErrorAnnotation: annotationValue,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	upstreamObjLogicalCluster := logicalcluster.From(upstreamObj)
+	if _, err := c.upstreamClient.Cluster(upstreamObjLogicalCluster).Resource(gvr).Namespace(upstreamObj.GetNamespace()).
+		Patch(ctx, upstreamObj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.Errorf("Failed updating the spec diff error of upstream resource %s|%s/%s: %v", upstreamObjLogicalCluster, upstreamObj.GetNamespace(), upstreamObj.GetName(), err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+// updateSyncError records the rejection of the upstream object by the downstream cluster, or its conflict
+// with a cluster-scoped downstream object, in the experimental.sync-error.workload.kcp.dev/<sync-target-name>
+// annotation of the upstream object, and in an Event on the upstream object, in its workspace. A nil applyErr removes the annotation.
+// The annotation and the Event are only updated when the failure changes.
+func (c *Controller) updateSyncError(ctx context.Context, gvr schema.GroupVersionResource, upstreamObj *unstructured.Unstructured, applyErr error) error {
+	syncErrorAnnotation := workloadv1alpha1.ClusterSyncErrorAnnotationPrefix + c.syncTargetName
+	value, found := upstreamObj.GetAnnotations()[syncErrorAnnotation]
+
+	// A nil value removes the annotation.
+	var annotationValue interface{}
+	if applyErr == nil {
+		if !found {
+			return nil
+		}
+	} else {
+		syncError := shared.SyncError{
+			Reason:             string(apierrors.ReasonForError(applyErr)),
+			Message:            applyErr.Error(),
+			LastTransitionTime: metav1.NewTime(c.now()),
+		}
+		var existing shared.SyncError
+		if found && json.Unmarshal([]byte(value), &existing) == nil && existing.Reason == syncError.Reason && existing.Message == syncError.Message {
+			return nil
+		}
+		syncErrorJSON, err := json.Marshal(syncError)
+		if err != nil {
+			return err
+		}
+		annotationValue = string(syncErrorJSON)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				syncErrorAnnotation: annotationValue,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	upstreamObjLogicalCluster := logicalcluster.From(upstreamObj)
+	if _, err := c.upstreamClient.Cluster(upstreamObjLogicalCluster).Resource(gvr).Namespace(upstreamObj.GetNamespace()).
+		Patch(ctx, upstreamObj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.Errorf("Failed updating the sync error of upstream resource %s|%s/%s: %v", upstreamObjLogicalCluster, upstreamObj.GetNamespace(), upstreamObj.GetName(), err)
+		return err
+	}
+
+	switch {
+	case applyErr == nil:
+	case apierrors.IsConflict(applyErr):
+		c.eventRecorderForCluster(upstreamObjLogicalCluster).Eventf(objectReference(upstreamObj), corev1.EventTypeWarning, shared.DownstreamConflictEventReason,
+			"%s conflicts with the cluster of SyncTarget %s: %v", gvr.GroupResource(), c.syncTargetName, applyErr)
+	default:
+		c.eventRecorderForCluster(upstreamObjLogicalCluster).Eventf(objectReference(upstreamObj), corev1.EventTypeWarning, shared.DownstreamRejectedEventReason,
+			"%s was rejected by the cluster of SyncTarget %s: %v", gvr.GroupResource(), c.syncTargetName, applyErr)
+	}
+	return nil
+}
+
+// objectReference returns a reference to the upstream object, to record Events on.
+func objectReference(upstreamObj *unstructured.Unstructured) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion:      upstreamObj.GetAPIVersion(),
+		Kind:            upstreamObj.GetKind(),
+		Namespace:       upstreamObj.GetNamespace(),
+		Name:            upstreamObj.GetName(),
+		UID:             upstreamObj.GetUID(),
+		ResourceVersion: upstreamObj.GetResourceVersion(),
+	}
+}
@@ -19,6 +19,7 @@ package spec
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/url"
 	"strings"
 	"testing"
@@ -30,6 +31,8 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -37,13 +40,14 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/dynamic/fake"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/clusters"
+	"k8s.io/client-go/tools/record"
 
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/resourcesync"
 	"github.com/kcp-dev/kcp/pkg/syncer/shared"
 )
 
@@ -53,6 +57,7 @@ func init() {
 	scheme = runtime.NewScheme()
 	_ = corev1.AddToScheme(scheme)
 	_ = appsv1.AddToScheme(scheme)
+	_ = schedulingv1.AddToScheme(scheme)
 }
 
 func TestDeepEqualApartFromStatus(t *testing.T) {
@@ -474,10 +479,14 @@ func TestSyncerProcess(t *testing.T) {
 		syncTargetName            string
 		syncTargetUID             types.UID
 		advancedSchedulingEnabled bool
+		namespaceNaming           string
+
+		downstreamApplyError error
 
 		expectError         bool
 		expectActionsOnFrom []clienttesting.Action
 		expectActionsOnTo   []clienttesting.Action
+		expectEvents        []string
 	}{
 		"SpecSyncer sync deployment to downstream, upstream gets patched with the finalizer and the object is created downstream": {
 			upstreamLogicalCluster: "root:org:ws",
@@ -539,6 +548,134 @@ func TestSyncerProcess(t *testing.T) {
 				),
 			},
 		},
+		"SpecSyncer sync deployment to downstream, the downstream namespace is named after the upstream namespace with the readable naming": {
+			upstreamLogicalCluster: "root:org:ws",
+			fromNamespace: namespace("test", "root:org:ws", map[string]string{
+				"internal.workload.kcp.dev/cluster": "us-west1",
+			}, nil),
+			gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			fromResources: []runtime.Object{
+				secret("default-token-abc", "test", "root:org:ws",
+					map[string]string{"state.workload.kcp.dev/us-west1": "Sync"},
+					map[string]string{"kubernetes.io/service-account.name": "default"},
+					map[string][]byte{
+						"token":     []byte("token"),
+						"namespace": []byte("namespace"),
+					}),
+				deployment("theDeployment", "test", "root:org:ws", map[string]string{
+					"state.workload.kcp.dev/us-west1": "Sync",
+				}, nil, nil),
+			},
+			resourceToProcessLogicalClusterName: "root:org:ws",
+			resourceToProcessName:               "theDeployment",
+			syncTargetName:                      "us-west1",
+			namespaceNaming:                     "readable",
+
+			expectActionsOnFrom: []clienttesting.Action{
+				updateDeploymentAction("test",
+					toUnstructured(t, changeDeployment(
+						deployment("theDeployment", "test", "root:org:ws", map[string]string{
+							"state.workload.kcp.dev/us-west1": "Sync",
+						}, nil, []string{"workload.kcp.dev/syncer-us-west1"}),
+					))),
+			},
+			expectActionsOnTo: []clienttesting.Action{
+				getNamespaceAction("root-org-ws-test"),
+				createNamespaceAction(
+					"",
+					changeUnstructured(
+						toUnstructured(t, namespace("root-org-ws-test", "",
+							map[string]string{
+								"internal.workload.kcp.dev/cluster": "us-west1",
+							},
+							map[string]string{
+								"kcp.dev/namespace-locator": `{"syncTarget":{"workspace":"root:org:ws","name":"us-west1","uid":"syncTargetUID"},"workspace":"root:org:ws","namespace":"test"}`,
+							})),
+						removeNilOrEmptyFields,
+					),
+				),
+				patchDeploymentAction(
+					"theDeployment",
+					"root-org-ws-test",
+					types.ApplyPatchType,
+					toJson(t,
+						changeUnstructured(
+							toUnstructured(t, deployment("theDeployment", "root-org-ws-test", "", map[string]string{
+								"internal.workload.kcp.dev/cluster": "us-west1",
+							}, nil, nil)),
+							setNestedField(map[string]interface{}{}, "status"),
+							setPodSpecServiceAccount("spec", "template", "spec"),
+						),
+					),
+				),
+			},
+		},
+		"SpecSyncer sync deployment to downstream, the readable downstream namespace name gets a hash suffix when taken": {
+			upstreamLogicalCluster: "root:org:ws",
+			fromNamespace: namespace("test", "root:org:ws", map[string]string{
+				"internal.workload.kcp.dev/cluster": "us-west1",
+			}, nil),
+			gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			fromResources: []runtime.Object{
+				secret("default-token-abc", "test", "root:org:ws",
+					map[string]string{"state.workload.kcp.dev/us-west1": "Sync"},
+					map[string]string{"kubernetes.io/service-account.name": "default"},
+					map[string][]byte{
+						"token":     []byte("token"),
+						"namespace": []byte("namespace"),
+					}),
+				deployment("theDeployment", "test", "root:org:ws", map[string]string{
+					"state.workload.kcp.dev/us-west1": "Sync",
+				}, nil, nil),
+			},
+			resourceToProcessLogicalClusterName: "root:org:ws",
+			resourceToProcessName:               "theDeployment",
+			syncTargetName:                      "us-west1",
+			namespaceNaming:                     "readable",
+			toResources: []runtime.Object{
+				namespace("root-org-ws-test", "", nil, nil),
+			},
+
+			expectActionsOnFrom: []clienttesting.Action{
+				updateDeploymentAction("test",
+					toUnstructured(t, changeDeployment(
+						deployment("theDeployment", "test", "root:org:ws", map[string]string{
+							"state.workload.kcp.dev/us-west1": "Sync",
+						}, nil, []string{"workload.kcp.dev/syncer-us-west1"}),
+					))),
+			},
+			expectActionsOnTo: []clienttesting.Action{
+				getNamespaceAction("root-org-ws-test"),
+				getNamespaceAction("root-org-ws-test-hcbsa8z6c2er"),
+				createNamespaceAction(
+					"",
+					changeUnstructured(
+						toUnstructured(t, namespace("root-org-ws-test-hcbsa8z6c2er", "",
+							map[string]string{
+								"internal.workload.kcp.dev/cluster": "us-west1",
+							},
+							map[string]string{
+								"kcp.dev/namespace-locator": `{"syncTarget":{"workspace":"root:org:ws","name":"us-west1","uid":"syncTargetUID"},"workspace":"root:org:ws","namespace":"test"}`,
+							})),
+						removeNilOrEmptyFields,
+					),
+				),
+				patchDeploymentAction(
+					"theDeployment",
+					"root-org-ws-test-hcbsa8z6c2er",
+					types.ApplyPatchType,
+					toJson(t,
+						changeUnstructured(
+							toUnstructured(t, deployment("theDeployment", "root-org-ws-test-hcbsa8z6c2er", "", map[string]string{
+								"internal.workload.kcp.dev/cluster": "us-west1",
+							}, nil, nil)),
+							setNestedField(map[string]interface{}{}, "status"),
+							setPodSpecServiceAccount("spec", "template", "spec"),
+						),
+					),
+				),
+			},
+		},
 		"SpecSyncer sync to downstream, syncer finalizer already there": {
 			upstreamLogicalCluster: "root:org:ws",
 			fromNamespace: namespace("test", "root:org:ws", map[string]string{
@@ -592,6 +729,192 @@ func TestSyncerProcess(t *testing.T) {
 				),
 			},
 		},
+		"SpecSyncer sync to downstream rejected by the cluster, the failure is reported upstream": {
+			upstreamLogicalCluster: "root:org:ws",
+			fromNamespace: namespace("test", "root:org:ws", map[string]string{
+				"state.workload.kcp.dev/us-west1": "Sync",
+			}, nil),
+			gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			fromResources: []runtime.Object{
+				secret("default-token-abc", "test", "root:org:ws",
+					map[string]string{"state.workload.kcp.dev/us-west1": "Sync"},
+					map[string]string{"kubernetes.io/service-account.name": "default"},
+					map[string][]byte{
+						"token":     []byte("token"),
+						"namespace": []byte("namespace"),
+					}),
+				deployment("theDeployment", "test", "root:org:ws", map[string]string{
+					"state.workload.kcp.dev/us-west1": "Sync",
+				}, nil, []string{"workload.kcp.dev/syncer-us-west1"}),
+			},
+			resourceToProcessLogicalClusterName: "root:org:ws",
+			resourceToProcessName:               "theDeployment",
+			syncTargetName:                      "us-west1",
+			downstreamApplyError: apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "theDeployment",
+				errors.New("exceeded quota: compute-resources")),
+
+			expectError: true,
+			expectActionsOnFrom: []clienttesting.Action{
+				patchDeploymentAction(
+					"theDeployment",
+					"test",
+					types.MergePatchType,
+					[]byte(`{"metadata":{"annotations":{"experimental.sync-error.workload.kcp.dev/us-west1":"{\"reason\":\"Forbidden\",\"message\":\"deployments.apps \\\"theDeployment\\\" is forbidden: exceeded quota: compute-resources\",\"lastTransitionTime\":\"2022-08-01T10:00:00Z\"}"}}}`),
+				),
+			},
+			expectActionsOnTo: []clienttesting.Action{
+				createNamespaceAction(
+					"",
+					changeUnstructured(
+						toUnstructured(t, namespace("kcp-hcbsa8z6c2er", "",
+							map[string]string{
+								"internal.workload.kcp.dev/cluster": "us-west1",
+							},
+							map[string]string{
+								"kcp.dev/namespace-locator": `{"syncTarget":{"workspace":"root:org:ws","name":"us-west1","uid":"syncTargetUID"},"workspace":"root:org:ws","namespace":"test"}`,
+							})),
+						removeNilOrEmptyFields,
+					),
+				),
+				patchDeploymentAction(
+					"theDeployment",
+					"kcp-hcbsa8z6c2er",
+					types.ApplyPatchType,
+					toJson(t,
+						changeUnstructured(
+							toUnstructured(t, deployment("theDeployment", "kcp-hcbsa8z6c2er", "", map[string]string{
+								"internal.workload.kcp.dev/cluster": "us-west1",
+							}, nil, nil)),
+							setNestedField(map[string]interface{}{}, "status"),
+							setPodSpecServiceAccount("spec", "template", "spec"),
+						),
+					),
+				),
+			},
+			expectEvents: []string{
+				`Warning DownstreamRejected deployments.apps was rejected by the cluster of SyncTarget us-west1: deployments.apps "theDeployment" is forbidden: exceeded quota: compute-resources involvedObject{kind=Deployment,apiVersion=apps/v1}`,
+			},
+		},
+		"SpecSyncer sync to downstream rejected again for the same reason, the failure is not reported again": {
+			upstreamLogicalCluster: "root:org:ws",
+			fromNamespace: namespace("test", "root:org:ws", map[string]string{
+				"state.workload.kcp.dev/us-west1": "Sync",
+			}, nil),
+			gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			fromResources: []runtime.Object{
+				secret("default-token-abc", "test", "root:org:ws",
+					map[string]string{"state.workload.kcp.dev/us-west1": "Sync"},
+					map[string]string{"kubernetes.io/service-account.name": "default"},
+					map[string][]byte{
+						"token":     []byte("token"),
+						"namespace": []byte("namespace"),
+					}),
+				deployment("theDeployment", "test", "root:org:ws", map[string]string{
+					"state.workload.kcp.dev/us-west1": "Sync",
+				}, map[string]string{
+					"experimental.sync-error.workload.kcp.dev/us-west1": `{"reason":"Forbidden","message":"deployments.apps \"theDeployment\" is forbidden: exceeded quota: compute-resources","lastTransitionTime":"2022-07-01T10:00:00Z"}`,
+				}, []string{"workload.kcp.dev/syncer-us-west1"}),
+			},
+			resourceToProcessLogicalClusterName: "root:org:ws",
+			resourceToProcessName:               "theDeployment",
+			syncTargetName:                      "us-west1",
+			downstreamApplyError: apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "theDeployment",
+				errors.New("exceeded quota: compute-resources")),
+
+			expectError:         true,
+			expectActionsOnFrom: []clienttesting.Action{},
+			expectActionsOnTo: []clienttesting.Action{
+				createNamespaceAction(
+					"",
+					changeUnstructured(
+						toUnstructured(t, namespace("kcp-hcbsa8z6c2er", "",
+							map[string]string{
+								"internal.workload.kcp.dev/cluster": "us-west1",
+							},
+							map[string]string{
+								"kcp.dev/namespace-locator": `{"syncTarget":{"workspace":"root:org:ws","name":"us-west1","uid":"syncTargetUID"},"workspace":"root:org:ws","namespace":"test"}`,
+							})),
+						removeNilOrEmptyFields,
+					),
+				),
+				patchDeploymentAction(
+					"theDeployment",
+					"kcp-hcbsa8z6c2er",
+					types.ApplyPatchType,
+					toJson(t,
+						changeUnstructured(
+							toUnstructured(t, deployment("theDeployment", "kcp-hcbsa8z6c2er", "", map[string]string{
+								"internal.workload.kcp.dev/cluster": "us-west1",
+							}, nil, nil)),
+							setNestedField(map[string]interface{}{}, "status"),
+							setPodSpecServiceAccount("spec", "template", "spec"),
+						),
+					),
+				),
+			},
+		},
+		"SpecSyncer sync to downstream succeeds after a rejection, the failure is cleared upstream": {
+			upstreamLogicalCluster: "root:org:ws",
+			fromNamespace: namespace("test", "root:org:ws", map[string]string{
+				"state.workload.kcp.dev/us-west1": "Sync",
+			}, nil),
+			gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			fromResources: []runtime.Object{
+				secret("default-token-abc", "test", "root:org:ws",
+					map[string]string{"state.workload.kcp.dev/us-west1": "Sync"},
+					map[string]string{"kubernetes.io/service-account.name": "default"},
+					map[string][]byte{
+						"token":     []byte("token"),
+						"namespace": []byte("namespace"),
+					}),
+				deployment("theDeployment", "test", "root:org:ws", map[string]string{
+					"state.workload.kcp.dev/us-west1": "Sync",
+				}, map[string]string{
+					"experimental.sync-error.workload.kcp.dev/us-west1": `{"reason":"Forbidden","message":"deployments.apps \"theDeployment\" is forbidden: exceeded quota: compute-resources","lastTransitionTime":"2022-07-01T10:00:00Z"}`,
+				}, []string{"workload.kcp.dev/syncer-us-west1"}),
+			},
+			resourceToProcessLogicalClusterName: "root:org:ws",
+			resourceToProcessName:               "theDeployment",
+			syncTargetName:                      "us-west1",
+
+			expectActionsOnFrom: []clienttesting.Action{
+				patchDeploymentAction(
+					"theDeployment",
+					"test",
+					types.MergePatchType,
+					[]byte(`{"metadata":{"annotations":{"experimental.sync-error.workload.kcp.dev/us-west1":null}}}`),
+				),
+			},
+			expectActionsOnTo: []clienttesting.Action{
+				createNamespaceAction(
+					"",
+					changeUnstructured(
+						toUnstructured(t, namespace("kcp-hcbsa8z6c2er", "",
+							map[string]string{
+								"internal.workload.kcp.dev/cluster": "us-west1",
+							},
+							map[string]string{
+								"kcp.dev/namespace-locator": `{"syncTarget":{"workspace":"root:org:ws","name":"us-west1","uid":"syncTargetUID"},"workspace":"root:org:ws","namespace":"test"}`,
+							})),
+						removeNilOrEmptyFields,
+					),
+				),
+				patchDeploymentAction(
+					"theDeployment",
+					"kcp-hcbsa8z6c2er",
+					types.ApplyPatchType,
+					toJson(t,
+						changeUnstructured(
+							toUnstructured(t, deployment("theDeployment", "kcp-hcbsa8z6c2er", "", map[string]string{
+								"internal.workload.kcp.dev/cluster": "us-west1",
+							}, nil, nil)),
+							setNestedField(map[string]interface{}{}, "status"),
+							setPodSpecServiceAccount("spec", "template", "spec"),
+						),
+					),
+				),
+			},
+		},
 		"SpecSyncer upstream resource has the state workload annotation removed, expect deletion downstream": {
 			upstreamLogicalCluster: "root:org:ws",
 			fromNamespace: namespace("test", "root:org:ws", map[string]string{
@@ -856,6 +1179,180 @@ func TestSyncerProcess(t *testing.T) {
 								},
 							}, "spec", "template"),
 							setNestedField(map[string]interface{}{}, "status"),
+							setPodSpecServiceAccount("spec", "template", "spec"),
+						),
+					),
+				),
+			},
+		},
+		"SpecSyncer with AdvancedScheduling, invalid SpecDiff is reported in an annotation": {
+			upstreamLogicalCluster: "root:org:ws",
+			fromNamespace: namespace("test", "root:org:ws", map[string]string{
+				"internal.workload.kcp.dev/cluster": "us-west1",
+			}, nil),
+			gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			fromResources: []runtime.Object{
+				deployment("theDeployment", "test", "root:org:ws", map[string]string{
+					"state.workload.kcp.dev/us-west1": "Sync",
+				}, map[string]string{
+					"experimental.spec-diff.workload.kcp.dev/us-west1": "[{\"op\":\"remove\",\"path\":\"/unknown\"}]",
+				}, []string{shared.SyncerFinalizerNamePrefix + "us-west1"}),
+			},
+			resourceToProcessLogicalClusterName: "root:org:ws",
+			resourceToProcessName:               "theDeployment",
+			syncTargetName:                      "us-west1",
+			advancedSchedulingEnabled:           true,
+
+			expectActionsOnFrom: []clienttesting.Action{
+				patchDeploymentAction(
+					"theDeployment",
+					"test",
+					types.MergePatchType,
+					[]byte(`{"metadata":{"annotations":{"experimental.spec-diff-error.workload.kcp.dev/us-west1":"{\"reason\":\"InvalidSpecDiff\",\"message\":\"error in remove for path: '/unknown': Unable to remove nonexistent key: unknown: missing value\",\"lastTransitionTime\":\"2022-08-01T10:00:00Z\"}"}}}`),
+				),
+			},
+			expectActionsOnTo: []clienttesting.Action{
+				createNamespaceAction(
+					"",
+					changeUnstructured(
+						toUnstructured(t, namespace("kcp-hcbsa8z6c2er", "",
+							map[string]string{
+								"internal.workload.kcp.dev/cluster": "us-west1",
+							},
+							map[string]string{
+								"kcp.dev/namespace-locator": `{"syncTarget":{"workspace":"root:org:ws","name":"us-west1","uid":"syncTargetUID"},"workspace":"root:org:ws","namespace":"test"}`,
+							})),
+						removeNilOrEmptyFields,
+					),
+				),
+			},
+		},
+		"SpecSyncer with AdvancedScheduling, object with an invalid SpecDiff is removed from the SyncTarget": {
+			upstreamLogicalCluster: "root:org:ws",
+			fromNamespace: namespace("test", "root:org:ws", map[string]string{
+				"internal.workload.kcp.dev/cluster": "us-west1",
+			}, nil),
+			gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			toResources: []runtime.Object{
+				namespace("kcp-hcbsa8z6c2er", "", map[string]string{
+					"state.workload.kcp.dev/us-west1": "Sync",
+				},
+					map[string]string{
+						"kcp.dev/namespace-locator": `{"syncTarget":{"workspace":"root:org:ws","name":"us-west1","uid":"syncTargetUID"},"workspace":"root:org:ws","namespace":"test"}`,
+					}),
+			},
+			fromResources: []runtime.Object{
+				deployment("theDeployment", "test", "root:org:ws",
+					map[string]string{"state.workload.kcp.dev/us-west1": "Sync"},
+					map[string]string{
+						"experimental.spec-diff.workload.kcp.dev/us-west1": "[{\"op\":\"remove\",\"path\":\"/unknown\"}]",
+						"deletion.internal.workload.kcp.dev/us-west1":      time.Now().Format(time.RFC3339),
+					},
+					[]string{"workload.kcp.dev/syncer-us-west1"}),
+			},
+			resourceToProcessLogicalClusterName: "root:org:ws",
+			resourceToProcessName:               "theDeployment",
+			syncTargetName:                      "us-west1",
+			advancedSchedulingEnabled:           true,
+
+			expectActionsOnFrom: []clienttesting.Action{
+				getDeploymentAction("theDeployment", "test"),
+				updateDeploymentAction("test",
+					changeUnstructured(
+						toUnstructured(t, changeDeployment(
+							deployment("theDeployment", "test", "root:org:ws", nil, map[string]string{
+								"experimental.spec-diff.workload.kcp.dev/us-west1": "[{\"op\":\"remove\",\"path\":\"/unknown\"}]",
+							}, nil),
+						),
+						),
+						setNestedField(map[string]interface{}{}, "metadata", "labels"),
+						setNestedField([]interface{}{}, "metadata", "finalizers"),
+						setNestedField(nil, "spec", "selector"),
+					)),
+			},
+			expectActionsOnTo: []clienttesting.Action{
+				deleteDeploymentAction(
+					"theDeployment",
+					"kcp-hcbsa8z6c2er",
+				),
+			},
+		},
+		"SpecSyncer with AdvancedScheduling, fixed SpecDiff clears the failure annotation": {
+			upstreamLogicalCluster: "root:org:ws",
+			fromNamespace: namespace("test", "root:org:ws", map[string]string{
+				"internal.workload.kcp.dev/cluster": "us-west1",
+			}, nil),
+			gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			fromResources: []runtime.Object{
+				secret("default-token-abc", "test", "root:org:ws",
+					map[string]string{"state.workload.kcp.dev/us-west1": "Sync"},
+					map[string]string{"kubernetes.io/service-account.name": "default"},
+					map[string][]byte{
+						"token":     []byte("token"),
+						"namespace": []byte("namespace"),
+					}),
+				deployment("theDeployment", "test", "root:org:ws", map[string]string{
+					"state.workload.kcp.dev/us-west1": "Sync",
+				}, map[string]string{
+					"experimental.spec-diff.workload.kcp.dev/us-west1":       "[{\"op\":\"replace\",\"path\":\"/replicas\",\"value\":3}]",
+					"experimental.spec-diff-error.workload.kcp.dev/us-west1": `{"reason":"InvalidSpecDiff","message":"invalid"}`,
+				}, []string{shared.SyncerFinalizerNamePrefix + "us-west1"}),
+			},
+			resourceToProcessLogicalClusterName: "root:org:ws",
+			resourceToProcessName:               "theDeployment",
+			syncTargetName:                      "us-west1",
+			advancedSchedulingEnabled:           true,
+
+			expectActionsOnFrom: []clienttesting.Action{
+				patchDeploymentAction(
+					"theDeployment",
+					"test",
+					types.MergePatchType,
+					[]byte(`{"metadata":{"annotations":{"experimental.spec-diff-error.workload.kcp.dev/us-west1":null}}}`),
+				),
+			},
+			expectActionsOnTo: []clienttesting.Action{
+				createNamespaceAction(
+					"",
+					changeUnstructured(
+						toUnstructured(t, namespace("kcp-hcbsa8z6c2er", "",
+							map[string]string{
+								"internal.workload.kcp.dev/cluster": "us-west1",
+							},
+							map[string]string{
+								"kcp.dev/namespace-locator": `{"syncTarget":{"workspace":"root:org:ws","name":"us-west1","uid":"syncTargetUID"},"workspace":"root:org:ws","namespace":"test"}`,
+							})),
+						removeNilOrEmptyFields,
+					),
+				),
+				patchDeploymentAction(
+					"theDeployment",
+					"kcp-hcbsa8z6c2er",
+					types.ApplyPatchType,
+					toJson(t,
+						changeUnstructured(
+							toUnstructured(t, deployment("theDeployment", "kcp-hcbsa8z6c2er", "", map[string]string{
+								"internal.workload.kcp.dev/cluster": "us-west1",
+							}, map[string]string{
+								"experimental.spec-diff.workload.kcp.dev/us-west1": "[{\"op\":\"replace\",\"path\":\"/replicas\",\"value\":3}]",
+							}, nil)),
+							setNestedField(map[string]interface{}{
+								"replicas": int64(3),
+							}, "spec"),
+							// TODO(jmprusi): Those next changes do "nothing", it's just for the test to pass
+							//                as the test expects some null fields to be there...
+							setNestedField(nil, "spec", "selector"),
+							setNestedField(map[string]interface{}{}, "spec", "strategy"),
+							setNestedField(map[string]interface{}{
+								"metadata": map[string]interface{}{
+									"creationTimestamp": nil,
+								},
+								"spec": map[string]interface{}{
+									"containers": nil,
+								},
+							}, "spec", "template"),
+							setNestedField(map[string]interface{}{}, "status"),
+							setPodSpecServiceAccount("spec", "template", "spec"),
 						),
 					),
 				),
@@ -997,14 +1494,12 @@ func TestSyncerProcess(t *testing.T) {
 
 			toClient := dynamicfake.NewSimpleDynamicClient(scheme, tc.toResources...)
 
-			fromInformers := dynamicinformer.NewFilteredDynamicSharedInformerFactory(fromClusterClient.Cluster(logicalcluster.Wildcard), time.Hour, metav1.NamespaceAll, func(o *metav1.ListOptions) {
-				o.LabelSelector = workloadv1alpha1.ClusterResourceStateLabelPrefix + tc.syncTargetName + "=" + string(workloadv1alpha1.ResourceStateSync)
-			})
-			toInformers := dynamicinformer.NewFilteredDynamicSharedInformerFactory(toClient, time.Hour, metav1.NamespaceAll, func(o *metav1.ListOptions) {
-				o.LabelSelector = workloadv1alpha1.ClusterResourceStateLabelPrefix + tc.syncTargetName + "=" + string(workloadv1alpha1.ResourceStateSync)
-			})
-
 			setupServersideApplyPatchReactor(toClient)
+			if tc.downstreamApplyError != nil {
+				toClient.PrependReactor("patch", tc.gvr.Resource, func(action clienttesting.Action) (handled bool, ret runtime.Object, err error) {
+					return true, nil, tc.downstreamApplyError
+				})
+			}
 			namespaceWatcherStarted := setupWatchReactor("namespaces", fromClient)
 			resourceWatcherStarted := setupWatchReactor(tc.gvr.Resource, fromClient)
 
@@ -1013,16 +1508,35 @@ func TestSyncerProcess(t *testing.T) {
 				{Group: "", Version: "v1", Resource: "secrets"},
 				tc.gvr,
 			}
+			syncerInformers := resourcesync.NewSyncerInformerFactory(
+				fromClusterClient.Cluster(logicalcluster.Wildcard), func(o *metav1.ListOptions) {
+					o.LabelSelector = workloadv1alpha1.ClusterResourceStateLabelPrefix + tc.syncTargetName + "=" + string(workloadv1alpha1.ResourceStateSync)
+				},
+				toClient, func(o *metav1.ListOptions) {
+					o.LabelSelector = workloadv1alpha1.ClusterResourceStateLabelPrefix + tc.syncTargetName + "=" + string(workloadv1alpha1.ResourceStateSync)
+				},
+				func(ctx context.Context) ([]schema.GroupVersionResource, error) { return gvrs, nil },
+				time.Hour, time.Hour,
+			)
+
 			upstreamURL, err := url.Parse("https://kcp.dev:6443")
 			require.NoError(t, err)
-			controller, err := NewSpecSyncer(gvrs, kcpLogicalCluster, tc.syncTargetName, upstreamURL, tc.advancedSchedulingEnabled, fromClusterClient, toClient, fromInformers, toInformers, syncTargetUID)
+			mutators := NewMutatorRegistry(upstreamURL, syncerInformers)
+			eventRecorder := record.NewFakeRecorder(10)
+			eventRecorder.IncludeObject = true
+			eventRecorderForCluster := func(clusterName logicalcluster.Name) record.EventRecorder {
+				assert.Equal(t, logicalcluster.New(tc.upstreamLogicalCluster), clusterName)
+				return eventRecorder
+			}
+			namespaceNamer, err := shared.ParseNamespaceNaming(tc.namespaceNaming)
 			require.NoError(t, err)
+			controller, err := NewSpecSyncer(kcpLogicalCluster, tc.syncTargetName, tc.advancedSchedulingEnabled, fromClusterClient, toClient, syncerInformers, mutators, syncTargetUID, eventRecorderForCluster,
+				func() *shared.NamespaceNamer { return namespaceNamer })
+			require.NoError(t, err)
+			controller.now = func() time.Time { return time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC) }
 
-			fromInformers.Start(ctx.Done())
-			toInformers.Start(ctx.Done())
-
-			fromInformers.WaitForCacheSync(ctx.Done())
-			toInformers.WaitForCacheSync(ctx.Done())
+			require.NoError(t, syncerInformers.Start(ctx))
+			syncerInformers.WaitForCacheSync(ctx.Done())
 
 			<-resourceWatcherStarted
 			<-namespaceWatcherStarted
@@ -1042,6 +1556,12 @@ func TestSyncerProcess(t *testing.T) {
 			}
 			assert.EqualValues(t, tc.expectActionsOnFrom, fromClient.Actions())
 			assert.EqualValues(t, tc.expectActionsOnTo, toClient.Actions())
+
+			var events []string
+			for len(eventRecorder.Events) > 0 {
+				events = append(events, <-eventRecorder.Events)
+			}
+			assert.Equal(t, tc.expectEvents, events)
 		})
 	}
 }
@@ -1211,6 +1731,13 @@ func getDeploymentAction(name, namespace string) clienttesting.GetActionImpl {
 	}
 }
 
+func getNamespaceAction(name string) clienttesting.GetActionImpl {
+	return clienttesting.GetActionImpl{
+		ActionImpl: namespaceAction("get"),
+		Name:       name,
+	}
+}
+
 func createNamespaceAction(name string, object runtime.Object) clienttesting.CreateActionImpl {
 	return clienttesting.CreateActionImpl{
 		ActionImpl: namespaceAction("create"),
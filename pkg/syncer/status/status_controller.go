@@ -29,11 +29,14 @@ import (
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	"github.com/kcp-dev/kcp/pkg/informer"
+	syncermetrics "github.com/kcp-dev/kcp/pkg/syncer/metrics"
+	"github.com/kcp-dev/kcp/pkg/syncer/resourcesync"
+	specmutators "github.com/kcp-dev/kcp/pkg/syncer/spec/mutators"
 	"github.com/kcp-dev/kcp/third_party/keyfunctions"
 )
 
@@ -42,12 +45,14 @@ const (
 )
 
 type Controller struct {
-	queue workqueue.RateLimitingInterface
+	queue   workqueue.RateLimitingInterface
+	metrics *syncermetrics.ControllerMetrics
 
-	upstreamClient                         dynamic.ClusterInterface
-	downstreamClient                       dynamic.Interface
-	upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory
-	downstreamNamespaceLister              cache.GenericLister
+	upstreamClient            dynamic.ClusterInterface
+	downstreamClient          dynamic.Interface
+	syncerInformers           *resourcesync.SyncerInformerFactory
+	downstreamNamespaceLister cache.GenericLister
+	mutators                  *specmutators.Registry
 
 	syncTargetName            string
 	syncTargetWorkspace       logicalcluster.Name
@@ -55,17 +60,19 @@ type Controller struct {
 	advancedSchedulingEnabled bool
 }
 
-func NewStatusSyncer(gvrs []schema.GroupVersionResource, syncTargetWorkspace logicalcluster.Name, syncTargetName string, advancedSchedulingEnabled bool,
-	upstreamClient dynamic.ClusterInterface, downstreamClient dynamic.Interface, upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory, syncTargetUID types.UID) (*Controller, error) {
+func NewStatusSyncer(syncTargetWorkspace logicalcluster.Name, syncTargetName string, advancedSchedulingEnabled bool,
+	upstreamClient dynamic.ClusterInterface, downstreamClient dynamic.Interface, syncerInformers *resourcesync.SyncerInformerFactory,
+	mutators *specmutators.Registry, syncTargetUID types.UID) (*Controller, error) {
 
 	c := &Controller{
-		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+		queue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+		metrics: syncermetrics.NewControllerMetrics(syncTargetName, syncermetrics.DirectionStatus),
 
 		upstreamClient:            upstreamClient,
 		downstreamClient:          downstreamClient,
-		upstreamInformers:         upstreamInformers,
-		downstreamInformers:       downstreamInformers,
-		downstreamNamespaceLister: downstreamInformers.ForResource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}).Lister(),
+		syncerInformers:           syncerInformers,
+		downstreamNamespaceLister: syncerInformers.DownstreamNamespaceInformer().Lister(),
+		mutators:                  mutators,
 
 		syncTargetName:            syncTargetName,
 		syncTargetWorkspace:       syncTargetWorkspace,
@@ -73,27 +80,23 @@ func NewStatusSyncer(gvrs []schema.GroupVersionResource, syncTargetWorkspace log
 		advancedSchedulingEnabled: advancedSchedulingEnabled,
 	}
 
-	for _, gvr := range gvrs {
-		gvr := gvr // because used in closure
-
-		downstreamInformers.ForResource(gvr).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-			AddFunc: func(obj interface{}) {
-				c.AddToQueue(gvr, obj)
-			},
-			UpdateFunc: func(oldObj, newObj interface{}) {
-				oldUnstrob := oldObj.(*unstructured.Unstructured)
-				newUnstrob := newObj.(*unstructured.Unstructured)
-
-				if !deepEqualFinalizersAndStatus(oldUnstrob, newUnstrob) {
-					c.AddToQueue(gvr, newUnstrob)
-				}
-			},
-			DeleteFunc: func(obj interface{}) {
-				c.AddToQueue(gvr, obj)
-			},
-		})
-		klog.InfoS("Set up informer", "SyncTarget Workspace", syncTargetWorkspace, "SyncTarget Name", syncTargetName, "gvr", gvr.String())
-	}
+	syncerInformers.AddDownstreamEventHandler(informer.GVREventHandlerFuncs{
+		AddFunc: func(gvr schema.GroupVersionResource, obj interface{}) {
+			c.AddToQueue(gvr, obj)
+		},
+		UpdateFunc: func(gvr schema.GroupVersionResource, oldObj, newObj interface{}) {
+			oldUnstrob := oldObj.(*unstructured.Unstructured)
+			newUnstrob := newObj.(*unstructured.Unstructured)
+
+			if !deepEqualFinalizersAndStatus(oldUnstrob, newUnstrob) {
+				c.AddToQueue(gvr, newUnstrob)
+			}
+		},
+		DeleteFunc: func(gvr schema.GroupVersionResource, obj interface{}) {
+			c.AddToQueue(gvr, obj)
+		},
+	})
+	klog.InfoS("Set up downstream event handler", "SyncTarget Workspace", syncTargetWorkspace, "SyncTarget Name", syncTargetName)
 
 	return c, nil
 }
@@ -111,6 +114,7 @@ func (c *Controller) AddToQueue(gvr schema.GroupVersionResource, obj interface{}
 	}
 
 	klog.Infof("%s queueing GVR %q %s", controllerName, gvr.String(), key)
+	c.metrics.Enqueued(gvr, key)
 	c.queue.Add(
 		queueKey{
 			gvr: gvr,
@@ -146,13 +150,18 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 		return false
 	}
 	qk := key.(queueKey)
+	c.metrics.Dequeued(qk.gvr, qk.key)
 
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
 	defer c.queue.Done(key)
 
-	if err := c.process(ctx, qk.gvr, qk.key); err != nil {
+	start := time.Now()
+	err := c.process(ctx, qk.gvr, qk.key)
+	c.metrics.Synced(qk.gvr, start)
+	if err != nil {
 		runtime.HandleError(fmt.Errorf("%s failed to sync %q, err: %w", controllerName, key, err))
+		c.metrics.Retried(qk.gvr, qk.key)
 		c.queue.AddRateLimited(key)
 		return true
 	}
@@ -20,24 +20,30 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"reflect"
 	"strings"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clusters"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
 
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 	workloadcliplugin "github.com/kcp-dev/kcp/pkg/cliplugins/workload/plugin"
 	"github.com/kcp-dev/kcp/pkg/syncer/shared"
 )
 
+const (
+	syncerApplyManagerPrefix = "syncer-"
+)
+
 func deepEqualFinalizersAndStatus(oldUnstrob, newUnstrob *unstructured.Unstructured) bool {
 	newFinalizers := newUnstrob.GetFinalizers()
 	oldFinalizers := oldUnstrob.GetFinalizers()
@@ -58,6 +64,9 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 		return nil
 	}
 	downstreamClusterName, name := clusters.SplitClusterAwareKey(clusterAwareName)
+	if downstreamNamespace == "" {
+		return c.processClusterScoped(ctx, gvr, key)
+	}
 	// TODO(sttts): do not reference the cli plugin here
 	if strings.HasPrefix(workloadcliplugin.SyncerIDPrefix, downstreamNamespace) {
 		// skip syncer namespace
@@ -95,7 +104,12 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 	upstreamWorkspace := namespaceLocator.Workspace
 
 	// get the downstream object
-	obj, exists, err := c.downstreamInformers.ForResource(gvr).Informer().GetIndexer().GetByKey(key)
+	downstreamInformer, ok := c.syncerInformers.DownstreamInformer(gvr)
+	if !ok {
+		klog.V(3).Infof("GVR %q is not synced anymore, skipping %s", gvr.String(), key)
+		return nil
+	}
+	obj, exists, err := downstreamInformer.Informer().GetIndexer().GetByKey(key)
 	if err != nil {
 		return err
 	}
@@ -112,6 +126,38 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 	return c.updateStatusInUpstream(ctx, gvr, upstreamNamespace, upstreamWorkspace, u)
 }
 
+// processClusterScoped updates the status of the upstream object of a cluster-scoped downstream object, whose
+// workspace is found in the namespace locator annotation of the object itself. The syncer finalizers of the
+// upstream cluster-scoped objects are removed by the spec syncer.
+func (c *Controller) processClusterScoped(ctx context.Context, gvr schema.GroupVersionResource, key string) error {
+	downstreamInformer, ok := c.syncerInformers.DownstreamInformer(gvr)
+	if !ok {
+		klog.V(3).Infof("GVR %q is not synced anymore, skipping %s", gvr.String(), key)
+		return nil
+	}
+	obj, exists, err := downstreamInformer.Informer().GetIndexer().GetByKey(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("object to synchronize is expected to be Unstructured, but is %T", obj)
+	}
+
+	locator, exists, err := shared.LocatorFromAnnotations(u.GetAnnotations())
+	if err != nil {
+		klog.Errorf("Downstream GVR %q object %s: error decoding annotation: %v", gvr.String(), key, err)
+		return nil
+	}
+	if !exists || locator.SyncTarget.UID != c.syncTargetUID {
+		return nil
+	}
+	return c.updateStatusInUpstream(ctx, gvr, "", locator.Workspace, u)
+}
+
 func (c *Controller) updateStatusInUpstream(ctx context.Context, gvr schema.GroupVersionResource, upstreamNamespace string, upstreamLogicalCluster logicalcluster.Name, downstreamObj *unstructured.Unstructured) error {
 	upstreamObj := downstreamObj.DeepCopy()
 	upstreamObj.SetUID("")
@@ -121,6 +167,13 @@ func (c *Controller) updateStatusInUpstream(ctx context.Context, gvr schema.Grou
 	// Run name transformations on upstreamObj
 	transformName(upstreamObj)
 
+	// Run any transformations on the object before we update its status upstream.
+	if mutator, ok := c.mutators.StatusMutator(gvr); ok {
+		if err := mutator.StatusMutate(upstreamObj); err != nil {
+			return err
+		}
+	}
+
 	name := upstreamObj.GetName()
 	downstreamStatus, statusExists, err := unstructured.NestedFieldCopy(upstreamObj.UnstructuredContent(), "status")
 	if err != nil {
@@ -131,55 +184,98 @@ func (c *Controller) updateStatusInUpstream(ctx context.Context, gvr schema.Grou
 	}
 
 	existing, err := c.upstreamClient.Cluster(upstreamLogicalCluster).Resource(gvr).Namespace(upstreamNamespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// The downstream object might be synced through another syncer virtual workspace (i.e. another shard).
+		klog.V(4).Infof("Resource %s|%s/%s not found upstream through this syncer virtual workspace, skipping status update", upstreamLogicalCluster, upstreamNamespace, name)
+		return nil
+	}
 	if err != nil {
 		klog.Errorf("Getting resource %s/%s: %v", upstreamNamespace, name, err)
 		return err
 	}
 
-	labels := upstreamObj.GetLabels()
-	delete(labels, workloadv1alpha1.InternalDownstreamClusterLabel)
-	labels[workloadv1alpha1.ClusterResourceStateLabelPrefix+c.syncTargetName] = string(workloadv1alpha1.ResourceStateSync)
-	upstreamObj.SetLabels(labels)
+	if gvr == persistentVolumeClaimsGVR {
+		if err := c.updateBoundVolumeInUpstream(ctx, gvr, upstreamLogicalCluster, existing, downstreamObj); err != nil {
+			return err
+		}
+	}
 
-	// TODO: verify that we really only update status, and not some non-status fields in ObjectMeta.
-	//       I believe to remember that we had resources where that happened.
+	if c.advancedSchedulingEnabled {
+		return c.applyStatusAnnotationInUpstream(ctx, gvr, upstreamLogicalCluster, existing, downstreamStatus)
+	}
 
-	upstreamObj.SetResourceVersion(existing.GetResourceVersion())
+	if equality.Semantic.DeepEqual(existing.UnstructuredContent()["status"], downstreamStatus) {
+		klog.V(4).Infof("No need to update the status of resource %q %s|%s/%s from pcluster namespace %s", gvr.String(), upstreamLogicalCluster, upstreamNamespace, name, downstreamObj.GetNamespace())
+		return nil
+	}
 
-	if c.advancedSchedulingEnabled {
-		newUpstream := existing.DeepCopy()
-		statusAnnotationValue, err := json.Marshal(downstreamStatus)
-		if err != nil {
-			return err
-		}
-		newUpstreamAnnotations := newUpstream.GetAnnotations()
-		if newUpstreamAnnotations == nil {
-			newUpstreamAnnotations = make(map[string]string)
-		}
-		newUpstreamAnnotations[workloadv1alpha1.InternalClusterStatusAnnotationPrefix+c.syncTargetName] = string(statusAnnotationValue)
-		newUpstream.SetAnnotations(newUpstreamAnnotations)
+	// Only the status is applied, and the SyncTarget field manager owns all of it.
+	if err := c.applyUpstream(ctx, gvr, upstreamLogicalCluster, existing, func(applied *unstructured.Unstructured) error {
+		return unstructured.SetNestedField(applied.UnstructuredContent(), downstreamStatus, "status")
+	}, c.fieldManager(), "status"); err != nil {
+		klog.Errorf("Failed updating status of resource %q %s|%s/%s from pcluster namespace %s: %v", gvr.String(), upstreamLogicalCluster, upstreamNamespace, name, downstreamObj.GetNamespace(), err)
+		return err
+	}
+	klog.Infof("Updated status of resource %q %s|%s/%s from pcluster namespace %s", gvr.String(), upstreamLogicalCluster, upstreamNamespace, name, downstreamObj.GetNamespace())
+	return nil
+}
 
-		if reflect.DeepEqual(existing, newUpstream) {
-			klog.V(2).Infof("No need to update the status of resource %s|%s/%s from syncTargetName namespace %s", upstreamLogicalCluster, upstreamNamespace, name, downstreamObj.GetNamespace())
-			return nil
-		}
+// applyStatusAnnotationInUpstream stores the downstream status in the
+// experimental.status.workload.kcp.dev/<sync-target-name> annotation of the upstream object, which is
+// owned by the SyncTarget field manager, so that the syncers of several SyncTargets don't conflict.
+// The canonical status of the upstream object is aggregated from these annotations by the kcp status
+// aggregator controller.
+func (c *Controller) applyStatusAnnotationInUpstream(ctx context.Context, gvr schema.GroupVersionResource, upstreamLogicalCluster logicalcluster.Name, existing *unstructured.Unstructured, downstreamStatus interface{}) error {
+	namespace, name := existing.GetNamespace(), existing.GetName()
 
-		if _, err := c.upstreamClient.Cluster(upstreamLogicalCluster).Resource(gvr).Namespace(upstreamNamespace).Update(ctx, newUpstream, metav1.UpdateOptions{}); err != nil {
-			klog.Errorf("Failed updating location status annotation of resource %s|%s/%s from syncTargetName namespace %s: %v", upstreamLogicalCluster, upstreamNamespace, upstreamObj.GetName(), downstreamObj.GetNamespace(), err)
-			return err
-		}
-		klog.Infof("Updated status of resource %s|%s/%s from syncTargetName namespace %s", upstreamLogicalCluster, upstreamNamespace, upstreamObj.GetName(), downstreamObj.GetNamespace())
+	statusAnnotation := workloadv1alpha1.InternalClusterStatusAnnotationPrefix + c.syncTargetName
+	statusAnnotationValue, err := json.Marshal(downstreamStatus)
+	if err != nil {
+		return err
+	}
+
+	if existing.GetAnnotations()[statusAnnotation] == string(statusAnnotationValue) {
+		klog.V(2).Infof("No need to update the status annotation of resource %s|%s/%s for SyncTarget %s", upstreamLogicalCluster, namespace, name, c.syncTargetName)
 		return nil
 	}
 
-	if _, err := c.upstreamClient.Cluster(upstreamLogicalCluster).Resource(gvr).Namespace(upstreamNamespace).UpdateStatus(ctx, upstreamObj, metav1.UpdateOptions{}); err != nil {
-		klog.Errorf("Failed updating status of resource %q %s|%s/%s from pcluster namespace %s: %v", gvr.String(), upstreamLogicalCluster, upstreamNamespace, upstreamObj.GetName(), downstreamObj.GetNamespace(), err)
+	if err := c.applyUpstream(ctx, gvr, upstreamLogicalCluster, existing, func(applied *unstructured.Unstructured) error {
+		applied.SetAnnotations(map[string]string{statusAnnotation: string(statusAnnotationValue)})
+		return nil
+	}, c.fieldManager()); err != nil {
+		klog.Errorf("Failed updating location status annotation of resource %s|%s/%s for SyncTarget %s: %v", upstreamLogicalCluster, namespace, name, c.syncTargetName, err)
 		return err
 	}
-	klog.Infof("Updated status of resource %q %s|%s/%s from pcluster namespace %s", gvr.String(), upstreamLogicalCluster, upstreamNamespace, upstreamObj.GetName(), downstreamObj.GetNamespace())
+	klog.Infof("Updated status annotation of resource %s|%s/%s for SyncTarget %s", upstreamLogicalCluster, namespace, name, c.syncTargetName)
 	return nil
 }
 
+// applyUpstream server-side applies the fields set by setFields on the given upstream object, with the given field manager.
+func (c *Controller) applyUpstream(ctx context.Context, gvr schema.GroupVersionResource, upstreamLogicalCluster logicalcluster.Name, existing *unstructured.Unstructured,
+	setFields func(applied *unstructured.Unstructured) error, fieldManager string, subresources ...string) error {
+	applied := &unstructured.Unstructured{}
+	applied.SetAPIVersion(existing.GetAPIVersion())
+	applied.SetKind(existing.GetKind())
+	applied.SetNamespace(existing.GetNamespace())
+	applied.SetName(existing.GetName())
+	if err := setFields(applied); err != nil {
+		return err
+	}
+	data, err := json.Marshal(applied)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.upstreamClient.Cluster(upstreamLogicalCluster).Resource(gvr).Namespace(existing.GetNamespace()).
+		Patch(ctx, existing.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: fieldManager, Force: pointer.Bool(true)}, subresources...)
+	return err
+}
+
+// fieldManager returns the name of the field manager owning the fields written upstream for the SyncTarget.
+func (c *Controller) fieldManager() string {
+	return syncerApplyManagerPrefix + c.syncTargetName
+}
+
 // TransformName changes the object name into the desired one upstream.
 func transformName(syncedObject *unstructured.Unstructured) {
 	configMapGVR := schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}
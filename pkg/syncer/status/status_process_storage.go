@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+// boundVolumeFieldManagerPrefix is the prefix of the field manager owning the bound volume annotation of
+// the upstream persistent volume claims for a SyncTarget.
+const boundVolumeFieldManagerPrefix = "syncer-bound-volume-"
+
+var (
+	persistentVolumeClaimsGVR = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "persistentvolumeclaims"}
+	persistentVolumesGVR      = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "persistentvolumes"}
+)
+
+// updateBoundVolumeInUpstream publishes the details of the persistent volume the downstream claim is bound to
+// in the experimental.bound-volume.workload.kcp.dev/<sync-target-name> annotation of the upstream claim,
+// and removes the annotation when the downstream claim is not bound.
+func (c *Controller) updateBoundVolumeInUpstream(ctx context.Context, gvr schema.GroupVersionResource, upstreamLogicalCluster logicalcluster.Name, existing, downstreamObj *unstructured.Unstructured) error {
+	annotation := workloadv1alpha1.ClusterBoundVolumeAnnotationPrefix + c.syncTargetName
+
+	boundVolume, err := c.boundVolume(ctx, downstreamObj)
+	if err != nil {
+		return err
+	}
+	var value string
+	if boundVolume != nil {
+		bytes, err := json.Marshal(boundVolume)
+		if err != nil {
+			return err
+		}
+		value = string(bytes)
+	}
+	if existing.GetAnnotations()[annotation] == value {
+		return nil
+	}
+
+	// The annotation is removed by applying without it.
+	if err := c.applyUpstream(ctx, gvr, upstreamLogicalCluster, existing, func(applied *unstructured.Unstructured) error {
+		if boundVolume != nil {
+			applied.SetAnnotations(map[string]string{annotation: value})
+		}
+		return nil
+	}, boundVolumeFieldManagerPrefix+c.syncTargetName); err != nil {
+		klog.Errorf("Failed updating bound volume annotation of resource %s|%s/%s for SyncTarget %s: %v", upstreamLogicalCluster, existing.GetNamespace(), existing.GetName(), c.syncTargetName, err)
+		return err
+	}
+	klog.Infof("Updated bound volume annotation of resource %s|%s/%s for SyncTarget %s", upstreamLogicalCluster, existing.GetNamespace(), existing.GetName(), c.syncTargetName)
+	return nil
+}
+
+// boundVolume returns the details of the persistent volume the downstream claim is bound to, or nil if it is not bound.
+func (c *Controller) boundVolume(ctx context.Context, downstreamObj *unstructured.Unstructured) (*shared.BoundVolume, error) {
+	phase, _, err := unstructured.NestedString(downstreamObj.UnstructuredContent(), "status", "phase")
+	if err != nil {
+		return nil, err
+	}
+	volumeName, _, err := unstructured.NestedString(downstreamObj.UnstructuredContent(), "spec", "volumeName")
+	if err != nil {
+		return nil, err
+	}
+	if phase != string(corev1.ClaimBound) || volumeName == "" {
+		return nil, nil
+	}
+
+	pvObj, err := c.downstreamClient.Resource(persistentVolumesGVR).Get(ctx, volumeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// the claim has lost its volume
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pv corev1.PersistentVolume
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(pvObj.UnstructuredContent(), &pv); err != nil {
+		return nil, err
+	}
+	return shared.NewBoundVolume(&pv), nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+func persistentVolumeClaim(t *testing.T, volumeName string, phase corev1.PersistentVolumeClaimPhase, annotations map[string]string) *unstructured.Unstructured {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&corev1.PersistentVolumeClaim{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "data", Annotations: annotations},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: volumeName},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+	})
+	require.NoError(t, err)
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func TestUpdateBoundVolumeInUpstream(t *testing.T) {
+	boundVolumeAnnotation := workloadv1alpha1.ClusterBoundVolumeAnnotationPrefix + "us-west1"
+	volume := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+		Spec: corev1.PersistentVolumeSpec{
+			StorageClassName:              "gp2",
+			Capacity:                      corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+		},
+	}
+	boundVolumeJSON := `{"volumeName":"pv-1","storageClassName":"gp2","capacity":{"storage":"1Gi"},"accessModes":["ReadWriteOnce"],"reclaimPolicy":"Delete"}`
+
+	tests := map[string]struct {
+		downstreamObj *unstructured.Unstructured
+		upstreamObj   *unstructured.Unstructured
+
+		wantPatch string
+	}{
+		"bound claim": {
+			downstreamObj: persistentVolumeClaim(t, "pv-1", corev1.ClaimBound, nil),
+			upstreamObj:   persistentVolumeClaim(t, "", corev1.ClaimPending, nil),
+			wantPatch:     `{"apiVersion":"v1","kind":"PersistentVolumeClaim","metadata":{"annotations":{"experimental.bound-volume.workload.kcp.dev/us-west1":` + mustMarshalString(t, boundVolumeJSON) + `},"name":"data","namespace":"test"}}`,
+		},
+		"bound claim already published": {
+			downstreamObj: persistentVolumeClaim(t, "pv-1", corev1.ClaimBound, nil),
+			upstreamObj:   persistentVolumeClaim(t, "", corev1.ClaimBound, map[string]string{boundVolumeAnnotation: boundVolumeJSON}),
+		},
+		"pending claim": {
+			downstreamObj: persistentVolumeClaim(t, "", corev1.ClaimPending, nil),
+			upstreamObj:   persistentVolumeClaim(t, "", corev1.ClaimPending, nil),
+		},
+		"claim that lost its volume": {
+			downstreamObj: persistentVolumeClaim(t, "pv-2", corev1.ClaimBound, nil),
+			upstreamObj:   persistentVolumeClaim(t, "", corev1.ClaimBound, map[string]string{boundVolumeAnnotation: boundVolumeJSON}),
+			wantPatch:     `{"apiVersion":"v1","kind":"PersistentVolumeClaim","metadata":{"name":"data","namespace":"test"}}`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			downstreamClient := dynamicfake.NewSimpleDynamicClient(scheme, volume)
+			upstreamClient := dynamicfake.NewSimpleDynamicClient(scheme)
+			setupServersideApplyPatchReactor(upstreamClient)
+
+			c := &Controller{
+				upstreamClient:   &mockedDynamicCluster{client: upstreamClient},
+				downstreamClient: downstreamClient,
+				syncTargetName:   "us-west1",
+			}
+			err := c.updateBoundVolumeInUpstream(context.Background(), persistentVolumeClaimsGVR, logicalcluster.New("root:org:ws"), tc.upstreamObj, tc.downstreamObj)
+			require.NoError(t, err)
+
+			var patches []clienttesting.PatchAction
+			for _, action := range upstreamClient.Actions() {
+				if patch, ok := action.(clienttesting.PatchAction); ok {
+					patches = append(patches, patch)
+				}
+			}
+			if tc.wantPatch == "" {
+				require.Empty(t, patches)
+				return
+			}
+			require.Len(t, patches, 1)
+			require.Equal(t, types.ApplyPatchType, patches[0].GetPatchType())
+			require.JSONEq(t, tc.wantPatch, string(patches[0].GetPatch()))
+		})
+	}
+}
+
+func mustMarshalString(t *testing.T, s string) string {
+	bytes, err := json.Marshal(s)
+	require.NoError(t, err)
+	return string(bytes)
+}
@@ -35,13 +35,14 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/dynamic/fake"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/clusters"
 
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/resourcesync"
+	specmutators "github.com/kcp-dev/kcp/pkg/syncer/spec/mutators"
 )
 
 var scheme *runtime.Scheme
@@ -348,15 +349,13 @@ func TestSyncerProcess(t *testing.T) {
 			expectActionsOnFrom: []clienttesting.Action{},
 			expectActionsOnTo: []clienttesting.Action{
 				getDeploymentAction("theDeployment", "test"),
-				updateDeploymentAction("test",
-					toUnstructured(t, changeDeployment(
-						deployment("theDeployment", "test", "", map[string]string{
-							"state.workload.kcp.dev/us-west1": "Sync",
-						}, nil, nil),
-						addDeploymentStatus(appsv1.DeploymentStatus{
-							Replicas: 15,
-						}))),
-					"status"),
+				patchDeploymentAction(
+					"theDeployment",
+					"test",
+					types.ApplyPatchType,
+					[]byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"theDeployment","namespace":"test"},"status":{"replicas":15}}`),
+					"status",
+				),
 			},
 		},
 		"StatusSyncer upstream deletion": {
@@ -418,13 +417,12 @@ func TestSyncerProcess(t *testing.T) {
 			expectActionsOnFrom: []clienttesting.Action{},
 			expectActionsOnTo: []clienttesting.Action{
 				getDeploymentAction("theDeployment", "test"),
-				updateDeploymentAction("test",
-					toUnstructured(t, changeDeployment(
-						deployment("theDeployment", "test", "root:org:ws", map[string]string{
-							"state.workload.kcp.dev/us-west1": "Sync",
-						}, map[string]string{
-							"experimental.status.workload.kcp.dev/us-west1": "{\"replicas\":15}",
-						}, nil)))),
+				patchDeploymentAction(
+					"theDeployment",
+					"test",
+					types.ApplyPatchType,
+					[]byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"annotations":{"experimental.status.workload.kcp.dev/us-west1":"{\"replicas\":15}"},"name":"theDeployment","namespace":"test"}}`),
+				),
 			},
 		},
 		"StatusSyncer with AdvancedScheduling, deletion: object exists upstream": {
@@ -528,13 +526,6 @@ func TestSyncerProcess(t *testing.T) {
 				client: toClient,
 			}
 
-			fromInformers := dynamicinformer.NewFilteredDynamicSharedInformerFactory(fromClient, time.Hour, metav1.NamespaceAll, func(o *metav1.ListOptions) {
-				o.LabelSelector = workloadv1alpha1.InternalDownstreamClusterLabel + "=" + tc.syncTargetName
-			})
-			toInformers := dynamicinformer.NewFilteredDynamicSharedInformerFactory(toClusterClient.Cluster(logicalcluster.Wildcard), time.Hour, metav1.NamespaceAll, func(o *metav1.ListOptions) {
-				o.LabelSelector = workloadv1alpha1.ClusterResourceStateLabelPrefix + tc.syncTargetName + "=" + string(workloadv1alpha1.ResourceStateSync)
-			})
-
 			setupServersideApplyPatchReactor(toClient)
 			namespaceWatcherStarted := setupWatchReactor("namespaces", fromClient)
 			resourceWatcherStarted := setupWatchReactor(tc.gvr.Resource, fromClient)
@@ -543,14 +534,22 @@ func TestSyncerProcess(t *testing.T) {
 				{Group: "", Version: "v1", Resource: "namespaces"},
 				tc.gvr,
 			}
-			controller, err := NewStatusSyncer(gvrs, kcpLogicalCluster, tc.syncTargetName, tc.advancedSchedulingEnabled, toClusterClient, fromClient, toInformers, fromInformers, syncTargetUID)
-			require.NoError(t, err)
+			syncerInformers := resourcesync.NewSyncerInformerFactory(
+				toClusterClient.Cluster(logicalcluster.Wildcard), func(o *metav1.ListOptions) {
+					o.LabelSelector = workloadv1alpha1.ClusterResourceStateLabelPrefix + tc.syncTargetName + "=" + string(workloadv1alpha1.ResourceStateSync)
+				},
+				fromClient, func(o *metav1.ListOptions) {
+					o.LabelSelector = workloadv1alpha1.InternalDownstreamClusterLabel + "=" + tc.syncTargetName
+				},
+				func(ctx context.Context) ([]schema.GroupVersionResource, error) { return gvrs, nil },
+				time.Hour, time.Hour,
+			)
 
-			fromInformers.Start(ctx.Done())
-			toInformers.Start(ctx.Done())
+			controller, err := NewStatusSyncer(kcpLogicalCluster, tc.syncTargetName, tc.advancedSchedulingEnabled, toClusterClient, fromClient, syncerInformers, specmutators.NewRegistry(), syncTargetUID)
+			require.NoError(t, err)
 
-			fromInformers.WaitForCacheSync(ctx.Done())
-			toInformers.WaitForCacheSync(ctx.Done())
+			require.NoError(t, syncerInformers.Start(ctx))
+			syncerInformers.WaitForCacheSync(ctx.Done())
 
 			<-resourceWatcherStarted
 			<-namespaceWatcherStarted
@@ -687,3 +686,12 @@ func updateDeploymentAction(namespace string, object runtime.Object, subresource
 		Object:     object,
 	}
 }
+
+func patchDeploymentAction(name, namespace string, patchType types.PatchType, patch []byte, subresources ...string) clienttesting.PatchActionImpl {
+	return clienttesting.PatchActionImpl{
+		ActionImpl: deploymentAction("patch", namespace, subresources...),
+		Name:       name,
+		PatchType:  patchType,
+		Patch:      patch,
+	}
+}
@@ -18,6 +18,8 @@ package syncer
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
@@ -25,38 +27,63 @@ import (
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/pkg/version"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
 	"k8s.io/klog/v2"
 
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	kcpexternalversions "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+	workloadinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/dryrun"
+	syncermetrics "github.com/kcp-dev/kcp/pkg/syncer/metrics"
+	"github.com/kcp-dev/kcp/pkg/syncer/namespace"
+	"github.com/kcp-dev/kcp/pkg/syncer/resourcesync"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
 	"github.com/kcp-dev/kcp/pkg/syncer/spec"
+	specmutators "github.com/kcp-dev/kcp/pkg/syncer/spec/mutators"
 	"github.com/kcp-dev/kcp/pkg/syncer/status"
-	"github.com/kcp-dev/kcp/third_party/keyfunctions"
+	"github.com/kcp-dev/kcp/pkg/syncer/upsync"
 )
 
 const (
 	AdvancedSchedulingFeatureAnnotation = "featuregates.experimental.workload.kcp.dev/advancedscheduling"
 
+	// UpsyncResourcesAnnotation is the annotation of a SyncTarget listing, comma-separated in the
+	// <resource>.<group> form, resource types to upsync in addition to the ones configured in the syncer.
+	UpsyncResourcesAnnotation = "experimental.workload.kcp.dev/upsync-resources"
+
 	resyncPeriod = 10 * time.Hour
 
-	// TODO(marun) Coordinate this value with the interval configured for the heartbeat controller
-	heartbeatInterval = 20 * time.Second
+	// defaultHeartbeatInterval is the interval at which the syncer heartbeats until kcp advertises the interval
+	// expected for the SyncTarget in its status.
+	defaultHeartbeatInterval = 20 * time.Second
 
-	// TODO(marun) Ensure backoff rather than using a constant to avoid thundering herds
-	gvrQueryInterval = 1 * time.Second
+	// resourcesDiscoveryInterval is the interval at which the syncer looks for new resource types
+	// to sync in the kcp workspaces. Changes of the SyncTarget synced resources are taken into
+	// account immediately.
+	resourcesDiscoveryInterval = 1 * time.Minute
 )
 
+var namespacesGR = schema.GroupResource{Resource: "namespaces"}
+
 // SyncerConfig defines the syncer configuration that is guaranteed to
 // vary across syncer deployments. Capturing these details in a struct
 // simplifies defining these details in test fixture.
@@ -66,6 +93,29 @@ type SyncerConfig struct {
 	ResourcesToSync     sets.String
 	SyncTargetWorkspace logicalcluster.Name
 	SyncTargetName      string
+
+	// ResourcesToUpsync are the resource types whose objects created downstream, in the namespaces
+	// synced by the syncer, are created and kept up-to-date in the upstream namespaces. They are
+	// synced as well.
+	ResourcesToUpsync sets.String
+
+	// LeaderElection, if set, allows several replicas of the syncer to run for the SyncTarget.
+	// Only the replica holding the lease syncs and heartbeats, while the others keep their
+	// informers warm to take over quickly.
+	LeaderElection *LeaderElectionConfig
+
+	// Mutators are applied to the objects synced downstream, in addition to the built-in ones. A mutator replaces
+	// the built-in one for the same resource type.
+	Mutators []specmutators.Mutator
+
+	// DryRun, if set, runs the spec syncer without writing anything: the objects that would be created,
+	// updated or deleted downstream are sent to the downstream API server in dry-run mode, and logged
+	// as diffs against the current downstream objects. The status syncer, the upsyncer, the API importer
+	// and the heartbeat don't run, so the resources to sync must already be available upstream.
+	DryRun bool
+
+	// InformersSyncedCheck, if set, is a readiness check passing once the informers of the syncers have synced.
+	InformersSyncedCheck *InformersSyncedCheck
 }
 
 func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, importPollInterval time.Duration) error {
@@ -77,32 +127,35 @@ func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, i
 	if err != nil {
 		return err
 	}
+	kcpClient := kcpClusterClient.Cluster(cfg.SyncTargetWorkspace)
+
+	// Without leader election, this syncer is the only replica and is always active.
+	alwaysLeading := make(chan struct{})
+	close(alwaysLeading)
+	var leading <-chan struct{} = alwaysLeading
+	var identity string
+	if cfg.LeaderElection != nil {
+		var stop context.CancelFunc
+		ctx, stop = context.WithCancel(ctx)
+		leading, err = startLeaderElection(ctx, cfg.LeaderElection, cfg.DownstreamConfig, stop)
+		if err != nil {
+			stop()
+			return err
+		}
+		identity = cfg.LeaderElection.Identity
+	}
 
-	// TODO(david): Implement real support for several virtual workspace URLs that can change over time.
-	// TODO(david): For now we retrieve the syncerVirtualWorkpaceURL at start, since we temporarily stick to a single URL (sharding not supported).
-	// TODO(david): But the complete implementation should setup a SyncTarget informer, create spec and status syncer for every URLs found in the
-	// TODO(david): Status.SyncerVirtualWorkspaceURLs slice, and update them each time this list changes.
-	var syncerVirtualWorkspaceURL string
 	// TODO(david): we need to provide user-facing details if this polling goes on forever. Blocking here is a bad UX.
 	// TODO(david): Also, any regressions in our code will make any e2e test that starts a syncer (at least in-process)
 	// TODO(david): block until it hits the 10 minute overall test timeout.
-	klog.Infof("Attempting to retrieve the Syncer virtual workspace URL from SyncTarget %s|%s", cfg.SyncTargetWorkspace, cfg.SyncTargetName)
+	klog.Infof("Attempting to retrieve SyncTarget %s|%s", cfg.SyncTargetWorkspace, cfg.SyncTargetName)
 	var syncTarget *workloadv1alpha1.SyncTarget
 	err = wait.PollImmediateInfinite(5*time.Second, func() (bool, error) {
 		var err error
-		syncTarget, err = kcpClusterClient.Cluster(cfg.SyncTargetWorkspace).WorkloadV1alpha1().SyncTargets().Get(ctx, cfg.SyncTargetName, metav1.GetOptions{})
+		syncTarget, err = kcpClient.WorkloadV1alpha1().SyncTargets().Get(ctx, cfg.SyncTargetName, metav1.GetOptions{})
 		if err != nil {
 			return false, err
 		}
-
-		if len(syncTarget.Status.VirtualWorkspaces) == 0 {
-			return false, nil
-		}
-
-		if len(syncTarget.Status.VirtualWorkspaces) > 1 {
-			klog.Errorf("SyncTarget %s|%s should not have several Syncer virtual workspace URLs: not supported for now, ignoring additional URLs", cfg.SyncTargetWorkspace, cfg.SyncTargetName)
-		}
-		syncerVirtualWorkspaceURL = syncTarget.Status.VirtualWorkspaces[0].URL
 		return true, nil
 	})
 	if err != nil {
@@ -112,7 +165,8 @@ func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, i
 	// Resources are accepted as a set to ensure the provision of a
 	// unique set of resources, but all subsequent consumption is via
 	// slice whose entries are assumed to be unique.
-	resources := cfg.ResourcesToSync.List()
+	upsyncResources := sets.NewString().Union(cfg.ResourcesToUpsync).Union(upsyncResourcesFromAnnotation(syncTarget))
+	resources := sets.NewString().Union(cfg.ResourcesToSync).Union(upsyncResources).List()
 
 	// Start api import first because spec and status syncers are blocked by
 	// gvr discovery finding all the configured resource types in the kcp
@@ -121,7 +175,238 @@ func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, i
 	if err != nil {
 		return err
 	}
-	go apiImporter.Start(ctx, importPollInterval)
+	go func() {
+		if !cfg.DryRun && waitForLeading(ctx, leading) {
+			apiImporter.Start(ctx, importPollInterval)
+		}
+	}()
+
+	// Check whether we're in the Advanced Scheduling feature-gated mode.
+	advancedSchedulingEnabled := false
+	if syncTarget.GetAnnotations()[AdvancedSchedulingFeatureAnnotation] == "true" {
+		klog.Infof("Advanced Scheduling feature is enabled for syncTarget %s", cfg.SyncTargetName)
+		advancedSchedulingEnabled = true
+	}
+
+	upstreamURL, err := url.Parse(cfg.UpstreamConfig.Host)
+	if err != nil {
+		return err
+	}
+
+	// Events are recorded on the upstream objects, in their workspace, as the syncer virtual workspace doesn't serve them.
+	kubeClusterClient, err := kubernetes.NewClusterForConfig(rest.AddUserAgent(rest.CopyConfig(cfg.UpstreamConfig), "kcp#syncer/"+kcpVersion))
+	if err != nil {
+		return err
+	}
+	eventRecorders := newClusterEventRecorders(ctx, func(clusterName logicalcluster.Name) typedcorev1.EventInterface {
+		return kubeClusterClient.Cluster(clusterName).CoreV1().Events("")
+	}, corev1.EventSource{Component: "kcp-syncer", Host: cfg.SyncTargetName}, cfg.DryRun)
+
+	syncTargetInformerFactory := kcpexternalversions.NewSharedInformerFactoryWithOptions(kcpClient, resyncPeriod, kcpexternalversions.WithTweakListOptions(
+		func(listOptions *metav1.ListOptions) {
+			listOptions.FieldSelector = fields.OneTermEqualSelector("metadata.name", cfg.SyncTargetName).String()
+		},
+	))
+	syncTargetInformer := syncTargetInformerFactory.Workload().V1alpha1().SyncTargets()
+
+	// Run a spec and status syncer pair for every syncer virtual workspace URL found in
+	// the SyncTarget status, and follow the changes of this list over time.
+	syncTargetUID := syncTarget.GetUID()
+	var namespaceController *namespace.Controller
+	virtualWorkspaceSyncers := newVirtualWorkspaceSyncers(func(ctx context.Context, syncerVirtualWorkspaceURL string,
+		informersCreated func(*resourcesync.SyncerInformerFactory), informersSynced func([]cache.Indexer)) error {
+		return startSyncersForVirtualWorkspace(ctx, cfg, syncerVirtualWorkspaceURL, upstreamURL, resources, upsyncResources, advancedSchedulingEnabled, syncTargetUID, syncTargetInformer,
+			eventRecorders.recorderFor, namespaceController, leading, numSyncerThreads, informersCreated, informersSynced)
+	})
+	if cfg.InformersSyncedCheck != nil {
+		cfg.InformersSyncedCheck.setSyncers(virtualWorkspaceSyncers)
+	}
+
+	// A single downstream namespace controller runs for all the syncer virtual workspaces, as the downstream
+	// namespaces are shared by all of them, while each one only serves the workspaces of its shard.
+	var downstreamNamespaceInformer informers.GenericInformer
+	if !cfg.DryRun {
+		namespaceDownstreamConfig := rest.AddUserAgent(rest.CopyConfig(cfg.DownstreamConfig), "kcp#spec-syncer/"+kcpVersion)
+		syncermetrics.CountDownstreamErrors(namespaceDownstreamConfig, cfg.SyncTargetName, syncermetrics.DirectionSpec)
+		namespaceDownstreamClient, err := dynamic.NewForConfig(namespaceDownstreamConfig)
+		if err != nil {
+			return err
+		}
+		downstreamNamespaceInformer = dynamicinformer.NewFilteredDynamicInformer(namespaceDownstreamClient, namespacesGR.WithVersion("v1"), metav1.NamespaceAll, resyncPeriod, cache.Indexers{},
+			func(o *metav1.ListOptions) {
+				o.LabelSelector = workloadv1alpha1.InternalDownstreamClusterLabel + "=" + cfg.SyncTargetName
+			})
+		namespaceController, err = namespace.NewNamespaceController(cfg.SyncTargetWorkspace, cfg.SyncTargetName, syncTargetUID,
+			namespaceDownstreamClient, downstreamNamespaceInformer, virtualWorkspaceSyncers.syncedIndexers)
+		if err != nil {
+			return err
+		}
+	}
+
+	syncTargetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			virtualWorkspaceSyncers.update(ctx, syncerVirtualWorkspaceURLs(obj))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			virtualWorkspaceSyncers.update(ctx, syncerVirtualWorkspaceURLs(newObj))
+
+			// Learn about changes of the SyncTarget synced resources as soon as possible.
+			oldSyncTarget, ok := oldObj.(*workloadv1alpha1.SyncTarget)
+			if !ok {
+				return
+			}
+			newSyncTarget, ok := newObj.(*workloadv1alpha1.SyncTarget)
+			if !ok {
+				return
+			}
+			if !syncedResourceNames(oldSyncTarget).Equal(syncedResourceNames(newSyncTarget)) {
+				virtualWorkspaceSyncers.notifyUpdateNeeded()
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			virtualWorkspaceSyncers.update(ctx, nil)
+		},
+	})
+	syncTargetInformerFactory.Start(ctx.Done())
+
+	if cfg.DryRun {
+		klog.Infof("Dry run: not heartbeating SyncTarget %s|%s", cfg.SyncTargetWorkspace, cfg.SyncTargetName)
+		return nil
+	}
+
+	go func() {
+		if !waitForLeading(ctx, leading) {
+			return
+		}
+		go downstreamNamespaceInformer.Informer().Run(ctx.Done())
+		if !cache.WaitForCacheSync(ctx.Done(), downstreamNamespaceInformer.Informer().HasSynced) {
+			return
+		}
+		namespaceController.Start(ctx, numSyncerThreads)
+	}()
+
+	downstreamKubeClient, err := kubernetes.NewForConfig(rest.AddUserAgent(rest.CopyConfig(cfg.DownstreamConfig), "kcp#syncer-capacity/"+kcpVersion))
+	if err != nil {
+		return err
+	}
+	capacityReporter := newCapacityReporter(downstreamKubeClient)
+
+	// Attempt to heartbeat every interval advertised by kcp, once this replica is active. The capacity and the
+	// allocatable resources of the downstream cluster are published along with the heartbeat.
+	go func() {
+		if !waitForLeading(ctx, leading) {
+			return
+		}
+		capacityReporter.Start(ctx)
+		cordoned := syncTarget.Spec.Unschedulable
+		interval := heartbeatInterval(syncTarget)
+		for {
+			var heartbeatTime time.Time
+
+			// TODO(marun) Figure out a strategy for backoff to avoid a thundering herd problem with lots of syncers
+
+			// Attempt to heartbeat every second until successful. Errors are logged instead of being returned so the
+			// poll error can be safely ignored.
+			_ = wait.PollImmediateInfiniteWithContext(ctx, 1*time.Second, func(ctx context.Context) (bool, error) {
+				patch := fmt.Sprintf(`{"op":"replace","path":"/status/lastSyncerHeartbeatTime","value":%q}`, time.Now().Format(time.RFC3339))
+				if identity != "" {
+					patch += fmt.Sprintf(`,{"op":"add","path":"/status/activeSyncer","value":%q}`, identity)
+				}
+				if capacity, allocatable, ok := capacityReporter.Resources(cordoned); ok {
+					capacityBytes, err := json.Marshal(capacity)
+					if err != nil {
+						return false, err
+					}
+					allocatableBytes, err := json.Marshal(allocatable)
+					if err != nil {
+						return false, err
+					}
+					patch += fmt.Sprintf(`,{"op":"add","path":"/status/capacity","value":%s},{"op":"add","path":"/status/allocatable","value":%s}`, capacityBytes, allocatableBytes)
+				}
+				patchBytes := []byte("[" + patch + "]")
+				syncTarget, err := kcpClient.WorkloadV1alpha1().SyncTargets().Patch(ctx, cfg.SyncTargetName, types.JSONPatchType, patchBytes, metav1.PatchOptions{}, "status")
+				if err != nil {
+					klog.Errorf("failed to set status.lastSyncerHeartbeatTime for SyncTarget %s|%s: %v", cfg.SyncTargetWorkspace, cfg.SyncTargetName, err)
+					return false, nil
+				}
+				heartbeatTime = syncTarget.Status.LastSyncerHeartbeatTime.Time
+				cordoned = syncTarget.Spec.Unschedulable
+				if next := heartbeatInterval(syncTarget); next != interval {
+					klog.Infof("Heartbeating SyncTarget %s|%s every %s", cfg.SyncTargetWorkspace, cfg.SyncTargetName, next)
+					interval = next
+				}
+				return true, nil
+			})
+
+			klog.V(5).Infof("Heartbeat set for SyncTarget %s|%s: %s", cfg.SyncTargetWorkspace, cfg.SyncTargetName, heartbeatTime)
+
+			select {
+			case <-ctx.Done():
+				if identity != "" {
+					clearActiveSyncer(kcpClient, cfg.SyncTargetWorkspace, cfg.SyncTargetName, identity)
+				}
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+
+	return nil
+}
+
+// clearActiveSyncer removes the identity of this replica from the SyncTarget status once it stopped being
+// active, unless another replica took over already. Otherwise a replica releasing its lease without any
+// other replica taking over would remain shown as active.
+func clearActiveSyncer(kcpClient kcpclient.Interface, syncTargetWorkspace logicalcluster.Name, syncTargetName, identity string) {
+	// The syncer is shutting down, so the patch gets its own deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	patch := fmt.Sprintf(`[{"op":"test","path":"/status/activeSyncer","value":%q},{"op":"remove","path":"/status/activeSyncer"}]`, identity)
+	if _, err := kcpClient.WorkloadV1alpha1().SyncTargets().Patch(ctx, syncTargetName, types.JSONPatchType, []byte(patch), metav1.PatchOptions{}, "status"); err != nil {
+		// A failed test means another replica is active already.
+		klog.V(2).Infof("Not clearing the active syncer replica %s of SyncTarget %s|%s: %v", identity, syncTargetWorkspace, syncTargetName, err)
+		return
+	}
+	klog.Infof("Cleared the active syncer replica %s of SyncTarget %s|%s", identity, syncTargetWorkspace, syncTargetName)
+}
+
+// heartbeatInterval returns the heartbeat interval advertised by kcp in the status of the SyncTarget.
+func heartbeatInterval(syncTarget *workloadv1alpha1.SyncTarget) time.Duration {
+	if interval := syncTarget.Status.HeartbeatInterval; interval != nil && interval.Duration > 0 {
+		return interval.Duration
+	}
+	return defaultHeartbeatInterval
+}
+
+// syncerVirtualWorkspaceURLs returns the syncer virtual workspace URLs published in the status
+// of the given SyncTarget.
+func syncerVirtualWorkspaceURLs(obj interface{}) []string {
+	syncTarget, ok := obj.(*workloadv1alpha1.SyncTarget)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("expected a SyncTarget, but got %T", obj))
+		return nil
+	}
+	urls := make([]string, 0, len(syncTarget.Status.VirtualWorkspaces))
+	for _, virtualWorkspace := range syncTarget.Status.VirtualWorkspaces {
+		urls = append(urls, virtualWorkspace.URL)
+	}
+	return urls
+}
+
+// startSyncersForVirtualWorkspace starts the spec and status syncers, and the upsyncer if some resources are
+// upsynced, syncing through the given syncer virtual workspace URL. The events of their informers are fed to
+// the given downstream namespace controller, if any.
+// It blocks until the configured resource types have been discovered through the virtual workspace,
+// the informers have synced, and this syncer replica is active.
+// informersCreated is called with the informers before they are started, and informersSynced once they have
+// synced. The syncers run until ctx is done.
+// Only the spec syncer runs in dry-run mode.
+func startSyncersForVirtualWorkspace(ctx context.Context, cfg *SyncerConfig, syncerVirtualWorkspaceURL string, upstreamURL *url.URL, resources []string, upsyncResources sets.String,
+	advancedSchedulingEnabled bool, syncTargetUID types.UID, syncTargetInformer workloadinformers.SyncTargetInformer, eventRecorderForCluster shared.EventRecorderForCluster,
+	namespaceController *namespace.Controller, leading <-chan struct{}, numSyncerThreads int,
+	informersCreated func(*resourcesync.SyncerInformerFactory), informersSynced func([]cache.Indexer)) error {
+	kcpVersion := version.Get().GitVersion
 
 	upstreamConfig := rest.CopyConfig(cfg.UpstreamConfig)
 	upstreamConfig.Host = syncerVirtualWorkspaceURL
@@ -137,104 +422,211 @@ func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, i
 	if err != nil {
 		return err
 	}
+	// The downstream requests of the spec and status syncers are instrumented separately, to count
+	// the downstream API errors per direction. Informers report their own errors.
+	specDownstreamConfig := rest.CopyConfig(downstreamConfig)
+	syncermetrics.CountDownstreamErrors(specDownstreamConfig, cfg.SyncTargetName, syncermetrics.DirectionSpec)
+	specDownstreamDynamicClient, err := dynamic.NewForConfig(specDownstreamConfig)
+	if err != nil {
+		return err
+	}
+	statusDownstreamConfig := rest.CopyConfig(downstreamConfig)
+	syncermetrics.CountDownstreamErrors(statusDownstreamConfig, cfg.SyncTargetName, syncermetrics.DirectionStatus)
+	statusDownstreamDynamicClient, err := dynamic.NewForConfig(statusDownstreamConfig)
+	if err != nil {
+		return err
+	}
+	// In dry-run mode, the spec syncer writes nothing upstream, and only dry-run requests downstream.
+	var specUpstreamClusterClient dynamic.ClusterInterface = upstreamDynamicClusterClient
+	var specDownstreamClient dynamic.Interface = specDownstreamDynamicClient
+	if cfg.DryRun {
+		specUpstreamClusterClient = dryrun.NewUpstreamClusterClient(upstreamDynamicClusterClient)
+		specDownstreamClient = dryrun.NewDownstreamClient(specDownstreamDynamicClient)
+	}
 	upstreamDiscoveryClusterClient, err := discovery.NewDiscoveryClientForConfig(upstreamConfig)
 	if err != nil {
 		return err
 	}
 	upstreamDiscoveryClient := upstreamDiscoveryClusterClient.WithCluster(logicalcluster.Wildcard)
 
-	upstreamInformers := dynamicinformer.NewFilteredDynamicSharedInformerFactory(upstreamDynamicClusterClient.Cluster(logicalcluster.Wildcard), resyncPeriod, metav1.NamespaceAll, func(o *metav1.ListOptions) {
-		o.LabelSelector = workloadv1alpha1.ClusterResourceStateLabelPrefix + cfg.SyncTargetName + "=" + string(workloadv1alpha1.ResourceStateSync)
-	})
-	downstreamInformers := dynamicinformer.NewFilteredDynamicSharedInformerFactoryWithOptions(downstreamDynamicClient, metav1.NamespaceAll, func(o *metav1.ListOptions) {
-		o.LabelSelector = workloadv1alpha1.InternalDownstreamClusterLabel + "=" + cfg.SyncTargetName
-	}, cache.WithResyncPeriod(resyncPeriod), cache.WithKeyFunction(keyfunctions.DeletionHandlingMetaNamespaceKeyFunc))
-
-	// TODO(ncdc): we need to provide user-facing details if this polling goes on forever. Blocking here is a bad UX.
-	// TODO(ncdc): Also, any regressions in our code will make any e2e test that starts a syncer (at least in-process)
-	// TODO(ncdc): block until it hits the 10 minute overall test timeout.
-	//
-	// Block syncer start on gvr discovery completing successfully and
-	// including the resources configured for syncing. The spec and status
-	// syncers depend on the types being present to start their informers.
-	var gvrs []schema.GroupVersionResource
-	err = wait.PollImmediateInfinite(gvrQueryInterval, func() (bool, error) {
-		klog.Infof("Attempting to retrieve GVRs from upstream...")
+	// The resource types to sync are the configured ones, which must all be found upstream,
+	// and the ones listed in the SyncTarget synced resources, as soon as they are found upstream.
+	gvrSource := func(ctx context.Context) ([]schema.GroupVersionResource, error) {
+		klog.V(2).Infof("Attempting to retrieve GVRs from upstream virtual workspace %s...", syncerVirtualWorkspaceURL)
 
-		var err error
-		// Get all types the upstream API server knows about.
-		// TODO: watch this and learn about new types, or forget about old ones.
-		gvrs, err = getAllGVRs(upstreamDiscoveryClient, resources...)
-		// TODO(marun) Should some of these errors be fatal?
-		if err != nil {
-			klog.Errorf("Failed to retrieve GVRs from kcp: %v", err)
-			return false, nil
+		syncedResources := sets.NewString()
+		syncTarget, err := syncTargetInformer.Lister().Get(clusters.ToClusterAwareKey(cfg.SyncTargetWorkspace, cfg.SyncTargetName))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
 		}
-		return true, nil
-	})
+		if err == nil {
+			syncedResources = syncedResourceNames(syncTarget)
+		}
+
+		return getAllGVRs(upstreamDiscoveryClient, sets.NewString(resources...), syncedResources)
+	}
+
+	syncerInformers := resourcesync.NewSyncerInformerFactory(
+		upstreamDynamicClusterClient.Cluster(logicalcluster.Wildcard), func(o *metav1.ListOptions) {
+			o.LabelSelector = workloadv1alpha1.ClusterResourceStateLabelPrefix + cfg.SyncTargetName + "=" + string(workloadv1alpha1.ResourceStateSync)
+		},
+		downstreamDynamicClient, func(o *metav1.ListOptions) {
+			o.LabelSelector = workloadv1alpha1.InternalDownstreamClusterLabel + "=" + cfg.SyncTargetName
+		},
+		gvrSource, resyncPeriod, resourcesDiscoveryInterval,
+	)
+	informersCreated(syncerInformers)
+
+	// The storage classes of the persistent volume claims are mapped according to the SyncTarget, unless a
+	// configured mutator replaces the built-in one.
+	pvcMutator := specmutators.NewPersistentVolumeClaimMutator(storageClassMapping(cfg, syncTargetInformer))
+	mutators := spec.NewMutatorRegistry(upstreamURL, syncerInformers, append([]specmutators.Mutator{pvcMutator}, cfg.Mutators...)...)
+
+	klog.Infof("Creating spec syncer for SyncTarget %s|%s, virtual workspace %s, resources %v", cfg.SyncTargetWorkspace, cfg.SyncTargetName, syncerVirtualWorkspaceURL, resources)
+	specSyncer, err := spec.NewSpecSyncer(cfg.SyncTargetWorkspace, cfg.SyncTargetName, advancedSchedulingEnabled,
+		specUpstreamClusterClient, specDownstreamClient, syncerInformers, mutators, syncTargetUID, eventRecorderForCluster,
+		namespaceNamer(cfg, syncTargetInformer))
 	if err != nil {
-		// Should never happen
 		return err
 	}
 
-	// Check whether we're in the Advanced Scheduling feature-gated mode.
-	advancedSchedulingEnabled := false
-	if syncTarget.GetAnnotations()[AdvancedSchedulingFeatureAnnotation] == "true" {
-		klog.Infof("Advanced Scheduling feature is enabled for syncTarget %s", cfg.SyncTargetName)
-		advancedSchedulingEnabled = true
+	var statusSyncer *status.Controller
+	if !cfg.DryRun {
+		klog.Infof("Creating status syncer for SyncTarget %s|%s, virtual workspace %s, resources %v", cfg.SyncTargetWorkspace, cfg.SyncTargetName, syncerVirtualWorkspaceURL, resources)
+		statusSyncer, err = status.NewStatusSyncer(cfg.SyncTargetWorkspace, cfg.SyncTargetName, advancedSchedulingEnabled,
+			upstreamDynamicClusterClient, statusDownstreamDynamicClient, syncerInformers, mutators, syncTargetUID)
+		if err != nil {
+			return err
+		}
 	}
 
-	klog.Infof("Creating spec syncer for SyncTarget %s|%s, resources %v", cfg.SyncTargetWorkspace, cfg.SyncTargetName, resources)
-	upstreamURL, err := url.Parse(cfg.UpstreamConfig.Host)
-	if err != nil {
-		return err
+	if namespaceController != nil {
+		namespaceController.AddSyncerInformers(syncerInformers)
 	}
-	specSyncer, err := spec.NewSpecSyncer(gvrs, cfg.SyncTargetWorkspace, cfg.SyncTargetName, upstreamURL, advancedSchedulingEnabled,
-		upstreamDynamicClusterClient, downstreamDynamicClient, upstreamInformers, downstreamInformers, syncTarget.GetUID())
-	if err != nil {
+
+	// Block syncer start on gvr discovery completing successfully and
+	// including the resources configured for syncing.
+	if err := syncerInformers.Start(ctx); err != nil {
 		return err
 	}
+	syncerInformers.WaitForCacheSync(ctx.Done())
+
+	// The upsynced resource types are part of the resources to sync, which have all been discovered at this point.
+	var upSyncer *upsync.Controller
+	var upsyncedIndexers []cache.Indexer
+	if upsyncGVRs := filterGVRs(syncerInformers.SyncedGVRs(), upsyncResources); len(upsyncGVRs) > 0 && !cfg.DryRun {
+		klog.Infof("Creating upsyncer for SyncTarget %s|%s, virtual workspace %s, resources %v", cfg.SyncTargetWorkspace, cfg.SyncTargetName, syncerVirtualWorkspaceURL, upsyncResources.List())
+		upsyncInformers := dynamicinformer.NewDynamicSharedInformerFactory(downstreamDynamicClient, resyncPeriod)
+		upSyncer, err = upsync.NewUpSyncer(cfg.SyncTargetWorkspace, cfg.SyncTargetName, syncTargetUID,
+			upstreamDynamicClusterClient, upsyncInformers, syncerInformers.DownstreamNamespaceInformer().Lister(), upsyncGVRs)
+		if err != nil {
+			return err
+		}
+		if namespaceController != nil {
+			// The upsynced objects are not synced by the spec syncer, but still hold their downstream namespace.
+			upsyncIndexInformers := make([]cache.SharedIndexInformer, 0, len(upsyncGVRs))
+			for _, gvr := range upsyncGVRs {
+				upsyncIndexInformers = append(upsyncIndexInformers, upsyncInformers.ForResource(gvr).Informer())
+				upsyncedIndexers = append(upsyncedIndexers, upsyncInformers.ForResource(gvr).Informer().GetIndexer())
+			}
+			namespaceController.AddUpsyncedInformers(upsyncIndexInformers...)
+		}
+		upsyncInformers.Start(ctx.Done())
+		upsyncInformers.WaitForCacheSync(ctx.Done())
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	informersSynced(upsyncedIndexers)
 
-	klog.Infof("Creating status syncer for SyncTarget %s|%s, resources %v", cfg.SyncTargetWorkspace, cfg.SyncTargetName, resources)
-	statusSyncer, err := status.NewStatusSyncer(gvrs, cfg.SyncTargetWorkspace, cfg.SyncTargetName, advancedSchedulingEnabled,
-		upstreamDynamicClusterClient, downstreamDynamicClient, upstreamInformers, downstreamInformers, syncTarget.GetUID())
-	if err != nil {
-		return err
+	// Standby replicas keep their informers warm, but only the active replica syncs.
+	if !waitForLeading(ctx, leading) {
+		return nil
 	}
 
-	upstreamInformers.Start(ctx.Done())
-	downstreamInformers.Start(ctx.Done())
+	go specSyncer.Start(ctx, numSyncerThreads)
+	if statusSyncer != nil {
+		go statusSyncer.Start(ctx, numSyncerThreads)
+	}
+	if upSyncer != nil {
+		go upSyncer.Start(ctx, numSyncerThreads)
+	}
+	return nil
+}
 
-	upstreamInformers.WaitForCacheSync(ctx.Done())
-	downstreamInformers.WaitForCacheSync(ctx.Done())
+// syncedResourceNames returns the names, in the <resource>.<group> form, of the resources listed in the
+// SyncTarget synced resources, apart from the ones known to be incompatible.
+func syncedResourceNames(syncTarget *workloadv1alpha1.SyncTarget) sets.String {
+	names := sets.NewString()
+	for _, syncedResource := range syncTarget.Status.SyncedResources {
+		if syncedResource.State == workloadv1alpha1.ResourceSchemaIncomptibleState {
+			continue
+		}
+		names.Insert(schema.GroupResource{Group: syncedResource.Group, Resource: syncedResource.Resource}.String())
+	}
+	return names
+}
 
-	go specSyncer.Start(ctx, numSyncerThreads)
-	go statusSyncer.Start(ctx, numSyncerThreads)
-
-	// Attempt to heartbeat every interval
-	go wait.UntilWithContext(ctx, func(ctx context.Context) {
-		var heartbeatTime time.Time
-
-		// TODO(marun) Figure out a strategy for backoff to avoid a thundering herd problem with lots of syncers
-
-		// Attempt to heartbeat every second until successful. Errors are logged instead of being returned so the
-		// poll error can be safely ignored.
-		_ = wait.PollImmediateInfiniteWithContext(ctx, 1*time.Second, func(ctx context.Context) (bool, error) {
-			patchBytes := []byte(fmt.Sprintf(`[{"op":"replace","path":"/status/lastSyncerHeartbeatTime","value":%q}]`, time.Now().Format(time.RFC3339)))
-			syncTarget, err := kcpClusterClient.Cluster(cfg.SyncTargetWorkspace).WorkloadV1alpha1().SyncTargets().Patch(ctx, cfg.SyncTargetName, types.JSONPatchType, patchBytes, metav1.PatchOptions{}, "status")
-			if err != nil {
-				klog.Errorf("failed to set status.lastSyncerHeartbeatTime for SyncTarget %s|%s: %v", cfg.SyncTargetWorkspace, cfg.SyncTargetName, err)
-				return false, nil
-			}
-			heartbeatTime = syncTarget.Status.LastSyncerHeartbeatTime.Time
-			return true, nil
-		})
+// upsyncResourcesFromAnnotation returns the resource types listed in the upsync resources annotation of the SyncTarget.
+func upsyncResourcesFromAnnotation(syncTarget *workloadv1alpha1.SyncTarget) sets.String {
+	resources := sets.NewString()
+	for _, resource := range strings.Split(syncTarget.GetAnnotations()[UpsyncResourcesAnnotation], ",") {
+		if resource = strings.TrimSpace(resource); resource != "" {
+			resources.Insert(resource)
+		}
+	}
+	return resources
+}
 
-		klog.V(5).Infof("Heartbeat set for SyncTarget %s|%s: %s", cfg.SyncTargetWorkspace, cfg.SyncTargetName, heartbeatTime)
+// storageClassMapping returns a function returning the storage class mapping currently found in the
+// experimental.workload.kcp.dev/storage-class-mapping annotation of the SyncTarget.
+func storageClassMapping(cfg *SyncerConfig, syncTargetInformer workloadinformers.SyncTargetInformer) specmutators.StorageClassMappingFunc {
+	return func() map[string]string {
+		syncTarget, err := syncTargetInformer.Lister().Get(clusters.ToClusterAwareKey(cfg.SyncTargetWorkspace, cfg.SyncTargetName))
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				utilruntime.HandleError(err)
+			}
+			return nil
+		}
+		mapping, err := shared.ParseStorageClassMapping(syncTarget.GetAnnotations()[workloadv1alpha1.StorageClassMappingAnnotation])
+		if err != nil {
+			klog.Errorf("Ignoring the storage class mapping of SyncTarget %s|%s: %v", cfg.SyncTargetWorkspace, cfg.SyncTargetName, err)
+			return nil
+		}
+		return mapping
+	}
+}
 
-	}, heartbeatInterval)
+// namespaceNamer returns the namespace naming strategy currently set on the SyncTarget, falling back to the
+// hash strategy if it is invalid.
+func namespaceNamer(cfg *SyncerConfig, syncTargetInformer workloadinformers.SyncTargetInformer) shared.NamespaceNamerFunc {
+	return func() *shared.NamespaceNamer {
+		syncTarget, err := syncTargetInformer.Lister().Get(clusters.ToClusterAwareKey(cfg.SyncTargetWorkspace, cfg.SyncTargetName))
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				utilruntime.HandleError(err)
+			}
+			return nil
+		}
+		namer, err := shared.ParseNamespaceNaming(syncTarget.GetAnnotations()[workloadv1alpha1.NamespaceNamingAnnotation])
+		if err != nil {
+			klog.Errorf("Ignoring the namespace naming of SyncTarget %s|%s: %v", cfg.SyncTargetWorkspace, cfg.SyncTargetName, err)
+			return nil
+		}
+		return namer
+	}
+}
 
-	return nil
+// filterGVRs returns the GroupVersionResources whose resource name, or <resource>.<group> name, is in the given set.
+func filterGVRs(gvrs []schema.GroupVersionResource, resources sets.String) []schema.GroupVersionResource {
+	var filtered []schema.GroupVersionResource
+	for _, gvr := range gvrs {
+		if resources.Has(gvr.GroupResource().String()) || resources.Has(gvr.Resource) {
+			filtered = append(filtered, gvr)
+		}
+	}
+	return filtered
 }
 
 func contains(ss []string, s string) bool {
@@ -246,8 +638,13 @@ func contains(ss []string, s string) bool {
 	return false
 }
 
-func getAllGVRs(discoveryClient discovery.DiscoveryInterface, resourcesToSync ...string) ([]schema.GroupVersionResource, error) {
-	toSyncSet := sets.NewString(resourcesToSync...)
+// getAllGVRs returns the GroupVersionResources of the given resources found through discovery. All the
+// resourcesToSync must be found, while the optionalResourcesToSync are ignored until they are found.
+func getAllGVRs(discoveryClient discovery.DiscoveryInterface, resourcesToSync, optionalResourcesToSync sets.String) ([]schema.GroupVersionResource, error) {
+	toSyncSet := resourcesToSync
+	if toSyncSet.Has(namespacesGR.String()) {
+		return nil, errors.New("namespaces cannot be synced: downstream namespaces are created for the synced namespaced resources")
+	}
 	willBeSyncedSet := sets.NewString()
 	rs, err := discoveryClient.ServerPreferredResources()
 	if err != nil {
@@ -287,6 +684,8 @@ func getAllGVRs(discoveryClient discovery.DiscoveryInterface, resourcesToSync ..
 				willBeSynced = groupResource.String()
 			} else if toSyncSet.Has(ai.Name) {
 				willBeSynced = ai.Name
+			} else if optionalResourcesToSync.Has(groupResource.String()) {
+				willBeSynced = groupResource.String()
 			} else {
 				// We're not interested in this resource type
 				continue
@@ -295,8 +694,10 @@ func getAllGVRs(discoveryClient discovery.DiscoveryInterface, resourcesToSync ..
 				// foo/status, pods/exec, namespace/finalize, etc.
 				continue
 			}
-			if !ai.Namespaced {
-				// Ignore cluster-scoped things.
+			if groupResource == namespacesGR {
+				// Namespaces are created downstream for the synced namespaced resources. They can only be
+				// found among the optional resources, required ones being rejected above.
+				klog.V(4).Infof("Not syncing %s found among the SyncTarget synced resources", groupResource)
 				continue
 			}
 			if !contains(ai.Verbs, "watch") {
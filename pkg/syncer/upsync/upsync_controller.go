@@ -0,0 +1,185 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upsync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	syncermetrics "github.com/kcp-dev/kcp/pkg/syncer/metrics"
+	"github.com/kcp-dev/kcp/third_party/keyfunctions"
+)
+
+const (
+	controllerName = "kcp-workload-syncer-upsync"
+
+	// upsyncFieldManager is the field manager of the upstream objects created by the upsyncer.
+	upsyncFieldManager = "syncer-upsync"
+)
+
+// Controller creates and updates objects in the upstream workspace namespaces from the objects
+// created downstream in the namespaces synced by the syncer, for the resource types configured
+// to be upsynced. The upstream objects are labeled with the "Upsync" state for the SyncTarget,
+// which makes the workload controllers and the spec syncer ignore them.
+type Controller struct {
+	queue   workqueue.RateLimitingInterface
+	metrics *syncermetrics.ControllerMetrics
+
+	upstreamClient            dynamic.ClusterInterface
+	downstreamNamespaceLister cache.GenericLister
+	downstreamIndexers        map[schema.GroupVersionResource]cache.Indexer
+
+	syncTargetName      string
+	syncTargetWorkspace logicalcluster.Name
+	syncTargetUID       types.UID
+}
+
+// NewUpSyncer returns an upsyncer for the given resource types. The downstream informers of these
+// resource types are registered in downstreamInformers, which must be started by the caller.
+// Downstream objects are not filtered by label, as they are not created by the syncer.
+func NewUpSyncer(syncTargetWorkspace logicalcluster.Name, syncTargetName string, syncTargetUID types.UID,
+	upstreamClient dynamic.ClusterInterface, downstreamInformers dynamicinformer.DynamicSharedInformerFactory,
+	downstreamNamespaceLister cache.GenericLister, gvrs []schema.GroupVersionResource) (*Controller, error) {
+
+	c := &Controller{
+		queue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+		metrics: syncermetrics.NewControllerMetrics(syncTargetName, syncermetrics.DirectionUpsync),
+
+		upstreamClient:            upstreamClient,
+		downstreamNamespaceLister: downstreamNamespaceLister,
+		downstreamIndexers:        map[schema.GroupVersionResource]cache.Indexer{},
+
+		syncTargetName:      syncTargetName,
+		syncTargetWorkspace: syncTargetWorkspace,
+		syncTargetUID:       syncTargetUID,
+	}
+
+	for _, gvr := range gvrs {
+		gvr := gvr
+		informer := downstreamInformers.ForResource(gvr)
+		informer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: isDownstreamOwned,
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { c.AddToQueue(gvr, obj) },
+				UpdateFunc: func(_, obj interface{}) { c.AddToQueue(gvr, obj) },
+				DeleteFunc: func(obj interface{}) { c.AddToQueue(gvr, obj) },
+			},
+		})
+		c.downstreamIndexers[gvr] = informer.Informer().GetIndexer()
+	}
+	klog.InfoS("Set up downstream upsync event handlers", "SyncTarget Workspace", syncTargetWorkspace, "SyncTarget Name", syncTargetName, "resources", gvrs)
+
+	return c, nil
+}
+
+// isDownstreamOwned returns true if the downstream object has not been synced from upstream by the spec syncer.
+func isDownstreamOwned(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	unstrob, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+	_, synced := unstrob.GetLabels()[workloadv1alpha1.InternalDownstreamClusterLabel]
+	return !synced
+}
+
+type queueKey struct {
+	gvr schema.GroupVersionResource
+	key string // meta namespace key
+}
+
+func (c *Controller) AddToQueue(gvr schema.GroupVersionResource, obj interface{}) {
+	key, err := keyfunctions.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	klog.V(2).Infof("%s queueing GVR %q %s", controllerName, gvr.String(), key)
+	c.metrics.Enqueued(gvr, key)
+	c.queue.Add(
+		queueKey{
+			gvr: gvr,
+			key: key,
+		},
+	)
+}
+
+// Start starts N worker processes processing work items.
+func (c *Controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.InfoS("Starting syncer workers", "controller", controllerName)
+	defer klog.InfoS("Stopping syncer workers", "controller", controllerName)
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+// startWorker processes work items until stopCh is closed.
+func (c *Controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *Controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	qk := key.(queueKey)
+	c.metrics.Dequeued(qk.gvr, qk.key)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	start := time.Now()
+	err := c.process(ctx, qk.gvr, qk.key)
+	c.metrics.Synced(qk.gvr, start)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("%s failed to sync %q, err: %w", controllerName, key, err))
+		c.metrics.Retried(qk.gvr, qk.key)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+
+	c.queue.Forget(key)
+
+	return true
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upsync
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/klog/v2"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResource, key string) error {
+	klog.V(3).InfoS("Processing", "gvr", gvr, "key", key)
+
+	// from downstream
+	downstreamNamespace, clusterAwareName, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		klog.Errorf("Invalid key: %q: %v", key, err)
+		return nil
+	}
+	if downstreamNamespace == "" {
+		// only namespaced resources are upsynced
+		return nil
+	}
+	downstreamClusterName, name := clusters.SplitClusterAwareKey(clusterAwareName)
+
+	// to upstream
+	nsKey := downstreamNamespace
+	if !downstreamClusterName.Empty() {
+		// If our "physical" cluster is a kcp instance (e.g. for testing purposes), it will return resources
+		// with metadata.clusterName set, which means their keys are cluster-aware, so we need to do the same here.
+		nsKey = clusters.ToClusterAwareKey(downstreamClusterName, nsKey)
+	}
+	nsObj, err := c.downstreamNamespaceLister.Get(nsKey)
+	if apierrors.IsNotFound(err) {
+		// not a namespace synced by this syncer
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	nsMeta, ok := nsObj.(metav1.Object)
+	if !ok {
+		klog.Errorf("Namespace %q expected to be metav1.Object, got %T", nsKey, nsObj)
+		return nil
+	}
+	namespaceLocator, exists, err := shared.LocatorFromAnnotations(nsMeta.GetAnnotations())
+	if err != nil {
+		klog.Errorf("Namespace %q: error decoding annotation: %v", nsKey, err)
+		return nil
+	}
+	if !exists || namespaceLocator == nil || namespaceLocator.SyncTarget.UID != c.syncTargetUID {
+		// Only upsync resources to the workspaces of the configured SyncTarget.
+		return nil
+	}
+	upstreamNamespace := namespaceLocator.Namespace
+	upstreamClient := c.upstreamClient.Cluster(namespaceLocator.Workspace).Resource(gvr).Namespace(upstreamNamespace)
+
+	// get the downstream object
+	downstreamIndexer, ok := c.downstreamIndexers[gvr]
+	if !ok {
+		klog.V(3).Infof("GVR %q is not upsynced, skipping %s", gvr.String(), key)
+		return nil
+	}
+	obj, exists, err := downstreamIndexer.GetByKey(key)
+	if err != nil {
+		return err
+	}
+	var downstreamObj *unstructured.Unstructured
+	if exists {
+		downstreamObj, ok = obj.(*unstructured.Unstructured)
+		if !ok {
+			klog.Errorf("Downstream object %s expected to be *unstructured.Unstructured, got %T", key, obj)
+			return nil
+		}
+	}
+
+	existing, err := upstreamClient.Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err != nil {
+		existing = nil
+	}
+	if existing != nil && !c.isUpsynced(existing) {
+		// The upstream object is owned by the workspace, and must not be overwritten.
+		klog.V(4).Infof("Upstream GVR %q object %s|%s/%s is not upsynced from SyncTarget %s, skipping", gvr.String(), namespaceLocator.Workspace, upstreamNamespace, name, c.syncTargetName)
+		return nil
+	}
+
+	if downstreamObj == nil || downstreamObj.GetDeletionTimestamp() != nil || !isDownstreamOwned(downstreamObj) {
+		if existing == nil {
+			return nil
+		}
+		klog.V(2).Infof("Deleting upsynced GVR %q object %s|%s/%s", gvr.String(), namespaceLocator.Workspace, upstreamNamespace, name)
+		uid := existing.GetUID()
+		err := upstreamClient.Delete(ctx, name, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid}})
+		if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+			return nil
+		}
+		return err
+	}
+
+	upstreamObj := c.toUpstream(downstreamObj, upstreamNamespace)
+	if existing == nil {
+		klog.V(2).Infof("Creating upsynced GVR %q object %s|%s/%s", gvr.String(), namespaceLocator.Workspace, upstreamNamespace, name)
+		existing, err = upstreamClient.Create(ctx, upstreamObj, metav1.CreateOptions{FieldManager: upsyncFieldManager})
+		if err != nil {
+			return err
+		}
+	} else if !equalIgnoringStatus(existing, upstreamObj) {
+		klog.V(2).Infof("Updating upsynced GVR %q object %s|%s/%s", gvr.String(), namespaceLocator.Workspace, upstreamNamespace, name)
+		upstreamObj.SetResourceVersion(existing.GetResourceVersion())
+		existing, err = upstreamClient.Update(ctx, upstreamObj, metav1.UpdateOptions{FieldManager: upsyncFieldManager})
+		if err != nil {
+			return err
+		}
+	}
+
+	// The status is persisted separately when the resource has a status subresource.
+	status, hasStatus := upstreamObj.UnstructuredContent()["status"]
+	if !hasStatus || equality.Semantic.DeepEqual(existing.UnstructuredContent()["status"], status) {
+		return nil
+	}
+	klog.V(2).Infof("Updating the status of upsynced GVR %q object %s|%s/%s", gvr.String(), namespaceLocator.Workspace, upstreamNamespace, name)
+	existing = existing.DeepCopy()
+	existing.UnstructuredContent()["status"] = status
+	_, err = upstreamClient.UpdateStatus(ctx, existing, metav1.UpdateOptions{FieldManager: upsyncFieldManager})
+	return err
+}
+
+// isUpsynced returns true if the upstream object is in the Upsync state for this SyncTarget.
+func (c *Controller) isUpsynced(upstreamObj *unstructured.Unstructured) bool {
+	return upstreamObj.GetLabels()[workloadv1alpha1.ClusterResourceStateLabelPrefix+c.syncTargetName] == string(workloadv1alpha1.ResourceStateUpsync)
+}
+
+// toUpstream returns the upstream object of the downstream object, without the metadata that is
+// specific to the downstream cluster, and in the Upsync state for this SyncTarget.
+func (c *Controller) toUpstream(downstreamObj *unstructured.Unstructured, upstreamNamespace string) *unstructured.Unstructured {
+	upstreamObj := downstreamObj.DeepCopy()
+	upstreamObj.SetNamespace(upstreamNamespace)
+	upstreamObj.SetZZZ_DeprecatedClusterName("")
+	upstreamObj.SetUID("")
+	upstreamObj.SetResourceVersion("")
+	upstreamObj.SetGeneration(0)
+	upstreamObj.SetSelfLink("")
+	upstreamObj.SetCreationTimestamp(metav1.Time{})
+	upstreamObj.SetDeletionTimestamp(nil)
+	upstreamObj.SetDeletionGracePeriodSeconds(nil)
+	upstreamObj.SetManagedFields(nil)
+	upstreamObj.SetOwnerReferences(nil)
+	upstreamObj.SetFinalizers(nil)
+
+	labels := upstreamObj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[workloadv1alpha1.ClusterResourceStateLabelPrefix+c.syncTargetName] = string(workloadv1alpha1.ResourceStateUpsync)
+	upstreamObj.SetLabels(labels)
+
+	return upstreamObj
+}
+
+// equalIgnoringStatus returns true if the existing upstream object has the labels, annotations
+// and content of the desired upstream object, apart from the status.
+func equalIgnoringStatus(existing, desired *unstructured.Unstructured) bool {
+	if !equality.Semantic.DeepEqual(existing.GetLabels(), desired.GetLabels()) ||
+		!equality.Semantic.DeepEqual(existing.GetAnnotations(), desired.GetAnnotations()) {
+		return false
+	}
+	existingContent := make(map[string]interface{}, len(existing.UnstructuredContent()))
+	for k, v := range existing.UnstructuredContent() {
+		if k != "metadata" && k != "status" {
+			existingContent[k] = v
+		}
+	}
+	desiredContent := make(map[string]interface{}, len(desired.UnstructuredContent()))
+	for k, v := range desired.UnstructuredContent() {
+		if k != "metadata" && k != "status" {
+			desiredContent[k] = v
+		}
+	}
+	return equality.Semantic.DeepEqual(existingContent, desiredContent)
+}
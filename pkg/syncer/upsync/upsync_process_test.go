@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upsync
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+var configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+type mockedDynamicCluster struct {
+	client *dynamicfake.FakeDynamicClient
+}
+
+func (mdc *mockedDynamicCluster) Cluster(name logicalcluster.Name) dynamic.Interface {
+	return mdc.client
+}
+
+func downstreamNamespace(t *testing.T, name string, syncTargetUID types.UID) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(name)
+	if syncTargetUID != "" {
+		locator := shared.NewNamespaceLocator(logicalcluster.New("root:org:ws"), logicalcluster.New("root:org:ws"), syncTargetUID, "us-west1", "test")
+		locatorJSON, err := json.Marshal(locator)
+		require.NoError(t, err)
+		ns.SetAnnotations(map[string]string{shared.NamespaceLocatorAnnotation: string(locatorJSON)})
+	}
+	return ns
+}
+
+func configMap(namespace string, labels map[string]string, data map[string]interface{}) *unstructured.Unstructured {
+	cm := &unstructured.Unstructured{Object: map[string]interface{}{"data": data}}
+	cm.SetAPIVersion("v1")
+	cm.SetKind("ConfigMap")
+	cm.SetNamespace(namespace)
+	cm.SetName("generated")
+	cm.SetLabels(labels)
+	return cm
+}
+
+func upsyncedLabels() map[string]string {
+	return map[string]string{workloadv1alpha1.ClusterResourceStateLabelPrefix + "us-west1": string(workloadv1alpha1.ResourceStateUpsync)}
+}
+
+func TestUpsyncProcess(t *testing.T) {
+	tests := map[string]struct {
+		downstreamNamespace *unstructured.Unstructured
+		downstreamObject    *unstructured.Unstructured
+		upstreamObject      *unstructured.Unstructured
+
+		wantUpstreamObject *unstructured.Unstructured
+	}{
+		"created upstream": {
+			downstreamNamespace: downstreamNamespace(t, "kcp-abc", "syncTargetUID"),
+			downstreamObject:    configMap("kcp-abc", map[string]string{"app": "operator"}, map[string]interface{}{"foo": "bar"}),
+			wantUpstreamObject: configMap("test", map[string]string{
+				"app": "operator",
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "us-west1": "Upsync",
+			}, map[string]interface{}{"foo": "bar"}),
+		},
+		"updated upstream": {
+			downstreamNamespace: downstreamNamespace(t, "kcp-abc", "syncTargetUID"),
+			downstreamObject:    configMap("kcp-abc", nil, map[string]interface{}{"foo": "baz"}),
+			upstreamObject:      configMap("test", upsyncedLabels(), map[string]interface{}{"foo": "bar"}),
+			wantUpstreamObject:  configMap("test", upsyncedLabels(), map[string]interface{}{"foo": "baz"}),
+		},
+		"deleted upstream when deleted downstream": {
+			downstreamNamespace: downstreamNamespace(t, "kcp-abc", "syncTargetUID"),
+			upstreamObject:      configMap("test", upsyncedLabels(), map[string]interface{}{"foo": "bar"}),
+		},
+		"upstream object owned by the workspace is not overwritten": {
+			downstreamNamespace: downstreamNamespace(t, "kcp-abc", "syncTargetUID"),
+			downstreamObject:    configMap("kcp-abc", nil, map[string]interface{}{"foo": "baz"}),
+			upstreamObject:      configMap("test", map[string]string{workloadv1alpha1.ClusterResourceStateLabelPrefix + "us-west1": "Sync"}, map[string]interface{}{"foo": "bar"}),
+			wantUpstreamObject:  configMap("test", map[string]string{workloadv1alpha1.ClusterResourceStateLabelPrefix + "us-west1": "Sync"}, map[string]interface{}{"foo": "bar"}),
+		},
+		"upstream object owned by the workspace is not deleted": {
+			downstreamNamespace: downstreamNamespace(t, "kcp-abc", "syncTargetUID"),
+			upstreamObject:      configMap("test", nil, map[string]interface{}{"foo": "bar"}),
+			wantUpstreamObject:  configMap("test", nil, map[string]interface{}{"foo": "bar"}),
+		},
+		"object synced by the spec syncer is not upsynced": {
+			downstreamNamespace: downstreamNamespace(t, "kcp-abc", "syncTargetUID"),
+			downstreamObject:    configMap("kcp-abc", map[string]string{workloadv1alpha1.InternalDownstreamClusterLabel: "us-west1"}, map[string]interface{}{"foo": "bar"}),
+		},
+		"namespace without locator": {
+			downstreamNamespace: downstreamNamespace(t, "kcp-abc", ""),
+			downstreamObject:    configMap("kcp-abc", nil, map[string]interface{}{"foo": "bar"}),
+		},
+		"namespace of another SyncTarget": {
+			downstreamNamespace: downstreamNamespace(t, "kcp-abc", "otherUID"),
+			downstreamObject:    configMap("kcp-abc", nil, map[string]interface{}{"foo": "bar"}),
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, corev1.AddToScheme(scheme))
+
+			var upstreamObjects []runtime.Object
+			if tc.upstreamObject != nil {
+				upstreamObjects = append(upstreamObjects, tc.upstreamObject)
+			}
+			upstreamClient := dynamicfake.NewSimpleDynamicClient(scheme, upstreamObjects...)
+
+			namespaceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			require.NoError(t, namespaceIndexer.Add(tc.downstreamNamespace))
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if tc.downstreamObject != nil {
+				require.NoError(t, configMapIndexer.Add(tc.downstreamObject))
+			}
+
+			c := &Controller{
+				upstreamClient:            &mockedDynamicCluster{client: upstreamClient},
+				downstreamNamespaceLister: cache.NewGenericLister(namespaceIndexer, corev1.Resource("namespaces")),
+				downstreamIndexers:        map[schema.GroupVersionResource]cache.Indexer{configMapsGVR: configMapIndexer},
+				syncTargetName:            "us-west1",
+				syncTargetWorkspace:       logicalcluster.New("root:org:ws"),
+				syncTargetUID:             "syncTargetUID",
+			}
+
+			err := c.process(context.Background(), configMapsGVR, "kcp-abc/generated")
+			require.NoError(t, err)
+
+			got, err := upstreamClient.Resource(configMapsGVR).Namespace("test").Get(context.Background(), "generated", metav1.GetOptions{})
+			if tc.wantUpstreamObject == nil {
+				require.True(t, apierrors.IsNotFound(err), "expected no upstream object, got %v", got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantUpstreamObject.GetLabels(), got.GetLabels())
+			require.Equal(t, tc.wantUpstreamObject.Object["data"], got.Object["data"])
+		})
+	}
+}
+
+func TestToUpstream(t *testing.T) {
+	c := &Controller{syncTargetName: "us-west1"}
+
+	downstreamObj := configMap("kcp-abc", nil, map[string]interface{}{"foo": "bar"})
+	downstreamObj.SetUID("uid")
+	downstreamObj.SetResourceVersion("42")
+	downstreamObj.SetFinalizers([]string{"operator"})
+	downstreamObj.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "v1", Kind: "Pod", Name: "operator", UID: "pod-uid"}})
+
+	upstreamObj := c.toUpstream(downstreamObj, "test")
+	require.Equal(t, "test", upstreamObj.GetNamespace())
+	require.Empty(t, upstreamObj.GetUID())
+	require.Empty(t, upstreamObj.GetResourceVersion())
+	require.Empty(t, upstreamObj.GetFinalizers())
+	require.Empty(t, upstreamObj.GetOwnerReferences())
+	require.Equal(t, upsyncedLabels(), upstreamObj.GetLabels())
+	require.Equal(t, "kcp-abc", downstreamObj.GetNamespace(), "the downstream object must not be mutated")
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/resourcesync"
+)
+
+// startSyncersFunc starts the syncers for a single syncer virtual workspace URL. The syncers
+// are expected to stop when the given context is done. informersCreated is called with the informers
+// of the syncers before they are started, and informersSynced once they have synced, with the
+// downstream indexers of the upsynced resource types.
+type startSyncersFunc func(ctx context.Context, syncerVirtualWorkspaceURL string,
+	informersCreated func(syncerInformers *resourcesync.SyncerInformerFactory), informersSynced func(upsyncedIndexers []cache.Indexer)) error
+
+// virtualWorkspaceSyncers keeps one set of syncers running per syncer virtual workspace URL.
+// Syncers are started for URLs that appear, and stopped for URLs that disappear.
+type virtualWorkspaceSyncers struct {
+	lock sync.Mutex
+	// desired are the syncer virtual workspace URLs of the SyncTarget, including the ones whose
+	// syncers failed to start.
+	desired sets.String
+	running map[string]*runningSyncers
+
+	startSyncers startSyncersFunc
+}
+
+type runningSyncers struct {
+	cancel          context.CancelFunc
+	informersSynced bool
+
+	// syncerInformers is set once the informers have been created, and upsyncedIndexers once they have synced.
+	syncerInformers  *resourcesync.SyncerInformerFactory
+	upsyncedIndexers []cache.Indexer
+}
+
+func newVirtualWorkspaceSyncers(startSyncers startSyncersFunc) *virtualWorkspaceSyncers {
+	return &virtualWorkspaceSyncers{
+		desired:      sets.NewString(),
+		running:      map[string]*runningSyncers{},
+		startSyncers: startSyncers,
+	}
+}
+
+// update reconciles the running syncers with the given list of syncer virtual workspace URLs.
+// It doesn't block: syncers are started asynchronously. If starting the syncers for a URL fails,
+// the URL is forgotten so that the next update retries.
+func (s *virtualWorkspaceSyncers) update(ctx context.Context, syncerVirtualWorkspaceURLs []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	desired := sets.NewString(syncerVirtualWorkspaceURLs...)
+	s.desired = desired
+
+	for url, running := range s.running {
+		if desired.Has(url) {
+			continue
+		}
+		klog.Infof("Stopping syncers for virtual workspace %s", url)
+		running.cancel()
+		delete(s.running, url)
+	}
+
+	for _, url := range desired.List() {
+		if _, found := s.running[url]; found {
+			continue
+		}
+
+		klog.Infof("Starting syncers for virtual workspace %s", url)
+		syncersCtx, cancel := context.WithCancel(ctx)
+		running := &runningSyncers{cancel: cancel}
+		s.running[url] = running
+
+		go func(url string) {
+			informersCreated := func(syncerInformers *resourcesync.SyncerInformerFactory) {
+				s.setInformersCreated(running, syncerInformers)
+			}
+			informersSynced := func(upsyncedIndexers []cache.Indexer) {
+				s.setInformersSynced(running, upsyncedIndexers)
+			}
+			if err := s.startSyncers(syncersCtx, url, informersCreated, informersSynced); err != nil {
+				utilruntime.HandleError(fmt.Errorf("failed to start syncers for virtual workspace %s: %w", url, err))
+				s.forget(url, running)
+			}
+		}(url)
+	}
+}
+
+// forget stops and removes the given running syncers, unless they have already been replaced.
+func (s *virtualWorkspaceSyncers) forget(url string, running *runningSyncers) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	running.cancel()
+	if s.running[url] == running {
+		delete(s.running, url)
+	}
+}
+
+// urls returns the syncer virtual workspace URLs for which syncers are currently running.
+func (s *virtualWorkspaceSyncers) urls() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return sets.StringKeySet(s.running).List()
+}
+
+// setInformersSynced records that the informers of the given running syncers have synced.
+func (s *virtualWorkspaceSyncers) setInformersSynced(running *runningSyncers, upsyncedIndexers []cache.Indexer) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	running.informersSynced = true
+	running.upsyncedIndexers = upsyncedIndexers
+}
+
+// setInformersCreated records the informers of the given running syncers.
+func (s *virtualWorkspaceSyncers) setInformersCreated(running *runningSyncers, syncerInformers *resourcesync.SyncerInformerFactory) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	running.syncerInformers = syncerInformers
+}
+
+// notifyUpdateNeeded requests the informers of all the running syncers to recalculate the set of synced
+// resource types.
+func (s *virtualWorkspaceSyncers) notifyUpdateNeeded() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, running := range s.running {
+		if running.syncerInformers != nil {
+			running.syncerInformers.NotifyUpdateNeeded()
+		}
+	}
+}
+
+// informersSynced returns an error unless syncers are running for at least one syncer virtual
+// workspace URL, and their informers have synced for all the URLs.
+func (s *virtualWorkspaceSyncers) informersSynced() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.running) == 0 {
+		return errors.New("no syncer virtual workspace to sync with")
+	}
+	var notSynced []string
+	for url, running := range s.running {
+		if !running.informersSynced {
+			notSynced = append(notSynced, url)
+		}
+	}
+	if len(notSynced) > 0 {
+		sort.Strings(notSynced)
+		return fmt.Errorf("informers not synced for syncer virtual workspaces %v", notSynced)
+	}
+	return nil
+}
+
+// syncedIndexers returns the upstream and downstream indexers of the resource types synced through all the
+// syncer virtual workspaces, along with the downstream indexers of the upsynced resource types. It returns
+// false unless the informers have synced for all the syncer virtual workspace URLs of the SyncTarget, as
+// each syncer virtual workspace only serves the workspaces of its shard.
+func (s *virtualWorkspaceSyncers) syncedIndexers() (upstream, downstream []cache.Indexer, synced bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.desired) == 0 {
+		return nil, nil, false
+	}
+	for url := range s.desired {
+		running, found := s.running[url]
+		if !found || !running.informersSynced {
+			return nil, nil, false
+		}
+		if running.syncerInformers != nil {
+			for _, gvr := range running.syncerInformers.SyncedGVRs() {
+				upstreamInformer, ok := running.syncerInformers.UpstreamInformer(gvr)
+				if !ok {
+					continue
+				}
+				downstreamInformer, ok := running.syncerInformers.DownstreamInformer(gvr)
+				if !ok {
+					continue
+				}
+				if !upstreamInformer.Informer().HasSynced() || !downstreamInformer.Informer().HasSynced() {
+					return nil, nil, false
+				}
+				upstream = append(upstream, upstreamInformer.Informer().GetIndexer())
+				downstream = append(downstream, downstreamInformer.Informer().GetIndexer())
+			}
+		}
+		downstream = append(downstream, running.upsyncedIndexers...)
+	}
+	return upstream, downstream, true
+}
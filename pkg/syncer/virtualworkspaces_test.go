@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/resourcesync"
+)
+
+type fakeSyncers struct {
+	lock     sync.Mutex
+	contexts map[string][]context.Context
+	fail     map[string]bool
+	unsynced map[string]bool
+}
+
+func (f *fakeSyncers) start(ctx context.Context, url string, informersCreated func(*resourcesync.SyncerInformerFactory), informersSynced func([]cache.Indexer)) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.contexts[url] = append(f.contexts[url], ctx)
+	if f.fail[url] {
+		return errors.New("failed")
+	}
+	if !f.unsynced[url] {
+		informersSynced([]cache.Indexer{cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})})
+	}
+	return nil
+}
+
+func (f *fakeSyncers) started(url string) []context.Context {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.contexts[url]
+}
+
+func TestVirtualWorkspaceSyncers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fake := &fakeSyncers{contexts: map[string][]context.Context{}, fail: map[string]bool{"https://failing": true}, unsynced: map[string]bool{"https://syncing": true}}
+	syncers := newVirtualWorkspaceSyncers(fake.start)
+	require.Error(t, syncers.informersSynced(), "not ready without syncers")
+	_, _, synced := syncers.syncedIndexers()
+	require.False(t, synced, "no indexers without syncers")
+
+	syncers.update(ctx, []string{"https://shard-1"})
+	require.Eventually(t, func() bool { return len(fake.started("https://shard-1")) == 1 }, wait.ForeverTestTimeout, 10*time.Millisecond)
+	require.Equal(t, []string{"https://shard-1"}, syncers.urls())
+	require.Eventually(t, func() bool { return syncers.informersSynced() == nil }, wait.ForeverTestTimeout, 10*time.Millisecond)
+
+	// Adding a URL starts a new set of syncers, and leaves the existing ones untouched.
+	syncers.update(ctx, []string{"https://shard-1", "https://shard-2"})
+	require.Eventually(t, func() bool { return len(fake.started("https://shard-2")) == 1 }, wait.ForeverTestTimeout, 10*time.Millisecond)
+	require.Len(t, fake.started("https://shard-1"), 1)
+	require.NoError(t, fake.started("https://shard-1")[0].Err())
+	require.Equal(t, []string{"https://shard-1", "https://shard-2"}, syncers.urls())
+	require.Eventually(t, func() bool {
+		_, downstream, synced := syncers.syncedIndexers()
+		return synced && len(downstream) == 2
+	}, wait.ForeverTestTimeout, 10*time.Millisecond, "indexers of all the syncer virtual workspaces")
+
+	// Removing a URL stops the corresponding syncers.
+	syncers.update(ctx, []string{"https://shard-2"})
+	require.Error(t, fake.started("https://shard-1")[0].Err())
+	require.NoError(t, fake.started("https://shard-2")[0].Err())
+	require.Equal(t, []string{"https://shard-2"}, syncers.urls())
+
+	// Syncers that fail to start are forgotten, so that they get restarted on the next update.
+	syncers.update(ctx, []string{"https://shard-2", "https://failing"})
+	require.Eventually(t, func() bool { return len(syncers.urls()) == 1 }, wait.ForeverTestTimeout, 10*time.Millisecond)
+	require.Error(t, fake.started("https://failing")[0].Err())
+	_, _, synced = syncers.syncedIndexers()
+	require.False(t, synced, "no indexers while the syncers of a syncer virtual workspace are not running")
+	syncers.update(ctx, []string{"https://shard-2", "https://failing"})
+	require.Eventually(t, func() bool { return len(fake.started("https://failing")) == 2 }, wait.ForeverTestTimeout, 10*time.Millisecond)
+
+	// Syncers whose informers haven't synced make the syncer not ready.
+	syncers.update(ctx, []string{"https://shard-2", "https://syncing"})
+	require.Eventually(t, func() bool { return len(fake.started("https://syncing")) == 1 }, wait.ForeverTestTimeout, 10*time.Millisecond)
+	require.EqualError(t, syncers.informersSynced(), "informers not synced for syncer virtual workspaces [https://syncing]")
+	_, _, synced = syncers.syncedIndexers()
+	require.False(t, synced, "no indexers while the informers of a syncer virtual workspace have not synced")
+
+	// Removing all the URLs stops all the syncers.
+	syncers.update(ctx, nil)
+	require.Error(t, fake.started("https://shard-2")[0].Err())
+	require.Empty(t, syncers.urls())
+	require.Error(t, syncers.informersSynced())
+}
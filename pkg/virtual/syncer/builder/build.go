@@ -25,6 +25,8 @@ import (
 	"github.com/kcp-dev/logicalcluster/v2"
 
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	genericapiserver "k8s.io/apiserver/pkg/server"
@@ -158,13 +160,19 @@ func BuildVirtualWorkspace(
 				wildcardKcpInformers.Apis().V1alpha1().APIResourceSchemas(),
 				wildcardKcpInformers.Apis().V1alpha1().APIExports(),
 				func(syncTargetName string, apiResourceSchema *apisv1alpha1.APIResourceSchema, version string, apiExportIdentityHash string) (apidefinition.APIDefinition, error) {
-					requirements, selectable := labels.SelectorFromSet(map[string]string{
-						workloadv1alpha1.ClusterResourceStateLabelPrefix + syncTargetName: string(workloadv1alpha1.ResourceStateSync),
-					}).Requirements()
-					if !selectable {
-						return nil, fmt.Errorf("unable to create a selector from the provided labels")
+					// The syncer sees the resources synced to the SyncTarget, and the ones it upsyncs from the SyncTarget.
+					requirement, err := labels.NewRequirement(workloadv1alpha1.ClusterResourceStateLabelPrefix+syncTargetName, selection.In, []string{
+						string(workloadv1alpha1.ResourceStateSync),
+						string(workloadv1alpha1.ResourceStateUpsync),
+					})
+					if err != nil {
+						return nil, fmt.Errorf("unable to create a selector for the SyncTarget resources: %w", err)
+					}
+					withLabelSelector := forwardingregistry.WithStaticLabelSelector(labels.Requirements{*requirement})
+					withUpsync := withUpsyncedCreateAndDelete(syncTargetName)
+					storageWrapper := func(resource schema.GroupResource, storage *forwardingregistry.StoreFuncs) *forwardingregistry.StoreFuncs {
+						return withUpsync(resource, withLabelSelector(resource, storage))
 					}
-					storageWrapper := forwardingregistry.WithStaticLabelSelector(requirements)
 
 					ctx, cancelFn := context.WithCancel(context.Background())
 					storageBuilder := NewStorageBuilder(ctx, dynamicClusterClient, apiExportIdentityHash, storageWrapper)
@@ -106,6 +106,8 @@ func NewStorageBuilder(ctx context.Context, clusterClient dynamic.ClusterInterfa
 			registry.DestroyerFunc
 
 			registry.GetterFunc
+			registry.CreaterFunc
+			registry.GracefulDeleterFunc
 			registry.ListerFunc
 			registry.UpdaterFunc
 			registry.WatcherFunc
@@ -118,10 +120,12 @@ func NewStorageBuilder(ctx context.Context, clusterClient dynamic.ClusterInterfa
 			ListFactoryFunc: storage.ListFactoryFunc,
 			DestroyerFunc:   storage.DestroyerFunc,
 
-			GetterFunc:  storage.GetterFunc,
-			ListerFunc:  storage.ListerFunc,
-			UpdaterFunc: storage.UpdaterFunc,
-			WatcherFunc: storage.WatcherFunc,
+			GetterFunc:          storage.GetterFunc,
+			CreaterFunc:         storage.CreaterFunc,
+			GracefulDeleterFunc: storage.GracefulDeleterFunc,
+			ListerFunc:          storage.ListerFunc,
+			UpdaterFunc:         storage.UpdaterFunc,
+			WatcherFunc:         storage.WatcherFunc,
 
 			TableConvertorFunc:      storage.TableConvertorFunc,
 			CategoriesProviderFunc:  storage.CategoriesProviderFunc,
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/forwardingregistry"
+)
+
+// withUpsyncedCreateAndDelete restricts creations and deletions to the resources in the
+// "Upsync" state for the given SyncTarget, which the syncer owns. Resources in the "Sync"
+// state are owned by the workspace and cannot be created or deleted by the syncer.
+func withUpsyncedCreateAndDelete(syncTargetName string) forwardingregistry.StorageWrapper {
+	isUpsynced := func(obj runtime.Object) (bool, error) {
+		metaObj, err := meta.Accessor(obj)
+		if err != nil {
+			return false, fmt.Errorf("expected a metav1.Object, got %T", obj)
+		}
+		return metaObj.GetLabels()[workloadv1alpha1.ClusterResourceStateLabelPrefix+syncTargetName] == string(workloadv1alpha1.ResourceStateUpsync), nil
+	}
+
+	return func(resource schema.GroupResource, storage *forwardingregistry.StoreFuncs) *forwardingregistry.StoreFuncs {
+		delegateCreater := storage.CreaterFunc
+		storage.CreaterFunc = func(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+			upsynced, err := isUpsynced(obj)
+			if err != nil {
+				return nil, err
+			}
+			if !upsynced {
+				return nil, errors.NewForbidden(resource, "", fmt.Errorf("only resources in the %q state can be created", workloadv1alpha1.ResourceStateUpsync))
+			}
+			return delegateCreater.Create(ctx, obj, createValidation, options)
+		}
+
+		delegateDeleter := storage.GracefulDeleterFunc
+		storage.GracefulDeleterFunc = func(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+			obj, err := storage.GetterFunc.Get(ctx, name, &metav1.GetOptions{})
+			if err != nil {
+				return nil, false, err
+			}
+			upsynced, err := isUpsynced(obj)
+			if err != nil {
+				return nil, false, err
+			}
+			if !upsynced {
+				return nil, false, errors.NewForbidden(resource, name, fmt.Errorf("only resources in the %q state can be deleted", workloadv1alpha1.ResourceStateUpsync))
+			}
+			return delegateDeleter.Delete(ctx, name, deleteValidation, options)
+		}
+
+		return storage
+	}
+}